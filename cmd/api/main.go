@@ -75,7 +75,9 @@ func main() {
 	hConfig := handler.Config{
 		JWTSecret:        cfg.JWTSecret,
 		WebhookURL:       cfg.Telegram.WebhookURL,
+		WebhookSecret:    cfg.Telegram.WebhookSecret,
 		TelegramBotToken: cfg.Telegram.BotToken,
+		TelegramAPIURL:   cfg.Telegram.APIURL,
 		AdminBotToken:    cfg.Telegram.AdminBotToken,
 		AssetsURL:        cfg.AssetsURL,
 		AdminChatID:      cfg.Telegram.AdminChatID,
@@ -83,6 +85,9 @@ func main() {
 		WebAppURL:        cfg.Telegram.WebAppURL,
 		BotWebApp:        cfg.Telegram.BotWebApp,
 		ImageServiceURL:  cfg.ImageServiceURL,
+		RateLimitStore:   cfg.RateLimit.Store,
+		RateLimits:       cfg.RateLimit.Routes,
+		RepostWindow:     time.Duration(cfg.RepostWindow) * 24 * time.Hour,
 	}
 
 	s3Client, err := s3.NewClient(
@@ -92,7 +97,12 @@ func main() {
 		cfg.AWSConfig.Bucket,
 	)
 
-	bot, err := telegram.New(cfg.Telegram.BotToken)
+	var botOptions []telegram.Option
+	if cfg.Telegram.APIURL != "" {
+		botOptions = append(botOptions, telegram.WithServerURL(cfg.Telegram.APIURL))
+	}
+
+	bot, err := telegram.New(cfg.Telegram.BotToken, botOptions...)
 	if err != nil {
 		log.Fatalf("failed to create telegram bot: %v", err)
 	}
@@ -108,17 +118,32 @@ func main() {
 		TestNotification: cfg.Telegram.TestNotification,
 	}
 
-	notifier := notification.NewNotifier(notifierConfig, bot)
+	adminBot, err := telegram.New(cfg.Telegram.AdminBotToken, botOptions...)
+	if err != nil {
+		log.Fatalf("failed to create telegram admin bot: %v", err)
+	}
+
+	notifier := notification.NewNotifier(notifierConfig, bot, adminBot)
 
 	embeddingService := embedding.New(cfg.OpenAIAPIKey)
-	h := handler.New(storage, hConfig, s3Client, logr, bot, notifier, embeddingService)
+	h := handler.New(storage, hConfig, s3Client, logr, bot, adminBot, notifier, embeddingService)
 
-	if err := h.SetupWebhook(context.Background()); err != nil {
+	if cfg.Telegram.UsePolling {
+		go func() {
+			if err := h.StartPolling(context.Background()); err != nil {
+				log.Fatalf("failed to start polling: %v", err)
+			}
+		}()
+	} else if err := h.SetupWebhook(context.Background()); err != nil {
 		log.Fatalf("failed to setup webhook: %v", err)
 	}
 
 	h.SetupRoutes(e)
 
+	if err := h.ResumeBroadcasts(context.Background()); err != nil {
+		log.Printf("failed to resume broadcasts: %v", err)
+	}
+
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
 	go gracefulShutdown(e, logr)
@@ -242,7 +242,8 @@ func getCollaborationsWithoutEmbeddings(ctx context.Context, storage *db.Storage
 	query := `
 		SELECT c.id, c.user_id, c.title, c.description, c.is_payable,
 		       c.created_at, c.updated_at, c.hidden_at, c.location, 
-		       c.links, c.badges, c.opportunity, c.verification_status, c.verified_at
+		       c.links, c.badges, c.opportunity, c.verification_status, c.verified_at,
+		       c.compensation
 		FROM collaborations c
 		LEFT JOIN collaboration_embeddings ce ON c.id = ce.collaboration_id
 		WHERE ce.collaboration_id IS NULL
@@ -264,12 +265,13 @@ func getCollaborationsWithoutEmbeddings(ctx context.Context, storage *db.Storage
 	var collabs []db.Collaboration
 	for rows.Next() {
 		var collab db.Collaboration
-		var locationJSON, linksJSON, badgesJSON, opportunityJSON sql.NullString
+		var locationJSON, linksJSON, badgesJSON, opportunityJSON, compensationJSON sql.NullString
 
 		err := rows.Scan(
 			&collab.ID, &collab.UserID, &collab.Title, &collab.Description, &collab.IsPayable,
 			&collab.CreatedAt, &collab.UpdatedAt, &collab.HiddenAt, &locationJSON,
 			&linksJSON, &badgesJSON, &opportunityJSON, &collab.VerificationStatus, &collab.VerifiedAt,
+			&compensationJSON,
 		)
 		if err != nil {
 			return nil, err
@@ -291,6 +293,9 @@ func getCollaborationsWithoutEmbeddings(ctx context.Context, storage *db.Storage
 		if opportunityJSON.Valid && opportunityJSON.String != "" {
 			json.Unmarshal([]byte(opportunityJSON.String), &collab.Opportunity)
 		}
+		if compensationJSON.Valid && compensationJSON.String != "" {
+			json.Unmarshal([]byte(compensationJSON.String), &collab.Compensation)
+		}
 
 		collabs = append(collabs, collab)
 	}
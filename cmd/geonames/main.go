@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/peatch-io/peatch/internal/db"
+)
+
+// Columns of the GeoNames cities*.txt dumps, see https://download.geonames.org/export/dump/
+const (
+	colGeonameID   = 0
+	colName        = 1
+	colASCIIName   = 2
+	colLatitude    = 4
+	colLongitude   = 5
+	colCountryCode = 8
+	colPopulation  = 14
+	cityColumns    = 19
+)
+
+// Columns of alternateNamesV2.txt, the older alternateNames.txt lacks the
+// last two
+const (
+	colAltGeonameID      = 1
+	colAltLanguage       = 2
+	colAltName           = 3
+	colAltColloquial     = 6
+	colAltHistoric       = 7
+	alternateNameColumns = 8
+)
+
+// alternateNameLanguages are the languages cities are searched in. The
+// alternate names column of the cities dumps doesn't say which language a
+// name is in, so they come from the alternate names dump instead.
+var alternateNameLanguages = map[string]bool{"en": true, "ru": true}
+
+// Columns of countryInfo.txt
+const (
+	colCountryISO  = 0
+	colCountryName = 4
+)
+
+func main() {
+	var (
+		dbPath        = flag.String("db", "data.sqlite", "Path to SQLite database")
+		citiesPath    = flag.String("cities", "", "Path to a GeoNames cities dump, e.g. cities15000.txt")
+		countriesPath = flag.String("countries", "", "Path to the GeoNames countryInfo.txt")
+		altNamesPath  = flag.String("alternate-names", "", "Path to the GeoNames alternateNamesV2.txt, for English and Russian names")
+		minPopulation = flag.Int64("min-population", 0, "Skip cities with fewer inhabitants")
+		batchSize     = flag.Int("batch", 1000, "Number of cities to upsert per transaction")
+	)
+	flag.Parse()
+
+	if *citiesPath == "" || *countriesPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	countries, err := readCountries(*countriesPath)
+	if err != nil {
+		log.Fatalf("Failed to read countries: %v", err)
+	}
+	log.Printf("Loaded %d countries", len(countries))
+
+	storage, err := db.NewStorage(*dbPath)
+	if err != nil {
+		log.Fatalf("Failed to create storage: %v", err)
+	}
+	defer storage.DB().Close()
+
+	if err := storage.InitSchema(); err != nil {
+		log.Fatalf("Failed to initialize schema: %v", err)
+	}
+
+	var records []db.CityRecord
+	skipped := 0
+
+	err = readCities(*citiesPath, func(fields []string) error {
+		record, err := parseCity(fields, countries)
+		if err != nil {
+			return err
+		}
+
+		if record.Population < *minPopulation {
+			skipped++
+			return nil
+		}
+
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Failed to read cities: %v", err)
+	}
+	log.Printf("Read %d cities", len(records))
+
+	if *altNamesPath != "" {
+		byID := make(map[string]*db.CityRecord, len(records))
+		for i := range records {
+			byID[records[i].ID] = &records[i]
+		}
+
+		err := readAlternateNames(*altNamesPath, func(geonameID, name string) {
+			if record, ok := byID[geonameID]; ok {
+				addAlternateName(record, name)
+			}
+		})
+		if err != nil {
+			log.Fatalf("Failed to read alternate names: %v", err)
+		}
+	}
+
+	ctx := context.Background()
+
+	var total db.CityImportStats
+	for batch := range slices.Chunk(records, max(*batchSize, 1)) {
+		stats, err := storage.UpsertCities(ctx, batch)
+		if err != nil {
+			log.Fatalf("Failed to import cities: %v", err)
+		}
+
+		total.Inserted += stats.Inserted
+		total.Updated += stats.Updated
+		total.Unchanged += stats.Unchanged
+		total.Users += stats.Users
+		total.Collaborations += stats.Collaborations
+
+		log.Printf("Processed %d cities", total.Inserted+total.Updated+total.Unchanged)
+	}
+
+	log.Printf("Done: %d inserted, %d updated, %d unchanged, %d skipped",
+		total.Inserted, total.Updated, total.Unchanged, skipped)
+	log.Printf("Refreshed the locations of %d users and %d collaborations", total.Users, total.Collaborations)
+}
+
+// readCountries maps ISO country codes to country names
+func readCountries(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	countries := make(map[string]string)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) <= colCountryName {
+			return nil, fmt.Errorf("malformed country line: %q", line)
+		}
+
+		countries[fields[colCountryISO]] = fields[colCountryName]
+	}
+
+	return countries, scanner.Err()
+}
+
+func readCities(path string, fn func(fields []string) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	// Alternate names of big cities make for long lines
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != cityColumns {
+			return fmt.Errorf("line %d: expected %d columns, got %d", lineNo, cityColumns, len(fields))
+		}
+
+		if err := fn(fields); err != nil {
+			return fmt.Errorf("line %d: %w", lineNo, err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+func parseCity(fields []string, countries map[string]string) (db.CityRecord, error) {
+	latitude, err := strconv.ParseFloat(fields[colLatitude], 64)
+	if err != nil {
+		return db.CityRecord{}, fmt.Errorf("invalid latitude: %w", err)
+	}
+
+	longitude, err := strconv.ParseFloat(fields[colLongitude], 64)
+	if err != nil {
+		return db.CityRecord{}, fmt.Errorf("invalid longitude: %w", err)
+	}
+
+	var population int64
+	if fields[colPopulation] != "" {
+		population, err = strconv.ParseInt(fields[colPopulation], 10, 64)
+		if err != nil {
+			return db.CityRecord{}, fmt.Errorf("invalid population: %w", err)
+		}
+	}
+
+	countryCode := fields[colCountryCode]
+	countryName, ok := countries[countryCode]
+	if !ok {
+		countryName = countryCode
+	}
+
+	record := db.CityRecord{
+		City: db.City{
+			ID:          fields[colGeonameID],
+			Name:        fields[colName],
+			CountryCode: countryCode,
+			CountryName: countryName,
+			Latitude:    latitude,
+			Longitude:   longitude,
+			Population:  population,
+		},
+	}
+	addAlternateName(&record, fields[colASCIIName])
+
+	return record, nil
+}
+
+// readAlternateNames calls fn with every English and Russian name in an
+// alternate names dump. Colloquial and historic names are left out.
+func readAlternateNames(path string, fn func(geonameID, name string)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) < alternateNameColumns {
+			return fmt.Errorf("line %d: expected at least %d columns, got %d", lineNo, alternateNameColumns, len(fields))
+		}
+
+		if !alternateNameLanguages[fields[colAltLanguage]] || fields[colAltColloquial] == "1" || fields[colAltHistoric] == "1" {
+			continue
+		}
+
+		fn(fields[colAltGeonameID], fields[colAltName])
+	}
+
+	return scanner.Err()
+}
+
+// addAlternateName adds a name the city can be found by, unless it's empty
+// or the city already has it
+func addAlternateName(record *db.CityRecord, name string) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == record.Name || slices.Contains(record.AlternateNames, name) {
+		return
+	}
+
+	record.AlternateNames = append(record.AlternateNames, name)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/peatch-io/peatch/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cityLine is a row of cities15000.txt
+func cityLine(id, name, asciiName, alternates, lat, lon, country, population string) []string {
+	fields := make([]string, cityColumns)
+	fields[colGeonameID] = id
+	fields[colName] = name
+	fields[colASCIIName] = asciiName
+	fields[3] = alternates
+	fields[colLatitude] = lat
+	fields[colLongitude] = lon
+	fields[colCountryCode] = country
+	fields[colPopulation] = population
+	return fields
+}
+
+func TestParseCity(t *testing.T) {
+	countries := map[string]string{"RU": "Russia", "DE": "Germany"}
+
+	tests := []struct {
+		name   string
+		fields []string
+		want   db.CityRecord
+		err    string
+	}{
+		{
+			name:   "city",
+			fields: cityLine("524901", "Moscow", "Moscow", "MOW,Maskva,Москва,モスクワ", "55.75222", "37.61556", "RU", "10381222"),
+			want: db.CityRecord{
+				City: db.City{ID: "524901", Name: "Moscow", CountryCode: "RU", CountryName: "Russia", Latitude: 55.75222, Longitude: 37.61556, Population: 10381222},
+			},
+		},
+		{
+			name:   "ascii name differs",
+			fields: cityLine("2867714", "München", "Munchen", "", "48.13743", "11.57549", "DE", "1260391"),
+			want: db.CityRecord{
+				City:           db.City{ID: "2867714", Name: "München", CountryCode: "DE", CountryName: "Germany", Latitude: 48.13743, Longitude: 11.57549, Population: 1260391},
+				AlternateNames: []string{"Munchen"},
+			},
+		},
+		{
+			name:   "unknown country and population",
+			fields: cityLine("1", "Nowhere", "Nowhere", "", "1.5", "-2.5", "XX", ""),
+			want: db.CityRecord{
+				City: db.City{ID: "1", Name: "Nowhere", CountryCode: "XX", CountryName: "XX", Latitude: 1.5, Longitude: -2.5},
+			},
+		},
+		{
+			name:   "invalid latitude",
+			fields: cityLine("1", "Nowhere", "Nowhere", "", "north", "0", "XX", ""),
+			err:    "invalid latitude",
+		},
+		{
+			name:   "invalid population",
+			fields: cityLine("1", "Nowhere", "Nowhere", "", "0", "0", "XX", "many"),
+			err:    "invalid population",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, err := parseCity(tt.fields, countries)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, record)
+		})
+	}
+}
+
+func TestReadAlternateNames(t *testing.T) {
+	rows := [][]string{
+		{"1", "524901", "en", "Moscow", "1", "", "", "", "", ""},
+		{"2", "524901", "ru", "Москва", "1", "", "", "", "", ""},
+		{"3", "524901", "ja", "モスクワ", "", "", "", "", "", ""},
+		{"4", "524901", "", "Maskva", "", "", "", "", "", ""},
+		{"5", "524901", "ru", "Белокаменная", "", "", "1", "", "", ""},
+		{"6", "524901", "ru", "Московь", "", "", "", "1", "", ""},
+		{"7", "524901", "en", "Moscow City", "", "", "", "", "", ""},
+		{"8", "2867714", "en", "Munich", "", "", "", "", "", ""},
+		{"9", "2867714", "ru", "Мюнхен", "", "", "", ""},
+	}
+
+	var lines []string
+	for _, row := range rows {
+		lines = append(lines, strings.Join(row, "\t"))
+	}
+
+	path := filepath.Join(t.TempDir(), "alternateNamesV2.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644))
+
+	moscow := db.CityRecord{City: db.City{ID: "524901", Name: "Moscow"}}
+	munich := db.CityRecord{City: db.City{ID: "2867714", Name: "München"}, AlternateNames: []string{"Munchen"}}
+	byID := map[string]*db.CityRecord{moscow.ID: &moscow, munich.ID: &munich}
+
+	err := readAlternateNames(path, func(geonameID, name string) {
+		addAlternateName(byID[geonameID], name)
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Москва", "Moscow City"}, moscow.AlternateNames)
+	assert.Equal(t, []string{"Munchen", "Munich", "Мюнхен"}, munich.AlternateNames)
+}
+
+func TestReadAlternateNames_Malformed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alternateNamesV2.txt")
+	require.NoError(t, os.WriteFile(path, []byte("1\t524901\ten\tMoscow\n"), 0o644))
+
+	err := readAlternateNames(path, func(geonameID, name string) {})
+	assert.ErrorContains(t, err, "line 1")
+}
@@ -83,6 +83,18 @@ const docTemplate = `{
                     "admin"
                 ],
                 "operationId": "admin-list-badges",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "approved"
+                        ],
+                        "type": "string",
+                        "description": "Filter by status",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -132,8 +144,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/cities/{name}": {
-            "get": {
+        "/admin/badges/{id}": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -148,12 +160,13 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "operationId": "admin-get-city-by-name",
+                "summary": "Delete badge",
+                "operationId": "admin-delete-badge",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "City name",
-                        "name": "name",
+                        "description": "Badge ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -162,23 +175,25 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/CityResponse"
-                            }
+                            "$ref": "#/definitions/BadgeRewriteResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/admin/collaborations": {
-            "get": {
+        "/admin/badges/{id}/approve": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get a list of collaborations filtered by verification status",
                 "consumes": [
                     "application/json"
                 ],
@@ -188,56 +203,40 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "List collaborations by verification status",
-                "operationId": "admin-list-collaborations",
+                "operationId": "admin-approve-badge",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Verification status (pending, verified, denied, blocked)",
-                        "name": "status",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number (default: 1)",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Items per page (default: 20, max: 100)",
-                        "name": "per_page",
-                        "in": "query"
+                        "description": "Badge ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/CollaborationResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
+                            "$ref": "#/definitions/StatusResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/badges/{id}/merge": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Create a new collaboration for a user as admin",
+                "description": "Replace the badge with the target in every user and collaboration, then delete it",
                 "consumes": [
                     "application/json"
                 ],
@@ -247,16 +246,23 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Create collaboration as admin",
-                "operationId": "admin-create-collaboration",
+                "summary": "Merge badge",
+                "operationId": "admin-merge-badge",
                 "parameters": [
                     {
-                        "description": "Collaboration data",
+                        "type": "string",
+                        "description": "Duplicate badge ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Badge to keep",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/AdminCreateCollaborationRequest"
+                            "$ref": "#/definitions/MergeBadgeRequest"
                         }
                     }
                 ],
@@ -264,13 +270,31 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/Collaboration"
+                            "$ref": "#/definitions/BadgeRewriteResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Target badge is not approved",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/admin/opportunities": {
+        "/admin/broadcasts": {
             "get": {
                 "security": [
                     {
@@ -286,28 +310,41 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "operationId": "admin-list-opportunities",
+                "summary": "List broadcasts",
+                "operationId": "admin-list-broadcasts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 20, max: 100)",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/Opportunity"
+                                "$ref": "#/definitions/Broadcast"
                             }
                         }
                     }
                 }
-            }
-        },
-        "/admin/users": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get a list of users filtered by verification status",
+                "description": "Create a draft broadcast for a user segment",
                 "consumes": [
                     "application/json"
                 ],
@@ -317,33 +354,24 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "List users by verification status",
-                "operationId": "admin-list-users",
+                "summary": "Create broadcast",
+                "operationId": "admin-create-broadcast",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Verification status (pending, verified, denied, blocked)",
-                        "name": "status",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number (default: 1)",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Items per page (default: 20, max: 100)",
-                        "name": "per_page",
-                        "in": "query"
+                        "description": "Broadcast data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/CreateBroadcastRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/UserResponse"
+                            "$ref": "#/definitions/Broadcast"
                         }
                     },
                     "400": {
@@ -351,22 +379,18 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
-                        }
                     }
                 }
-            },
+            }
+        },
+        "/admin/broadcasts/preview": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Create a new user with optional fields as admin",
+                "description": "Count the users a segment would reach",
                 "consumes": [
                     "application/json"
                 ],
@@ -376,16 +400,16 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Create user as admin",
-                "operationId": "admin-create-user",
+                "summary": "Preview broadcast audience",
+                "operationId": "admin-preview-broadcast",
                 "parameters": [
                     {
-                        "description": "User data",
+                        "description": "Audience segment",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/AdminCreateUserRequest"
+                            "$ref": "#/definitions/BroadcastSegmentRequest"
                         }
                     }
                 ],
@@ -393,7 +417,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/UserResponse"
+                            "$ref": "#/definitions/BroadcastPreviewResponse"
                         }
                     },
                     "400": {
@@ -401,29 +425,18 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
-                        }
                     }
                 }
             }
         },
-        "/admin/users/chat/{id}": {
+        "/admin/broadcasts/{id}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
+                "description": "Get a broadcast with its delivery progress",
                 "consumes": [
                     "application/json"
                 ],
@@ -433,11 +446,12 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "operationId": "admin-get-user-by-chat-id",
+                "summary": "Get broadcast",
+                "operationId": "admin-get-broadcast",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Chat ID",
+                        "description": "Broadcast ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -447,20 +461,26 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/User"
+                            "$ref": "#/definitions/Broadcast"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/admin/users/{id}": {
-            "delete": {
+        "/admin/broadcasts/{id}/cancel": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Delete a user and all their related data including collaborations and followers",
+                "description": "Cancel a draft broadcast",
                 "consumes": [
                     "application/json"
                 ],
@@ -470,12 +490,12 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Delete user completely",
-                "operationId": "admin-delete-user",
+                "summary": "Cancel broadcast",
+                "operationId": "admin-cancel-broadcast",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Broadcast ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -488,18 +508,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/StatusResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
-                        }
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -509,13 +517,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/users/{id}/collaborations": {
+        "/admin/broadcasts/{id}/recipients": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
+                "description": "Per-recipient delivery results",
                 "consumes": [
                     "application/json"
                 ],
@@ -525,14 +534,33 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "operationId": "admin-get-users-collaborations",
+                "summary": "List broadcast recipients",
+                "operationId": "admin-list-broadcast-recipients",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Broadcast ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery status (pending, sent, failed)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 50, max: 500)",
+                        "name": "per_page",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -541,21 +569,33 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/Collaboration"
+                                "$ref": "#/definitions/BroadcastRecipient"
                             }
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/admin/users/{id}/verify": {
-            "put": {
+        "/admin/broadcasts/{id}/send": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Change the verification status of a user",
+                "description": "Start delivering a draft broadcast. Delivery runs in the background; poll the broadcast for progress.",
                 "consumes": [
                     "application/json"
                 ],
@@ -565,47 +605,32 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Update user verification status",
-                "operationId": "admin-update-user-verification",
+                "summary": "Send broadcast",
+                "operationId": "admin-send-broadcast",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Broadcast ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "New verification status",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/VerificationUpdateRequest"
-                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/StatusResponse"
+                            "$ref": "#/definitions/Broadcast"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -613,14 +638,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/users/{user_id}/collaborations/{collab_id}/verify": {
-            "put": {
+        "/admin/broadcasts/{id}/test": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Change the verification status of a collaboration",
+                "description": "Send the broadcast to the requesting admin in every language it has",
                 "consumes": [
                     "application/json"
                 ],
@@ -630,24 +655,15 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Update collaboration verification status",
-                "operationId": "admin-update-collaboration-verification",
+                "summary": "Test broadcast",
+                "operationId": "admin-test-broadcast",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Collaboration ID",
+                        "description": "Broadcast ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "New verification status",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/VerificationUpdateRequest"
-                        }
                     }
                 ],
                 "responses": {
@@ -663,12 +679,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
-                        }
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -678,7 +688,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/users/{username}": {
+        "/admin/cities/{name}": {
             "get": {
                 "security": [
                     {
@@ -694,84 +704,37 @@ const docTemplate = `{
                 "tags": [
                     "admin"
                 ],
-                "operationId": "admin-get-user-by-username",
+                "operationId": "admin-get-city-by-name",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Username",
-                        "name": "username",
+                        "description": "City name",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     }
                 ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/User"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/badges": {
-            "get": {
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "badges"
-                ],
-                "summary": "List badges",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/BadgeResponse"
+                                "$ref": "#/definitions/CityResponse"
                             }
                         }
                     }
                 }
             }
         },
-        "/api/badges/{id}": {
+        "/admin/collaborations": {
             "get": {
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "badges"
-                ],
-                "summary": "Get badge",
-                "parameters": [
+                "security": [
                     {
-                        "type": "integer",
-                        "description": "Badge ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "ApiKeyAuth": []
                     }
                 ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/BadgeResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/collaborations": {
-            "get": {
+                "description": "Get a list of collaborations filtered by verification status",
                 "consumes": [
                     "application/json"
                 ],
@@ -779,26 +742,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "collaborations"
+                    "admin"
                 ],
-                "summary": "List collaborations",
+                "summary": "List collaborations by verification status",
+                "operationId": "admin-list-collaborations",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Page",
-                        "name": "page",
+                        "type": "string",
+                        "description": "Verification status (pending, verified, denied, blocked)",
+                        "name": "status",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Limit",
-                        "name": "limit",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
                         "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "Order by",
-                        "name": "order",
+                        "type": "integer",
+                        "description": "Items per page (default: 20, max: 100)",
+                        "name": "per_page",
                         "in": "query"
                     }
                 ],
@@ -806,15 +770,30 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/CollaborationResponse"
-                            }
+                            "$ref": "#/definitions/CollaborationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
             },
             "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a new collaboration for a user as admin",
                 "consumes": [
                     "application/json"
                 ],
@@ -822,32 +801,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "collaborations"
+                    "admin"
                 ],
-                "summary": "Create collaboration",
+                "summary": "Create collaboration as admin",
+                "operationId": "admin-create-collaboration",
                 "parameters": [
                     {
                         "description": "Collaboration data",
-                        "name": "collaboration",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/CreateCollaboration"
+                            "$ref": "#/definitions/AdminCreateCollaborationRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/CollaborationResponse"
+                            "$ref": "#/definitions/Collaboration"
                         }
                     }
                 }
             }
         },
-        "/api/collaborations/{id}": {
-            "get": {
+        "/admin/collaborations/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete a collaboration by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -855,12 +841,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "collaborations"
+                    "admin"
                 ],
-                "summary": "Get collaboration",
+                "summary": "Delete collaboration",
+                "operationId": "admin-delete-collaboration",
                 "parameters": [
                     {
-                        "type": "integer",
+                        "type": "string",
                         "description": "Collaboration ID",
                         "name": "id",
                         "in": "path",
@@ -871,59 +858,95 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/CollaborationResponse"
+                            "$ref": "#/definitions/StatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/admin/comments/reported": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "collaborations"
+                    "admin"
                 ],
-                "summary": "Update collaboration",
+                "operationId": "admin-list-reported-comments",
                 "parameters": [
                     {
-                        "description": "Collaboration data",
-                        "name": "collaboration",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/CreateCollaboration"
-                        }
+                        "type": "integer",
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/CollaborationResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/AdminCommentResponse"
+                            }
                         }
                     }
                 }
             }
         },
-        "/api/collaborations/{id}/interest": {
+        "/admin/comments/{id}/hide": {
             "post": {
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "collaborations"
+                    "admin"
                 ],
-                "summary": "Express interest in a collaboration",
+                "operationId": "admin-hide-comment",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Collaboration ID",
+                        "description": "Comment ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -931,44 +954,66 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "When user has blocked the bot, returns username for direct Telegram navigation",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/BotBlockedResponse"
+                            "$ref": "#/definitions/StatusResponse"
                         }
                     },
-                    "204": {
-                        "description": "No Content"
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/locations": {
-            "get": {
-                "consumes": [
-                    "application/json"
+        "/admin/comments/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "cities"
+                    "admin"
+                ],
+                "operationId": "admin-restore-comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "List cities",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/CityResponse"
-                            }
+                            "$ref": "#/definitions/StatusResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/opportunities": {
+        "/admin/denial-reasons": {
             "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -976,24 +1021,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "opportunities"
+                    "admin"
                 ],
-                "summary": "List opportunities",
+                "operationId": "admin-list-denial-reasons",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/OpportunityResponse"
+                                "$ref": "#/definitions/DenialReason"
                             }
                         }
                     }
                 }
-            }
-        },
-        "/api/users": {
-            "get": {
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -1001,54 +1049,119 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "List users",
+                "operationId": "admin-create-denial-reason",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Page",
-                        "name": "page",
-                        "in": "query"
+                        "description": "Denial reason data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/CreateDenialReasonRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/DenialReason"
+                        }
                     },
-                    {
-                        "type": "integer",
-                        "description": "Limit",
-                        "name": "limit",
-                        "in": "query"
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/denial-reasons/{id}": {
+            "delete": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "Order by",
-                        "name": "order",
-                        "in": "query"
-                    },
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "operationId": "admin-delete-denial-reason",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "Search",
-                        "name": "search",
-                        "in": "query"
+                        "description": "Denial reason ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/StatusResponse"
+                        }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/opportunities": {
+            "get": {
+                "security": [
                     {
-                        "type": "boolean",
-                        "description": "Find similar",
-                        "name": "find_similar",
-                        "in": "query"
+                        "ApiKeyAuth": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "operationId": "admin-list-opportunities",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/UserProfileResponse"
+                                "$ref": "#/definitions/Opportunity"
                             }
                         }
                     }
                 }
             },
-            "put": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -1056,57 +1169,72 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "Update user",
+                "operationId": "admin-create-opportunity",
                 "parameters": [
                     {
-                        "description": "User data",
-                        "name": "user",
+                        "description": "Opportunity data",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/UpdateUserRequest"
+                            "$ref": "#/definitions/OpportunityRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/UserResponse"
+                            "$ref": "#/definitions/Opportunity"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/users/avatar": {
-            "post": {
-                "description": "Upload a photo for the authenticated user to S3 and store record in database",
+        "/admin/opportunities/order": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "photos"
+                    "admin"
                 ],
-                "summary": "Upload user photo",
+                "operationId": "admin-reorder-opportunities",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "Photo file to upload",
-                        "name": "photo",
-                        "in": "formData",
-                        "required": true
+                        "description": "New order",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ReorderOpportunitiesRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/StatusResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/Opportunity"
+                            }
                         }
                     },
                     "400": {
@@ -1115,14 +1243,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/ErrorResponse"
                         }
@@ -1130,8 +1252,13 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/users/links": {
+        "/admin/opportunities/{id}": {
             "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -1139,17 +1266,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "Update user links",
+                "operationId": "admin-update-opportunity",
                 "parameters": [
                     {
-                        "description": "User links data",
-                        "name": "links",
+                        "type": "string",
+                        "description": "Opportunity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Opportunity data",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/UpdateUserLinksRequest"
+                            "$ref": "#/definitions/OpportunityRequest"
                         }
                     }
                 ],
@@ -1157,14 +1291,31 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/UserResponse"
+                            "$ref": "#/definitions/Opportunity"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/users/me": {
-            "get": {
+        "/admin/opportunities/{id}/archive": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -1172,22 +1323,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "Get current user",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/UserResponse"
-                        }
-                    }
+                "operationId": "admin-archive-opportunity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Opportunity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/StatusResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "/api/users/publish": {
+        "/admin/opportunities/{id}/restore": {
             "post": {
-                "description": "Makes the user profile visible by setting hidden_at to null",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -1195,21 +1365,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
+                ],
+                "operationId": "admin-restore-opportunity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Opportunity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Publish user profile",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/StatusResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/api/users/{id}": {
+        "/admin/users": {
             "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a list of users filtered by verification status",
                 "consumes": [
                     "application/json"
                 ],
@@ -1217,13 +1408,129 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "Get user",
+                "summary": "List users by verification status",
+                "operationId": "admin-list-users",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID or username",
+                        "description": "Verification status (pending, verified, denied, blocked)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 20, max: 100)",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a new user with optional fields as admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create user as admin",
+                "operationId": "admin-create-user",
+                "parameters": [
+                    {
+                        "description": "User data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/AdminCreateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/chat/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "operationId": "admin-get-user-by-chat-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1233,14 +1540,20 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/UserProfileResponse"
+                            "$ref": "#/definitions/User"
                         }
                     }
                 }
             }
         },
-        "/api/users/{id}/follow": {
-            "post": {
+        "/admin/users/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete a user and all their related data including collaborations and followers",
                 "consumes": [
                     "application/json"
                 ],
@@ -1248,13 +1561,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "Follow user",
+                "summary": "Delete user completely",
+                "operationId": "admin-delete-user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID to follow",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1262,204 +1576,3358 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "When user has blocked the bot, returns username for direct Telegram navigation",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/BotBlockedResponse"
+                            "$ref": "#/definitions/StatusResponse"
                         }
                     },
-                    "204": {
-                        "description": "No Content"
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/auth/telegram": {
-            "post": {
-                "description": "Authenticate user via Telegram using init data",
+        "/admin/users/{id}/collaborations": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Telegram Auth",
+                "operationId": "admin-get-users-collaborations",
                 "parameters": [
                     {
-                        "description": "Telegram Auth Request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/AuthTelegramRequest"
-                        }
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "400": {
-                        "description": "Bad Request",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/Collaboration"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/verify": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Change the verification status of a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Update user verification status",
+                "operationId": "admin-update-user-verification",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New verification status",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/VerificationUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/StatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{user_id}/collaborations/{collab_id}/verify": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Change the verification status of a collaboration",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Update collaboration verification status",
+                "operationId": "admin-update-collaboration-verification",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collaboration ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New verification status",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/VerificationUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/StatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{username}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "operationId": "admin-get-user-by-username",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/User"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/applications/{id}/accept": {
+            "post": {
+                "description": "Collaboration owner only. The applicant is notified.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Accept application",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ApplicationResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Application was withdrawn",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/applications/{id}/decline": {
+            "post": {
+                "description": "Collaboration owner only. The applicant is notified.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Decline application",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ApplicationResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Application was withdrawn",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/applications/{id}/shortlist": {
+            "post": {
+                "description": "Collaboration owner only. The applicant is notified.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Shortlist application",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ApplicationResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Application was withdrawn",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/applications/{id}/withdraw": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "Withdraw application",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Application ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ApplicationResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Application was already withdrawn",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/badges": {
+            "get": {
+                "description": "Approved badges plus the caller's own badges still pending review",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "badges"
+                ],
+                "summary": "List badges",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search by text",
+                        "name": "search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/BadgeResponse"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "The badge stays pending, and visible only to its creator, until an admin approves it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "badges"
+                ],
+                "summary": "Create badge",
+                "parameters": [
+                    {
+                        "description": "Badge data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/CreateBadgeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/BadgeResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/DuplicateBadgeResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/collaborations": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collaborations"
+                ],
+                "summary": "List collaborations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text in the title or description",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Looking for any of these opportunities",
+                        "name": "opportunity_ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Tagged with these badges",
+                        "name": "badge_ids",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Whether any or all of badge_ids must match, any by default",
+                        "name": "badge_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 3166-1 alpha-2 country code of the location",
+                        "name": "country_code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "City ID of the location",
+                        "name": "city_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only paid collaborations",
+                        "name": "payable",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "paid",
+                                "equity",
+                                "revshare",
+                                "volunteer",
+                                "barter"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Compensated in any of these ways",
+                        "name": "compensation_types",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Paying at least this much by the top of the range, requires currency",
+                        "name": "min_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 currency of min_amount",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after, RFC 3339 or YYYY-MM-DD",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "open",
+                                "in_progress",
+                                "filled",
+                                "closed",
+                                "expired"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Lifecycle statuses, open by default",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "City ID to search around",
+                        "name": "near",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Search radius around near, 50 km by default",
+                        "name": "radius_km",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "newest",
+                            "interest",
+                            "match",
+                            "distance"
+                        ],
+                        "type": "string",
+                        "description": "Sort order, newest by default. match ranks by badges and opportunities shared with the viewer, distance requires near",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/CollaborationResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collaborations"
+                ],
+                "summary": "Create collaboration",
+                "parameters": [
+                    {
+                        "description": "Collaboration data",
+                        "name": "collaboration",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/CreateCollaboration"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/CollaborationResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limited (code rate_limited), see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/collaborations/{id}": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collaborations"
+                ],
+                "summary": "Get collaboration",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Collaboration ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/CollaborationResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collaborations"
+                ],
+                "summary": "Update collaboration",
+                "parameters": [
+                    {
+                        "description": "Collaboration data",
+                        "name": "collaboration",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/CreateCollaboration"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/CollaborationResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/collaborations/{id}/close": {
+            "post": {
+                "description": "Takes the collaboration out of the feed, as in progress, filled or closed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collaborations"
+                ],
+                "summary": "Close collaboration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collaboration ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Status, closed by default",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/CloseCollaborationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/CollaborationResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/collaborations/{id}/comments": {
+            "get": {
+                "description": "Questions and answers on a collaboration, pinned first and then oldest first, each with its replies. Comments of blocked or hidden users are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "List collaboration comments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collaboration ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/CommentResponse"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Asks a question, or replies in a thread with parent_id. The collaboration team is notified, and so is the author of the thread on a reply.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Comment on collaboration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collaboration ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Comment",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/CommentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/CommentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Collaboration or parent comment not found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/collaborations/{id}/interest": {
+            "post": {
+                "description": "Sends an application with an optional pitch to the owner",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collaborations"
+                ],
+                "summary": "Apply to a collaboration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collaboration ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Pitch",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/ApplyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "When user has blocked the bot, returns username for direct Telegram navigation",
+                        "schema": {
+                            "$ref": "#/definitions/BotBlockedResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limited (code rate_limited), see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/collaborations/{id}/interests": {
+            "get": {
+                "description": "Collaboration owner only. Includes answered and withdrawn applications, with how well each applicant matches the collaboration.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "List applications to a collaboration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collaboration ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/ApplicationResponse"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Collaboration not found or not owned",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/collaborations/{id}/invite": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collaborations"
+                ],
+                "summary": "Decline team invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collaboration ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/StatusResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/collaborations/{id}/invite/accept": {
+            "post": {
+                "description": "Joins the team with the role of the invitation",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collaborations"
+                ],
+                "summary": "Accept team invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collaboration ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/CollaborationResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/collaborations/{id}/members/{user_id}": {
+            "put": {
+                "description": "Changes the role of a team member, or invites a verified user to join the team with it. Invited users join once they accept. Owners can edit, close and manage the team, editors can only edit. Only owners can call this.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collaborations"
+                ],
+                "summary": "Set collaboration member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collaboration ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/CollaborationMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/CollaborationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Owners can remove anyone but the creator and withdraw invitations, other members can remove themselves to leave the team",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collaborations"
+                ],
+                "summary": "Remove collaboration member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collaboration ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/StatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/collaborations/{id}/reopen": {
+            "post": {
+                "description": "Puts a closed or expired collaboration back in the feed. An expiry that has passed is dropped unless a new one is given.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collaborations"
+                ],
+                "summary": "Reopen collaboration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collaboration ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New expiry",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/ReopenCollaborationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/CollaborationResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/collaborations/{id}/save": {
+            "post": {
+                "description": "Bookmarks a collaboration to come back to later. Saving twice is fine.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collaborations"
+                ],
+                "summary": "Save collaboration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collaboration ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/StatusResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collaborations"
+                ],
+                "summary": "Unsave collaboration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collaboration ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/StatusResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/collaborations/{id}/stats": {
+            "get": {
+                "description": "Daily views, deep-link opens and applications of a collaboration, for its team only. Views are counted once per viewer a day.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collaborations"
+                ],
+                "summary": "Collaboration stats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collaboration ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Days, 30 by default and at most 90",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/StatsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Collaboration not found or not on the team",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/comments/{id}": {
+            "put": {
+                "description": "Authors can edit their own comments until deleted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Edit comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Comment",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/UpdateCommentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/CommentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Authors can delete their comments, collaboration owners any comment on it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Delete comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/StatusResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/comments/{id}/pin": {
+            "post": {
+                "description": "Collaboration owners pin questions with their answers to the top of the thread list",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Pin comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/CommentResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Comment not found, a reply or not owned",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Unpin comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/CommentResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/comments/{id}/report": {
+            "post": {
+                "description": "Flags a comment to the moderators. Reporting twice counts once, and a comment with enough reports is hidden until reviewed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Report comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/ReportCommentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/StatusResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/locations": {
+            "get": {
+                "description": "Cities matching the name or an alternate name, most populated first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cities"
+                ],
+                "summary": "List cities",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "City name, in English or Russian",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/CityResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/opportunities": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "opportunities"
+                ],
+                "summary": "List opportunities",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/OpportunityResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Order by",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Find similar",
+                        "name": "find_similar",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "City ID to search around",
+                        "name": "near",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Search radius around near, 50 km by default",
+                        "name": "radius_km",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "distance"
+                        ],
+                        "type": "string",
+                        "description": "Sort order, distance requires near",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/UserProfileResponse"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update user",
+                "parameters": [
+                    {
+                        "description": "User data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/UserResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/avatar": {
+            "post": {
+                "description": "Upload a photo for the authenticated user to S3 and store record in database",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "photos"
+                ],
+                "summary": "Upload user photo",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Photo file to upload",
+                        "name": "photo",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/StatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/links": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update user links",
+                "parameters": [
+                    {
+                        "description": "User links data",
+                        "name": "links",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/UpdateUserLinksRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/UserResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/me": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get current user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/UserResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/me/applications": {
+            "get": {
+                "description": "Applications the current user sent, newest first, with their status",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "applications"
+                ],
+                "summary": "List my applications",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/ApplicationResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/me/invites": {
+            "get": {
+                "description": "Invitations to join the team of a collaboration, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collaborations"
+                ],
+                "summary": "List team invitations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/CollaborationInviteResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/me/saved": {
+            "get": {
+                "description": "Most recently saved first. Closed and hidden collaborations stay in the list and are flagged.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collaborations"
+                ],
+                "summary": "List saved collaborations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/SavedCollaborationResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/me/searches": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "saved searches"
+                ],
+                "summary": "List saved searches",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/SavedSearchResponse"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Saves the search text and feed filters. The user gets a bot message when a new collaboration matching them is verified.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "saved searches"
+                ],
+                "summary": "Save a search",
+                "parameters": [
+                    {
+                        "description": "Search",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/SavedSearchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/SavedSearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid search or too many saved searches",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/me/searches/{id}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "saved searches"
+                ],
+                "summary": "Delete a saved search",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Saved search ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/StatusResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/me/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the current user's active sessions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/SessionResponse"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Log out everywhere except the current session",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Revoke other sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/StatusResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/me/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Log out a single session",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Revoke session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/StatusResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/me/stats": {
+            "get": {
+                "description": "Daily views, deep-link opens and applications of the current user's profile and of the collaborations they posted. Views are counted once per viewer a day.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "My stats",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Days, 30 by default and at most 90",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/UserStatsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/publish": {
+            "post": {
+                "description": "Makes the user profile visible by setting hidden_at to null",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Publish user profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/StatusResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID or username",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/UserProfileResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/follow": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Follow user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID to follow",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "When user has blocked the bot, returns username for direct Telegram navigation",
+                        "schema": {
+                            "$ref": "#/definitions/BotBlockedResponse"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "429": {
+                        "description": "Rate limited (code rate_limited), see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "Revoke the session a refresh token belongs to",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Logout",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/StatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Exchange a refresh token for a new access token. The refresh token is rotated; reusing an old one revokes the session.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Refresh tokens",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/contract.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limited (code rate_limited), see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/telegram": {
+            "post": {
+                "description": "Authenticate user via Telegram using init data",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Telegram Auth",
+                "parameters": [
+                    {
+                        "description": "Telegram Auth Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/AuthTelegramRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/contract.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "User is blocked (code user_blocked)",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limited (code rate_limited), see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "AdminAuthResponse": {
+            "type": "object",
+            "properties": {
+                "admin": {
+                    "$ref": "#/definitions/AdminResponse"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "AdminCommentResponse": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "collaboration_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "edited_at": {
+                    "type": "string"
+                },
+                "hidden_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_deleted": {
+                    "description": "Only kept as the head of a thread, without text or author",
+                    "type": "boolean"
+                },
+                "is_pinned": {
+                    "type": "boolean"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "replies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/CommentResponse"
+                    }
+                },
+                "report_count": {
+                    "type": "integer"
+                },
+                "user": {
+                    "$ref": "#/definitions/UserProfileResponse"
+                }
+            }
+        },
+        "AdminCreateCollaborationRequest": {
+            "type": "object",
+            "properties": {
+                "badge_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "description": {
+                    "type": "string"
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Link"
+                    }
+                },
+                "location": {
+                    "type": "string"
+                },
+                "opportunity_id": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "AdminCreateUserRequest": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "badge_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "chat_id": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Link"
+                    }
+                },
+                "location": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "opportunity_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "AdminResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "AdminTelegramAuthRequest": {
+            "type": "object",
+            "properties": {
+                "query": {
+                    "description": "Telegram init data",
+                    "type": "string"
+                }
+            }
+        },
+        "ApplicationCollaborationResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/db.CollaborationStatus"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "ApplicationResponse": {
+            "type": "object",
+            "properties": {
+                "collaboration": {
+                    "$ref": "#/definitions/ApplicationCollaborationResponse"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Link"
+                    }
+                },
+                "match_score": {
+                    "description": "Only shown to the collaboration owner",
+                    "type": "number"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/db.ApplicationStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/UserProfileResponse"
+                }
+            }
+        },
+        "ApplyRequest": {
+            "type": "object",
+            "properties": {
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Link"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "AuthTelegramRequest": {
+            "type": "object",
+            "properties": {
+                "query": {
+                    "type": "string"
+                }
+            }
+        },
+        "Badge": {
+            "type": "object",
+            "properties": {
+                "color": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "icon": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/db.BadgeStatus"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "BadgeResponse": {
+            "type": "object",
+            "properties": {
+                "color": {
+                    "type": "string"
+                },
+                "icon": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Only set in badge lists, pending badges are shown to their creator alone",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/db.BadgeStatus"
+                        }
+                    ]
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "BadgeRewriteResult": {
+            "type": "object",
+            "properties": {
+                "collaborations": {
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "integer"
+                }
+            }
+        },
+        "BotBlockedResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "Broadcast": {
+            "type": "object",
+            "properties": {
+                "admin_id": {
+                    "type": "string"
+                },
+                "button_path": {
+                    "type": "string"
+                },
+                "button_text": {
+                    "type": "string"
+                },
+                "button_text_ru": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "image_url": {
+                    "type": "string"
+                },
+                "segment": {
+                    "$ref": "#/definitions/BroadcastSegment"
+                },
+                "sent": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/BroadcastStatus"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "text_ru": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "BroadcastPreviewResponse": {
+            "type": "object",
+            "properties": {
+                "recipients": {
+                    "type": "integer"
+                }
+            }
+        },
+        "BroadcastRecipient": {
+            "type": "object",
+            "properties": {
+                "broadcast_id": {
+                    "type": "string"
+                },
+                "chat_id": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "language_code": {
+                    "type": "string"
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/BroadcastRecipientStatus"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "BroadcastRecipientStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "sent",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "BroadcastRecipientPending",
+                "BroadcastRecipientSent",
+                "BroadcastRecipientFailed"
+            ]
+        },
+        "BroadcastSegment": {
+            "type": "object",
+            "properties": {
+                "active_after": {
+                    "type": "string"
+                },
+                "active_before": {
+                    "type": "string"
+                },
+                "badge_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "country_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "languages": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "opportunity_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "statuses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/VerificationStatus"
+                    }
+                }
+            }
+        },
+        "BroadcastSegmentRequest": {
+            "type": "object",
+            "properties": {
+                "active_after": {
+                    "type": "string"
+                },
+                "active_before": {
+                    "type": "string"
+                },
+                "badge_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "country_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "languages": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "opportunity_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "statuses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/VerificationStatus"
+                    }
+                }
+            }
+        },
+        "BroadcastStatus": {
+            "type": "string",
+            "enum": [
+                "draft",
+                "sending",
+                "completed",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "BroadcastStatusDraft",
+                "BroadcastStatusSending",
+                "BroadcastStatusCompleted",
+                "BroadcastStatusCancelled"
+            ]
+        },
+        "City": {
+            "type": "object",
+            "properties": {
+                "country_code": {
+                    "type": "string"
+                },
+                "country_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "population": {
+                    "type": "integer"
+                }
+            }
+        },
+        "CityResponse": {
+            "type": "object",
+            "properties": {
+                "country_code": {
+                    "type": "string"
+                },
+                "country_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "CloseCollaborationRequest": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "description": "Status is in_progress, filled or closed, closed by default",
+                    "enum": [
+                        "in_progress",
+                        "filled",
+                        "closed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/db.CollaborationStatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "Collaboration": {
+            "type": "object",
+            "properties": {
+                "badges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Badge"
+                    }
+                },
+                "compensation": {
+                    "$ref": "#/definitions/db.Compensation"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "denial_comment": {
+                    "type": "string"
+                },
+                "denial_reason": {
+                    "$ref": "#/definitions/DenialReason"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "distance_km": {
+                    "description": "Set when listing near a city",
+                    "type": "number"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "has_interest": {
+                    "type": "boolean"
+                },
+                "hidden_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_payable": {
+                    "description": "Kept in line with Compensation",
+                    "type": "boolean"
+                },
+                "is_saved": {
+                    "type": "boolean"
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Link"
+                    }
+                },
+                "location": {
+                    "$ref": "#/definitions/City"
+                },
+                "opportunity": {
+                    "$ref": "#/definitions/Opportunity"
+                },
+                "status": {
+                    "$ref": "#/definitions/db.CollaborationStatus"
+                },
+                "team": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/CollaborationMember"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "user": {
+                    "description": "The creator",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/User"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "verification_status": {
+                    "$ref": "#/definitions/VerificationStatus"
+                },
+                "verified_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "CollaborationInviteResponse": {
+            "type": "object",
+            "properties": {
+                "collaboration_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/db.CollaborationRole"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "CollaborationMember": {
+            "type": "object",
+            "properties": {
+                "collaboration_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/db.CollaborationRole"
+                },
+                "user": {
+                    "$ref": "#/definitions/User"
+                }
+            }
+        },
+        "CollaborationMemberRequest": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "$ref": "#/definitions/db.CollaborationRole"
+                }
+            }
+        },
+        "CollaborationMemberResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/db.CollaborationRole"
+                },
+                "user": {
+                    "$ref": "#/definitions/UserProfileResponse"
+                }
+            }
+        },
+        "CollaborationResponse": {
+            "type": "object",
+            "properties": {
+                "badges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/BadgeResponse"
+                    }
+                },
+                "compensation": {
+                    "$ref": "#/definitions/Compensation"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "denial_comment": {
+                    "type": "string"
+                },
+                "denial_reason": {
+                    "$ref": "#/definitions/DenialReasonResponse"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "distance_km": {
+                    "description": "Set when listing near a city",
+                    "type": "number"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "has_interest": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_payable": {
+                    "description": "Deprecated, same as compensation.type being paid",
+                    "type": "boolean"
+                },
+                "is_saved": {
+                    "type": "boolean"
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Link"
+                    }
+                },
+                "location": {
+                    "$ref": "#/definitions/CityResponse"
+                },
+                "opportunity": {
+                    "$ref": "#/definitions/OpportunityResponse"
+                },
+                "status": {
+                    "$ref": "#/definitions/db.CollaborationStatus"
+                },
+                "team": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/CollaborationMemberResponse"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "description": "The creator, also on the team as an owner",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/UserProfileResponse"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "verification_status": {
+                    "$ref": "#/definitions/VerificationStatus"
+                }
+            }
+        },
+        "CommentRequest": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "description": "Comment to reply to",
+                    "type": "string"
+                }
+            }
+        },
+        "CommentResponse": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "collaboration_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "edited_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_deleted": {
+                    "description": "Only kept as the head of a thread, without text or author",
+                    "type": "boolean"
+                },
+                "is_pinned": {
+                    "type": "boolean"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "replies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/CommentResponse"
+                    }
+                },
+                "user": {
+                    "$ref": "#/definitions/UserProfileResponse"
+                }
+            }
+        },
+        "Compensation": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "description": "ISO 4217, e.g. EUR",
+                    "type": "string"
+                },
+                "max_amount": {
+                    "type": "number"
+                },
+                "min_amount": {
+                    "type": "number"
+                },
+                "period": {
+                    "enum": [
+                        "project",
+                        "hour",
+                        "day",
+                        "week",
+                        "month"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/db.CompensationPeriod"
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/ErrorResponse"
+                    ]
+                },
+                "type": {
+                    "enum": [
+                        "paid",
+                        "equity",
+                        "revshare",
+                        "volunteer",
+                        "barter"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/db.CompensationType"
                         }
-                    }
+                    ]
                 }
             }
-        }
-    },
-    "definitions": {
-        "AdminAuthResponse": {
+        },
+        "CreateBadgeRequest": {
             "type": "object",
             "properties": {
-                "admin": {
-                    "$ref": "#/definitions/AdminResponse"
+                "color": {
+                    "type": "string"
                 },
-                "token": {
+                "icon": {
+                    "type": "string"
+                },
+                "text": {
                     "type": "string"
                 }
             }
         },
-        "AdminCreateCollaborationRequest": {
+        "CreateBroadcastRequest": {
             "type": "object",
             "properties": {
-                "badges": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
-                },
-                "description": {
+                "button_path": {
+                    "description": "Web app path the button opens, e.g. /collaborations",
                     "type": "string"
                 },
-                "links": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/Link"
-                    }
+                "button_text_en": {
+                    "type": "string"
                 },
-                "location": {
+                "button_text_ru": {
                     "type": "string"
                 },
-                "opportunity_id": {
+                "image_url": {
                     "type": "string"
                 },
-                "title": {
+                "segment": {
+                    "$ref": "#/definitions/BroadcastSegmentRequest"
+                },
+                "text_en": {
                     "type": "string"
                 },
-                "user_id": {
+                "text_ru": {
                     "type": "string"
                 }
             }
         },
-        "AdminCreateUserRequest": {
+        "CreateCollaboration": {
             "type": "object",
             "properties": {
-                "avatar_url": {
-                    "type": "string"
-                },
-                "badges": {
+                "badge_ids": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "chat_id": {
-                    "type": "integer"
+                "compensation": {
+                    "description": "Takes precedence over is_payable",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/Compensation"
+                        }
+                    ]
                 },
                 "description": {
                     "type": "string"
                 },
-                "links": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/Link"
-                    }
-                },
-                "location": {
+                "expires_at": {
+                    "description": "ExpiresAt takes the collaboration out of the feed, set at creation only",
                     "type": "string"
                 },
-                "name": {
-                    "type": "string"
+                "is_payable": {
+                    "description": "Deprecated, use compensation. True without compensation means paid.",
+                    "type": "boolean"
                 },
-                "opportunity_ids": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "location_id": {
+                    "type": "string"
                 },
-                "title": {
+                "opportunity_id": {
                     "type": "string"
                 },
-                "username": {
+                "title": {
                     "type": "string"
                 }
             }
         },
-        "AdminResponse": {
+        "CreateDenialReasonRequest": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
+                "code": {
                     "type": "string"
                 },
-                "updated_at": {
+                "text_en": {
                     "type": "string"
                 },
-                "username": {
-                    "type": "string"
-                }
-            }
-        },
-        "AdminTelegramAuthRequest": {
-            "type": "object",
-            "properties": {
-                "query": {
-                    "description": "Telegram init data",
+                "text_ru": {
                     "type": "string"
                 }
             }
         },
-        "AuthTelegramRequest": {
+        "DailyStatsResponse": {
             "type": "object",
             "properties": {
-                "query": {
+                "date": {
+                    "description": "YYYY-MM-DD in UTC",
                     "type": "string"
+                },
+                "deep_links": {
+                    "type": "integer"
+                },
+                "interests": {
+                    "type": "integer"
+                },
+                "views": {
+                    "type": "integer"
                 }
             }
         },
-        "Badge": {
+        "DenialReason": {
             "type": "object",
             "properties": {
-                "color": {
+                "code": {
                     "type": "string"
                 },
                 "created_at": {
                     "type": "string"
                 },
-                "icon": {
-                    "type": "string"
-                },
                 "id": {
                     "type": "string"
                 },
                 "text": {
                     "type": "string"
+                },
+                "text_ru": {
+                    "type": "string"
                 }
             }
         },
-        "BadgeResponse": {
+        "DenialReasonResponse": {
             "type": "object",
             "properties": {
-                "color": {
-                    "type": "string"
-                },
-                "icon": {
-                    "type": "string"
-                },
-                "id": {
+                "code": {
                     "type": "string"
                 },
                 "text": {
@@ -1467,311 +4935,357 @@ const docTemplate = `{
                 }
             }
         },
-        "BotBlockedResponse": {
+        "DuplicateBadgeResponse": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string"
+                "badge": {
+                    "$ref": "#/definitions/BadgeResponse"
                 },
-                "status": {
+                "code": {
                     "type": "string"
                 },
-                "username": {
+                "error": {
                     "type": "string"
                 }
             }
         },
-        "City": {
+        "ErrorResponse": {
             "type": "object",
             "properties": {
-                "country_code": {
-                    "type": "string"
-                },
-                "country_name": {
+                "code": {
+                    "description": "Machine-readable reason, set for errors clients handle specially",
                     "type": "string"
                 },
-                "id": {
-                    "type": "string"
-                },
-                "latitude": {
-                    "type": "number"
-                },
-                "longitude": {
-                    "type": "number"
-                },
-                "name": {
+                "error": {
                     "type": "string"
                 }
             }
         },
-        "CityResponse": {
+        "Link": {
             "type": "object",
             "properties": {
-                "country_code": {
-                    "type": "string"
-                },
-                "country_name": {
+                "icon": {
+                    "description": "Optional icon for the link",
                     "type": "string"
                 },
-                "id": {
+                "label": {
                     "type": "string"
                 },
-                "latitude": {
-                    "type": "number"
+                "order": {
+                    "type": "integer"
                 },
-                "longitude": {
-                    "type": "number"
+                "type": {
+                    "description": "e.g., \"github\", \"linkedin\", \"website\", \"portfolio\"",
+                    "type": "string"
                 },
-                "name": {
+                "url": {
                     "type": "string"
                 }
             }
         },
-        "Collaboration": {
+        "LoginMeta": {
             "type": "object",
             "properties": {
-                "badges": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/Badge"
-                    }
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "has_interest": {
-                    "type": "boolean"
-                },
-                "hidden_at": {
+                "city": {
                     "type": "string"
                 },
-                "id": {
+                "country": {
                     "type": "string"
                 },
-                "is_payable": {
-                    "type": "boolean"
-                },
-                "links": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/Link"
-                    }
-                },
-                "location": {
-                    "$ref": "#/definitions/City"
-                },
-                "opportunity": {
-                    "$ref": "#/definitions/Opportunity"
-                },
-                "title": {
+                "ip": {
                     "type": "string"
                 },
-                "user": {
-                    "$ref": "#/definitions/User"
-                },
-                "user_id": {
+                "user_agent": {
                     "type": "string"
-                },
-                "verification_status": {
-                    "$ref": "#/definitions/VerificationStatus"
-                },
-                "verified_at": {
+                }
+            }
+        },
+        "MergeBadgeRequest": {
+            "type": "object",
+            "properties": {
+                "target_id": {
                     "type": "string"
                 }
             }
         },
-        "CollaborationResponse": {
+        "Opportunity": {
             "type": "object",
             "properties": {
-                "badges": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/BadgeResponse"
-                    }
-                },
-                "created_at": {
+                "archived_at": {
                     "type": "string"
                 },
-                "description": {
+                "color": {
                     "type": "string"
                 },
-                "has_interest": {
-                    "type": "boolean"
-                },
-                "id": {
+                "created_at": {
                     "type": "string"
                 },
-                "is_payable": {
-                    "type": "boolean"
-                },
-                "links": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/Link"
-                    }
-                },
-                "location": {
-                    "$ref": "#/definitions/CityResponse"
+                "description": {
+                    "type": "string"
                 },
-                "opportunity": {
-                    "$ref": "#/definitions/OpportunityResponse"
+                "description_ru": {
+                    "type": "string"
                 },
-                "title": {
+                "icon": {
                     "type": "string"
                 },
-                "updated_at": {
+                "id": {
                     "type": "string"
                 },
-                "user": {
-                    "$ref": "#/definitions/UserProfileResponse"
+                "position": {
+                    "type": "integer"
                 },
-                "user_id": {
+                "text": {
                     "type": "string"
                 },
-                "verification_status": {
-                    "$ref": "#/definitions/VerificationStatus"
+                "text_ru": {
+                    "type": "string"
                 }
             }
         },
-        "CreateBadgeRequest": {
+        "OpportunityRequest": {
             "type": "object",
             "properties": {
                 "color": {
+                    "description": "Hex RGB without #",
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "description_ru": {
                     "type": "string"
                 },
                 "icon": {
+                    "description": "Hex code point of the icon glyph",
                     "type": "string"
                 },
                 "text": {
                     "type": "string"
+                },
+                "text_ru": {
+                    "type": "string"
                 }
             }
         },
-        "CreateCollaboration": {
+        "OpportunityResponse": {
             "type": "object",
             "properties": {
-                "badge_ids": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "color": {
+                    "type": "string"
                 },
                 "description": {
                     "type": "string"
                 },
-                "is_payable": {
-                    "type": "boolean"
-                },
-                "location_id": {
+                "icon": {
                     "type": "string"
                 },
-                "opportunity_id": {
+                "id": {
                     "type": "string"
                 },
-                "title": {
+                "text": {
                     "type": "string"
                 }
             }
         },
-        "ErrorResponse": {
+        "RefreshTokenRequest": {
             "type": "object",
             "properties": {
-                "error": {
+                "refresh_token": {
                     "type": "string"
                 }
             }
         },
-        "Link": {
+        "ReopenCollaborationRequest": {
             "type": "object",
             "properties": {
-                "icon": {
-                    "description": "Optional icon for the link",
+                "expires_at": {
                     "type": "string"
-                },
-                "label": {
+                }
+            }
+        },
+        "ReorderOpportunitiesRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "description": "Listed first, in this order; the rest keep their relative order",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "ReportCommentRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
                     "type": "string"
+                }
+            }
+        },
+        "SavedCollaborationResponse": {
+            "type": "object",
+            "properties": {
+                "collaboration": {
+                    "$ref": "#/definitions/CollaborationResponse"
                 },
-                "order": {
-                    "type": "integer"
+                "is_closed": {
+                    "description": "No longer open for applications",
+                    "type": "boolean"
                 },
-                "type": {
-                    "description": "e.g., \"github\", \"linkedin\", \"website\", \"portfolio\"",
-                    "type": "string"
+                "is_hidden": {
+                    "description": "Hidden by the owner or moderation, only the ID and title are shown",
+                    "type": "boolean"
                 },
-                "url": {
+                "saved_at": {
                     "type": "string"
                 }
             }
         },
-        "LoginMeta": {
+        "SavedSearchRequest": {
             "type": "object",
             "properties": {
-                "city": {
+                "badge_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "badge_match": {
+                    "description": "any by default",
+                    "type": "string",
+                    "enum": [
+                        "any",
+                        "all"
+                    ]
+                },
+                "city_id": {
                     "type": "string"
                 },
-                "country": {
+                "compensation_types": {
+                    "description": "Compensated in any of these ways",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/db.CompensationType"
+                    }
+                },
+                "country_code": {
                     "type": "string"
                 },
-                "ip": {
+                "name": {
                     "type": "string"
                 },
-                "user_agent": {
+                "opportunity_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "payable": {
+                    "type": "boolean"
+                },
+                "query": {
                     "type": "string"
                 }
             }
         },
-        "Opportunity": {
+        "SavedSearchResponse": {
             "type": "object",
             "properties": {
-                "color": {
-                    "type": "string"
+                "badge_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "created_at": {
+                "badge_match": {
                     "type": "string"
                 },
-                "description": {
+                "city_id": {
                     "type": "string"
                 },
-                "description_ru": {
+                "compensation_types": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/db.CompensationType"
+                    }
+                },
+                "country_code": {
                     "type": "string"
                 },
-                "icon": {
+                "created_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
-                "text": {
+                "name": {
                     "type": "string"
                 },
-                "text_ru": {
+                "opportunity_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "payable": {
+                    "type": "boolean"
+                },
+                "query": {
                     "type": "string"
                 }
             }
         },
-        "OpportunityResponse": {
+        "SessionResponse": {
             "type": "object",
             "properties": {
-                "color": {
+                "city": {
                     "type": "string"
                 },
-                "description": {
+                "country": {
                     "type": "string"
                 },
-                "icon": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "boolean"
+                },
+                "device": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
-                "text": {
+                "last_used_at": {
+                    "type": "string"
+                },
+                "user_agent": {
                     "type": "string"
                 }
             }
         },
+        "StatsResponse": {
+            "type": "object",
+            "properties": {
+                "daily": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/DailyStatsResponse"
+                    }
+                },
+                "deep_links": {
+                    "type": "integer"
+                },
+                "interests": {
+                    "type": "integer"
+                },
+                "views": {
+                    "type": "integer"
+                }
+            }
+        },
         "StatusResponse": {
             "type": "object",
             "properties": {
@@ -1780,6 +5294,14 @@ const docTemplate = `{
                 }
             }
         },
+        "UpdateCommentRequest": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                }
+            }
+        },
         "UpdateUserLinksRequest": {
             "type": "object",
             "properties": {
@@ -1838,9 +5360,19 @@ const docTemplate = `{
                 "created_at": {
                     "type": "string"
                 },
+                "denial_comment": {
+                    "type": "string"
+                },
+                "denial_reason": {
+                    "$ref": "#/definitions/DenialReason"
+                },
                 "description": {
                     "type": "string"
                 },
+                "distance_km": {
+                    "description": "Set when listing near a city",
+                    "type": "number"
+                },
                 "hidden_at": {
                     "type": "string"
                 },
@@ -1906,6 +5438,10 @@ const docTemplate = `{
                 "description": {
                     "type": "string"
                 },
+                "distance_km": {
+                    "description": "Set when listing near a city",
+                    "type": "number"
+                },
                 "id": {
                     "type": "string"
                 },
@@ -1959,6 +5495,12 @@ const docTemplate = `{
                 "created_at": {
                     "type": "string"
                 },
+                "denial_comment": {
+                    "type": "string"
+                },
+                "denial_reason": {
+                    "$ref": "#/definitions/DenialReasonResponse"
+                },
                 "description": {
                     "type": "string"
                 },
@@ -2006,6 +5548,22 @@ const docTemplate = `{
                 }
             }
         },
+        "UserStatsResponse": {
+            "type": "object",
+            "properties": {
+                "collaborations": {
+                    "description": "Summed over the collaborations the user posted",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/StatsResponse"
+                        }
+                    ]
+                },
+                "profile": {
+                    "$ref": "#/definitions/StatsResponse"
+                }
+            }
+        },
         "VerificationStatus": {
             "type": "string",
             "enum": [
@@ -2026,10 +5584,149 @@ const docTemplate = `{
         "VerificationUpdateRequest": {
             "type": "object",
             "properties": {
+                "comment": {
+                    "description": "Optional free text shown to the user",
+                    "type": "string"
+                },
+                "reason_code": {
+                    "description": "Code from the denial reasons catalogue",
+                    "type": "string"
+                },
                 "status": {
                     "$ref": "#/definitions/VerificationStatus"
                 }
             }
+        },
+        "contract.AuthResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "refresh_expires_at": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/UserResponse"
+                }
+            }
+        },
+        "db.ApplicationStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "shortlisted",
+                "accepted",
+                "declined",
+                "withdrawn"
+            ],
+            "x-enum-varnames": [
+                "ApplicationStatusPending",
+                "ApplicationStatusShortlisted",
+                "ApplicationStatusAccepted",
+                "ApplicationStatusDeclined",
+                "ApplicationStatusWithdrawn"
+            ]
+        },
+        "db.BadgeStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "approved"
+            ],
+            "x-enum-varnames": [
+                "BadgeStatusPending",
+                "BadgeStatusApproved"
+            ]
+        },
+        "db.CollaborationRole": {
+            "type": "string",
+            "enum": [
+                "owner",
+                "editor"
+            ],
+            "x-enum-varnames": [
+                "CollaborationRoleOwner",
+                "CollaborationRoleEditor"
+            ]
+        },
+        "db.CollaborationStatus": {
+            "type": "string",
+            "enum": [
+                "open",
+                "in_progress",
+                "filled",
+                "closed",
+                "expired"
+            ],
+            "x-enum-varnames": [
+                "CollaborationStatusOpen",
+                "CollaborationStatusInProgress",
+                "CollaborationStatusFilled",
+                "CollaborationStatusClosed",
+                "CollaborationStatusExpired"
+            ]
+        },
+        "db.Compensation": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "description": "ISO 4217, e.g. EUR",
+                    "type": "string"
+                },
+                "max_amount": {
+                    "type": "number"
+                },
+                "min_amount": {
+                    "type": "number"
+                },
+                "period": {
+                    "$ref": "#/definitions/db.CompensationPeriod"
+                },
+                "type": {
+                    "$ref": "#/definitions/db.CompensationType"
+                }
+            }
+        },
+        "db.CompensationPeriod": {
+            "type": "string",
+            "enum": [
+                "project",
+                "hour",
+                "day",
+                "week",
+                "month"
+            ],
+            "x-enum-varnames": [
+                "CompensationPerProject",
+                "CompensationPerHour",
+                "CompensationPerDay",
+                "CompensationPerWeek",
+                "CompensationPerMonth"
+            ]
+        },
+        "db.CompensationType": {
+            "type": "string",
+            "enum": [
+                "paid",
+                "equity",
+                "revshare",
+                "volunteer",
+                "barter"
+            ],
+            "x-enum-varnames": [
+                "CompensationPaid",
+                "CompensationEquity",
+                "CompensationRevShare",
+                "CompensationVolunteer",
+                "CompensationBarter"
+            ]
         }
     }
 }`
@@ -3,23 +3,31 @@ package config
 import (
 	"fmt"
 	"github.com/go-playground/validator"
+	"github.com/peatch-io/peatch/internal/ratelimit"
 	"gopkg.in/yaml.v3"
 	"os"
 )
 
 type Config struct {
-	Host            string         `yaml:"host" validate:"required"`
-	Port            int            `yaml:"port" validate:"required,gt=0"`
-	DBURL           string         `yaml:"mongo_uri" validate:"required"`
-	DBName          string         `yaml:"mongo_db" validate:"required"`
-	JWTSecret       string         `yaml:"jwt_secret" validate:"required"`
-	Telegram        TelegramConfig `yaml:"telegram" validate:"required"`
-	LogLevel        string         `yaml:"log_level" validate:"required,oneof=debug info warn error"`
-	AWSConfig       AWSConfig      `yaml:"aws" validate:"required"`
-	AssetsURL       string         `yaml:"assets_url" validate:"required,url"`
-	ImageServiceURL string         `yaml:"image_service_url" validate:"required,url"`
-	OpenAIAPIKey    string         `yaml:"openai_api_key" validate:"required"`
-	DBPath          string         `yaml:"db_path"`
+	Host            string          `yaml:"host" validate:"required"`
+	Port            int             `yaml:"port" validate:"required,gt=0"`
+	DBURL           string          `yaml:"mongo_uri" validate:"required"`
+	DBName          string          `yaml:"mongo_db" validate:"required"`
+	JWTSecret       string          `yaml:"jwt_secret" validate:"required"`
+	Telegram        TelegramConfig  `yaml:"telegram" validate:"required"`
+	LogLevel        string          `yaml:"log_level" validate:"required,oneof=debug info warn error"`
+	AWSConfig       AWSConfig       `yaml:"aws" validate:"required"`
+	AssetsURL       string          `yaml:"assets_url" validate:"required,url"`
+	ImageServiceURL string          `yaml:"image_service_url" validate:"required,url"`
+	OpenAIAPIKey    string          `yaml:"openai_api_key" validate:"required"`
+	DBPath          string          `yaml:"db_path"`
+	RateLimit       RateLimitConfig `yaml:"rate_limit"`
+	RepostWindow    int             `yaml:"repost_window_days" validate:"gte=0"` // Days in which reposting an own collaboration is denied, 0 allows it
+}
+
+type RateLimitConfig struct {
+	Store  string                      `yaml:"store" validate:"omitempty,oneof=memory sqlite"`
+	Routes map[string]ratelimit.Policy `yaml:"routes"` // Keyed by route name, e.g. follow or create_collaboration
 }
 
 type AWSConfig struct {
@@ -36,7 +44,10 @@ type TelegramConfig struct {
 	CommunityChatID  int64  `yaml:"community_chat_id" validate:"required"`
 	WebAppURL        string `yaml:"webapp_url" validate:"required,url"`
 	BotWebApp        string `yaml:"bot_webapp" validate:"required"`
-	WebhookURL       string `yaml:"webhook_url" validate:"required,url"`
+	WebhookURL       string `yaml:"webhook_url" validate:"omitempty,url"` // Required unless polling
+	WebhookSecret    string `yaml:"webhook_secret"`
+	UsePolling       bool   `yaml:"use_polling"`                      // getUpdates instead of the webhook, for local development
+	APIURL           string `yaml:"api_url" validate:"omitempty,url"` // Bot API server, api.telegram.org if empty
 	TestNotification bool   `yaml:"test_notification"`
 }
 
@@ -59,9 +70,18 @@ func LoadConfig() (*Config, error) {
 	}
 
 	validate := validator.New()
+	validate.RegisterStructValidation(validateTelegramConfig, TelegramConfig{})
 	if err := validate.Struct(&cfg); err != nil {
 		return nil, fmt.Errorf("configuration validation error: %w", err)
 	}
 
 	return &cfg, nil
 }
+
+// validateTelegramConfig requires a webhook URL unless the bot polls
+func validateTelegramConfig(sl validator.StructLevel) {
+	cfg := sl.Current().Interface().(TelegramConfig)
+	if !cfg.UsePolling && cfg.WebhookURL == "" {
+		sl.ReportError(cfg.WebhookURL, "WebhookURL", "webhook_url", "required", "")
+	}
+}
@@ -0,0 +1,77 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/peatch-io/peatch/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const baseConfig = `
+host: localhost
+port: 8080
+mongo_uri: mongodb://localhost
+mongo_db: peatch
+jwt_secret: secret
+log_level: info
+assets_url: https://assets.example.com
+image_service_url: https://images.example.com
+openai_api_key: key
+aws:
+  access_key_id: key
+  secret_access_key: secret
+  bucket: bucket
+  endpoint: https://s3.example.com
+telegram:
+  bot_token: token
+  admin_bot_token: admin-token
+  admin_chat_id: 1
+  community_chat_id: 2
+  webapp_url: https://app.example.com
+  bot_webapp: peatch_bot/app
+`
+
+func loadConfig(t *testing.T, telegram ...string) (*config.Config, error) {
+	t.Helper()
+
+	var extra string
+	for _, line := range telegram {
+		extra += "  " + line + "\n"
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte(strings.TrimPrefix(baseConfig, "\n")+extra), 0o644))
+	t.Setenv("CONFIG_FILE_PATH", path)
+
+	return config.LoadConfig()
+}
+
+func TestLoadConfig_WebhookURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		telegram []string
+		err      string
+	}{
+		{"webhook", []string{"webhook_url: https://api.example.com/webhook"}, ""},
+		{"polling without webhook", []string{"use_polling: true"}, ""},
+		{"neither", nil, "WebhookURL"},
+		{"invalid webhook", []string{"webhook_url: not a url"}, "WebhookURL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadConfig(t, tt.telegram...)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "token", cfg.Telegram.BotToken)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/peatch-io/peatch/internal/db"
+	"strings"
 	"time"
 )
 
@@ -46,7 +47,9 @@ type AdminJWTClaims struct {
 }
 
 type VerificationUpdateRequest struct {
-	Status db.VerificationStatus `json:"status"`
+	Status     db.VerificationStatus `json:"status"`
+	ReasonCode string                `json:"reason_code,omitempty"` // Code from the denial reasons catalogue
+	Comment    *string               `json:"comment,omitempty"`     // Optional free text shown to the user
 } // @Name VerificationUpdateRequest
 
 func (r VerificationUpdateRequest) Validate() error {
@@ -62,6 +65,39 @@ func (r VerificationUpdateRequest) Validate() error {
 		return fmt.Errorf("invalid status: %s", r.Status)
 	}
 
+	if r.Status != db.VerificationStatusDenied && (r.ReasonCode != "" || r.Comment != nil) {
+		return fmt.Errorf("reason_code and comment are only allowed when denying")
+	}
+
+	if r.Comment != nil && len(*r.Comment) > 500 {
+		return fmt.Errorf("comment must not exceed 500 characters")
+	}
+
+	return nil
+}
+
+type CreateDenialReasonRequest struct {
+	Code   string `json:"code"`
+	TextEN string `json:"text_en"`
+	TextRU string `json:"text_ru"`
+} // @Name CreateDenialReasonRequest
+
+func (r CreateDenialReasonRequest) Validate() error {
+	if r.Code == "" {
+		return fmt.Errorf("code is required")
+	}
+	if len(r.Code) > 50 {
+		return fmt.Errorf("code must not exceed 50 characters")
+	}
+	if r.TextEN == "" {
+		return fmt.Errorf("text_en is required")
+	}
+	if r.TextRU == "" {
+		return fmt.Errorf("text_ru is required")
+	}
+	if len(r.TextEN) > 500 || len(r.TextRU) > 500 {
+		return fmt.Errorf("text must not exceed 500 characters")
+	}
 	return nil
 }
 
@@ -131,3 +167,177 @@ func (r AdminCreateCollaborationRequest) Validate() error {
 	}
 	return nil
 }
+
+type BroadcastSegmentRequest struct {
+	Statuses       []db.VerificationStatus `json:"statuses,omitempty"`
+	OpportunityIDs []string                `json:"opportunity_ids,omitempty"`
+	BadgeIDs       []string                `json:"badge_ids,omitempty"`
+	CountryCodes   []string                `json:"country_codes,omitempty"`
+	Languages      []db.LanguageCode       `json:"languages,omitempty"`
+	ActiveAfter    *time.Time              `json:"active_after,omitempty"`
+	ActiveBefore   *time.Time              `json:"active_before,omitempty"`
+} // @Name BroadcastSegmentRequest
+
+func (r BroadcastSegmentRequest) Validate() error {
+	for _, status := range r.Statuses {
+		switch status {
+		case db.VerificationStatusPending, db.VerificationStatusVerified, db.VerificationStatusDenied,
+			db.VerificationStatusBlocked, db.VerificationStatusUnverified:
+		default:
+			return fmt.Errorf("invalid status: %s", status)
+		}
+	}
+	for _, lang := range r.Languages {
+		if lang != db.LanguageEN && lang != db.LanguageRU {
+			return fmt.Errorf("invalid language: %s", lang)
+		}
+	}
+	for _, code := range r.CountryCodes {
+		if len(code) != 2 {
+			return fmt.Errorf("invalid country code: %s", code)
+		}
+	}
+	if r.ActiveAfter != nil && r.ActiveBefore != nil && !r.ActiveAfter.Before(*r.ActiveBefore) {
+		return fmt.Errorf("active_after must be before active_before")
+	}
+	return nil
+}
+
+// ToSegment converts the request to a db.BroadcastSegment
+func (r BroadcastSegmentRequest) ToSegment() db.BroadcastSegment {
+	return db.BroadcastSegment{
+		Statuses:       r.Statuses,
+		OpportunityIDs: r.OpportunityIDs,
+		BadgeIDs:       r.BadgeIDs,
+		CountryCodes:   r.CountryCodes,
+		Languages:      r.Languages,
+		ActiveAfter:    r.ActiveAfter,
+		ActiveBefore:   r.ActiveBefore,
+	}
+}
+
+type CreateBroadcastRequest struct {
+	TextEN       string                  `json:"text_en"`
+	TextRU       string                  `json:"text_ru"`
+	ImageURL     *string                 `json:"image_url,omitempty"`
+	ButtonTextEN *string                 `json:"button_text_en,omitempty"`
+	ButtonTextRU *string                 `json:"button_text_ru,omitempty"`
+	ButtonPath   *string                 `json:"button_path,omitempty"` // Web app path the button opens, e.g. /collaborations
+	Segment      BroadcastSegmentRequest `json:"segment"`
+} // @Name CreateBroadcastRequest
+
+func (r CreateBroadcastRequest) Validate() error {
+	if r.TextEN == "" {
+		return fmt.Errorf("text_en is required")
+	}
+
+	// Captions are capped at 1024 characters, messages at 4096
+	maxLen := 4096
+	if r.ImageURL != nil {
+		if *r.ImageURL == "" {
+			return fmt.Errorf("when provided, image_url must not be empty")
+		}
+		maxLen = 1024
+	}
+	if len([]rune(r.TextEN)) > maxLen || len([]rune(r.TextRU)) > maxLen {
+		return fmt.Errorf("text must not exceed %d characters", maxLen)
+	}
+
+	if r.ButtonPath != nil {
+		if !strings.HasPrefix(*r.ButtonPath, "/") {
+			return fmt.Errorf("button_path must start with /")
+		}
+		if r.ButtonTextEN == nil || *r.ButtonTextEN == "" {
+			return fmt.Errorf("button_text_en is required with button_path")
+		}
+	} else if r.ButtonTextEN != nil || r.ButtonTextRU != nil {
+		return fmt.Errorf("button_path is required with button text")
+	}
+
+	return r.Segment.Validate()
+}
+
+type BroadcastPreviewResponse struct {
+	Recipients int `json:"recipients"`
+} // @Name BroadcastPreviewResponse
+
+type MergeBadgeRequest struct {
+	TargetID string `json:"target_id"`
+} // @Name MergeBadgeRequest
+
+func (r MergeBadgeRequest) Validate() error {
+	if r.TargetID == "" {
+		return fmt.Errorf("target_id is required")
+	}
+	return nil
+}
+
+type OpportunityRequest struct {
+	Text          string `json:"text"`
+	TextRU        string `json:"text_ru"`
+	Description   string `json:"description"`
+	DescriptionRU string `json:"description_ru"`
+	Icon          string `json:"icon"`  // Hex code point of the icon glyph
+	Color         string `json:"color"` // Hex RGB without #
+} // @Name OpportunityRequest
+
+func (r OpportunityRequest) Validate() error {
+	if r.Text == "" || r.TextRU == "" {
+		return fmt.Errorf("text and text_ru are required")
+	}
+	if len(r.Text) > 100 || len(r.TextRU) > 100 {
+		return fmt.Errorf("text must not exceed 100 characters")
+	}
+	if r.Description == "" || r.DescriptionRU == "" {
+		return fmt.Errorf("description and description_ru are required")
+	}
+	if len(r.Description) > 500 || len(r.DescriptionRU) > 500 {
+		return fmt.Errorf("description must not exceed 500 characters")
+	}
+	if len(r.Icon) != 4 || !isHex(r.Icon) {
+		return fmt.Errorf("icon must be exactly 4 hexadecimal characters")
+	}
+	if len(r.Color) != 6 || !isHex(r.Color) {
+		return fmt.Errorf("color must be exactly 6 hexadecimal characters")
+	}
+	return nil
+}
+
+func (r OpportunityRequest) ToOpportunity(id string) db.Opportunity {
+	return db.Opportunity{
+		ID:            id,
+		Text:          r.Text,
+		TextRU:        r.TextRU,
+		Description:   r.Description,
+		DescriptionRU: r.DescriptionRU,
+		Icon:          strings.ToLower(r.Icon),
+		Color:         strings.ToLower(r.Color),
+	}
+}
+
+type ReorderOpportunitiesRequest struct {
+	IDs []string `json:"ids"` // Listed first, in this order; the rest keep their relative order
+} // @Name ReorderOpportunitiesRequest
+
+func (r ReorderOpportunitiesRequest) Validate() error {
+	if len(r.IDs) == 0 {
+		return fmt.Errorf("ids are required")
+	}
+	seen := make(map[string]bool, len(r.IDs))
+	for _, id := range r.IDs {
+		if seen[id] {
+			return fmt.Errorf("duplicate id %s", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/peatch-io/peatch/internal/db"
+	"strings"
 	"time"
 )
 
@@ -13,6 +14,7 @@ type OkResponse struct {
 
 type ErrorResponse struct {
 	Error string `json:"error"`
+	Code  string `json:"code,omitempty"` // Machine-readable reason, set for errors clients handle specially
 } // @Name ErrorResponse
 
 type StatusResponse struct {
@@ -38,15 +40,55 @@ func (a AuthTelegramRequest) Validate() error {
 }
 
 type AuthResponse struct {
-	Token string       `json:"token"`
-	User  UserResponse `json:"user"`
+	Token            string       `json:"token"`
+	ExpiresAt        time.Time    `json:"expires_at"`
+	RefreshToken     string       `json:"refresh_token"`
+	RefreshExpiresAt time.Time    `json:"refresh_expires_at"`
+	User             UserResponse `json:"user"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+} // @Name RefreshTokenRequest
+
+func (r RefreshTokenRequest) Validate() error {
+	if r.RefreshToken == "" {
+		return fmt.Errorf("refresh_token is required")
+	}
+
+	return nil
+}
+
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	Country    string    `json:"country"`
+	City       string    `json:"city"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Current    bool      `json:"current"`
+} // @Name SessionResponse
+
+func ToSessionResponse(session db.Session, currentSessionID string) SessionResponse {
+	return SessionResponse{
+		ID:         session.ID,
+		Device:     session.Device,
+		UserAgent:  session.UserAgent,
+		Country:    session.Country,
+		City:       session.City,
+		CreatedAt:  session.CreatedAt,
+		LastUsedAt: session.LastUsedAt,
+		Current:    session.ID == currentSessionID,
+	}
 }
 
 type JWTClaims struct {
 	jwt.RegisteredClaims
-	UID    string `json:"uid"`
-	ChatID int64  `json:"chat_id"`
-	Lang   string `json:"lang"`
+	UID       string `json:"uid"`
+	ChatID    int64  `json:"chat_id"`
+	Lang      string `json:"lang"`
+	SessionID string `json:"sid,omitempty"`
 }
 
 type Link struct {
@@ -138,15 +180,86 @@ func (r UpdateUserLinksRequest) Validate() error {
 	return nil
 }
 
+// Compensation describes how a collaboration pays. Amounts, currency and
+// period are only accepted for the paid type.
+type Compensation struct {
+	Type      db.CompensationType   `json:"type" enums:"paid,equity,revshare,volunteer,barter"`
+	MinAmount *float64              `json:"min_amount,omitempty"`
+	MaxAmount *float64              `json:"max_amount,omitempty"`
+	Currency  string                `json:"currency,omitempty"` // ISO 4217, e.g. EUR
+	Period    db.CompensationPeriod `json:"period,omitempty" enums:"project,hour,day,week,month"`
+} // @Name Compensation
+
+func (c Compensation) Validate() error {
+	if !db.IsValidCompensationType(c.Type) {
+		return fmt.Errorf("compensation type must be paid, equity, revshare, volunteer or barter")
+	}
+	if c.Type != db.CompensationPaid {
+		if c.MinAmount != nil || c.MaxAmount != nil || c.Currency != "" || c.Period != "" {
+			return fmt.Errorf("compensation amounts are only allowed for the paid type")
+		}
+		return nil
+	}
+	if (c.MinAmount != nil && *c.MinAmount < 0) || (c.MaxAmount != nil && *c.MaxAmount < 0) {
+		return fmt.Errorf("compensation amounts must not be negative")
+	}
+	if c.MinAmount != nil && c.MaxAmount != nil && *c.MinAmount > *c.MaxAmount {
+		return fmt.Errorf("compensation min_amount must not exceed max_amount")
+	}
+	if (c.MinAmount != nil || c.MaxAmount != nil) && len(c.Currency) != 3 {
+		return fmt.Errorf("compensation currency must be a three-letter code")
+	}
+	if c.Currency != "" && c.MinAmount == nil && c.MaxAmount == nil {
+		return fmt.Errorf("compensation currency requires an amount")
+	}
+	if c.Period != "" && !db.IsValidCompensationPeriod(c.Period) {
+		return fmt.Errorf("compensation period must be project, hour, day, week or month")
+	}
+	return nil
+}
+
+func ToCompensationResponse(c *db.Compensation) *Compensation {
+	if c == nil {
+		return nil
+	}
+	return &Compensation{
+		Type:      c.Type,
+		MinAmount: c.MinAmount,
+		MaxAmount: c.MaxAmount,
+		Currency:  c.Currency,
+		Period:    c.Period,
+	}
+}
+
 type CreateCollaboration struct {
-	OpportunityID string   `json:"opportunity_id"`
-	Title         string   `json:"title"`
-	Description   string   `json:"description"`
-	IsPayable     bool     `json:"is_payable"`
-	LocationID    *string  `json:"location_id"`
-	BadgeIDs      []string `json:"badge_ids"`
+	OpportunityID string        `json:"opportunity_id"`
+	Title         string        `json:"title"`
+	Description   string        `json:"description"`
+	IsPayable     bool          `json:"is_payable"` // Deprecated, use compensation. True without compensation means paid.
+	LocationID    *string       `json:"location_id"`
+	BadgeIDs      []string      `json:"badge_ids"`
+	Compensation  *Compensation `json:"compensation,omitempty"` // Takes precedence over is_payable
+	// ExpiresAt takes the collaboration out of the feed, set at creation only
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 } // @Name CreateCollaboration
 
+// maxCollaborationLifetime bounds how far ahead an expiry can be set
+const maxCollaborationLifetime = 180 * 24 * time.Hour
+
+func validateExpiresAt(expiresAt *time.Time) error {
+	if expiresAt == nil {
+		return nil
+	}
+	now := time.Now()
+	if !expiresAt.After(now) {
+		return fmt.Errorf("expires_at must be in the future")
+	}
+	if expiresAt.After(now.Add(maxCollaborationLifetime)) {
+		return fmt.Errorf("expires_at must be within 180 days")
+	}
+	return nil
+}
+
 func (r CreateCollaboration) Validate() error {
 	if r.OpportunityID == "" {
 		return fmt.Errorf("opportunity_id is required")
@@ -169,9 +282,254 @@ func (r CreateCollaboration) Validate() error {
 	if len(r.BadgeIDs) == 0 {
 		return fmt.Errorf("badge_ids must contain at least one element")
 	}
+	if r.Compensation != nil {
+		if err := r.Compensation.Validate(); err != nil {
+			return err
+		}
+	}
+	return validateExpiresAt(r.ExpiresAt)
+}
+
+// ToCompensation is the requested compensation, paid when only the
+// deprecated is_payable is set
+func (r CreateCollaboration) ToCompensation() *db.Compensation {
+	if r.Compensation == nil {
+		if r.IsPayable {
+			return &db.Compensation{Type: db.CompensationPaid}
+		}
+		return nil
+	}
+	return &db.Compensation{
+		Type:      r.Compensation.Type,
+		MinAmount: r.Compensation.MinAmount,
+		MaxAmount: r.Compensation.MaxAmount,
+		Currency:  strings.ToUpper(r.Compensation.Currency),
+		Period:    r.Compensation.Period,
+	}
+}
+
+type CloseCollaborationRequest struct {
+	// Status is in_progress, filled or closed, closed by default
+	Status db.CollaborationStatus `json:"status,omitempty" enums:"in_progress,filled,closed"`
+} // @Name CloseCollaborationRequest
+
+func (r CloseCollaborationRequest) Validate() error {
+	switch r.Status {
+	case "", db.CollaborationStatusInProgress, db.CollaborationStatusFilled, db.CollaborationStatusClosed:
+		return nil
+	default:
+		return fmt.Errorf("status must be in_progress, filled or closed")
+	}
+}
+
+// TargetStatus is the requested status, closed when none is given
+func (r CloseCollaborationRequest) TargetStatus() db.CollaborationStatus {
+	if r.Status == "" {
+		return db.CollaborationStatusClosed
+	}
+	return r.Status
+}
+
+type ReopenCollaborationRequest struct {
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+} // @Name ReopenCollaborationRequest
+
+func (r ReopenCollaborationRequest) Validate() error {
+	return validateExpiresAt(r.ExpiresAt)
+}
+
+// CollaborationMemberRequest sets the role of a team member
+type CollaborationMemberRequest struct {
+	Role db.CollaborationRole `json:"role"`
+} // @Name CollaborationMemberRequest
+
+func (r CollaborationMemberRequest) Validate() error {
+	if !db.IsValidCollaborationRole(r.Role) {
+		return fmt.Errorf("role must be owner or editor")
+	}
+	return nil
+}
+
+// CollaborationInviteResponse is an invitation to join the team of a collaboration
+type CollaborationInviteResponse struct {
+	CollaborationID string               `json:"collaboration_id"`
+	Title           string               `json:"title"`
+	Role            db.CollaborationRole `json:"role"`
+	CreatedAt       time.Time            `json:"created_at"`
+} // @Name CollaborationInviteResponse
+
+func ToCollaborationInviteResponse(invite db.CollaborationInvite) CollaborationInviteResponse {
+	return CollaborationInviteResponse{
+		CollaborationID: invite.CollaborationID,
+		Title:           invite.Title,
+		Role:            invite.Role,
+		CreatedAt:       invite.CreatedAt,
+	}
+}
+
+// ApplyRequest is the pitch sent along with interest in a collaboration
+type ApplyRequest struct {
+	Message string `json:"message"`
+	Links   []Link `json:"links"`
+} // @Name ApplyRequest
+
+func (r ApplyRequest) Validate() error {
+	if len(r.Message) > 1000 {
+		return fmt.Errorf("message must not exceed 1000 characters")
+	}
+	if len(r.Links) > 5 {
+		return fmt.Errorf("links must not contain more than 5 elements")
+	}
+	return UpdateUserLinksRequest{Links: r.Links}.Validate()
+}
+
+func (r ApplyRequest) ToLinks() []db.Link {
+	links := make([]db.Link, 0, len(r.Links))
+	for _, link := range r.Links {
+		links = append(links, db.Link{
+			URL:   link.URL,
+			Label: link.Label,
+			Type:  link.Type,
+			Order: link.Order,
+		})
+	}
+	return links
+}
+
+// CommentRequest posts a question on a collaboration, or a reply in a thread
+type CommentRequest struct {
+	Body     string  `json:"body"`
+	ParentID *string `json:"parent_id,omitempty"` // Comment to reply to
+} // @Name CommentRequest
+
+func (r CommentRequest) Validate() error {
+	return validateCommentBody(r.Body)
+}
+
+// UpdateCommentRequest edits the text of a comment
+type UpdateCommentRequest struct {
+	Body string `json:"body"`
+} // @Name UpdateCommentRequest
+
+func (r UpdateCommentRequest) Validate() error {
+	return validateCommentBody(r.Body)
+}
+
+func validateCommentBody(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("body is required")
+	}
+	if len(body) > 1000 {
+		return fmt.Errorf("body must not exceed 1000 characters")
+	}
+	return nil
+}
+
+// ReportCommentRequest flags a comment to the moderators
+type ReportCommentRequest struct {
+	Reason string `json:"reason"`
+} // @Name ReportCommentRequest
+
+func (r ReportCommentRequest) Validate() error {
+	if len(r.Reason) > 500 {
+		return fmt.Errorf("reason must not exceed 500 characters")
+	}
+	return nil
+}
+
+// SavedSearchRequest saves the feed's search text and filters for alerts
+type SavedSearchRequest struct {
+	Name              string                `json:"name"`
+	Query             string                `json:"query"`
+	OpportunityIDs    []string              `json:"opportunity_ids"`
+	BadgeIDs          []string              `json:"badge_ids"`
+	BadgeMatch        string                `json:"badge_match,omitempty" enums:"any,all"` // any by default
+	CountryCode       string                `json:"country_code,omitempty"`
+	CityID            string                `json:"city_id,omitempty"`
+	Payable           bool                  `json:"payable,omitempty"`
+	CompensationTypes []db.CompensationType `json:"compensation_types,omitempty"` // Compensated in any of these ways
+} // @Name SavedSearchRequest
+
+func (r SavedSearchRequest) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(r.Name) > 100 {
+		return fmt.Errorf("name must not exceed 100 characters")
+	}
+	if len(r.Query) > 255 {
+		return fmt.Errorf("query must not exceed 255 characters")
+	}
+	if r.BadgeMatch != "" && r.BadgeMatch != "any" && r.BadgeMatch != "all" {
+		return fmt.Errorf("badge_match must be any or all")
+	}
+	if r.CountryCode != "" && len(r.CountryCode) != 2 {
+		return fmt.Errorf("country_code must be a two-letter code")
+	}
+	for _, t := range r.CompensationTypes {
+		if !db.IsValidCompensationType(t) {
+			return fmt.Errorf("compensation_types must be paid, equity, revshare, volunteer or barter")
+		}
+	}
+	if r.Query == "" && len(r.OpportunityIDs) == 0 && len(r.BadgeIDs) == 0 &&
+		r.CountryCode == "" && r.CityID == "" && !r.Payable && len(r.CompensationTypes) == 0 {
+		return fmt.Errorf("a saved search needs a query or at least one filter")
+	}
 	return nil
 }
 
+func (r SavedSearchRequest) ToSavedSearch(userID string) db.SavedSearch {
+	return db.SavedSearch{
+		UserID: userID,
+		Name:   r.Name,
+		Query:  r.Query,
+		Filters: db.SavedSearchFilters{
+			OpportunityIDs:    r.OpportunityIDs,
+			BadgeIDs:          r.BadgeIDs,
+			MatchAllBadges:    r.BadgeMatch == "all",
+			CountryCode:       strings.ToUpper(r.CountryCode),
+			CityID:            r.CityID,
+			PayableOnly:       r.Payable,
+			CompensationTypes: r.CompensationTypes,
+		},
+	}
+}
+
+type SavedSearchResponse struct {
+	ID                string                `json:"id"`
+	Name              string                `json:"name"`
+	Query             string                `json:"query,omitempty"`
+	OpportunityIDs    []string              `json:"opportunity_ids,omitempty"`
+	BadgeIDs          []string              `json:"badge_ids,omitempty"`
+	BadgeMatch        string                `json:"badge_match"`
+	CountryCode       string                `json:"country_code,omitempty"`
+	CityID            string                `json:"city_id,omitempty"`
+	Payable           bool                  `json:"payable"`
+	CreatedAt         time.Time             `json:"created_at"`
+	CompensationTypes []db.CompensationType `json:"compensation_types,omitempty"`
+} // @Name SavedSearchResponse
+
+func ToSavedSearchResponse(search db.SavedSearch) SavedSearchResponse {
+	badgeMatch := "any"
+	if search.Filters.MatchAllBadges {
+		badgeMatch = "all"
+	}
+
+	return SavedSearchResponse{
+		ID:                search.ID,
+		Name:              search.Name,
+		Query:             search.Query,
+		OpportunityIDs:    search.Filters.OpportunityIDs,
+		BadgeIDs:          search.Filters.BadgeIDs,
+		BadgeMatch:        badgeMatch,
+		CountryCode:       search.Filters.CountryCode,
+		CityID:            search.Filters.CityID,
+		Payable:           search.Filters.PayableOnly,
+		CreatedAt:         search.CreatedAt,
+		CompensationTypes: search.Filters.CompensationTypes,
+	}
+}
+
 type CreateBadgeRequest struct {
 	Text  string `json:"text"`
 	Icon  string `json:"icon"`
@@ -223,6 +581,8 @@ type UserResponse struct {
 	UpdatedAt          time.Time             `json:"updated_at"`
 	LastActiveAt       *time.Time            `json:"last_active_at"`
 	VerificationStatus db.VerificationStatus `json:"verification_status"`
+	DenialReason       *DenialReasonResponse `json:"denial_reason,omitempty"`
+	DenialComment      *string               `json:"denial_comment,omitempty"`
 } // @Name UserResponse
 
 func ToUserResponse(user db.User) UserResponse {
@@ -255,6 +615,23 @@ func ToUserResponse(user db.User) UserResponse {
 		UpdatedAt:          user.UpdatedAt,
 		VerificationStatus: user.VerificationStatus,
 		HiddenAt:           user.HiddenAt,
+		DenialReason:       ToDenialReasonResponse(user.DenialReason, user.LanguageCode),
+		DenialComment:      user.DenialComment,
+	}
+}
+
+type DenialReasonResponse struct {
+	Code string `json:"code"`
+	Text string `json:"text"`
+} // @Name DenialReasonResponse
+
+func ToDenialReasonResponse(reason *db.DenialReason, lang db.LanguageCode) *DenialReasonResponse {
+	if reason == nil {
+		return nil
+	}
+	return &DenialReasonResponse{
+		Code: reason.Code,
+		Text: reason.LocalizedText(lang),
 	}
 }
 
@@ -271,6 +648,7 @@ type UserProfileResponse struct {
 	Links         []Link                `json:"links"`
 	LastActiveAt  *time.Time            `json:"last_active_at"`
 	Username      string                `json:"username"`
+	DistanceKm    *float64              `json:"distance_km,omitempty"` // Set when listing near a city
 } // @Name UserProfileResponse
 
 func ToUserProfile(user db.User) UserProfileResponse {
@@ -307,25 +685,35 @@ func ToUserProfile(user db.User) UserProfileResponse {
 		Links:         ToLinkResponseList(user.Links),
 		LastActiveAt:  user.LastActiveAt,
 		Username:      user.Username,
+		DistanceKm:    user.DistanceKm,
 	}
 }
 
 type BadgeResponse struct {
-	ID    string `json:"id"`
-	Text  string `json:"text"`
-	Icon  string `json:"icon"`
-	Color string `json:"color"`
+	ID     string         `json:"id"`
+	Text   string         `json:"text"`
+	Icon   string         `json:"icon"`
+	Color  string         `json:"color"`
+	Status db.BadgeStatus `json:"status,omitempty"` // Only set in badge lists, pending badges are shown to their creator alone
 } // @Name BadgeResponse
 
 func ToBadgeResponse(badge db.Badge) BadgeResponse {
 	return BadgeResponse{
-		ID:    badge.ID,
-		Text:  badge.Text,
-		Icon:  badge.Icon,
-		Color: badge.Color,
+		ID:     badge.ID,
+		Text:   badge.Text,
+		Icon:   badge.Icon,
+		Color:  badge.Color,
+		Status: badge.Status,
 	}
 }
 
+// DuplicateBadgeResponse is returned with 409 when a similar badge already exists
+type DuplicateBadgeResponse struct {
+	Error string        `json:"error"`
+	Code  string        `json:"code"`
+	Badge BadgeResponse `json:"badge"`
+} // @Name DuplicateBadgeResponse
+
 func ToBadgeResponseList(badges []db.Badge) []BadgeResponse {
 	badgeResponses := make([]BadgeResponse, len(badges))
 	for i, badge := range badges {
@@ -334,6 +722,42 @@ func ToBadgeResponseList(badges []db.Badge) []BadgeResponse {
 	return badgeResponses
 }
 
+type ApplicationCollaborationResponse struct {
+	ID     string                 `json:"id"`
+	Title  string                 `json:"title"`
+	Status db.CollaborationStatus `json:"status"`
+} // @Name ApplicationCollaborationResponse
+
+type ApplicationResponse struct {
+	ID            string                           `json:"id"`
+	Message       string                           `json:"message"`
+	Links         []Link                           `json:"links"`
+	Status        db.ApplicationStatus             `json:"status"`
+	CreatedAt     time.Time                        `json:"created_at"`
+	UpdatedAt     *time.Time                       `json:"updated_at,omitempty"`
+	User          UserProfileResponse              `json:"user"`
+	Collaboration ApplicationCollaborationResponse `json:"collaboration"`
+	MatchScore    *float64                         `json:"match_score,omitempty"` // Only shown to the collaboration owner
+} // @Name ApplicationResponse
+
+func ToApplicationResponse(app db.Application) ApplicationResponse {
+	return ApplicationResponse{
+		ID:        app.ID,
+		Message:   app.Message,
+		Links:     ToLinkResponseList(app.Links),
+		Status:    app.Status,
+		CreatedAt: app.CreatedAt,
+		UpdatedAt: app.UpdatedAt,
+		User:      ToUserProfile(app.User),
+		Collaboration: ApplicationCollaborationResponse{
+			ID:     app.Collaboration.ID,
+			Title:  app.Collaboration.Title,
+			Status: app.Collaboration.Status,
+		},
+		MatchScore: app.MatchScore,
+	}
+}
+
 func ToLinkResponseList(links []db.Link) []Link {
 	linkResponses := make([]Link, len(links))
 	for i, link := range links {
@@ -369,20 +793,28 @@ func ToOpportunityResponseList(opportunities []db.Opportunity, lang db.LanguageC
 }
 
 type CollaborationResponse struct {
-	ID                 string                `json:"id"`
-	UserID             string                `json:"user_id"`
-	Title              string                `json:"title"`
-	Description        string                `json:"description"`
-	IsPayable          bool                  `json:"is_payable"`
-	Badges             []BadgeResponse       `json:"badges"`
-	Opportunity        OpportunityResponse   `json:"opportunity"`
-	Location           *CityResponse         `json:"location"`
-	CreatedAt          time.Time             `json:"created_at"`
-	UpdatedAt          time.Time             `json:"updated_at"`
-	Links              []Link                `json:"links"`
-	User               UserProfileResponse   `json:"user"`
-	VerificationStatus db.VerificationStatus `json:"verification_status"`
-	HasInterest        bool                  `json:"has_interest,omitempty"`
+	ID                 string                        `json:"id"`
+	UserID             string                        `json:"user_id"`
+	Title              string                        `json:"title"`
+	Description        string                        `json:"description"`
+	IsPayable          bool                          `json:"is_payable"` // Deprecated, same as compensation.type being paid
+	Compensation       *Compensation                 `json:"compensation,omitempty"`
+	Badges             []BadgeResponse               `json:"badges"`
+	Opportunity        OpportunityResponse           `json:"opportunity"`
+	Location           *CityResponse                 `json:"location"`
+	CreatedAt          time.Time                     `json:"created_at"`
+	UpdatedAt          time.Time                     `json:"updated_at"`
+	Links              []Link                        `json:"links"`
+	User               UserProfileResponse           `json:"user"` // The creator, also on the team as an owner
+	Team               []CollaborationMemberResponse `json:"team"`
+	VerificationStatus db.VerificationStatus         `json:"verification_status"`
+	HasInterest        bool                          `json:"has_interest,omitempty"`
+	IsSaved            bool                          `json:"is_saved"`
+	DenialReason       *DenialReasonResponse         `json:"denial_reason,omitempty"`
+	DenialComment      *string                       `json:"denial_comment,omitempty"`
+	Status             db.CollaborationStatus        `json:"status"`
+	ExpiresAt          *time.Time                    `json:"expires_at,omitempty"`
+	DistanceKm         *float64                      `json:"distance_km,omitempty"` // Set when listing near a city
 } // @Name CollaborationResponse
 
 func ToCollaborationResponse(collab db.Collaboration) CollaborationResponse {
@@ -393,14 +825,22 @@ func ToCollaborationResponse(collab db.Collaboration) CollaborationResponse {
 		Title:              collab.Title,
 		Description:        collab.Description,
 		IsPayable:          collab.IsPayable,
+		Compensation:       ToCompensationResponse(collab.Compensation),
 		Badges:             ToBadgeResponseList(collab.Badges),
 		Opportunity:        ToOpportunityResponseList([]db.Opportunity{collab.Opportunity}, db.LanguageEN)[0],
 		CreatedAt:          collab.CreatedAt,
 		UpdatedAt:          collab.UpdatedAt,
 		Links:              ToLinkResponseList(collab.Links),
 		User:               ToUserProfile(collab.User),
+		Team:               ToCollaborationMemberResponseList(collab.Team),
 		VerificationStatus: collab.VerificationStatus,
 		HasInterest:        collab.HasInterest,
+		IsSaved:            collab.IsSaved,
+		DenialReason:       ToDenialReasonResponse(collab.DenialReason, collab.User.LanguageCode),
+		DenialComment:      collab.DenialComment,
+		Status:             collab.Status,
+		ExpiresAt:          collab.ExpiresAt,
+		DistanceKm:         collab.DistanceKm,
 	}
 
 	if collab.Location != nil {
@@ -410,3 +850,132 @@ func ToCollaborationResponse(collab db.Collaboration) CollaborationResponse {
 
 	return response
 }
+
+type CollaborationMemberResponse struct {
+	User      UserProfileResponse  `json:"user"`
+	Role      db.CollaborationRole `json:"role"`
+	CreatedAt time.Time            `json:"created_at"`
+} // @Name CollaborationMemberResponse
+
+func ToCollaborationMemberResponseList(team []db.CollaborationMember) []CollaborationMemberResponse {
+	resp := make([]CollaborationMemberResponse, len(team))
+	for i, m := range team {
+		resp[i] = CollaborationMemberResponse{
+			User:      ToUserProfile(m.User),
+			Role:      m.Role,
+			CreatedAt: m.CreatedAt,
+		}
+	}
+	return resp
+}
+
+// SavedCollaborationResponse flags bookmarks that can't be applied to anymore
+// instead of dropping them from the list
+type SavedCollaborationResponse struct {
+	Collaboration CollaborationResponse `json:"collaboration"`
+	SavedAt       time.Time             `json:"saved_at"`
+	IsClosed      bool                  `json:"is_closed"` // No longer open for applications
+	IsHidden      bool                  `json:"is_hidden"` // Hidden by the owner or moderation, only the ID and title are shown
+} // @Name SavedCollaborationResponse
+
+func ToSavedCollaborationResponse(saved db.SavedCollaboration) SavedCollaborationResponse {
+	collab := saved.Collaboration
+	if saved.Hidden {
+		collab = db.Collaboration{ID: collab.ID, UserID: collab.UserID, Title: collab.Title, Status: collab.Status, IsSaved: true}
+	}
+
+	return SavedCollaborationResponse{
+		Collaboration: ToCollaborationResponse(collab),
+		SavedAt:       saved.SavedAt,
+		IsClosed:      saved.Collaboration.Status != db.CollaborationStatusOpen,
+		IsHidden:      saved.Hidden,
+	}
+}
+
+type CommentResponse struct {
+	ID              string              `json:"id"`
+	CollaborationID string              `json:"collaboration_id"`
+	ParentID        *string             `json:"parent_id,omitempty"`
+	User            UserProfileResponse `json:"user"`
+	Body            string              `json:"body"`
+	CreatedAt       time.Time           `json:"created_at"`
+	EditedAt        *time.Time          `json:"edited_at,omitempty"`
+	IsDeleted       bool                `json:"is_deleted"` // Only kept as the head of a thread, without text or author
+	IsPinned        bool                `json:"is_pinned"`
+	Replies         []CommentResponse   `json:"replies,omitempty"`
+} // @Name CommentResponse
+
+func ToCommentResponse(comment db.Comment) CommentResponse {
+	resp := CommentResponse{
+		ID:              comment.ID,
+		CollaborationID: comment.CollaborationID,
+		ParentID:        comment.ParentID,
+		User:            ToUserProfile(comment.User),
+		Body:            comment.Body,
+		CreatedAt:       comment.CreatedAt,
+		EditedAt:        comment.EditedAt,
+		IsDeleted:       comment.DeletedAt != nil,
+		IsPinned:        comment.PinnedAt != nil,
+	}
+
+	if comment.DeletedAt != nil {
+		resp.User = UserProfileResponse{}
+	}
+
+	for _, reply := range comment.Replies {
+		resp.Replies = append(resp.Replies, ToCommentResponse(reply))
+	}
+
+	return resp
+}
+
+// AdminCommentResponse is a reported comment in the moderation queue
+type AdminCommentResponse struct {
+	CommentResponse
+	ReportCount int        `json:"report_count"`
+	HiddenAt    *time.Time `json:"hidden_at,omitempty"`
+} // @Name AdminCommentResponse
+
+func ToAdminCommentResponse(comment db.Comment) AdminCommentResponse {
+	return AdminCommentResponse{
+		CommentResponse: ToCommentResponse(comment),
+		ReportCount:     comment.ReportCount,
+		HiddenAt:        comment.HiddenAt,
+	}
+}
+
+type DailyStatsResponse struct {
+	Date      string `json:"date"` // YYYY-MM-DD in UTC
+	Views     int    `json:"views"`
+	DeepLinks int    `json:"deep_links"`
+	Interests int    `json:"interests"`
+} // @Name DailyStatsResponse
+
+// StatsResponse has the totals of a period and its daily series, oldest first
+type StatsResponse struct {
+	Views     int                  `json:"views"`
+	DeepLinks int                  `json:"deep_links"`
+	Interests int                  `json:"interests"`
+	Daily     []DailyStatsResponse `json:"daily"`
+} // @Name StatsResponse
+
+func ToStatsResponse(series []db.DailyStats) StatsResponse {
+	resp := StatsResponse{Daily: make([]DailyStatsResponse, len(series))}
+	for i, day := range series {
+		resp.Views += day.Views
+		resp.DeepLinks += day.DeepLinks
+		resp.Interests += day.Interests
+		resp.Daily[i] = DailyStatsResponse{
+			Date:      day.Day,
+			Views:     day.Views,
+			DeepLinks: day.DeepLinks,
+			Interests: day.Interests,
+		}
+	}
+	return resp
+}
+
+type UserStatsResponse struct {
+	Profile        StatsResponse `json:"profile"`
+	Collaborations StatsResponse `json:"collaborations"` // Summed over the collaborations the user posted
+} // @Name UserStatsResponse
@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"time"
 )
 
@@ -180,3 +181,54 @@ func (s *Storage) GetAdminByID(ctx context.Context, id string) (Admin, error) {
 
 	return admin, nil
 }
+
+// AdminStats is a moderation overview of users and collaborations
+type AdminStats struct {
+	UsersTotal             int `json:"users_total"`
+	UsersVerified          int `json:"users_verified"`
+	UsersPending           int `json:"users_pending"`
+	UsersNewThisWeek       int `json:"users_new_this_week"`
+	CollaborationsTotal    int `json:"collaborations_total"`
+	CollaborationsVerified int `json:"collaborations_verified"`
+	CollaborationsPending  int `json:"collaborations_pending"`
+} // @Name AdminStats
+
+// GetAdminStats counts users and collaborations by verification status
+func (s *Storage) GetAdminStats(ctx context.Context) (AdminStats, error) {
+	weekAgo := time.Now().AddDate(0, 0, -7)
+
+	var stats AdminStats
+	err := s.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(verification_status = 'verified'), 0),
+			COALESCE(SUM(verification_status = 'pending'), 0),
+			COALESCE(SUM(created_at >= ?), 0)
+		FROM users
+	`, weekAgo).Scan(
+		&stats.UsersTotal,
+		&stats.UsersVerified,
+		&stats.UsersPending,
+		&stats.UsersNewThisWeek,
+	)
+	if err != nil {
+		return AdminStats{}, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	err = s.db.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(verification_status = 'verified'), 0),
+			COALESCE(SUM(verification_status = 'pending'), 0)
+		FROM collaborations
+	`).Scan(
+		&stats.CollaborationsTotal,
+		&stats.CollaborationsVerified,
+		&stats.CollaborationsPending,
+	)
+	if err != nil {
+		return AdminStats{}, fmt.Errorf("failed to count collaborations: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AnalyticsEventKind is what happened to a profile or collaboration
+type AnalyticsEventKind string
+
+const (
+	AnalyticsEventView     AnalyticsEventKind = "view"
+	AnalyticsEventDeepLink AnalyticsEventKind = "deep_link" // Opened from a startapp or /start link
+	AnalyticsEventInterest AnalyticsEventKind = "interest"  // Someone applied
+)
+
+// AnalyticsTarget is what an event is about
+type AnalyticsTarget string
+
+const (
+	AnalyticsTargetUser          AnalyticsTarget = "user"
+	AnalyticsTargetCollaboration AnalyticsTarget = "collaboration"
+)
+
+// analyticsDayFormat is how days are stored, always in UTC
+const analyticsDayFormat = "2006-01-02"
+
+// AnalyticsEvent is a raw event, kept until its day is rolled up
+type AnalyticsEvent struct {
+	Kind     AnalyticsEventKind
+	Target   AnalyticsTarget
+	TargetID string
+	ViewerID string
+}
+
+// DailyStats are the counts of a target for one day
+type DailyStats struct {
+	Day       string `json:"day"`
+	Views     int    `json:"views"`
+	DeepLinks int    `json:"deep_links"`
+	Interests int    `json:"interests"`
+} // @Name DailyStats
+
+// RecordAnalyticsEvent records an event once per viewer, target and day.
+// Events of missing targets and of owners looking at their own profile or
+// collaboration are dropped.
+func (s *Storage) RecordAnalyticsEvent(ctx context.Context, event AnalyticsEvent) error {
+	var exists string
+	switch event.Target {
+	case AnalyticsTargetUser:
+		exists = `EXISTS (SELECT 1 FROM users WHERE id = ?1) AND ?1 != ?2`
+	case AnalyticsTargetCollaboration:
+		exists = `EXISTS (SELECT 1 FROM collaborations WHERE id = ?1) AND NOT EXISTS (
+			SELECT 1 FROM collaboration_members WHERE collaboration_id = ?1 AND user_id = ?2
+		)`
+	default:
+		return fmt.Errorf("unknown analytics target %q", event.Target)
+	}
+
+	now := time.Now().UTC()
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO analytics_events (kind, target_type, target_id, viewer_id, day, created_at)
+		SELECT ?3, ?4, ?1, ?2, ?5, ?6 WHERE `+exists+`
+		ON CONFLICT (kind, target_type, target_id, viewer_id, day) DO NOTHING
+	`, event.TargetID, event.ViewerID, event.Kind, event.Target, now.Format(analyticsDayFormat), now)
+	if err != nil {
+		return fmt.Errorf("failed to record analytics event: %w", err)
+	}
+
+	return nil
+}
+
+// RollupAnalytics adds the events of the days before today to the daily
+// counts and deletes them, so only today's events stay in the raw table
+func (s *Storage) RollupAnalytics(ctx context.Context, now time.Time) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	today := now.UTC().Format(analyticsDayFormat)
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO analytics_daily (target_type, target_id, day, views, deep_links, interests)
+		SELECT target_type, target_id, day,
+			SUM(kind = 'view'), SUM(kind = 'deep_link'), SUM(kind = 'interest')
+		FROM analytics_events
+		WHERE day < ?
+		GROUP BY target_type, target_id, day
+		ON CONFLICT (target_type, target_id, day) DO UPDATE SET
+			views = views + excluded.views,
+			deep_links = deep_links + excluded.deep_links,
+			interests = interests + excluded.interests
+	`, today)
+	if err != nil {
+		return 0, fmt.Errorf("failed to roll up analytics: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM analytics_events WHERE day < ?`, today)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete rolled up events: %w", err)
+	}
+	rolledUp, _ := result.RowsAffected()
+
+	return rolledUp, tx.Commit()
+}
+
+// GetDailyStats sums the counts of the targets for every day from since to
+// today, rolled up or not. Days without events are zero.
+func (s *Storage) GetDailyStats(ctx context.Context, target AnalyticsTarget, targetIDs []string, since, now time.Time) ([]DailyStats, error) {
+	from := since.UTC().Format(analyticsDayFormat)
+
+	byDay := make(map[string]DailyStats)
+	if len(targetIDs) > 0 {
+		args := []interface{}{target, from}
+		for _, id := range targetIDs {
+			args = append(args, id)
+		}
+		args = append(args, args...)
+
+		in := placeholders(len(targetIDs))
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT day, SUM(views), SUM(deep_links), SUM(interests) FROM (
+				SELECT day, views, deep_links, interests FROM analytics_daily
+				WHERE target_type = ? AND day >= ? AND target_id IN (`+in+`)
+				UNION ALL
+				SELECT day, kind = 'view', kind = 'deep_link', kind = 'interest' FROM analytics_events
+				WHERE target_type = ? AND day >= ? AND target_id IN (`+in+`)
+			)
+			GROUP BY day
+		`, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get stats: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var day DailyStats
+			if err := rows.Scan(&day.Day, &day.Views, &day.DeepLinks, &day.Interests); err != nil {
+				return nil, err
+			}
+			byDay[day.Day] = day
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	var series []DailyStats
+	last := now.UTC().Format(analyticsDayFormat)
+	for day := since.UTC(); ; day = day.AddDate(0, 0, 1) {
+		key := day.Format(analyticsDayFormat)
+		if key > last {
+			break
+		}
+		stats := byDay[key]
+		stats.Day = key
+		series = append(series, stats)
+	}
+
+	return series, nil
+}
@@ -0,0 +1,327 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	nanoid "github.com/matoous/go-nanoid/v2"
+)
+
+// ApplicationStatus is where an application to a collaboration stands. The
+// owner moves it out of pending, the applicant can withdraw it at any time.
+type ApplicationStatus string
+
+const (
+	ApplicationStatusPending     ApplicationStatus = "pending"
+	ApplicationStatusShortlisted ApplicationStatus = "shortlisted"
+	ApplicationStatusAccepted    ApplicationStatus = "accepted"
+	ApplicationStatusDeclined    ApplicationStatus = "declined"
+	ApplicationStatusWithdrawn   ApplicationStatus = "withdrawn"
+)
+
+// ErrApplicationClosed is returned when an application can't change status
+// anymore, e.g. answering one that was withdrawn
+var ErrApplicationClosed = errors.New("application is closed")
+
+// Application is a user's interest in a collaboration, with a short pitch.
+// Applications are stored in collaboration_interests.
+type Application struct {
+	ID              string            `json:"id"`
+	CollaborationID string            `json:"collaboration_id"`
+	UserID          string            `json:"user_id"`
+	Message         string            `json:"message"`
+	Links           []Link            `json:"links"`
+	Status          ApplicationStatus `json:"status"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       *time.Time        `json:"updated_at"`
+	User            User              `json:"user"`          // The applicant
+	Collaboration   Collaboration     `json:"collaboration"` // Only the ID, title, status and owner
+	MatchScore      *float64          `json:"match_score"`   // Set in the owner listing when both embeddings exist
+} // @Name Application
+
+const applicationColumns = `
+	ci.id, ci.collaboration_id, ci.user_id, COALESCE(ci.message, ''), ci.links,
+	ci.status, ci.created_at, ci.updated_at,
+	u.id, u.chat_id, u.name, u.username, u.avatar_url, u.title, u.language_code,
+	u.description, u.location, u.links, u.badges, u.opportunities, u.last_active_at,
+	c.id, c.user_id, c.title, c.status,
+	o.id, o.chat_id, o.name, o.username, o.language_code`
+
+const applicationJoins = `
+	FROM collaboration_interests ci
+	JOIN users u ON u.id = ci.user_id
+	JOIN collaborations c ON c.id = ci.collaboration_id
+	JOIN users o ON o.id = c.user_id`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanApplication(row rowScanner) (Application, error) {
+	var app Application
+	var linksJSON, userLocationJSON, userLinksJSON, userBadgesJSON, userOppsJSON sql.NullString
+	owner := &app.Collaboration.User
+
+	err := row.Scan(
+		&app.ID, &app.CollaborationID, &app.UserID, &app.Message, &linksJSON,
+		&app.Status, &app.CreatedAt, &app.UpdatedAt,
+		&app.User.ID, &app.User.ChatID, &app.User.Name, &app.User.Username, &app.User.AvatarURL,
+		&app.User.Title, &app.User.LanguageCode,
+		&app.User.Description, &userLocationJSON, &userLinksJSON, &userBadgesJSON, &userOppsJSON, &app.User.LastActiveAt,
+		&app.Collaboration.ID, &app.Collaboration.UserID, &app.Collaboration.Title, &app.Collaboration.Status,
+		&owner.ID, &owner.ChatID, &owner.Name, &owner.Username, &owner.LanguageCode,
+	)
+	if err != nil {
+		return app, err
+	}
+
+	if linksJSON.Valid && linksJSON.String != "" {
+		json.Unmarshal([]byte(linksJSON.String), &app.Links)
+	}
+	if userLocationJSON.Valid && userLocationJSON.String != "" {
+		json.Unmarshal([]byte(userLocationJSON.String), &app.User.Location)
+	}
+	if userLinksJSON.Valid && userLinksJSON.String != "" {
+		json.Unmarshal([]byte(userLinksJSON.String), &app.User.Links)
+	}
+	if userBadgesJSON.Valid && userBadgesJSON.String != "" {
+		json.Unmarshal([]byte(userBadgesJSON.String), &app.User.Badges)
+	}
+	if userOppsJSON.Valid && userOppsJSON.String != "" {
+		json.Unmarshal([]byte(userOppsJSON.String), &app.User.Opportunities)
+	}
+
+	return app, nil
+}
+
+// CreateApplication applies to an open collaboration. Applying again is only
+// possible after withdrawing.
+func (s *Storage) CreateApplication(ctx context.Context, app Application) (Application, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Application{}, err
+	}
+	defer tx.Rollback()
+
+	// Check user exists and is verified
+	var userExists bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM users
+			WHERE id = ? AND hidden_at IS NULL AND verification_status = 'verified'
+		)
+	`, app.UserID).Scan(&userExists)
+	if err != nil {
+		return Application{}, err
+	}
+	if !userExists {
+		return Application{}, ErrNotFound
+	}
+
+	// Check collaboration exists, is verified and still accepts applications
+	var collabExists bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM collaborations
+			WHERE id = ? AND verification_status = 'verified' AND hidden_at IS NULL AND status = 'open'
+		)
+	`, app.CollaborationID).Scan(&collabExists)
+	if err != nil {
+		return Application{}, err
+	}
+	if !collabExists {
+		return Application{}, ErrNotFound
+	}
+
+	var linksJSON *string
+	if len(app.Links) > 0 {
+		data, _ := json.Marshal(app.Links)
+		links := string(data)
+		linksJSON = &links
+	}
+
+	now := time.Now()
+
+	// expires_at is a leftover of interests having a TTL, applications are kept
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO collaboration_interests (id, user_id, collaboration_id, expires_at, created_at, message, links, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 'pending')
+		ON CONFLICT (user_id, collaboration_id) DO UPDATE SET
+			expires_at = EXCLUDED.expires_at,
+			created_at = EXCLUDED.created_at,
+			message = EXCLUDED.message,
+			links = EXCLUDED.links,
+			status = 'pending',
+			updated_at = NULL
+		WHERE collaboration_interests.status = 'withdrawn'
+	`, nanoid.Must(), app.UserID, app.CollaborationID, now, now, app.Message, linksJSON)
+	if err != nil {
+		return Application{}, fmt.Errorf("failed to create application: %w", err)
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return Application{}, ErrAlreadyExists
+	}
+
+	created, err := scanApplication(tx.QueryRowContext(ctx, `SELECT `+applicationColumns+applicationJoins+`
+		WHERE ci.user_id = ? AND ci.collaboration_id = ?`, app.UserID, app.CollaborationID))
+	if err != nil {
+		return Application{}, fmt.Errorf("failed to get application: %w", err)
+	}
+
+	return created, tx.Commit()
+}
+
+// GetApplicationByID returns an application if the viewer is the applicant
+// or on the team of the collaboration
+func (s *Storage) GetApplicationByID(ctx context.Context, viewerID, id string) (Application, error) {
+	app, err := scanApplication(s.db.QueryRowContext(ctx, `SELECT `+applicationColumns+applicationJoins+`
+		WHERE ci.id = ? AND (ci.user_id = ? OR c.id IN (
+			SELECT collaboration_id FROM collaboration_members WHERE user_id = ?
+		))`, id, viewerID, viewerID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Application{}, ErrNotFound
+		}
+		return Application{}, err
+	}
+
+	return app, nil
+}
+
+// ListUserApplications lists the applications a user sent, newest first
+func (s *Storage) ListUserApplications(ctx context.Context, userID string, page, perPage int) ([]Application, error) {
+	query := `SELECT ` + applicationColumns + applicationJoins + `
+		WHERE ci.user_id = ?
+		ORDER BY ci.created_at DESC`
+	args := []interface{}{userID}
+
+	if page > 0 && perPage > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, perPage, (page-1)*perPage)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	applications := []Application{}
+	for rows.Next() {
+		app, err := scanApplication(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		applications = append(applications, app)
+	}
+
+	return applications, rows.Err()
+}
+
+// ListCollaborationApplications lists everyone who applied to a collaboration
+// the member is on the team of, newest first. Withdrawn applications stay in
+// the history.
+func (s *Storage) ListCollaborationApplications(ctx context.Context, memberID, collabID string, page, perPage int) ([]Application, error) {
+	if _, err := s.collaborationRole(ctx, s.db, collabID, memberID); err != nil {
+		return nil, err
+	}
+
+	// Embeddings are unit vectors, so one minus the cosine distance is the similarity
+	query := `SELECT ` + applicationColumns + `,
+		(SELECT 1 - vec_distance_cosine(ue.embedding, ce.embedding)
+		 FROM user_embeddings ue, collaboration_embeddings ce
+		 WHERE ue.user_id = ci.user_id AND ce.collaboration_id = ci.collaboration_id)
+		` + applicationJoins + `
+		WHERE ci.collaboration_id = ?
+		ORDER BY ci.created_at DESC`
+	args := []interface{}{collabID}
+
+	if page > 0 && perPage > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, perPage, (page-1)*perPage)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	applications := []Application{}
+	for rows.Next() {
+		var matchScore sql.NullFloat64
+		app, err := scanApplication(extraColumns{rows, []interface{}{&matchScore}})
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		if matchScore.Valid {
+			app.MatchScore = &matchScore.Float64
+		}
+		applications = append(applications, app)
+	}
+
+	return applications, rows.Err()
+}
+
+// extraColumns scans columns selected after the ones a scan function knows about
+type extraColumns struct {
+	row  rowScanner
+	dest []interface{}
+}
+
+func (r extraColumns) Scan(dest ...interface{}) error {
+	return r.row.Scan(append(dest, r.dest...)...)
+}
+
+// AnswerApplication lets a member of the collaboration team shortlist,
+// accept or decline an application that hasn't been withdrawn
+func (s *Storage) AnswerApplication(ctx context.Context, memberID, id string, status ApplicationStatus) (Application, error) {
+	app, err := s.GetApplicationByID(ctx, memberID, id)
+	if err != nil {
+		return Application{}, err
+	}
+	if _, err := s.collaborationRole(ctx, s.db, app.CollaborationID, memberID); err != nil {
+		return Application{}, err
+	}
+
+	return s.setApplicationStatus(ctx, app, status)
+}
+
+// WithdrawApplication withdraws an application of the user
+func (s *Storage) WithdrawApplication(ctx context.Context, userID, id string) (Application, error) {
+	app, err := s.GetApplicationByID(ctx, userID, id)
+	if err != nil {
+		return Application{}, err
+	}
+	if app.UserID != userID {
+		return Application{}, ErrNotFound
+	}
+
+	return s.setApplicationStatus(ctx, app, ApplicationStatusWithdrawn)
+}
+
+func (s *Storage) setApplicationStatus(ctx context.Context, app Application, status ApplicationStatus) (Application, error) {
+	now := time.Now()
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE collaboration_interests SET status = ?, updated_at = ?
+		WHERE id = ? AND status != 'withdrawn'
+	`, status, now, app.ID)
+	if err != nil {
+		return Application{}, fmt.Errorf("failed to update application: %w", err)
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return Application{}, ErrApplicationClosed
+	}
+
+	app.Status = status
+	app.UpdatedAt = &now
+
+	return app, nil
+}
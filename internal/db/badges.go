@@ -3,38 +3,86 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
+	"unicode"
+)
+
+// ErrBadgeNotApproved is returned when badges are merged into one that
+// isn't approved yet
+var ErrBadgeNotApproved = errors.New("badge is not approved")
+
+type BadgeStatus string
+
+const (
+	// BadgeStatusPending badges were created by users and are only visible
+	// to their creator until an admin approves them
+	BadgeStatusPending  BadgeStatus = "pending"
+	BadgeStatusApproved BadgeStatus = "approved"
 )
 
 type Badge struct {
-	ID        string    `json:"id"`
-	Text      string    `json:"text"`
-	Icon      string    `json:"icon"`
-	Color     string    `json:"color"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        string      `json:"id"`
+	Text      string      `json:"text"`
+	Icon      string      `json:"icon"`
+	Color     string      `json:"color"`
+	Status    BadgeStatus `json:"status,omitempty"`
+	CreatedBy *string     `json:"created_by,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
 } // @Name Badge
 
-// ListBadges lists all badges with optional search
-func (s *Storage) ListBadges(ctx context.Context, search string) ([]Badge, error) {
-	query := `
-		SELECT id, text, icon, color, created_at
-		FROM badges
-	`
-	var args []interface{}
-	argPos := 1
+// BadgeRewriteResult reports how many snapshots a merge or delete rewrote
+type BadgeRewriteResult struct {
+	Users          int `json:"users"`
+	Collaborations int `json:"collaborations"`
+} // @Name BadgeRewriteResult
 
-	// Add search filter
-	if search != "" {
-		query += fmt.Sprintf(`WHERE text LIKE ?`)
-		args = append(args, "%"+search+"%")
-		argPos++
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// NormalizeBadgeText reduces a badge text to a key for near-duplicate
+// detection: case, whitespace and punctuation are dropped and Cyrillic is
+// transliterated letter by letter, so "UI / UX" collides with "ui-ux" and
+// "Фронтенд Дев" with "Frontend Dev".
+func NormalizeBadgeText(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		if latin, ok := cyrillicToLatin[r]; ok {
+			b.WriteString(latin)
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
 	}
+	return b.String()
+}
 
-	// Add ordering
-	query += fmt.Sprintf(` ORDER BY created_at DESC`)
+const badgeColumns = `id, text, icon, color, status, created_by, created_at`
 
+func scanBadge(row interface{ Scan(...any) error }) (Badge, error) {
+	var badge Badge
+	err := row.Scan(
+		&badge.ID,
+		&badge.Text,
+		&badge.Icon,
+		&badge.Color,
+		&badge.Status,
+		&badge.CreatedBy,
+		&badge.CreatedAt,
+	)
+	return badge, err
+}
+
+func (s *Storage) queryBadges(ctx context.Context, query string, args ...interface{}) ([]Badge, error) {
 	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
@@ -43,14 +91,7 @@ func (s *Storage) ListBadges(ctx context.Context, search string) ([]Badge, error
 
 	var badges []Badge
 	for rows.Next() {
-		var badge Badge
-		err := rows.Scan(
-			&badge.ID,
-			&badge.Text,
-			&badge.Icon,
-			&badge.Color,
-			&badge.CreatedAt,
-		)
+		badge, err := scanBadge(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -60,18 +101,57 @@ func (s *Storage) ListBadges(ctx context.Context, search string) ([]Badge, error
 	return badges, rows.Err()
 }
 
-// CreateBadge creates a new badge
+// ListBadges lists approved badges and the viewer's own pending ones, with optional search
+func (s *Storage) ListBadges(ctx context.Context, search, viewerID string) ([]Badge, error) {
+	query := `SELECT ` + badgeColumns + ` FROM badges
+		WHERE (status = 'approved' OR (status = 'pending' AND created_by = ?))`
+	args := []interface{}{viewerID}
+
+	if search != "" {
+		query += ` AND text LIKE ?`
+		args = append(args, "%"+search+"%")
+	}
+
+	query += ` ORDER BY created_at DESC`
+
+	return s.queryBadges(ctx, query, args...)
+}
+
+// ListBadgesByStatus lists badges for moderation, all of them when status is empty
+func (s *Storage) ListBadgesByStatus(ctx context.Context, status BadgeStatus) ([]Badge, error) {
+	query := `SELECT ` + badgeColumns + ` FROM badges`
+	var args []interface{}
+
+	if status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, status)
+	}
+
+	query += ` ORDER BY created_at DESC`
+
+	return s.queryBadges(ctx, query, args...)
+}
+
+// CreateBadge creates a new badge, approved unless a status is given
 func (s *Storage) CreateBadge(ctx context.Context, badgeInput Badge) error {
 	query := `
-		INSERT INTO badges (id, text, icon, color, created_at)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO badges (id, text, icon, color, status, created_by, normalized_text, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
+	status := badgeInput.Status
+	if status == "" {
+		status = BadgeStatusApproved
+	}
+
 	_, err := s.db.ExecContext(ctx, query,
 		badgeInput.ID,
 		badgeInput.Text,
 		badgeInput.Icon,
 		badgeInput.Color,
+		status,
+		badgeInput.CreatedBy,
+		NormalizeBadgeText(badgeInput.Text),
 		time.Now(),
 	)
 
@@ -85,23 +165,224 @@ func (s *Storage) CreateBadge(ctx context.Context, badgeInput Badge) error {
 	return nil
 }
 
+// FindSimilarBadge returns a badge whose text normalizes the same as text,
+// among the approved badges and the viewer's own pending ones
+func (s *Storage) FindSimilarBadge(ctx context.Context, text, viewerID string) (Badge, error) {
+	query := `SELECT ` + badgeColumns + ` FROM badges
+		WHERE normalized_text = ?
+		  AND (status = 'approved' OR (status = 'pending' AND created_by = ?))
+		ORDER BY status = 'approved' DESC, created_at
+		LIMIT 1`
+
+	badge, err := scanBadge(s.db.QueryRowContext(ctx, query, NormalizeBadgeText(text), viewerID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Badge{}, ErrNotFound
+		}
+		return Badge{}, err
+	}
+
+	return badge, nil
+}
+
+// ApproveBadge makes a pending badge visible to everyone
+func (s *Storage) ApproveBadge(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE badges SET status = ? WHERE id = ?`, BadgeStatusApproved, id)
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// MergeBadges replaces the source badge with the target in every user and
+// collaboration snapshot, then deletes the source
+func (s *Storage) MergeBadges(ctx context.Context, sourceID, targetID string) (BadgeRewriteResult, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return BadgeRewriteResult{}, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM badges WHERE id = ?)`, sourceID).Scan(&exists); err != nil {
+		return BadgeRewriteResult{}, err
+	}
+	if !exists {
+		return BadgeRewriteResult{}, ErrNotFound
+	}
+
+	target, err := scanBadge(tx.QueryRowContext(ctx, `SELECT `+badgeColumns+` FROM badges WHERE id = ?`, targetID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return BadgeRewriteResult{}, ErrNotFound
+		}
+		return BadgeRewriteResult{}, err
+	}
+
+	// Merging into another pending badge would hand it out before review
+	if target.Status != BadgeStatusApproved {
+		return BadgeRewriteResult{}, ErrBadgeNotApproved
+	}
+
+	// Snapshots only carry the display fields
+	target.Status = ""
+	target.CreatedBy = nil
+
+	result, err := replaceBadgeReferencesTx(ctx, tx, sourceID, &target)
+	if err != nil {
+		return BadgeRewriteResult{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM badges WHERE id = ?`, sourceID); err != nil {
+		return BadgeRewriteResult{}, err
+	}
+
+	return result, tx.Commit()
+}
+
+// DeleteBadge deletes a badge and removes it from every user and collaboration
+func (s *Storage) DeleteBadge(ctx context.Context, id string) (BadgeRewriteResult, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return BadgeRewriteResult{}, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM badges WHERE id = ?`, id)
+	if err != nil {
+		return BadgeRewriteResult{}, err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return BadgeRewriteResult{}, ErrNotFound
+	}
+
+	result, err := replaceBadgeReferencesTx(ctx, tx, id, nil)
+	if err != nil {
+		return BadgeRewriteResult{}, err
+	}
+
+	return result, tx.Commit()
+}
+
+// replaceBadgeReferencesTx rewrites the badges snapshots referencing badgeID,
+// swapping in replacement or dropping the badge when it's nil
+func replaceBadgeReferencesTx(ctx context.Context, tx *sql.Tx, badgeID string, replacement *Badge) (BadgeRewriteResult, error) {
+	var result BadgeRewriteResult
+
+	for _, table := range []string{"users", "collaborations"} {
+		count, err := replaceBadgeInTableTx(ctx, tx, table, badgeID, replacement)
+		if err != nil {
+			return BadgeRewriteResult{}, fmt.Errorf("failed to rewrite %s badges: %w", table, err)
+		}
+
+		if table == "users" {
+			result.Users = count
+		} else {
+			result.Collaborations = count
+		}
+	}
+
+	return result, nil
+}
+
+func replaceBadgeInTableTx(ctx context.Context, tx *sql.Tx, table, badgeID string, replacement *Badge) (int, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+		SELECT t.id, t.badges FROM %s t
+		WHERE EXISTS (SELECT 1 FROM json_each(t.badges) WHERE json_extract(value, '$.id') = ?)
+	`, table), badgeID)
+	if err != nil {
+		return 0, err
+	}
+
+	snapshots := make(map[string][]Badge)
+	for rows.Next() {
+		var id string
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			rows.Close()
+			return 0, err
+		}
+
+		var badges []Badge
+		if err := json.Unmarshal(data, &badges); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to decode badges of %s: %w", id, err)
+		}
+		snapshots[id] = badges
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for id, badges := range snapshots {
+		rewritten := make([]Badge, 0, len(badges))
+		seen := make(map[string]bool, len(badges))
+
+		for _, badge := range badges {
+			if badge.ID == badgeID {
+				if replacement == nil {
+					continue
+				}
+				badge = *replacement
+			}
+			if seen[badge.ID] {
+				continue
+			}
+			seen[badge.ID] = true
+			rewritten = append(rewritten, badge)
+		}
+
+		data, _ := json.Marshal(rewritten)
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET badges = ? WHERE id = ?`, table), data, id); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(snapshots), nil
+}
+
+// backfillBadgeNormalizedText fills normalized_text for badges created before it existed
+func backfillBadgeNormalizedText(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id, text FROM badges WHERE normalized_text IS NULL`)
+	if err != nil {
+		return err
+	}
+
+	texts := make(map[string]string)
+	for rows.Next() {
+		var id, text string
+		if err := rows.Scan(&id, &text); err != nil {
+			rows.Close()
+			return err
+		}
+		texts[id] = text
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, text := range texts {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE badges SET normalized_text = ? WHERE id = ?`, NormalizeBadgeText(text), id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // GetBadgeByID retrieves a badge by ID
 func (s *Storage) GetBadgeByID(ctx context.Context, id string) (*Badge, error) {
-	query := `
-		SELECT id, text, icon, color, created_at
-		FROM badges
-		WHERE id = ?
-	`
-
-	var badge Badge
-	err := s.db.QueryRowContext(ctx, query, id).Scan(
-		&badge.ID,
-		&badge.Text,
-		&badge.Icon,
-		&badge.Color,
-		&badge.CreatedAt,
-	)
+	query := `SELECT ` + badgeColumns + ` FROM badges WHERE id = ?`
 
+	badge, err := scanBadge(s.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrNotFound
@@ -158,7 +439,9 @@ func (s *Storage) GetBadgesByIDs(ctx context.Context, ids []string) ([]Badge, er
 	return badges, rows.Err()
 }
 
-func fetchItemsByID[T any](ctx context.Context, tx *sql.Tx, queryTemplate string, ids []string, scanFunc func(*sql.Rows) (T, error)) ([]T, error) {
+// fetchItemsByID runs queryTemplate with the placeholders for ids; extraArgs
+// bind any placeholders following the IN clause
+func fetchItemsByID[T any](ctx context.Context, tx *sql.Tx, queryTemplate string, ids []string, scanFunc func(*sql.Rows) (T, error), extraArgs ...interface{}) ([]T, error) {
 	if len(ids) == 0 {
 		return nil, nil
 	}
@@ -168,6 +451,7 @@ func fetchItemsByID[T any](ctx context.Context, tx *sql.Tx, queryTemplate string
 		placeholders[i] = "?"
 		args[i] = id
 	}
+	args = append(args, extraArgs...)
 	query := fmt.Sprintf(queryTemplate, strings.Join(placeholders, ","))
 
 	rows, err := tx.QueryContext(ctx, query, args...)
@@ -190,11 +474,13 @@ func fetchItemsByID[T any](ctx context.Context, tx *sql.Tx, queryTemplate string
 	return items, nil
 }
 
-func (s *Storage) fetchBadgesTx(ctx context.Context, tx *sql.Tx, ids []string) ([]Badge, error) {
+// fetchBadgesTx loads the badges ownerID may pick: approved ones and their own pending ones
+func (s *Storage) fetchBadgesTx(ctx context.Context, tx *sql.Tx, ids []string, ownerID string) ([]Badge, error) {
 	queryTemplate := `
 		SELECT id, text, icon, color, created_at
 		FROM badges
 		WHERE id IN (%s)
+		  AND (status = 'approved' OR (status = 'pending' AND created_by = ?))
 	`
 
 	return fetchItemsByID(ctx, tx, queryTemplate, ids, func(rows *sql.Rows) (Badge, error) {
@@ -207,5 +493,5 @@ func (s *Storage) fetchBadgesTx(ctx context.Context, tx *sql.Tx, ids []string) (
 			&badge.CreatedAt,
 		)
 		return badge, err
-	})
+	}, ownerID)
 }
@@ -0,0 +1,444 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type BroadcastStatus string // @Name BroadcastStatus
+
+const (
+	BroadcastStatusDraft     BroadcastStatus = "draft"
+	BroadcastStatusSending   BroadcastStatus = "sending"
+	BroadcastStatusCompleted BroadcastStatus = "completed"
+	BroadcastStatusCancelled BroadcastStatus = "cancelled"
+)
+
+type BroadcastRecipientStatus string // @Name BroadcastRecipientStatus
+
+const (
+	BroadcastRecipientPending BroadcastRecipientStatus = "pending"
+	BroadcastRecipientSent    BroadcastRecipientStatus = "sent"
+	BroadcastRecipientFailed  BroadcastRecipientStatus = "failed"
+)
+
+// BroadcastSegment selects the users a broadcast goes to. Empty fields
+// don't filter; values within a field are alternatives.
+type BroadcastSegment struct {
+	Statuses       []VerificationStatus `json:"statuses,omitempty"`
+	OpportunityIDs []string             `json:"opportunity_ids,omitempty"`
+	BadgeIDs       []string             `json:"badge_ids,omitempty"`
+	CountryCodes   []string             `json:"country_codes,omitempty"`
+	Languages      []LanguageCode       `json:"languages,omitempty"`
+	ActiveAfter    *time.Time           `json:"active_after,omitempty"`
+	ActiveBefore   *time.Time           `json:"active_before,omitempty"`
+} // @Name BroadcastSegment
+
+type Broadcast struct {
+	ID           string           `json:"id"`
+	AdminID      string           `json:"admin_id"`
+	Text         string           `json:"text"`
+	TextRU       string           `json:"text_ru"`
+	ImageURL     *string          `json:"image_url"`
+	ButtonText   *string          `json:"button_text"`
+	ButtonTextRU *string          `json:"button_text_ru"`
+	ButtonPath   *string          `json:"button_path"`
+	Segment      BroadcastSegment `json:"segment"`
+	Status       BroadcastStatus  `json:"status"`
+	Total        int              `json:"total"`
+	Sent         int              `json:"sent"`
+	Failed       int              `json:"failed"`
+	CreatedAt    time.Time        `json:"created_at"`
+	StartedAt    *time.Time       `json:"started_at"`
+	FinishedAt   *time.Time       `json:"finished_at"`
+} // @Name Broadcast
+
+// LocalizedText returns the broadcast text in the given language
+func (b Broadcast) LocalizedText(lang LanguageCode) string {
+	if lang == LanguageRU && b.TextRU != "" {
+		return b.TextRU
+	}
+	return b.Text
+}
+
+// LocalizedButtonText returns the button label in the given language
+func (b Broadcast) LocalizedButtonText(lang LanguageCode) string {
+	if lang == LanguageRU && b.ButtonTextRU != nil && *b.ButtonTextRU != "" {
+		return *b.ButtonTextRU
+	}
+	if b.ButtonText != nil {
+		return *b.ButtonText
+	}
+	return ""
+}
+
+type BroadcastRecipient struct {
+	BroadcastID  string                   `json:"broadcast_id"`
+	UserID       string                   `json:"user_id"`
+	ChatID       int64                    `json:"chat_id"`
+	Username     string                   `json:"username"`
+	LanguageCode LanguageCode             `json:"language_code"`
+	Status       BroadcastRecipientStatus `json:"status"`
+	Error        *string                  `json:"error"`
+	SentAt       *time.Time               `json:"sent_at"`
+} // @Name BroadcastRecipient
+
+// audienceFilter builds the WHERE clause for a segment. Only users the bot
+// can reach are included.
+func audienceFilter(segment BroadcastSegment) (string, []interface{}) {
+	conditions := []string{
+		"chat_id IS NOT NULL", "notifications_enabled_at IS NOT NULL",
+		"hidden_at IS NULL", "verification_status != 'blocked'",
+	}
+	var args []interface{}
+
+	in := func(column string, values []string) {
+		conditions = append(conditions, fmt.Sprintf("%s IN (%s)", column, placeholders(len(values))))
+		for _, v := range values {
+			args = append(args, v)
+		}
+	}
+
+	if len(segment.Statuses) > 0 {
+		statuses := make([]string, len(segment.Statuses))
+		for i, s := range segment.Statuses {
+			statuses[i] = string(s)
+		}
+		in("verification_status", statuses)
+	}
+
+	if len(segment.Languages) > 0 {
+		languages := make([]string, len(segment.Languages))
+		for i, l := range segment.Languages {
+			languages[i] = string(l)
+		}
+		in("language_code", languages)
+	}
+
+	if len(segment.CountryCodes) > 0 {
+		in("json_extract(location, '$.country_code')", segment.CountryCodes)
+	}
+
+	if len(segment.OpportunityIDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM json_each(users.opportunities) WHERE json_extract(value, '$.id') IN (%s))",
+			placeholders(len(segment.OpportunityIDs))))
+		for _, id := range segment.OpportunityIDs {
+			args = append(args, id)
+		}
+	}
+
+	if len(segment.BadgeIDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM json_each(users.badges) WHERE json_extract(value, '$.id') IN (%s))",
+			placeholders(len(segment.BadgeIDs))))
+		for _, id := range segment.BadgeIDs {
+			args = append(args, id)
+		}
+	}
+
+	if segment.ActiveAfter != nil {
+		conditions = append(conditions, "last_active_at >= ?")
+		args = append(args, *segment.ActiveAfter)
+	}
+
+	if segment.ActiveBefore != nil {
+		conditions = append(conditions, "last_active_at < ?")
+		args = append(args, *segment.ActiveBefore)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// CountBroadcastAudience counts the users a segment would reach
+func (s *Storage) CountBroadcastAudience(ctx context.Context, segment BroadcastSegment) (int, error) {
+	where, args := audienceFilter(segment)
+
+	var count int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE `+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count audience: %w", err)
+	}
+
+	return count, nil
+}
+
+// CreateBroadcast stores a draft broadcast
+func (s *Storage) CreateBroadcast(ctx context.Context, b Broadcast) error {
+	segmentJSON, _ := json.Marshal(b.Segment)
+
+	query := `
+		INSERT INTO broadcasts (id, admin_id, text_en, text_ru, image_url, button_text_en, button_text_ru,
+		                        button_path, segment, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := s.db.ExecContext(ctx, query,
+		b.ID,
+		b.AdminID,
+		b.Text,
+		b.TextRU,
+		b.ImageURL,
+		b.ButtonText,
+		b.ButtonTextRU,
+		b.ButtonPath,
+		string(segmentJSON),
+		BroadcastStatusDraft,
+		time.Now(),
+	)
+
+	return err
+}
+
+const broadcastColumns = `
+	id, admin_id, text_en, text_ru, image_url, button_text_en, button_text_ru, button_path,
+	segment, status, total, sent, failed, created_at, started_at, finished_at
+`
+
+func scanBroadcast(scanner interface{ Scan(...any) error }) (Broadcast, error) {
+	var b Broadcast
+	var segmentJSON string
+
+	err := scanner.Scan(
+		&b.ID,
+		&b.AdminID,
+		&b.Text,
+		&b.TextRU,
+		&b.ImageURL,
+		&b.ButtonText,
+		&b.ButtonTextRU,
+		&b.ButtonPath,
+		&segmentJSON,
+		&b.Status,
+		&b.Total,
+		&b.Sent,
+		&b.Failed,
+		&b.CreatedAt,
+		&b.StartedAt,
+		&b.FinishedAt,
+	)
+	if err != nil {
+		return Broadcast{}, err
+	}
+
+	if err := json.Unmarshal([]byte(segmentJSON), &b.Segment); err != nil {
+		return Broadcast{}, fmt.Errorf("failed to unmarshal segment: %w", err)
+	}
+
+	return b, nil
+}
+
+// GetBroadcastByID retrieves a broadcast with its delivery progress
+func (s *Storage) GetBroadcastByID(ctx context.Context, id string) (Broadcast, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+broadcastColumns+` FROM broadcasts WHERE id = ?`, id)
+
+	b, err := scanBroadcast(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Broadcast{}, ErrNotFound
+		}
+		return Broadcast{}, err
+	}
+
+	return b, nil
+}
+
+// ListBroadcasts lists broadcasts, newest first
+func (s *Storage) ListBroadcasts(ctx context.Context, page, perPage int) ([]Broadcast, error) {
+	return s.queryBroadcasts(ctx,
+		`SELECT `+broadcastColumns+` FROM broadcasts ORDER BY created_at DESC LIMIT ? OFFSET ?`,
+		perPage, (page-1)*perPage)
+}
+
+// ListSendingBroadcasts lists the broadcasts still being delivered, oldest first
+func (s *Storage) ListSendingBroadcasts(ctx context.Context) ([]Broadcast, error) {
+	return s.queryBroadcasts(ctx,
+		`SELECT `+broadcastColumns+` FROM broadcasts WHERE status = ? ORDER BY started_at`,
+		BroadcastStatusSending)
+}
+
+func (s *Storage) queryBroadcasts(ctx context.Context, query string, args ...interface{}) ([]Broadcast, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list broadcasts: %w", err)
+	}
+	defer rows.Close()
+
+	var broadcasts []Broadcast
+	for rows.Next() {
+		b, err := scanBroadcast(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan broadcast: %w", err)
+		}
+		broadcasts = append(broadcasts, b)
+	}
+
+	return broadcasts, rows.Err()
+}
+
+// CancelBroadcast cancels a broadcast that hasn't been sent yet
+func (s *Storage) CancelBroadcast(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE broadcasts SET status = ? WHERE id = ? AND status = ?`,
+		BroadcastStatusCancelled, id, BroadcastStatusDraft)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// StartBroadcast snapshots the segment's audience as pending recipients and
+// marks the broadcast as sending. Only drafts can be started.
+func (s *Storage) StartBroadcast(ctx context.Context, id string) (Broadcast, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Broadcast{}, err
+	}
+	defer tx.Rollback()
+
+	b, err := scanBroadcast(tx.QueryRowContext(ctx, `SELECT `+broadcastColumns+` FROM broadcasts WHERE id = ?`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Broadcast{}, ErrNotFound
+		}
+		return Broadcast{}, err
+	}
+
+	if b.Status != BroadcastStatusDraft {
+		return Broadcast{}, ErrAlreadyExists
+	}
+
+	where, args := audienceFilter(b.Segment)
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO broadcast_recipients (broadcast_id, user_id, chat_id, status)
+		SELECT ?, id, chat_id, ? FROM users WHERE `+where,
+		append([]interface{}{id, BroadcastRecipientPending}, args...)...)
+	if err != nil {
+		return Broadcast{}, fmt.Errorf("failed to add recipients: %w", err)
+	}
+	total, _ := result.RowsAffected()
+
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE broadcasts SET status = ?, total = ?, started_at = ? WHERE id = ?`,
+		BroadcastStatusSending, total, now, id); err != nil {
+		return Broadcast{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Broadcast{}, err
+	}
+
+	b.Status = BroadcastStatusSending
+	b.Total = int(total)
+	b.StartedAt = &now
+
+	return b, nil
+}
+
+// ListBroadcastRecipients lists a broadcast's recipients, optionally by status
+func (s *Storage) ListBroadcastRecipients(ctx context.Context, broadcastID string, status BroadcastRecipientStatus, page, perPage int) ([]BroadcastRecipient, error) {
+	query := `
+		SELECT r.broadcast_id, r.user_id, r.chat_id, COALESCE(u.username, ''), COALESCE(u.language_code, 'en'),
+		       r.status, r.error, r.sent_at
+		FROM broadcast_recipients r
+		LEFT JOIN users u ON u.id = r.user_id
+		WHERE r.broadcast_id = ?
+	`
+	args := []interface{}{broadcastID}
+
+	if status != "" {
+		query += ` AND r.status = ?`
+		args = append(args, status)
+	}
+
+	query += ` ORDER BY r.rowid ASC`
+	if page > 0 && perPage > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, perPage, (page-1)*perPage)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recipients: %w", err)
+	}
+	defer rows.Close()
+
+	var recipients []BroadcastRecipient
+	for rows.Next() {
+		var r BroadcastRecipient
+		if err := rows.Scan(
+			&r.BroadcastID,
+			&r.UserID,
+			&r.ChatID,
+			&r.Username,
+			&r.LanguageCode,
+			&r.Status,
+			&r.Error,
+			&r.SentAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan recipient: %w", err)
+		}
+		recipients = append(recipients, r)
+	}
+
+	return recipients, rows.Err()
+}
+
+// UpdateBroadcastRecipient records a delivery result and bumps the
+// broadcast's progress counters
+func (s *Storage) UpdateBroadcastRecipient(ctx context.Context, broadcastID, userID string, status BroadcastRecipientStatus, errMsg *string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE broadcast_recipients SET status = ?, error = ?, sent_at = ?
+		WHERE broadcast_id = ? AND user_id = ? AND status = ?
+	`, status, errMsg, time.Now(), broadcastID, userID, BroadcastRecipientPending)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	counter := "sent"
+	if status == BroadcastRecipientFailed {
+		counter = "failed"
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		fmt.Sprintf(`UPDATE broadcasts SET %s = %s + 1 WHERE id = ?`, counter, counter), broadcastID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FinishBroadcast marks a broadcast as delivered
+func (s *Storage) FinishBroadcast(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE broadcasts SET status = ?, finished_at = ? WHERE id = ?`,
+		BroadcastStatusCompleted, time.Now(), id)
+
+	return err
+}
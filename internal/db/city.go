@@ -3,8 +3,10 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -15,12 +17,36 @@ type City struct {
 	CountryName string  `json:"country_name"`
 	Latitude    float64 `json:"latitude,omitempty"`
 	Longitude   float64 `json:"longitude,omitempty"`
+	Population  int64   `json:"population,omitempty"`
 } // @Name City
 
-// SearchCities searches cities by name with pagination
+// CityRecord is a city as imported from a dataset, with the extra names it
+// can be found by
+type CityRecord struct {
+	City
+	AlternateNames []string
+}
+
+// CityImportStats counts the outcome of UpsertCities
+type CityImportStats struct {
+	Inserted  int
+	Updated   int
+	Unchanged int
+	// Location snapshots refreshed because their city was updated
+	Users          int
+	Collaborations int
+}
+
+// citySearchNames builds the lowercased text cities are matched against.
+// SQLite's LIKE only folds ASCII case, so Cyrillic names are folded here.
+func citySearchNames(name string, alternateNames []string) string {
+	return strings.ToLower(strings.Join(append([]string{name}, alternateNames...), "\n"))
+}
+
+// SearchCities searches cities by name or alternate name, most populated first
 func (s *Storage) SearchCities(ctx context.Context, search string, limit, skip int) ([]City, error) {
 	query := `
-		SELECT id, name, country_code, country_name, latitude, longitude
+		SELECT id, name, country_code, country_name, latitude, longitude, population
 		FROM cities
 		WHERE 1=1
 	`
@@ -28,12 +54,12 @@ func (s *Storage) SearchCities(ctx context.Context, search string, limit, skip i
 
 	// Add search filter
 	if search != "" {
-		query += fmt.Sprintf(` AND name LIKE ?`)
-		args = append(args, "%"+search+"%")
+		query += ` AND search_names LIKE ?`
+		args = append(args, "%"+strings.ToLower(search)+"%")
 	}
 
 	// Add ordering and pagination
-	query += fmt.Sprintf(` ORDER BY name ASC LIMIT ? OFFSET ?`)
+	query += ` ORDER BY population DESC, name ASC LIMIT ? OFFSET ?`
 	args = append(args, limit, skip)
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -60,7 +86,7 @@ func (s *Storage) SearchCities(ctx context.Context, search string, limit, skip i
 // GetCityByID retrieves a city by ID
 func (s *Storage) GetCityByID(ctx context.Context, id string) (City, error) {
 	query := `
-		SELECT id, name, country_code, country_name, latitude, longitude
+		SELECT id, name, country_code, country_name, latitude, longitude, population
 		FROM cities
 		WHERE id = ?
 	`
@@ -80,8 +106,8 @@ func (s *Storage) GetCityByID(ctx context.Context, id string) (City, error) {
 // CreateCity creates a new city
 func (s *Storage) CreateCity(ctx context.Context, city City) error {
 	query := `
-		INSERT INTO cities (id, name, country_code, country_name, latitude, longitude, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO cities (id, name, country_code, country_name, latitude, longitude, population, search_names, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err := s.db.ExecContext(ctx, query,
@@ -91,6 +117,8 @@ func (s *Storage) CreateCity(ctx context.Context, city City) error {
 		city.CountryName,
 		city.Latitude,
 		city.Longitude,
+		city.Population,
+		citySearchNames(city.Name, nil),
 		time.Now(),
 	)
 
@@ -114,6 +142,7 @@ func scanCity(rows *sql.Rows) (City, error) {
 		&city.CountryName,
 		&city.Latitude,
 		&city.Longitude,
+		&city.Population,
 	)
 	if err != nil {
 		return city, err
@@ -132,6 +161,7 @@ func scanCityRow(row *sql.Row) (City, error) {
 		&city.CountryName,
 		&city.Latitude,
 		&city.Longitude,
+		&city.Population,
 	)
 	if err != nil {
 		return city, err
@@ -142,7 +172,7 @@ func scanCityRow(row *sql.Row) (City, error) {
 
 func (s *Storage) fetchCityTx(ctx context.Context, tx *sql.Tx, id string) (City, error) {
 	query := `
-		SELECT id, name, country_code, country_name, latitude, longitude
+		SELECT id, name, country_code, country_name, latitude, longitude, population
 		FROM cities
 		WHERE id = ?
 	`
@@ -161,9 +191,11 @@ func (s *Storage) fetchCityTx(ctx context.Context, tx *sql.Tx, id string) (City,
 
 func (s *Storage) GetCityByName(ctx context.Context, name string) (City, error) {
 	query := `
-		SELECT id, name, country_code, country_name, latitude, longitude
+		SELECT id, name, country_code, country_name, latitude, longitude, population
 		FROM cities
 		WHERE name = ?
+		ORDER BY population DESC
+		LIMIT 1
 	`
 
 	row := s.db.QueryRowContext(ctx, query, name)
@@ -177,3 +209,168 @@ func (s *Storage) GetCityByName(ctx context.Context, name string) (City, error)
 
 	return city, nil
 }
+
+// UpsertCities inserts or updates cities by ID, which for imported datasets
+// is the GeoNames ID, in a single transaction. Users and collaborations keep
+// a snapshot of their city, those of updated cities are rewritten and the
+// location index follows through its triggers.
+func (s *Storage) UpsertCities(ctx context.Context, cities []CityRecord) (CityImportStats, error) {
+	var stats CityImportStats
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return stats, err
+	}
+	defer tx.Rollback()
+
+	selectStmt, err := tx.PrepareContext(ctx, `
+		SELECT name, country_code, country_name, latitude, longitude, population, COALESCE(alternate_names, '')
+		FROM cities
+		WHERE id = ?
+	`)
+	if err != nil {
+		return stats, err
+	}
+	defer selectStmt.Close()
+
+	upsertStmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO cities (
+			id, name, country_code, country_name, latitude, longitude,
+			population, alternate_names, search_names, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			name = excluded.name,
+			country_code = excluded.country_code,
+			country_name = excluded.country_name,
+			latitude = excluded.latitude,
+			longitude = excluded.longitude,
+			population = excluded.population,
+			alternate_names = excluded.alternate_names,
+			search_names = excluded.search_names
+	`)
+	if err != nil {
+		return stats, err
+	}
+	defer upsertStmt.Close()
+
+	usersStmt, err := tx.PrepareContext(ctx,
+		`UPDATE users SET location = ? WHERE json_extract(location, '$.id') = ?`)
+	if err != nil {
+		return stats, err
+	}
+	defer usersStmt.Close()
+
+	collabsStmt, err := tx.PrepareContext(ctx,
+		`UPDATE collaborations SET location = ? WHERE json_extract(location, '$.id') = ?`)
+	if err != nil {
+		return stats, err
+	}
+	defer collabsStmt.Close()
+
+	now := time.Now()
+
+	for _, record := range cities {
+		city := record.City
+		alternateNames := strings.Join(record.AlternateNames, ",")
+
+		var existing City
+		var existingAlternateNames string
+		updated := false
+		err := selectStmt.QueryRowContext(ctx, city.ID).Scan(
+			&existing.Name,
+			&existing.CountryCode,
+			&existing.CountryName,
+			&existing.Latitude,
+			&existing.Longitude,
+			&existing.Population,
+			&existingAlternateNames,
+		)
+
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			stats.Inserted++
+		case err != nil:
+			return stats, fmt.Errorf("failed to read city %s: %w", city.ID, err)
+		default:
+			existing.ID = city.ID
+			if existing == city && existingAlternateNames == alternateNames {
+				stats.Unchanged++
+				continue
+			}
+			stats.Updated++
+			updated = true
+		}
+
+		if _, err := upsertStmt.ExecContext(ctx,
+			city.ID,
+			city.Name,
+			city.CountryCode,
+			city.CountryName,
+			city.Latitude,
+			city.Longitude,
+			city.Population,
+			alternateNames,
+			citySearchNames(city.Name, record.AlternateNames),
+			now,
+		); err != nil {
+			return stats, fmt.Errorf("failed to upsert city %s: %w", city.ID, err)
+		}
+
+		// Only the names changed, snapshots don't carry them
+		if !updated || existing == city {
+			continue
+		}
+
+		location, err := json.Marshal(city)
+		if err != nil {
+			return stats, err
+		}
+
+		result, err := usersStmt.ExecContext(ctx, location, city.ID)
+		if err != nil {
+			return stats, fmt.Errorf("failed to refresh user locations in %s: %w", city.ID, err)
+		}
+		users, _ := result.RowsAffected()
+		stats.Users += int(users)
+
+		result, err = collabsStmt.ExecContext(ctx, location, city.ID)
+		if err != nil {
+			return stats, fmt.Errorf("failed to refresh collaboration locations in %s: %w", city.ID, err)
+		}
+		collabs, _ := result.RowsAffected()
+		stats.Collaborations += int(collabs)
+	}
+
+	return stats, tx.Commit()
+}
+
+// backfillCitySearchNames fills search_names for cities created before it existed
+func backfillCitySearchNames(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id, name FROM cities WHERE search_names IS NULL`)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]string)
+	for rows.Next() {
+		var id, name string
+		if err := rows.Scan(&id, &name); err != nil {
+			rows.Close()
+			return err
+		}
+		names[id] = name
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, name := range names {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE cities SET search_names = ? WHERE id = ?`, citySearchNames(name, nil), id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
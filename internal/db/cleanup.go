@@ -6,7 +6,9 @@ import (
 	"time"
 )
 
-// CleanupExpiredRecords removes expired records from tables with TTL
+// CleanupExpiredRecords removes expired records from tables with TTL.
+// Collaboration interests are kept, owners browse them as history. Sessions,
+// rate limits and Telegram updates are pruned by the job instead.
 func (s *Storage) CleanupExpiredRecords(ctx context.Context) error {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -24,20 +26,11 @@ func (s *Storage) CleanupExpiredRecords(ctx context.Context) error {
 	}
 	followersDeleted, _ := result.RowsAffected()
 
-	// Clean up expired collaboration interests
-	result, err = tx.ExecContext(ctx,
-		`DELETE FROM collaboration_interests WHERE expires_at < ?`, now)
-	if err != nil {
-		return fmt.Errorf("failed to cleanup collaboration_interests: %w", err)
-	}
-	interestsDeleted, _ := result.RowsAffected()
-
 	// Log cleanup results
 	_, err = tx.ExecContext(ctx, `
 		INSERT INTO cleanup_log (table_name, deleted) VALUES 
-		('user_followers', ?),
-		('collaboration_interests', ?)
-	`, followersDeleted, interestsDeleted)
+		('user_followers', ?)
+	`, followersDeleted)
 	if err != nil {
 		return fmt.Errorf("failed to log cleanup: %w", err)
 	}
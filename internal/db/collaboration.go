@@ -9,11 +9,27 @@ import (
 	"github.com/mattn/go-sqlite3"
 	"log"
 	"math/rand"
+	"slices"
+	"strings"
 	"time"
+)
+
+// CollaborationStatus is where a collaboration is in its lifecycle. Only open
+// collaborations are shown in the feed and accept interest.
+type CollaborationStatus string
 
-	nanoid "github.com/matoous/go-nanoid/v2"
+const (
+	CollaborationStatusOpen       CollaborationStatus = "open"
+	CollaborationStatusInProgress CollaborationStatus = "in_progress"
+	CollaborationStatusFilled     CollaborationStatus = "filled"
+	CollaborationStatusClosed     CollaborationStatus = "closed"
+	CollaborationStatusExpired    CollaborationStatus = "expired"
 )
 
+// CollaborationExtension is how long the owner's "extend" button keeps an
+// expiring collaboration open
+const CollaborationExtension = 30 * 24 * time.Hour
+
 type CollabInterest struct {
 	ID        string
 	UserID    string
@@ -22,22 +38,30 @@ type CollabInterest struct {
 } // @Name CollabInterest
 
 type Collaboration struct {
-	ID                 string             `json:"id"`
-	UserID             string             `json:"user_id"`
-	Title              string             `json:"title"`
-	Description        string             `json:"description"`
-	IsPayable          bool               `json:"is_payable"`
-	CreatedAt          time.Time          `json:"created_at"`
-	UpdatedAt          time.Time          `json:"-"`
-	HiddenAt           *time.Time         `json:"hidden_at"`
-	Badges             []Badge            `json:"badges"`
-	Opportunity        Opportunity        `json:"opportunity"`
-	Location           *City              `json:"location"`
-	User               User               `json:"user"`
-	VerificationStatus VerificationStatus `json:"verification_status"`
-	VerifiedAt         *time.Time         `json:"verified_at"`
-	HasInterest        bool               `json:"has_interest"`
-	Links              []Link             `json:"links"`
+	ID                 string                `json:"id"`
+	UserID             string                `json:"user_id"`
+	Title              string                `json:"title"`
+	Description        string                `json:"description"`
+	IsPayable          bool                  `json:"is_payable"` // Kept in line with Compensation
+	Compensation       *Compensation         `json:"compensation"`
+	CreatedAt          time.Time             `json:"created_at"`
+	UpdatedAt          time.Time             `json:"-"`
+	HiddenAt           *time.Time            `json:"hidden_at"`
+	Badges             []Badge               `json:"badges"`
+	Opportunity        Opportunity           `json:"opportunity"`
+	Location           *City                 `json:"location"`
+	User               User                  `json:"user"` // The creator
+	Team               []CollaborationMember `json:"team"`
+	VerificationStatus VerificationStatus    `json:"verification_status"`
+	VerifiedAt         *time.Time            `json:"verified_at"`
+	HasInterest        bool                  `json:"has_interest"`
+	IsSaved            bool                  `json:"is_saved"`
+	Links              []Link                `json:"links"`
+	DenialReason       *DenialReason         `json:"denial_reason"`
+	DenialComment      *string               `json:"denial_comment"`
+	Status             CollaborationStatus   `json:"status"`
+	ExpiresAt          *time.Time            `json:"expires_at"`
+	DistanceKm         *float64              `json:"distance_km,omitempty"` // Set when listing near a city
 } // @Name Collaboration
 
 func (c *Collaboration) ToString() string {
@@ -70,7 +94,9 @@ func (c *Collaboration) ToString() string {
 		parts = append(parts, c.Opportunity.Description)
 	}
 
-	if c.IsPayable {
+	if c.Compensation != nil {
+		parts = append(parts, fmt.Sprintf("Compensation: %s", c.Compensation))
+	} else if c.IsPayable {
 		parts = append(parts, "Paid opportunity")
 	}
 
@@ -92,14 +118,153 @@ func (c *Collaboration) ToString() string {
 	return text
 }
 
+// CollaborationSort is the order of the collaboration feed
+type CollaborationSort string
+
+const (
+	CollaborationSortNewest   CollaborationSort = "newest"
+	CollaborationSortInterest CollaborationSort = "interest" // Most interested users first
+	CollaborationSortMatch    CollaborationSort = "match"    // Best fit for the viewer's profile first
+	CollaborationSortDistance CollaborationSort = "distance" // Nearest first, requires Near
+)
+
 type CollaborationQuery struct {
-	Page     int
-	Limit    int
-	Search   string
-	ViewerID string
+	Page              int
+	Limit             int
+	Search            string
+	ViewerID          string
+	Near              *GeoFilter // Only collaborations located within the radius
+	OpportunityIDs    []string   // Looking for any of these opportunities
+	BadgeIDs          []string   // Tagged with any of these badges, or all of them with MatchAllBadges
+	MatchAllBadges    bool
+	CountryCode       string
+	CityID            string
+	PayableOnly       bool
+	CompensationTypes []CompensationType // Compensated in any of these ways
+	MinAmount         *float64           // Paying at least this much in Currency, by the top of the range
+	Currency          string
+	CreatedAfter      *time.Time
+	Statuses          []CollaborationStatus // Only open ones when empty
+	Sort              CollaborationSort     // Newest first when empty
+}
+
+// collaborationFilter builds the conditions of the feed filters on top of
+// visibility, which ListCollaborations adds itself
+func collaborationFilter(params CollaborationQuery) (string, []interface{}) {
+	statuses := params.Statuses
+	if len(statuses) == 0 {
+		statuses = []CollaborationStatus{CollaborationStatusOpen}
+	}
+
+	conditions := []string{fmt.Sprintf("c.status IN (%s)", placeholders(len(statuses)))}
+	var args []interface{}
+	for _, status := range statuses {
+		args = append(args, status)
+	}
+
+	if params.Search != "" {
+		conditions = append(conditions, "(c.title LIKE ? OR c.description LIKE ?)")
+		searchPattern := "%" + params.Search + "%"
+		args = append(args, searchPattern, searchPattern)
+	}
+
+	if len(params.OpportunityIDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf(
+			"json_extract(c.opportunity, '$.id') IN (%s)", placeholders(len(params.OpportunityIDs))))
+		for _, id := range params.OpportunityIDs {
+			args = append(args, id)
+		}
+	}
+
+	if len(params.BadgeIDs) > 0 {
+		// Repeated IDs would make an all match impossible
+		badgeIDs := slices.Compact(slices.Sorted(slices.Values(params.BadgeIDs)))
+
+		if params.MatchAllBadges {
+			conditions = append(conditions, fmt.Sprintf(
+				"(SELECT COUNT(DISTINCT json_extract(value, '$.id')) FROM json_each(c.badges) WHERE json_extract(value, '$.id') IN (%s)) = ?",
+				placeholders(len(badgeIDs))))
+		} else {
+			conditions = append(conditions, fmt.Sprintf(
+				"EXISTS (SELECT 1 FROM json_each(c.badges) WHERE json_extract(value, '$.id') IN (%s))",
+				placeholders(len(badgeIDs))))
+		}
+		for _, id := range badgeIDs {
+			args = append(args, id)
+		}
+		if params.MatchAllBadges {
+			args = append(args, len(badgeIDs))
+		}
+	}
+
+	if params.CountryCode != "" {
+		conditions = append(conditions, "json_extract(c.location, '$.country_code') = ?")
+		args = append(args, strings.ToUpper(params.CountryCode))
+	}
+
+	if params.CityID != "" {
+		conditions = append(conditions, "json_extract(c.location, '$.id') = ?")
+		args = append(args, params.CityID)
+	}
+
+	if params.PayableOnly {
+		conditions = append(conditions, "c.is_payable = 1")
+	}
+
+	if len(params.CompensationTypes) > 0 {
+		conditions = append(conditions, fmt.Sprintf(
+			"json_extract(c.compensation, '$.type') IN (%s)", placeholders(len(params.CompensationTypes))))
+		for _, t := range params.CompensationTypes {
+			args = append(args, t)
+		}
+	}
+
+	if params.MinAmount != nil {
+		conditions = append(conditions, "json_extract(c.compensation, '$.currency') = ?"+
+			" AND COALESCE(json_extract(c.compensation, '$.max_amount'), json_extract(c.compensation, '$.min_amount')) >= ?")
+		args = append(args, strings.ToUpper(params.Currency), *params.MinAmount)
+	}
+
+	if params.CreatedAfter != nil {
+		conditions = append(conditions, "c.created_at >= ?")
+		args = append(args, *params.CreatedAfter)
+	}
+
+	if params.Near != nil {
+		condition, geoArgs := params.Near.geoCondition("c", "collaboration_locations")
+		conditions = append(conditions, condition)
+		args = append(args, geoArgs...)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// collaborationOrder builds the ORDER BY clause of the feed. Ties are broken
+// by recency.
+func collaborationOrder(params CollaborationQuery) (string, []interface{}) {
+	switch params.Sort {
+	case CollaborationSortInterest:
+		return `(SELECT COUNT(*) FROM collaboration_interests ci WHERE ci.collaboration_id = c.id AND ci.status != 'withdrawn') DESC, c.created_at DESC`, nil
+	case CollaborationSortMatch:
+		// One point per badge shared with the viewer, two when the viewer
+		// offers the opportunity the collaboration is looking for
+		return `(
+			(SELECT COUNT(*) FROM json_each(c.badges) cb
+			 WHERE json_extract(cb.value, '$.id') IN (
+			     SELECT json_extract(vb.value, '$.id') FROM users v, json_each(v.badges) vb WHERE v.id = ?))
+			+ 2 * EXISTS (SELECT 1 FROM users v, json_each(v.opportunities) vo
+			     WHERE v.id = ? AND json_extract(vo.value, '$.id') = json_extract(c.opportunity, '$.id'))
+		) DESC, c.created_at DESC`, []interface{}{params.ViewerID, params.ViewerID}
+	case CollaborationSortDistance:
+		if params.Near != nil {
+			return distanceExpr("c") + ` ASC, c.created_at DESC`, []interface{}{params.Near.Latitude, params.Near.Longitude}
+		}
+	}
+
+	return `c.created_at DESC`, nil
 }
 
-// ListCollaborations lists collaborations with pagination and search
+// ListCollaborations lists collaborations with pagination, filters and search
 func (s *Storage) ListCollaborations(ctx context.Context, params CollaborationQuery) ([]Collaboration, error) {
 	query := `
 		SELECT 
@@ -107,27 +272,32 @@ func (s *Storage) ListCollaborations(ctx context.Context, params CollaborationQu
 			c.created_at, c.updated_at, c.hidden_at,
 			c.location, c.links, c.badges, c.opportunity,
 			c.verification_status, c.verified_at,
+			c.denial_reason, c.denial_comment,
+			c.status, c.expires_at, c.compensation,
 			u.id, u.name, u.username, u.avatar_url, u.title,
-			u.verification_status, u.verified_at
+			u.verification_status, u.verified_at, u.language_code
 		FROM collaborations c
 		LEFT JOIN users u ON c.user_id = u.id
 		WHERE 1=1
 	`
 	var args []interface{}
 
-	// Add search filter
-	if params.Search != "" {
-		query += fmt.Sprintf(` AND (c.title LIKE ? OR c.description LIKE ?)`)
-		searchPattern := "%" + params.Search + "%"
-		args = append(args, searchPattern, searchPattern)
-	}
-
-	// Add visibility filter - show own collaborations or verified public ones
-	query += fmt.Sprintf(` AND (c.user_id = ? OR (c.verification_status = 'verified' AND c.hidden_at IS NULL))`)
+	// Add visibility filter - show the viewer's team collaborations or verified
+	// public ones of owners who aren't blocked
+	query += ` AND (c.id IN (SELECT collaboration_id FROM collaboration_members WHERE user_id = ?)
+		OR (c.verification_status = 'verified' AND c.hidden_at IS NULL AND u.verification_status != 'blocked'))`
 	args = append(args, params.ViewerID)
 
+	if where, filterArgs := collaborationFilter(params); where != "" {
+		query += ` AND ` + where
+		args = append(args, filterArgs...)
+	}
+
 	// Add ordering and pagination
-	query += ` ORDER BY c.created_at DESC`
+	order, orderArgs := collaborationOrder(params)
+	query += ` ORDER BY ` + order
+	args = append(args, orderArgs...)
+
 	if params.Page > 0 && params.Limit > 0 {
 		skip := (params.Page - 1) * params.Limit
 		query += fmt.Sprintf(` LIMIT ? OFFSET ?`)
@@ -146,10 +316,32 @@ func (s *Storage) ListCollaborations(ctx context.Context, params CollaborationQu
 		if err != nil {
 			return nil, fmt.Errorf("scan failed: %w", err)
 		}
+		collab.DistanceKm = params.Near.DistanceTo(collab.Location)
 		collaborations = append(collaborations, collab)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-	return collaborations, rows.Err()
+	ids := make([]string, len(collaborations))
+	for i, collab := range collaborations {
+		ids[i] = collab.ID
+	}
+
+	saved, err := s.savedCollaborationIDs(ctx, params.ViewerID, ids)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := s.collaborationTeams(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range collaborations {
+		collaborations[i].IsSaved = saved[collaborations[i].ID]
+		collaborations[i].Team = teams[collaborations[i].ID]
+	}
+
+	return collaborations, nil
 }
 
 // GetCollaborationByID retrieves a collaboration by ID
@@ -160,12 +352,15 @@ func (s *Storage) GetCollaborationByID(ctx context.Context, viewerID string, col
 			c.created_at, c.updated_at, c.hidden_at,
 			c.location, c.links, c.badges, c.opportunity,
 			c.verification_status, c.verified_at,
+			c.denial_reason, c.denial_comment,
+			c.status, c.expires_at, c.compensation,
 			u.id, u.chat_id, u.name, u.username, u.avatar_url, u.title,
-			u.verification_status, u.verified_at
+			u.verification_status, u.verified_at, u.language_code
 		FROM collaborations c
 		LEFT JOIN users u ON c.user_id = u.id
 		WHERE c.id = ?
-		AND (c.user_id = ? OR (c.verification_status = 'verified' AND c.hidden_at IS NULL))
+		AND (c.id IN (SELECT collaboration_id FROM collaboration_members WHERE user_id = ?)
+			OR (c.verification_status = 'verified' AND c.hidden_at IS NULL))
 	`
 
 	row := s.db.QueryRowContext(ctx, query, collabID, viewerID)
@@ -177,17 +372,41 @@ func (s *Storage) GetCollaborationByID(ctx context.Context, viewerID string, col
 		return Collaboration{}, err
 	}
 
+	teams, err := s.collaborationTeams(ctx, []string{collab.ID})
+	if err != nil {
+		return Collaboration{}, err
+	}
+	collab.Team = teams[collab.ID]
+
 	// Check if viewer has expressed interest
-	if viewerID != "" && collab.UserID != viewerID {
+	if viewerID != "" && collab.MemberRole(viewerID) == "" {
 		hasInterest, err := s.HasExpressedInterest(ctx, viewerID, collabID)
 		if err == nil {
 			collab.HasInterest = hasInterest
 		}
 	}
 
+	if saved, err := s.savedCollaborationIDs(ctx, viewerID, []string{collab.ID}); err == nil {
+		collab.IsSaved = saved[collab.ID]
+	}
+
 	return collab, nil
 }
 
+// GetCollaborationOwnerID returns the ID of the user who posted a collaboration
+func (s *Storage) GetCollaborationOwnerID(ctx context.Context, collabID string) (string, error) {
+	var userID string
+	err := s.db.QueryRowContext(ctx, `SELECT user_id FROM collaborations WHERE id = ?`, collabID).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+
+	return userID, nil
+}
+
 type CreateCollaborationParams struct {
 	Collaboration Collaboration
 	BadgeIDs      []string
@@ -209,13 +428,13 @@ func (s *Storage) CreateCollaboration(
 	// Preload
 	now := time.Now()
 
-	badges, err := s.fetchBadgesTx(ctx, tx, params.BadgeIDs)
+	badges, err := s.fetchBadgesTx(ctx, tx, params.BadgeIDs, params.Collaboration.UserID)
 	if err != nil {
 		return fmt.Errorf("failed to fetch badges: %w", err)
 	}
 	badgesJSON, _ := json.Marshal(badges)
 
-	opportunity, err := s.fetchOpportunityTx(ctx, tx, params.OpportunityID)
+	opportunity, err := s.fetchOpportunityTx(ctx, tx, params.OpportunityID, params.Collaboration.ID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return ErrNotFound
@@ -243,17 +462,27 @@ func (s *Storage) CreateCollaboration(
 		linksJSON = &data
 	}
 
+	isPayable, compensationJSON := compensationValues(params.Collaboration)
+
+	// Expiries are compared as text, so they're all stored in UTC
+	var expiresAt *time.Time
+	if params.Collaboration.ExpiresAt != nil {
+		utc := params.Collaboration.ExpiresAt.UTC()
+		expiresAt = &utc
+	}
+
 	_, err = tx.ExecContext(ctx, `
 		INSERT INTO collaborations (
 			id, user_id, title, description, is_payable,
 			created_at, updated_at, location,
-			links, badges, opportunity, verification_status
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			links, badges, opportunity, verification_status,
+			status, expires_at, compensation
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		params.Collaboration.ID,
 		params.Collaboration.UserID,
 		params.Collaboration.Title,
 		params.Collaboration.Description,
-		params.Collaboration.IsPayable,
+		isPayable,
 		now,
 		now,
 		locationJSON,
@@ -261,11 +490,22 @@ func (s *Storage) CreateCollaboration(
 		string(badgesJSON),
 		string(opportunityJSON),
 		VerificationStatusPending,
+		CollaborationStatusOpen,
+		expiresAt,
+		compensationJSON,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert collaboration: %w", err)
 	}
 
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO collaboration_members (collaboration_id, user_id, role, created_at)
+		VALUES (?, ?, ?, ?)`,
+		params.Collaboration.ID, params.Collaboration.UserID, CollaborationRoleOwner, now)
+	if err != nil {
+		return fmt.Errorf("failed to add collaboration owner: %w", err)
+	}
+
 	return tx.Commit()
 }
 
@@ -279,26 +519,26 @@ func (s *Storage) UpdateCollaboration(
 	}
 	defer tx.Rollback()
 
-	var exists bool
-	err = tx.QueryRowContext(ctx, `
-		SELECT EXISTS(SELECT 1 FROM collaborations WHERE id = ? AND user_id = ?)
-	`, params.Collaboration.ID, params.Collaboration.UserID).Scan(&exists)
-	if err != nil {
+	// Owners and editors can edit, Collaboration.UserID is the one editing
+	if _, err := s.collaborationRole(ctx, tx, params.Collaboration.ID, params.Collaboration.UserID); err != nil {
 		return err
 	}
-	if !exists {
-		return ErrNotFound
-	}
 
 	now := time.Now()
 
-	badges, err := s.fetchBadgesTx(ctx, tx, params.BadgeIDs)
+	// The creator's pending badges stay pickable whoever saves
+	creatorID, err := s.getCollaborationCreatorTx(ctx, tx, params.Collaboration.ID)
+	if err != nil {
+		return err
+	}
+
+	badges, err := s.fetchBadgesTx(ctx, tx, params.BadgeIDs, creatorID)
 	if err != nil {
 		return fmt.Errorf("failed to fetch badges: %w", err)
 	}
 	badgesJSON, _ := json.Marshal(badges)
 
-	opportunity, err := s.fetchOpportunityTx(ctx, tx, params.OpportunityID)
+	opportunity, err := s.fetchOpportunityTx(ctx, tx, params.OpportunityID, params.Collaboration.ID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return ErrNotFound
@@ -324,22 +564,23 @@ func (s *Storage) UpdateCollaboration(
 		UPDATE collaborations SET
 			title = ?, description = ?, is_payable = ?,
 			updated_at = ?, location = ?,
-			badges = ?, opportunity = ?
-		WHERE id = ? AND user_id = ?
+			badges = ?, opportunity = ?, compensation = ?
+		WHERE id = ?
 	`
 
 	collabInput := params.Collaboration
+	isPayable, compensationJSON := compensationValues(collabInput)
 
 	result, err := tx.ExecContext(ctx, query,
 		collabInput.Title,
 		collabInput.Description,
-		collabInput.IsPayable,
+		isPayable,
 		now,
 		locationJSON,
 		string(badgesJSON),
 		string(opportunityJSON),
+		compensationJSON,
 		collabInput.ID,
-		collabInput.UserID,
 	)
 
 	if err != nil {
@@ -362,8 +603,10 @@ func (s *Storage) GetCollaborationsByVerificationStatus(ctx context.Context, sta
 			c.created_at, c.updated_at, c.hidden_at,
 			c.location, c.links, c.badges, c.opportunity,
 			c.verification_status, c.verified_at,
+			c.denial_reason, c.denial_comment,
+			c.status, c.expires_at, c.compensation,
 			u.id, u.name, u.username, u.avatar_url, u.title,
-			u.verification_status, u.verified_at
+			u.verification_status, u.verified_at, u.language_code
 		FROM collaborations c
 		LEFT JOIN users u ON c.user_id = u.id
 	`
@@ -402,7 +645,8 @@ func (s *Storage) GetCollaborationsByVerificationStatus(ctx context.Context, sta
 	return collaborations, rows.Err()
 }
 
-// UpdateCollaborationVerificationStatus updates verification status
+// UpdateCollaborationVerificationStatus updates verification status.
+// Any stored denial reason is cleared once the status leaves denied.
 func (s *Storage) UpdateCollaborationVerificationStatus(ctx context.Context, id string, status VerificationStatus) error {
 	now := time.Now()
 	var verifiedAt *time.Time
@@ -414,11 +658,13 @@ func (s *Storage) UpdateCollaborationVerificationStatus(ctx context.Context, id
 		UPDATE collaborations SET
 			verification_status = ?,
 			updated_at = ?,
-			verified_at = ?
+			verified_at = ?,
+			denial_reason = CASE WHEN ? = 'denied' THEN denial_reason END,
+			denial_comment = CASE WHEN ? = 'denied' THEN denial_comment END
 		WHERE id = ?
 	`
 
-	result, err := s.db.ExecContext(ctx, query, status, now, verifiedAt, id)
+	result, err := s.db.ExecContext(ctx, query, status, now, verifiedAt, status, status, id)
 	if err != nil {
 		return fmt.Errorf("failed to update collaboration verification status: %w", err)
 	}
@@ -431,69 +677,13 @@ func (s *Storage) UpdateCollaborationVerificationStatus(ctx context.Context, id
 	return nil
 }
 
-// ExpressInterest creates an interest expression
-func (s *Storage) ExpressInterest(ctx context.Context, collabID string, userID string, ttlDuration time.Duration) error {
-	tx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	// Check user exists and is verified
-	var userExists bool
-	err = tx.QueryRowContext(ctx, `
-		SELECT EXISTS(
-			SELECT 1 FROM users 
-			WHERE id = ? AND hidden_at IS NULL AND verification_status = 'verified'
-		)
-	`, userID).Scan(&userExists)
-	if err != nil {
-		return err
-	}
-	if !userExists {
-		return ErrNotFound
-	}
-
-	// Check collaboration exists and is verified
-	var collabExists bool
-	err = tx.QueryRowContext(ctx, `
-		SELECT EXISTS(
-			SELECT 1 FROM collaborations 
-			WHERE id = ? AND verification_status = 'verified' AND hidden_at IS NULL
-		)
-	`, collabID).Scan(&collabExists)
-	if err != nil {
-		return err
-	}
-	if !collabExists {
-		return ErrNotFound
-	}
-
-	// Insert interest with TTL
-	id := nanoid.Must()
-	expiresAt := time.Now().Add(ttlDuration)
-
-	_, err = tx.ExecContext(ctx, `
-		INSERT INTO collaboration_interests (id, user_id, collaboration_id, expires_at)
-		VALUES (?, ?, ?, ?)
-		ON CONFLICT (user_id, collaboration_id) DO UPDATE SET
-			expires_at = EXCLUDED.expires_at
-	`, id, userID, collabID, expiresAt)
-
-	if err != nil {
-		return fmt.Errorf("failed to express interest: %w", err)
-	}
-
-	return tx.Commit()
-}
-
-// HasExpressedInterest checks if user has expressed interest
+// HasExpressedInterest checks if user has applied and not withdrawn since
 func (s *Storage) HasExpressedInterest(ctx context.Context, userID string, collabID string) (bool, error) {
 	var count int
 	err := s.db.QueryRowContext(ctx, `
 		SELECT COUNT(*) FROM collaboration_interests
-		WHERE user_id = ? AND collaboration_id = ? AND expires_at > ?
-	`, userID, collabID, time.Now()).Scan(&count)
+		WHERE user_id = ? AND collaboration_id = ? AND status != 'withdrawn'
+	`, userID, collabID).Scan(&count)
 
 	if err != nil {
 		return false, fmt.Errorf("failed to check interest status: %w", err)
@@ -504,18 +694,20 @@ func (s *Storage) HasExpressedInterest(ctx context.Context, userID string, colla
 
 // Helper functions
 
-func scanCollaboration(rows *sql.Rows) (Collaboration, error) {
+func scanCollaboration(rows rowScanner) (Collaboration, error) {
 	var collab Collaboration
 	var user User
-	var locationJSON, linksJSON, badgesJSON, opportunityJSON sql.NullString
+	var locationJSON, linksJSON, badgesJSON, opportunityJSON, denialReasonJSON, compensationJSON sql.NullString
 
 	err := rows.Scan(
 		&collab.ID, &collab.UserID, &collab.Title, &collab.Description, &collab.IsPayable,
 		&collab.CreatedAt, &collab.UpdatedAt, &collab.HiddenAt,
 		&locationJSON, &linksJSON, &badgesJSON, &opportunityJSON,
 		&collab.VerificationStatus, &collab.VerifiedAt,
+		&denialReasonJSON, &collab.DenialComment,
+		&collab.Status, &collab.ExpiresAt, &compensationJSON,
 		&user.ID, &user.Name, &user.Username, &user.AvatarURL, &user.Title,
-		&user.VerificationStatus, &user.VerifiedAt,
+		&user.VerificationStatus, &user.VerifiedAt, &user.LanguageCode,
 	)
 	if err != nil {
 		return collab, err
@@ -537,6 +729,12 @@ func scanCollaboration(rows *sql.Rows) (Collaboration, error) {
 	if opportunityJSON.Valid && opportunityJSON.String != "" {
 		json.Unmarshal([]byte(opportunityJSON.String), &collab.Opportunity)
 	}
+	if denialReasonJSON.Valid && denialReasonJSON.String != "" {
+		json.Unmarshal([]byte(denialReasonJSON.String), &collab.DenialReason)
+	}
+	if compensationJSON.Valid && compensationJSON.String != "" {
+		json.Unmarshal([]byte(compensationJSON.String), &collab.Compensation)
+	}
 	collab.User = user
 
 	return collab, nil
@@ -545,15 +743,17 @@ func scanCollaboration(rows *sql.Rows) (Collaboration, error) {
 func scanCollaborationRow(row *sql.Row) (Collaboration, error) {
 	var collab Collaboration
 	var user User
-	var locationJSON, linksJSON, badgesJSON, opportunityJSON sql.NullString
+	var locationJSON, linksJSON, badgesJSON, opportunityJSON, denialReasonJSON, compensationJSON sql.NullString
 
 	err := row.Scan(
 		&collab.ID, &collab.UserID, &collab.Title, &collab.Description, &collab.IsPayable,
 		&collab.CreatedAt, &collab.UpdatedAt, &collab.HiddenAt,
 		&locationJSON, &linksJSON, &badgesJSON, &opportunityJSON,
 		&collab.VerificationStatus, &collab.VerifiedAt,
+		&denialReasonJSON, &collab.DenialComment,
+		&collab.Status, &collab.ExpiresAt, &compensationJSON,
 		&user.ID, &user.ChatID, &user.Name, &user.Username, &user.AvatarURL,
-		&user.Title, &user.VerificationStatus, &user.VerifiedAt,
+		&user.Title, &user.VerificationStatus, &user.VerifiedAt, &user.LanguageCode,
 	)
 	if err != nil {
 		return collab, err
@@ -575,6 +775,12 @@ func scanCollaborationRow(row *sql.Row) (Collaboration, error) {
 	if opportunityJSON.Valid && opportunityJSON.String != "" {
 		json.Unmarshal([]byte(opportunityJSON.String), &collab.Opportunity)
 	}
+	if denialReasonJSON.Valid && denialReasonJSON.String != "" {
+		json.Unmarshal([]byte(denialReasonJSON.String), &collab.DenialReason)
+	}
+	if compensationJSON.Valid && compensationJSON.String != "" {
+		json.Unmarshal([]byte(compensationJSON.String), &collab.Compensation)
+	}
 
 	collab.User = user
 
@@ -588,8 +794,10 @@ func (s *Storage) GetUserCollaborations(ctx context.Context, userID string) ([]C
 			c.created_at, c.updated_at, c.hidden_at,
 			c.location, c.links, c.badges, c.opportunity,
 			c.verification_status, c.verified_at,
+			c.denial_reason, c.denial_comment,
+			c.status, c.expires_at, c.compensation,
 			u.id, u.name, u.username, u.avatar_url, u.title,
-			u.verification_status, u.verified_at
+			u.verification_status, u.verified_at, u.language_code
 		FROM collaborations c
 		LEFT JOIN users u ON c.user_id = u.id
 		WHERE c.user_id = ?
@@ -0,0 +1,161 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CloseCollaboration takes a collaboration of an owner out of the feed, as
+// in progress, filled or simply closed
+func (s *Storage) CloseCollaboration(ctx context.Context, ownerID, collabID string, status CollaborationStatus) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE collaborations SET status = ?, updated_at = ?
+		WHERE id = ? AND `+ownedByMember+`
+	`, status, time.Now(), collabID, ownerID)
+	if err != nil {
+		return fmt.Errorf("failed to close collaboration: %w", err)
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ReopenCollaboration puts a collaboration of an owner back in the feed.
+// A new expiry replaces the old one, otherwise an expiry that has already
+// passed is dropped. Expiries are stored and compared in UTC, they're
+// compared as text.
+func (s *Storage) ReopenCollaboration(ctx context.Context, ownerID, collabID string, expiresAt *time.Time) error {
+	now := time.Now().UTC()
+	if expiresAt != nil {
+		utc := expiresAt.UTC()
+		expiresAt = &utc
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE collaborations SET
+			status = 'open',
+			expires_at = CASE
+				WHEN ? IS NOT NULL THEN ?
+				WHEN expires_at <= ? THEN NULL
+				ELSE expires_at
+			END,
+			expiry_reminded_at = NULL,
+			updated_at = ?
+		WHERE id = ? AND `+ownedByMember+`
+	`, expiresAt, expiresAt, now, now, collabID, ownerID)
+	if err != nil {
+		return fmt.Errorf("failed to reopen collaboration: %w", err)
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ExtendCollaboration pushes the expiry of an open or expired collaboration
+// of the owner back by the given duration, reopening it if it had expired
+func (s *Storage) ExtendCollaboration(ctx context.Context, ownerID, collabID string, by time.Duration) (time.Time, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer tx.Rollback()
+
+	var expiresAt *time.Time
+	err = tx.QueryRowContext(ctx, `
+		SELECT expires_at FROM collaborations
+		WHERE id = ? AND `+ownedByMember+` AND status IN ('open', 'expired')
+	`, collabID, ownerID).Scan(&expiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, ErrNotFound
+		}
+		return time.Time{}, err
+	}
+
+	now := time.Now().UTC()
+	from := now
+	if expiresAt != nil && expiresAt.After(now) {
+		from = expiresAt.UTC()
+	}
+	newExpiresAt := from.Add(by)
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE collaborations SET
+			status = 'open', expires_at = ?, expiry_reminded_at = NULL, updated_at = ?
+		WHERE id = ?
+	`, newExpiresAt, now, collabID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to extend collaboration: %w", err)
+	}
+
+	return newExpiresAt, tx.Commit()
+}
+
+// ExpireCollaborations marks open collaborations past their expiry as expired
+func (s *Storage) ExpireCollaborations(ctx context.Context, now time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE collaborations SET status = 'expired', updated_at = ?
+		WHERE status = 'open' AND expires_at <= ?
+	`, now, now.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire collaborations: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// ListCollaborationsExpiringBefore lists open collaborations expiring before
+// the given time whose owner hasn't been reminded yet, with the owner's chat
+func (s *Storage) ListCollaborationsExpiringBefore(ctx context.Context, before time.Time) ([]Collaboration, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id FROM collaborations
+		WHERE status = 'open' AND expires_at <= ? AND expiry_reminded_at IS NULL
+		ORDER BY expires_at
+	`, before.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	type ownedCollab struct{ id, ownerID string }
+	var owned []ownedCollab
+	for rows.Next() {
+		var c ownedCollab
+		if err := rows.Scan(&c.id, &c.ownerID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		owned = append(owned, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	collabs := make([]Collaboration, 0, len(owned))
+	for _, c := range owned {
+		collab, err := s.GetCollaborationByID(ctx, c.ownerID, c.id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get collaboration %s: %w", c.id, err)
+		}
+		collabs = append(collabs, collab)
+	}
+
+	return collabs, nil
+}
+
+// MarkCollaborationExpiryReminded records that the owner was told about the
+// upcoming expiry, so they're only reminded once per expiry
+func (s *Storage) MarkCollaborationExpiryReminded(ctx context.Context, collabID string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE collaborations SET expiry_reminded_at = ? WHERE id = ?`, time.Now(), collabID)
+	return err
+}
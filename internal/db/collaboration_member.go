@@ -0,0 +1,346 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CollaborationRole is what a team member can do with a collaboration.
+// Owners can also close it and manage the team, editors only edit it.
+type CollaborationRole string
+
+const (
+	CollaborationRoleOwner  CollaborationRole = "owner"
+	CollaborationRoleEditor CollaborationRole = "editor"
+)
+
+// ownedByMember matches the collaborations the ? user is an owner of
+const ownedByMember = `id IN (
+	SELECT collaboration_id FROM collaboration_members WHERE user_id = ? AND role = 'owner'
+)`
+
+// ErrCollaborationCreator is returned when removing or demoting the user who
+// posted a collaboration, they stay its owner
+var ErrCollaborationCreator = errors.New("the creator stays an owner")
+
+// CollaborationMember is someone on the team behind a collaboration. The user
+// who posted it is always a member with the owner role.
+type CollaborationMember struct {
+	CollaborationID string            `json:"collaboration_id"`
+	User            User              `json:"user"`
+	Role            CollaborationRole `json:"role"`
+	CreatedAt       time.Time         `json:"created_at"`
+} // @Name CollaborationMember
+
+// IsValidCollaborationRole reports whether r is one of the known roles
+func IsValidCollaborationRole(r CollaborationRole) bool {
+	return r == CollaborationRoleOwner || r == CollaborationRoleEditor
+}
+
+// MemberRole returns the role of the user on the team, empty if not a member.
+// Only set on collaborations loaded with their team.
+func (c *Collaboration) MemberRole(userID string) CollaborationRole {
+	for _, m := range c.Team {
+		if m.User.ID == userID {
+			return m.Role
+		}
+	}
+	return ""
+}
+
+// backfillCollaborationMembers makes the poster of every collaboration its owner
+func backfillCollaborationMembers(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO collaboration_members (collaboration_id, user_id, role, created_at)
+		SELECT id, user_id, ?, created_at FROM collaborations WHERE true
+		ON CONFLICT (collaboration_id, user_id) DO NOTHING
+	`, CollaborationRoleOwner)
+	if err != nil {
+		return fmt.Errorf("failed to backfill collaboration members: %w", err)
+	}
+
+	return nil
+}
+
+// rowQuerier is either the database or a transaction on it
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// collaborationRole returns the role of the user on a collaboration team,
+// ErrNotFound if the user is not a member
+func (s *Storage) collaborationRole(ctx context.Context, q rowQuerier, collabID, userID string) (CollaborationRole, error) {
+	var role CollaborationRole
+	err := q.QueryRowContext(ctx, `
+		SELECT role FROM collaboration_members
+		WHERE collaboration_id = ? AND user_id = ?
+	`, collabID, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+
+	return role, nil
+}
+
+// SetCollaborationMember changes the role of someone on the team of a
+// collaboration owned by ownerID, or invites a verified, visible user to join
+// it with the role. It reports whether an invitation was sent, the user joins
+// once they accept it.
+func (s *Storage) SetCollaborationMember(ctx context.Context, ownerID, collabID, userID string, role CollaborationRole) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	creatorID, err := s.requireCollaborationOwner(ctx, tx, ownerID, collabID)
+	if err != nil {
+		return false, err
+	}
+	if userID == creatorID {
+		return false, ErrCollaborationCreator
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE collaboration_members SET role = ? WHERE collaboration_id = ? AND user_id = ?
+	`, role, collabID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to set collaboration member: %w", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected > 0 {
+		return false, tx.Commit()
+	}
+
+	var invitable bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM users
+			WHERE id = ? AND verification_status = 'verified' AND hidden_at IS NULL
+		)
+	`, userID).Scan(&invitable)
+	if err != nil {
+		return false, err
+	}
+	if !invitable {
+		return false, ErrNotFound
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO collaboration_invites (collaboration_id, user_id, role, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (collaboration_id, user_id) DO UPDATE SET role = excluded.role
+	`, collabID, userID, role, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to invite collaboration member: %w", err)
+	}
+
+	return true, tx.Commit()
+}
+
+// CollaborationInvite is an invitation to join the team of a collaboration
+type CollaborationInvite struct {
+	CollaborationID string            `json:"collaboration_id"`
+	Title           string            `json:"title"`
+	Role            CollaborationRole `json:"role"`
+	CreatedAt       time.Time         `json:"created_at"`
+}
+
+// ListCollaborationInvites lists the invitations of the user, newest first
+func (s *Storage) ListCollaborationInvites(ctx context.Context, userID string) ([]CollaborationInvite, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT i.collaboration_id, c.title, i.role, i.created_at
+		FROM collaboration_invites i
+		JOIN collaborations c ON c.id = i.collaboration_id
+		WHERE i.user_id = ?
+		ORDER BY i.created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	invites := []CollaborationInvite{}
+	for rows.Next() {
+		var invite CollaborationInvite
+		if err := rows.Scan(&invite.CollaborationID, &invite.Title, &invite.Role, &invite.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		invites = append(invites, invite)
+	}
+
+	return invites, rows.Err()
+}
+
+// AcceptCollaborationInvite puts the user on the team with the role they
+// were invited with
+func (s *Storage) AcceptCollaborationInvite(ctx context.Context, userID, collabID string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var role CollaborationRole
+	err = tx.QueryRowContext(ctx, `
+		DELETE FROM collaboration_invites WHERE collaboration_id = ? AND user_id = ?
+		RETURNING role
+	`, collabID, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO collaboration_members (collaboration_id, user_id, role, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (collaboration_id, user_id) DO UPDATE SET role = excluded.role
+	`, collabID, userID, role, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to add collaboration member: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// DeclineCollaborationInvite drops an invitation of the user
+func (s *Storage) DeclineCollaborationInvite(ctx context.Context, userID, collabID string) error {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM collaboration_invites WHERE collaboration_id = ? AND user_id = ?
+	`, collabID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to decline invite: %w", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// RemoveCollaborationMember takes a user off the team. Owners can remove
+// anyone but the creator and withdraw invitations, other members can only
+// leave.
+func (s *Storage) RemoveCollaborationMember(ctx context.Context, actorID, collabID, userID string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var creatorID string
+	if actorID == userID {
+		if _, err := s.collaborationRole(ctx, tx, collabID, userID); err != nil {
+			return err
+		}
+		if creatorID, err = s.getCollaborationCreatorTx(ctx, tx, collabID); err != nil {
+			return err
+		}
+	} else if creatorID, err = s.requireCollaborationOwner(ctx, tx, actorID, collabID); err != nil {
+		return err
+	}
+	if userID == creatorID {
+		return ErrCollaborationCreator
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		DELETE FROM collaboration_members WHERE collaboration_id = ? AND user_id = ?
+	`, collabID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove collaboration member: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+
+	if actorID != userID {
+		result, err := tx.ExecContext(ctx, `
+			DELETE FROM collaboration_invites WHERE collaboration_id = ? AND user_id = ?
+		`, collabID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to withdraw invite: %w", err)
+		}
+		withdrawn, _ := result.RowsAffected()
+		rowsAffected += withdrawn
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return tx.Commit()
+}
+
+// requireCollaborationOwner checks the user owns the collaboration and
+// returns the ID of its creator
+func (s *Storage) requireCollaborationOwner(ctx context.Context, tx *sql.Tx, userID, collabID string) (string, error) {
+	role, err := s.collaborationRole(ctx, tx, collabID, userID)
+	if err != nil {
+		return "", err
+	}
+	if role != CollaborationRoleOwner {
+		return "", ErrNotFound
+	}
+
+	return s.getCollaborationCreatorTx(ctx, tx, collabID)
+}
+
+func (s *Storage) getCollaborationCreatorTx(ctx context.Context, tx *sql.Tx, collabID string) (string, error) {
+	var creatorID string
+	err := tx.QueryRowContext(ctx, `SELECT user_id FROM collaborations WHERE id = ?`, collabID).Scan(&creatorID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+
+	return creatorID, nil
+}
+
+// collaborationTeams loads the members of the collaborations, owners first
+func (s *Storage) collaborationTeams(ctx context.Context, collabIDs []string) (map[string][]CollaborationMember, error) {
+	teams := make(map[string][]CollaborationMember)
+	if len(collabIDs) == 0 {
+		return teams, nil
+	}
+
+	args := make([]interface{}, len(collabIDs))
+	for i, id := range collabIDs {
+		args[i] = id
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			m.collaboration_id, m.role, m.created_at,
+			u.id, u.chat_id, u.name, u.username, u.avatar_url, u.title, u.language_code
+		FROM collaboration_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.collaboration_id IN (`+placeholders(len(collabIDs))+`)
+		ORDER BY m.role = 'owner' DESC, m.created_at
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collaboration members: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m CollaborationMember
+		err := rows.Scan(
+			&m.CollaborationID, &m.Role, &m.CreatedAt,
+			&m.User.ID, &m.User.ChatID, &m.User.Name, &m.User.Username,
+			&m.User.AvatarURL, &m.User.Title, &m.User.LanguageCode,
+		)
+		if err != nil {
+			return nil, err
+		}
+		teams[m.CollaborationID] = append(teams[m.CollaborationID], m)
+	}
+
+	return teams, rows.Err()
+}
@@ -0,0 +1,382 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	nanoid "github.com/matoous/go-nanoid/v2"
+)
+
+// CommentReportsToHide is how many reports take a comment down until a
+// moderator looks at it
+const CommentReportsToHide = 3
+
+// Comment is a question or answer on a collaboration. Replies are one level
+// deep, a reply to a reply goes to the thread it's in.
+type Comment struct {
+	ID              string     `json:"id"`
+	CollaborationID string     `json:"collaboration_id"`
+	ParentID        *string    `json:"parent_id"`
+	UserID          string     `json:"user_id"`
+	User            User       `json:"user"`
+	Body            string     `json:"body"` // Empty once deleted
+	CreatedAt       time.Time  `json:"created_at"`
+	EditedAt        *time.Time `json:"edited_at"`
+	DeletedAt       *time.Time `json:"deleted_at"` // Kept as a placeholder while it has replies
+	PinnedAt        *time.Time `json:"pinned_at"`
+	HiddenAt        *time.Time `json:"hidden_at"` // Taken down by reports or a moderator
+	ReportCount     int        `json:"report_count"`
+	Replies         []Comment  `json:"replies"`
+} // @Name Comment
+
+const commentColumns = `
+	cm.id, cm.collaboration_id, cm.parent_id, cm.user_id, cm.body,
+	cm.created_at, cm.edited_at, cm.deleted_at, cm.pinned_at, cm.hidden_at, cm.report_count,
+	u.id, u.chat_id, u.name, u.username, u.avatar_url, u.title, u.language_code`
+
+const commentJoins = `
+	FROM collaboration_comments cm
+	JOIN users u ON u.id = cm.user_id`
+
+// commentVisible matches the comments the ? viewer can read: not taken down,
+// by authors who aren't blocked or hidden, unless the viewer wrote them
+const commentVisible = `(cm.user_id = ? OR (
+	cm.hidden_at IS NULL AND u.hidden_at IS NULL AND u.verification_status != 'blocked'
+))`
+
+func scanComment(row rowScanner) (Comment, error) {
+	var comment Comment
+	err := row.Scan(
+		&comment.ID, &comment.CollaborationID, &comment.ParentID, &comment.UserID, &comment.Body,
+		&comment.CreatedAt, &comment.EditedAt, &comment.DeletedAt, &comment.PinnedAt, &comment.HiddenAt, &comment.ReportCount,
+		&comment.User.ID, &comment.User.ChatID, &comment.User.Name, &comment.User.Username,
+		&comment.User.AvatarURL, &comment.User.Title, &comment.User.LanguageCode,
+	)
+	return comment, err
+}
+
+// requireCollaborationVisible returns ErrNotFound unless the collaboration is
+// verified and public, or the user is on its team
+func (s *Storage) requireCollaborationVisible(ctx context.Context, q rowQuerier, userID, collabID string) error {
+	var visible bool
+	err := q.QueryRowContext(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM collaborations
+			WHERE id = ? AND (
+				id IN (SELECT collaboration_id FROM collaboration_members WHERE user_id = ?)
+				OR (verification_status = 'verified' AND hidden_at IS NULL)
+			)
+		)
+	`, collabID, userID).Scan(&visible)
+	if err != nil {
+		return err
+	}
+	if !visible {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// GetComment returns a comment with its author
+func (s *Storage) GetComment(ctx context.Context, id string) (Comment, error) {
+	comment, err := scanComment(s.db.QueryRowContext(ctx, `SELECT `+commentColumns+commentJoins+` WHERE cm.id = ?`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Comment{}, ErrNotFound
+		}
+		return Comment{}, err
+	}
+
+	return comment, nil
+}
+
+// CreateComment adds a comment to a collaboration the author can see
+func (s *Storage) CreateComment(ctx context.Context, comment Comment) (Comment, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Comment{}, err
+	}
+	defer tx.Rollback()
+
+	if err := s.requireCollaborationVisible(ctx, tx, comment.UserID, comment.CollaborationID); err != nil {
+		return Comment{}, err
+	}
+
+	if comment.ParentID != nil {
+		var rootID *string
+		err := tx.QueryRowContext(ctx, `
+			SELECT parent_id FROM collaboration_comments
+			WHERE id = ? AND collaboration_id = ? AND deleted_at IS NULL AND hidden_at IS NULL
+		`, *comment.ParentID, comment.CollaborationID).Scan(&rootID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return Comment{}, ErrNotFound
+			}
+			return Comment{}, err
+		}
+		if rootID != nil {
+			comment.ParentID = rootID
+		}
+	}
+
+	comment.ID = nanoid.Must()
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO collaboration_comments (id, collaboration_id, parent_id, user_id, body, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, comment.ID, comment.CollaborationID, comment.ParentID, comment.UserID, comment.Body, time.Now())
+	if err != nil {
+		return Comment{}, fmt.Errorf("failed to insert comment: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Comment{}, err
+	}
+
+	return s.GetComment(ctx, comment.ID)
+}
+
+// ListComments lists the threads on a collaboration, pinned first and then
+// oldest first, each with all its replies. Deleted questions stay as
+// placeholders while they have replies.
+func (s *Storage) ListComments(ctx context.Context, viewerID, collabID string, page, perPage int) ([]Comment, error) {
+	if err := s.requireCollaborationVisible(ctx, s.db, viewerID, collabID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + commentColumns + commentJoins + `
+		WHERE cm.collaboration_id = ? AND cm.parent_id IS NULL AND ` + commentVisible + `
+		AND (cm.deleted_at IS NULL OR EXISTS (
+			SELECT 1 FROM collaboration_comments r
+			WHERE r.parent_id = cm.id AND r.deleted_at IS NULL AND r.hidden_at IS NULL
+		))
+		ORDER BY cm.pinned_at IS NULL, cm.pinned_at DESC, cm.created_at`
+	args := []interface{}{collabID, viewerID}
+
+	if page > 0 && perPage > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, perPage, (page-1)*perPage)
+	}
+
+	threads, err := s.queryComments(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(threads) == 0 {
+		return threads, nil
+	}
+
+	args = []interface{}{viewerID}
+	for _, thread := range threads {
+		args = append(args, thread.ID)
+	}
+
+	replies, err := s.queryComments(ctx, `SELECT `+commentColumns+commentJoins+`
+		WHERE cm.deleted_at IS NULL AND `+commentVisible+`
+		AND cm.parent_id IN (`+placeholders(len(threads))+`)
+		ORDER BY cm.created_at`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	byParent := make(map[string][]Comment)
+	for _, reply := range replies {
+		byParent[*reply.ParentID] = append(byParent[*reply.ParentID], reply)
+	}
+	for i := range threads {
+		threads[i].Replies = byParent[threads[i].ID]
+	}
+
+	return threads, nil
+}
+
+func (s *Storage) queryComments(ctx context.Context, query string, args ...interface{}) ([]Comment, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []Comment{}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+
+	return comments, rows.Err()
+}
+
+// UpdateComment changes the text of one of the user's comments
+func (s *Storage) UpdateComment(ctx context.Context, userID, id, body string) (Comment, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE collaboration_comments SET body = ?, edited_at = ?
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+	`, body, time.Now(), id, userID)
+	if err != nil {
+		return Comment{}, fmt.Errorf("failed to update comment: %w", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return Comment{}, ErrNotFound
+	}
+
+	return s.GetComment(ctx, id)
+}
+
+// DeleteComment deletes a comment of the user, or any comment on a
+// collaboration the user owns
+func (s *Storage) DeleteComment(ctx context.Context, userID, id string) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE collaboration_comments SET body = '', deleted_at = ?, pinned_at = NULL
+		WHERE id = ? AND deleted_at IS NULL AND (
+			user_id = ? OR collaboration_id IN (
+				SELECT collaboration_id FROM collaboration_members WHERE user_id = ? AND role = 'owner'
+			)
+		)
+	`, time.Now(), id, userID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// PinComment pins a question to the top of a collaboration the user owns, or
+// unpins it
+func (s *Storage) PinComment(ctx context.Context, ownerID, id string, pinned bool) (Comment, error) {
+	var pinnedAt *time.Time
+	if pinned {
+		now := time.Now()
+		pinnedAt = &now
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE collaboration_comments SET pinned_at = ?
+		WHERE id = ? AND parent_id IS NULL AND deleted_at IS NULL AND collaboration_id IN (
+			SELECT collaboration_id FROM collaboration_members WHERE user_id = ? AND role = 'owner'
+		)
+	`, pinnedAt, id, ownerID)
+	if err != nil {
+		return Comment{}, fmt.Errorf("failed to pin comment: %w", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return Comment{}, ErrNotFound
+	}
+
+	return s.GetComment(ctx, id)
+}
+
+// ReportComment records a report of someone else's comment, once per user,
+// and takes the comment down at CommentReportsToHide reports. It reports
+// whether the report was new.
+func (s *Storage) ReportComment(ctx context.Context, userID, id, reason string) (Comment, bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Comment{}, false, err
+	}
+	defer tx.Rollback()
+
+	var collabID string
+	err = tx.QueryRowContext(ctx, `
+		SELECT collaboration_id FROM collaboration_comments
+		WHERE id = ? AND user_id != ? AND deleted_at IS NULL
+	`, id, userID).Scan(&collabID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Comment{}, false, ErrNotFound
+		}
+		return Comment{}, false, err
+	}
+	if err := s.requireCollaborationVisible(ctx, tx, userID, collabID); err != nil {
+		return Comment{}, false, err
+	}
+
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO comment_reports (comment_id, user_id, reason, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (comment_id, user_id) DO NOTHING
+	`, id, userID, reason, now)
+	if err != nil {
+		return Comment{}, false, fmt.Errorf("failed to report comment: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected > 0 {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE collaboration_comments SET
+				report_count = report_count + 1,
+				hidden_at = CASE WHEN report_count + 1 >= ? THEN COALESCE(hidden_at, ?) ELSE hidden_at END
+			WHERE id = ?
+		`, CommentReportsToHide, now, id)
+		if err != nil {
+			return Comment{}, false, fmt.Errorf("failed to count report: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Comment{}, false, err
+	}
+
+	comment, err := s.GetComment(ctx, id)
+	return comment, rowsAffected > 0, err
+}
+
+// ListReportedComments lists comments waiting for a moderator, most reported first
+func (s *Storage) ListReportedComments(ctx context.Context, page, perPage int) ([]Comment, error) {
+	query := `SELECT ` + commentColumns + commentJoins + `
+		WHERE cm.report_count > 0 AND cm.deleted_at IS NULL
+		ORDER BY cm.report_count DESC, cm.created_at DESC`
+	var args []interface{}
+
+	if page > 0 && perPage > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, perPage, (page-1)*perPage)
+	}
+
+	return s.queryComments(ctx, query, args...)
+}
+
+// SetCommentHidden takes a comment down or restores it. Restoring clears its
+// reports, so it leaves the moderation queue and can be reported again.
+func (s *Storage) SetCommentHidden(ctx context.Context, id string, hidden bool) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var result sql.Result
+	if hidden {
+		result, err = tx.ExecContext(ctx, `
+			UPDATE collaboration_comments SET hidden_at = COALESCE(hidden_at, ?), report_count = 0
+			WHERE id = ?
+		`, time.Now(), id)
+	} else {
+		result, err = tx.ExecContext(ctx, `
+			UPDATE collaboration_comments SET hidden_at = NULL, report_count = 0 WHERE id = ?
+		`, id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	if !hidden {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM comment_reports WHERE comment_id = ?`, id); err != nil {
+			return fmt.Errorf("failed to clear reports: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
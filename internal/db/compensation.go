@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// CompensationType is how a collaboration rewards the people who join it
+type CompensationType string
+
+const (
+	CompensationPaid      CompensationType = "paid"
+	CompensationEquity    CompensationType = "equity"
+	CompensationRevShare  CompensationType = "revshare"
+	CompensationVolunteer CompensationType = "volunteer"
+	CompensationBarter    CompensationType = "barter"
+)
+
+// CompensationPeriod is what a paid amount is for
+type CompensationPeriod string
+
+const (
+	CompensationPerProject CompensationPeriod = "project"
+	CompensationPerHour    CompensationPeriod = "hour"
+	CompensationPerDay     CompensationPeriod = "day"
+	CompensationPerWeek    CompensationPeriod = "week"
+	CompensationPerMonth   CompensationPeriod = "month"
+)
+
+var compensationLabels = map[CompensationType]string{
+	CompensationPaid:      "Paid",
+	CompensationEquity:    "Equity",
+	CompensationRevShare:  "Revenue share",
+	CompensationVolunteer: "Volunteer",
+	CompensationBarter:    "Barter",
+}
+
+// Compensation replaces the is_payable flag of collaborations. Amounts,
+// currency and period only apply to paid collaborations.
+type Compensation struct {
+	Type      CompensationType   `json:"type"`
+	MinAmount *float64           `json:"min_amount,omitempty"`
+	MaxAmount *float64           `json:"max_amount,omitempty"`
+	Currency  string             `json:"currency,omitempty"` // ISO 4217, e.g. EUR
+	Period    CompensationPeriod `json:"period,omitempty"`
+}
+
+// IsPaid tells if the collaboration pays money, which is what is_payable means
+func (c *Compensation) IsPaid() bool {
+	return c != nil && c.Type == CompensationPaid
+}
+
+// Amount formats the amount range, e.g. "500-1000 EUR / month", or returns
+// an empty string when no amount is set
+func (c *Compensation) Amount() string {
+	if c == nil || (c.MinAmount == nil && c.MaxAmount == nil) {
+		return ""
+	}
+
+	var amount string
+	switch {
+	case c.MinAmount != nil && c.MaxAmount != nil && *c.MinAmount != *c.MaxAmount:
+		amount = formatAmount(*c.MinAmount) + "-" + formatAmount(*c.MaxAmount)
+	case c.MinAmount != nil:
+		amount = formatAmount(*c.MinAmount)
+	default:
+		amount = "up to " + formatAmount(*c.MaxAmount)
+	}
+
+	if c.Currency != "" {
+		amount += " " + c.Currency
+	}
+	if c.Period != "" {
+		amount += " / " + string(c.Period)
+	}
+
+	return amount
+}
+
+// String describes the compensation in English, e.g. "Paid, 500 EUR / project".
+// It goes into the embedding text and the community chat image.
+func (c *Compensation) String() string {
+	if c == nil {
+		return ""
+	}
+
+	label, ok := compensationLabels[c.Type]
+	if !ok {
+		label = string(c.Type)
+	}
+
+	if amount := c.Amount(); amount != "" {
+		return label + ", " + amount
+	}
+	return label
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', -1, 64)
+}
+
+// backfillCollaborationCompensation turns is_payable into a paid compensation
+// for collaborations created before compensation existed. Unpaid ones are
+// left without one, is_payable = false only ever was the default.
+func backfillCollaborationCompensation(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE collaborations SET compensation = json_object('type', ?)
+		WHERE compensation IS NULL AND is_payable = 1
+	`, CompensationPaid)
+	if err != nil {
+		return fmt.Errorf("failed to backfill compensation: %w", err)
+	}
+
+	return nil
+}
+
+// IsValidCompensationType reports whether t is one of the known types
+func IsValidCompensationType(t CompensationType) bool {
+	_, ok := compensationLabels[t]
+	return ok
+}
+
+// IsValidCompensationPeriod reports whether p is one of the known periods
+func IsValidCompensationPeriod(p CompensationPeriod) bool {
+	switch p {
+	case CompensationPerProject, CompensationPerHour, CompensationPerDay, CompensationPerWeek, CompensationPerMonth:
+		return true
+	default:
+		return false
+	}
+}
+
+// compensationValues returns the is_payable and compensation column values
+// of a collaboration, keeping is_payable in line with the compensation
+func compensationValues(c Collaboration) (bool, *string) {
+	if c.Compensation == nil {
+		return c.IsPayable, nil
+	}
+
+	data, _ := json.Marshal(c.Compensation)
+	compensationJSON := string(data)
+	return c.Compensation.IsPaid(), &compensationJSON
+}
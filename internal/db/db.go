@@ -9,6 +9,7 @@ import (
 	sqlite_vec "github.com/asg017/sqlite-vec-go-bindings/cgo"
 	"github.com/mattn/go-sqlite3"
 	"log"
+	"strings"
 	"time"
 )
 
@@ -41,8 +42,11 @@ func init() {
 					PRAGMA temp_store         = MEMORY;
 					PRAGMA cache_size         = -16000;
 				`, nil)
+				if err != nil {
+					return err
+				}
 
-				return err
+				return conn.RegisterFunc("distance_km", sqlDistanceKm, true)
 			},
 		},
 	)
@@ -56,7 +60,7 @@ func NewStorage(dbFile string) (*Storage, error) {
 
 	// Set connection pool parameters
 	// For in-memory databases with cache=shared, we need to be careful with connection pooling
-	if dbFile == "file::memory:?cache=shared" || dbFile == ":memory:" {
+	if strings.Contains(dbFile, ":memory:") || strings.Contains(dbFile, "mode=memory") {
 		// For tests, use a single connection to avoid issues
 		db.SetMaxOpenConns(1)
 		db.SetMaxIdleConns(1)
@@ -98,13 +102,16 @@ func (s *Storage) InitSchema() error {
 	statements := []string{
 		// Cities table
 		`CREATE TABLE IF NOT EXISTS cities (
-			id           TEXT PRIMARY KEY,
-			name         TEXT NOT NULL,
-			country_code TEXT NOT NULL,
-			country_name TEXT NOT NULL,
-			latitude     REAL,
-			longitude    REAL,
-			created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			id              TEXT PRIMARY KEY,
+			name            TEXT NOT NULL,
+			country_code    TEXT NOT NULL,
+			country_name    TEXT NOT NULL,
+			latitude        REAL,
+			longitude       REAL,
+			population      INTEGER NOT NULL DEFAULT 0,
+			alternate_names TEXT,
+			search_names    TEXT,
+			created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		)`,
 		// Opportunities table
 		`CREATE TABLE IF NOT EXISTS opportunities (
@@ -115,15 +122,20 @@ func (s *Storage) InitSchema() error {
 			description_ru TEXT,
 			icon           TEXT,
 			color          TEXT,
+			position       INTEGER NOT NULL DEFAULT 0,
+			archived_at    TIMESTAMP,
 			created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		)`,
 		// Badges table
 		`CREATE TABLE IF NOT EXISTS badges (
-			id         TEXT PRIMARY KEY,
-			text       TEXT NOT NULL,
-			icon       TEXT,
-			color      TEXT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			id              TEXT PRIMARY KEY,
+			text            TEXT NOT NULL,
+			icon            TEXT,
+			color           TEXT,
+			status          TEXT NOT NULL DEFAULT 'approved',
+			created_by      TEXT,
+			normalized_text TEXT,
+			created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		)`,
 		// Users table
 		`CREATE TABLE IF NOT EXISTS users (
@@ -148,6 +160,9 @@ func (s *Storage) InitSchema() error {
 			links                    TEXT,
 			badges                   TEXT,
 			opportunities            TEXT,
+			denial_reason            TEXT,
+			denial_comment           TEXT,
+			referred_by              TEXT,
 			CHECK (verification_status IN ('pending', 'verified', 'denied', 'blocked', 'unverified'))
 		)`,
 		// User followers table
@@ -178,6 +193,12 @@ func (s *Storage) InitSchema() error {
 			verification_status TEXT      DEFAULT 'pending',
 			embedding_updated_at TIMESTAMP,
 			verified_at         TIMESTAMP,
+			denial_reason       TEXT,
+			denial_comment      TEXT,
+			status              TEXT      NOT NULL DEFAULT 'open',
+			expires_at          TIMESTAMP,
+			expiry_reminded_at  TIMESTAMP,
+			compensation        TEXT,
 			FOREIGN KEY (user_id) REFERENCES users (id),
 			CHECK (verification_status IN ('pending', 'verified', 'denied', 'blocked', 'unverified'))
 		)`,
@@ -188,10 +209,103 @@ func (s *Storage) InitSchema() error {
 			collaboration_id TEXT      NOT NULL,
 			expires_at       TIMESTAMP NOT NULL,
 			created_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			message          TEXT,
+			links            TEXT,
+			status           TEXT      NOT NULL DEFAULT 'pending',
+			updated_at       TIMESTAMP,
 			UNIQUE (user_id, collaboration_id),
 			FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
 			FOREIGN KEY (collaboration_id) REFERENCES collaborations (id) ON DELETE CASCADE
 		)`,
+		// Collaboration members table, the poster is an owner
+		`CREATE TABLE IF NOT EXISTS collaboration_members (
+			collaboration_id TEXT NOT NULL,
+			user_id          TEXT NOT NULL,
+			role             TEXT NOT NULL,
+			created_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (collaboration_id, user_id),
+			FOREIGN KEY (collaboration_id) REFERENCES collaborations (id) ON DELETE CASCADE,
+			FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+			CHECK (role IN ('owner', 'editor'))
+		)`,
+		// Team invitations, the user joins the team once they accept
+		`CREATE TABLE IF NOT EXISTS collaboration_invites (
+			collaboration_id TEXT NOT NULL,
+			user_id          TEXT NOT NULL,
+			role             TEXT NOT NULL,
+			created_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (collaboration_id, user_id),
+			FOREIGN KEY (collaboration_id) REFERENCES collaborations (id) ON DELETE CASCADE,
+			FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+			CHECK (role IN ('owner', 'editor'))
+		)`,
+		// Collaboration comments table, replies point to the first comment of the thread
+		`CREATE TABLE IF NOT EXISTS collaboration_comments (
+			id               TEXT PRIMARY KEY,
+			collaboration_id TEXT    NOT NULL,
+			parent_id        TEXT,
+			user_id          TEXT    NOT NULL,
+			body             TEXT    NOT NULL,
+			created_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			edited_at        TIMESTAMP,
+			deleted_at       TIMESTAMP,
+			pinned_at        TIMESTAMP,
+			hidden_at        TIMESTAMP,
+			report_count     INTEGER NOT NULL DEFAULT 0,
+			FOREIGN KEY (collaboration_id) REFERENCES collaborations (id) ON DELETE CASCADE,
+			FOREIGN KEY (parent_id) REFERENCES collaboration_comments (id) ON DELETE CASCADE,
+			FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+		)`,
+		// Comment reports table, one per reporter
+		`CREATE TABLE IF NOT EXISTS comment_reports (
+			comment_id TEXT NOT NULL,
+			user_id    TEXT NOT NULL,
+			reason     TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (comment_id, user_id),
+			FOREIGN KEY (comment_id) REFERENCES collaboration_comments (id) ON DELETE CASCADE,
+			FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+		)`,
+		// Saved collaborations table
+		`CREATE TABLE IF NOT EXISTS saved_collaborations (
+			user_id          TEXT NOT NULL,
+			collaboration_id TEXT NOT NULL,
+			created_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_id, collaboration_id),
+			FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+			FOREIGN KEY (collaboration_id) REFERENCES collaborations (id) ON DELETE CASCADE
+		)`,
+		// Saved searches table
+		`CREATE TABLE IF NOT EXISTS saved_searches (
+			id         TEXT PRIMARY KEY,
+			user_id    TEXT NOT NULL,
+			name       TEXT NOT NULL,
+			query      TEXT,
+			filters    TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+		)`,
+		// Analytics events, one per viewer, target and day until rolled up
+		`CREATE TABLE IF NOT EXISTS analytics_events (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			kind        TEXT NOT NULL,
+			target_type TEXT NOT NULL,
+			target_id   TEXT NOT NULL,
+			viewer_id   TEXT NOT NULL,
+			day         TEXT NOT NULL,
+			created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (kind, target_type, target_id, viewer_id, day)
+		)`,
+		// Analytics daily rollups
+		`CREATE TABLE IF NOT EXISTS analytics_daily (
+			target_type TEXT    NOT NULL,
+			target_id   TEXT    NOT NULL,
+			day         TEXT    NOT NULL,
+			views       INTEGER NOT NULL DEFAULT 0,
+			deep_links  INTEGER NOT NULL DEFAULT 0,
+			interests   INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (target_type, target_id, day)
+		)`,
 		// Admins table
 		`CREATE TABLE IF NOT EXISTS admins (
 			id            TEXT PRIMARY KEY,
@@ -201,6 +315,87 @@ func (s *Storage) InitSchema() error {
 			created_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		)`,
+		// Denial reasons catalogue
+		`CREATE TABLE IF NOT EXISTS denial_reasons (
+			id         TEXT PRIMARY KEY,
+			code       TEXT UNIQUE NOT NULL,
+			text_en    TEXT NOT NULL,
+			text_ru    TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		// Telegram updates already handled, to skip redeliveries
+		`CREATE TABLE IF NOT EXISTS telegram_updates (
+			bot          TEXT    NOT NULL,
+			update_id    INTEGER NOT NULL,
+			processed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (bot, update_id)
+		)`,
+		// Last update ID handled per bot in long polling mode
+		`CREATE TABLE IF NOT EXISTS telegram_offsets (
+			bot        TEXT PRIMARY KEY,
+			update_id  INTEGER NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		// Mini app sessions, one per refresh token chain
+		`CREATE TABLE IF NOT EXISTS sessions (
+			id                  TEXT PRIMARY KEY,
+			user_id             TEXT      NOT NULL,
+			token_hash          TEXT      NOT NULL UNIQUE,
+			previous_token_hash TEXT,
+			device              TEXT      NOT NULL DEFAULT '',
+			user_agent          TEXT      NOT NULL DEFAULT '',
+			ip                  TEXT      NOT NULL DEFAULT '',
+			country             TEXT      NOT NULL DEFAULT '',
+			city                TEXT      NOT NULL DEFAULT '',
+			created_at          TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			last_used_at        TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			expires_at          TIMESTAMP NOT NULL,
+			revoked_at          TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+		)`,
+		// Rate limit token buckets and daily quotas, see internal/ratelimit
+		`CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+			key        TEXT PRIMARY KEY,
+			tokens     REAL      NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS rate_limit_quotas (
+			key   TEXT    NOT NULL,
+			day   TEXT    NOT NULL,
+			count INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (key, day)
+		)`,
+		// Admin broadcasts and their per-recipient delivery results
+		`CREATE TABLE IF NOT EXISTS broadcasts (
+			id             TEXT PRIMARY KEY,
+			admin_id       TEXT    NOT NULL,
+			text_en        TEXT    NOT NULL,
+			text_ru        TEXT    NOT NULL DEFAULT '',
+			image_url      TEXT,
+			button_text_en TEXT,
+			button_text_ru TEXT,
+			button_path    TEXT,
+			segment        TEXT    NOT NULL,
+			status         TEXT    NOT NULL DEFAULT 'draft',
+			total          INTEGER NOT NULL DEFAULT 0,
+			sent           INTEGER NOT NULL DEFAULT 0,
+			failed         INTEGER NOT NULL DEFAULT 0,
+			created_at     TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			started_at     TIMESTAMP,
+			finished_at    TIMESTAMP,
+			CHECK (status IN ('draft', 'sending', 'completed', 'cancelled'))
+		)`,
+		`CREATE TABLE IF NOT EXISTS broadcast_recipients (
+			broadcast_id TEXT    NOT NULL,
+			user_id      TEXT    NOT NULL,
+			chat_id      INTEGER NOT NULL,
+			status       TEXT    NOT NULL DEFAULT 'pending',
+			error        TEXT,
+			sent_at      TIMESTAMP,
+			PRIMARY KEY (broadcast_id, user_id),
+			FOREIGN KEY (broadcast_id) REFERENCES broadcasts (id) ON DELETE CASCADE,
+			CHECK (status IN ('pending', 'sent', 'failed'))
+		)`,
 		// Cleanup log table
 		`CREATE TABLE IF NOT EXISTS cleanup_log (
 			id          INTEGER PRIMARY KEY,
@@ -217,6 +412,61 @@ func (s *Storage) InitSchema() error {
 		}
 	}
 
+	// Add columns introduced after the initial schema to existing databases
+	columns := []struct {
+		table      string
+		name       string
+		definition string
+	}{
+		{"users", "denial_reason", "TEXT"},
+		{"users", "denial_comment", "TEXT"},
+		{"collaborations", "denial_reason", "TEXT"},
+		{"collaborations", "denial_comment", "TEXT"},
+		{"users", "referred_by", "TEXT"},
+		{"cities", "population", "INTEGER NOT NULL DEFAULT 0"},
+		{"cities", "alternate_names", "TEXT"},
+		{"cities", "search_names", "TEXT"},
+		{"opportunities", "position", "INTEGER NOT NULL DEFAULT 0"},
+		{"opportunities", "archived_at", "TIMESTAMP"},
+		{"badges", "status", "TEXT NOT NULL DEFAULT 'approved'"},
+		{"badges", "created_by", "TEXT"},
+		{"badges", "normalized_text", "TEXT"},
+		{"collaborations", "status", "TEXT NOT NULL DEFAULT 'open'"},
+		{"collaborations", "expires_at", "TIMESTAMP"},
+		{"collaborations", "expiry_reminded_at", "TIMESTAMP"},
+		{"collaboration_interests", "message", "TEXT"},
+		{"collaboration_interests", "links", "TEXT"},
+		{"collaboration_interests", "status", "TEXT NOT NULL DEFAULT 'pending'"},
+		{"collaboration_interests", "updated_at", "TIMESTAMP"},
+		{"collaborations", "compensation", "TEXT"},
+	}
+
+	for _, col := range columns {
+		if err := addColumnIfNotExists(ctx, tx, col.table, col.name, col.definition); err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %w", col.table, col.name, err)
+		}
+	}
+
+	if err := backfillCollaborationCompensation(ctx, tx); err != nil {
+		return fmt.Errorf("failed to backfill collaboration compensation: %w", err)
+	}
+
+	if err := backfillCollaborationMembers(ctx, tx); err != nil {
+		return fmt.Errorf("failed to backfill collaboration members: %w", err)
+	}
+
+	if err := backfillBadgeNormalizedText(ctx, tx); err != nil {
+		return fmt.Errorf("failed to backfill badges: %w", err)
+	}
+
+	if err := backfillCitySearchNames(ctx, tx); err != nil {
+		return fmt.Errorf("failed to backfill cities: %w", err)
+	}
+
+	if err := initLocationIndexes(ctx, tx); err != nil {
+		return fmt.Errorf("failed to create location indexes: %w", err)
+	}
+
 	// Try to create vec0 virtual tables (might fail in tests)
 	vecStatements := []string{
 		`CREATE VIRTUAL TABLE IF NOT EXISTS user_embeddings USING vec0 (
@@ -245,8 +495,24 @@ func (s *Storage) InitSchema() error {
 		`CREATE INDEX IF NOT EXISTS idx_collaborations_user ON collaborations (user_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_collaborations_verification ON collaborations (verification_status, hidden_at)`,
 		`CREATE INDEX IF NOT EXISTS idx_collaborations_created ON collaborations (created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_collaborations_status_expires ON collaborations (status, expires_at)`,
 		`CREATE INDEX IF NOT EXISTS idx_user_followers_expires ON user_followers (expires_at)`,
 		`CREATE INDEX IF NOT EXISTS idx_collab_interests_expires ON collaboration_interests (expires_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_collab_interests_user ON collaboration_interests (user_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_collab_interests_collab ON collaboration_interests (collaboration_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_collaboration_members_user ON collaboration_members (user_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_collaboration_invites_user ON collaboration_invites (user_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_collab_comments_collab ON collaboration_comments (collaboration_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_collab_comments_parent ON collaboration_comments (parent_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_collab_comments_reported ON collaboration_comments (report_count) WHERE report_count > 0`,
+		`CREATE INDEX IF NOT EXISTS idx_saved_collaborations_user ON saved_collaborations (user_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_saved_searches_user ON saved_searches (user_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_analytics_events_target ON analytics_events (target_type, target_id, day)`,
+		`CREATE INDEX IF NOT EXISTS idx_analytics_events_day ON analytics_events (day)`,
+		`CREATE INDEX IF NOT EXISTS idx_cities_population ON cities (population DESC)`,
+		`CREATE INDEX IF NOT EXISTS idx_badges_normalized_text ON badges (normalized_text)`,
+		`CREATE INDEX IF NOT EXISTS idx_sessions_user ON sessions (user_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_sessions_previous_token ON sessions (previous_token_hash)`,
 		`CREATE UNIQUE INDEX IF NOT EXISTS idx_admins_api_token ON admins(api_token) WHERE api_token IS NOT NULL`,
 	}
 
@@ -259,6 +525,24 @@ func (s *Storage) InitSchema() error {
 	return tx.Commit()
 }
 
+// addColumnIfNotExists adds a column unless the table already has it,
+// since SQLite has no ADD COLUMN IF NOT EXISTS.
+func addColumnIfNotExists(ctx context.Context, tx *sql.Tx, table, column, definition string) error {
+	var exists bool
+	err := tx.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM pragma_table_info(?) WHERE name = ?)`,
+		table, column).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition))
+	return err
+}
+
 func (s *Storage) DB() *sql.DB {
 	return s.db
 }
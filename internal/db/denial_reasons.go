@@ -0,0 +1,159 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DenialReason is an admin-managed explanation attached to a denied user or
+// collaboration. A copy is stored on the entity so later catalogue edits
+// don't change what the user was told.
+type DenialReason struct {
+	ID        string    `json:"id"`
+	Code      string    `json:"code"`
+	Text      string    `json:"text"`
+	TextRU    string    `json:"text_ru"`
+	CreatedAt time.Time `json:"created_at"`
+} // @Name DenialReason
+
+// LocalizedText returns the reason text in the given language
+func (r DenialReason) LocalizedText(lang LanguageCode) string {
+	if lang == LanguageRU && r.TextRU != "" {
+		return r.TextRU
+	}
+	return r.Text
+}
+
+// ListDenialReasons lists the denial reasons catalogue
+func (s *Storage) ListDenialReasons(ctx context.Context) ([]DenialReason, error) {
+	query := `
+		SELECT id, code, text_en, text_ru, created_at
+		FROM denial_reasons
+		ORDER BY code ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find denial reasons: %w", err)
+	}
+	defer rows.Close()
+
+	var reasons []DenialReason
+	for rows.Next() {
+		var reason DenialReason
+		if err := rows.Scan(
+			&reason.ID,
+			&reason.Code,
+			&reason.Text,
+			&reason.TextRU,
+			&reason.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan denial reason: %w", err)
+		}
+		reasons = append(reasons, reason)
+	}
+
+	return reasons, rows.Err()
+}
+
+// GetDenialReasonByCode retrieves a denial reason by its code
+func (s *Storage) GetDenialReasonByCode(ctx context.Context, code string) (DenialReason, error) {
+	query := `
+		SELECT id, code, text_en, text_ru, created_at
+		FROM denial_reasons
+		WHERE code = ?
+	`
+
+	var reason DenialReason
+	err := s.db.QueryRowContext(ctx, query, code).Scan(
+		&reason.ID,
+		&reason.Code,
+		&reason.Text,
+		&reason.TextRU,
+		&reason.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return DenialReason{}, ErrNotFound
+		}
+		return DenialReason{}, err
+	}
+
+	return reason, nil
+}
+
+// CreateDenialReason adds a reason to the catalogue
+func (s *Storage) CreateDenialReason(ctx context.Context, reason DenialReason) error {
+	query := `
+		INSERT INTO denial_reasons (id, code, text_en, text_ru, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	_, err := s.db.ExecContext(ctx, query,
+		reason.ID,
+		reason.Code,
+		reason.Text,
+		reason.TextRU,
+		time.Now(),
+	)
+	if err != nil {
+		if isSQLiteConstraintError(err) {
+			return ErrAlreadyExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// DeleteDenialReason removes a reason from the catalogue. Entities that were
+// already denied keep their own copy of it.
+func (s *Storage) DeleteDenialReason(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM denial_reasons WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// UpdateUserDenialReason stores the reason a user's profile was denied
+func (s *Storage) UpdateUserDenialReason(ctx context.Context, userID string, reason *DenialReason, comment *string) error {
+	return s.updateDenialReason(ctx, "users", userID, reason, comment)
+}
+
+// UpdateCollaborationDenialReason stores the reason a collaboration was denied
+func (s *Storage) UpdateCollaborationDenialReason(ctx context.Context, collabID string, reason *DenialReason, comment *string) error {
+	return s.updateDenialReason(ctx, "collaborations", collabID, reason, comment)
+}
+
+func (s *Storage) updateDenialReason(ctx context.Context, table, id string, reason *DenialReason, comment *string) error {
+	var reasonJSON *[]byte
+	if reason != nil {
+		data, _ := json.Marshal(reason)
+		reasonJSON = &data
+	}
+
+	query := fmt.Sprintf(`UPDATE %s SET denial_reason = ?, denial_comment = ?, updated_at = ? WHERE id = ?`, table)
+
+	result, err := s.db.ExecContext(ctx, query, reasonJSON, comment, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
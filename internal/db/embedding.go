@@ -125,6 +125,7 @@ func (s *Storage) GetMatchingUsersForCollaboration(ctx context.Context, collabID
 			u.description, u.language_code, u.last_active_at,
 			u.verification_status, u.verified_at, u.embedding_updated_at,
 			u.login_metadata, u.location, u.links, u.badges, u.opportunities,
+			u.denial_reason, u.denial_comment,
 			vec_distance_L2(ue.embedding, ?) as distance
 		FROM user_embeddings ue
 		JOIN users u ON u.id = ue.user_id
@@ -172,6 +173,7 @@ func (s *Storage) FindSimilarUsers(ctx context.Context, embeddingVector []float6
 			u.description, u.language_code, u.last_active_at,
 			u.verification_status, u.verified_at, u.embedding_updated_at,
 			u.login_metadata, u.location, u.links, u.badges, u.opportunities,
+			u.denial_reason, u.denial_comment,
 			vec_distance_L2(ue.embedding, ?) as distance
 		FROM user_embeddings ue
 		JOIN users u ON u.id = ue.user_id
@@ -212,7 +214,7 @@ func vectorFloat64ToFloat32(vec []float64) []float32 {
 // scanUserWithDistance is similar to scanUser but includes distance from vector search
 func scanUserWithDistance(rows *sql.Rows) (*User, error) {
 	var user User
-	var loginMetaJSON, locationJSON, linksJSON, badgesJSON, oppsJSON sql.NullString
+	var loginMetaJSON, locationJSON, linksJSON, badgesJSON, oppsJSON, denialReasonJSON sql.NullString
 	var distance float64
 
 	err := rows.Scan(
@@ -222,6 +224,7 @@ func scanUserWithDistance(rows *sql.Rows) (*User, error) {
 		&user.LanguageCode, &user.LastActiveAt,
 		&user.VerificationStatus, &user.VerifiedAt, &user.EmbeddingUpdatedAt,
 		&loginMetaJSON, &locationJSON, &linksJSON, &badgesJSON, &oppsJSON,
+		&denialReasonJSON, &user.DenialComment,
 		&distance, // Additional field for vector search distance
 	)
 	if err != nil {
@@ -244,6 +247,67 @@ func scanUserWithDistance(rows *sql.Rows) (*User, error) {
 	if oppsJSON.Valid && oppsJSON.String != "" {
 		json.Unmarshal([]byte(oppsJSON.String), &user.Opportunities)
 	}
+	if denialReasonJSON.Valid && denialReasonJSON.String != "" {
+		json.Unmarshal([]byte(denialReasonJSON.String), &user.DenialReason)
+	}
 
 	return &user, nil
 }
+
+// DuplicateSimilarity is the cosine similarity from which two collaborations
+// are taken for the same post
+const DuplicateSimilarity = 0.95
+
+// SimilarCollaboration is an earlier collaboration close to a new one
+type SimilarCollaboration struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	Title      string    `json:"title"`
+	CreatedAt  time.Time `json:"created_at"`
+	Similarity float64   `json:"similarity"`
+}
+
+// FindSimilarCollaborations compares the stored embedding of a collaboration
+// with those of the author's other collaborations and of everyone's created
+// since, and returns the ones at least minSimilarity close, closest first.
+// Only open collaborations that are pending or verified and not hidden count.
+func (s *Storage) FindSimilarCollaborations(ctx context.Context, collabID string, since time.Time, minSimilarity float64, limit int) ([]SimilarCollaboration, error) {
+	if limit <= 0 {
+		limit = 5
+	}
+
+	// Embeddings are unit vectors, so one minus the cosine distance is the similarity
+	query := `
+		SELECT id, user_id, title, created_at, similarity FROM (
+			SELECT c.id, c.user_id, c.title, c.created_at,
+				1 - vec_distance_cosine(ce.embedding, src.embedding) AS similarity
+			FROM collaborations c
+			JOIN collaborations new ON new.id = ?1
+			JOIN collaboration_embeddings src ON src.collaboration_id = new.id
+			JOIN collaboration_embeddings ce ON ce.collaboration_id = c.id
+			WHERE c.id != new.id AND (c.user_id = new.user_id OR c.created_at >= ?2)
+				AND c.verification_status IN ('pending', 'verified')
+				AND c.hidden_at IS NULL AND c.status = 'open'
+		)
+		WHERE similarity >= ?3
+		ORDER BY similarity DESC
+		LIMIT ?4
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, collabID, since, minSimilarity, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find similar collaborations: %w", err)
+	}
+	defer rows.Close()
+
+	var similar []SimilarCollaboration
+	for rows.Next() {
+		var collab SimilarCollaboration
+		if err := rows.Scan(&collab.ID, &collab.UserID, &collab.Title, &collab.CreatedAt, &collab.Similarity); err != nil {
+			return nil, fmt.Errorf("failed to scan similar collaboration: %w", err)
+		}
+		similar = append(similar, collab)
+	}
+
+	return similar, rows.Err()
+}
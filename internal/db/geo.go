@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"math"
+)
+
+const earthRadiusKm = 6371.0
+
+// GeoFilter restricts a listing to locations within RadiusKm of a point
+type GeoFilter struct {
+	Latitude  float64
+	Longitude float64
+	RadiusKm  float64
+}
+
+// DistanceKm is the great-circle distance between two points, by the haversine formula
+func DistanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLon := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// DistanceTo is the distance from the filter's center to city, nil when
+// there is no filter or city
+func (f *GeoFilter) DistanceTo(city *City) *float64 {
+	if f == nil || city == nil {
+		return nil
+	}
+
+	// Rounded to 100 m, more precision would only help locating people
+	distance := math.Round(DistanceKm(f.Latitude, f.Longitude, city.Latitude, city.Longitude)*10) / 10
+	return &distance
+}
+
+// boundingBox is the latitude/longitude rectangle holding every point within
+// the radius, used to narrow candidates through the R*Tree before computing
+// exact distances
+func (f GeoFilter) boundingBox() (minLat, maxLat, minLon, maxLon float64) {
+	dLat := f.RadiusKm / earthRadiusKm * 180 / math.Pi
+
+	minLat = math.Max(f.Latitude-dLat, -90)
+	maxLat = math.Min(f.Latitude+dLat, 90)
+
+	// Near the poles, or across the antimeridian, fall back to every longitude
+	cosLat := math.Cos(f.Latitude * math.Pi / 180)
+	if maxLat >= 90 || minLat <= -90 || cosLat < 1e-6 {
+		return minLat, maxLat, -180, 180
+	}
+
+	dLon := dLat / cosLat
+	minLon, maxLon = f.Longitude-dLon, f.Longitude+dLon
+	if minLon < -180 || maxLon > 180 {
+		return minLat, maxLat, -180, 180
+	}
+
+	return minLat, maxLat, minLon, maxLon
+}
+
+// geoCondition filters rows of table, whose location column holds a City,
+// to those within the filter using the given R*Tree index
+func (f GeoFilter) geoCondition(table, index string) (string, []interface{}) {
+	minLat, maxLat, minLon, maxLon := f.boundingBox()
+
+	condition := table + `.rowid IN (
+			SELECT id FROM ` + index + `
+			WHERE min_lat <= ? AND max_lat >= ? AND min_lon <= ? AND max_lon >= ?
+		) AND ` + distanceExpr(table) + ` <= ?`
+
+	return condition, []interface{}{
+		maxLat, minLat, maxLon, minLon,
+		f.Latitude, f.Longitude,
+		f.RadiusKm,
+	}
+}
+
+// distanceExpr computes the distance from a point to a row's location,
+// taking the point's coordinates as its two arguments
+func distanceExpr(table string) string {
+	return `distance_km(?, ?, json_extract(` + table + `.location, '$.latitude'), json_extract(` + table + `.location, '$.longitude'))`
+}
+
+// sqlDistanceKm backs the distance_km SQL function. Missing coordinates
+// give NULL rather than an error.
+func sqlDistanceKm(lat1, lon1, lat2, lon2 any) any {
+	coords := make([]float64, 4)
+	for i, v := range []any{lat1, lon1, lat2, lon2} {
+		switch n := v.(type) {
+		case float64:
+			coords[i] = n
+		case int64:
+			coords[i] = float64(n)
+		default:
+			return nil
+		}
+	}
+
+	return DistanceKm(coords[0], coords[1], coords[2], coords[3])
+}
+
+// initLocationIndexes creates the R*Tree indexes over user and collaboration
+// locations, kept in sync by triggers, and indexes rows that predate them
+func initLocationIndexes(ctx context.Context, tx *sql.Tx) error {
+	for _, t := range []struct{ table, index string }{
+		{"users", "user_locations"},
+		{"collaborations", "collaboration_locations"},
+	} {
+		lat := `json_extract(NEW.location, '$.latitude')`
+		lon := `json_extract(NEW.location, '$.longitude')`
+
+		statements := []string{
+			`CREATE VIRTUAL TABLE IF NOT EXISTS ` + t.index + ` USING rtree (id, min_lat, max_lat, min_lon, max_lon)`,
+			`CREATE TRIGGER IF NOT EXISTS ` + t.table + `_location_insert AFTER INSERT ON ` + t.table + `
+			WHEN ` + lat + ` IS NOT NULL AND ` + lon + ` IS NOT NULL
+			BEGIN
+				INSERT OR REPLACE INTO ` + t.index + ` VALUES (NEW.rowid, ` + lat + `, ` + lat + `, ` + lon + `, ` + lon + `);
+			END`,
+			`CREATE TRIGGER IF NOT EXISTS ` + t.table + `_location_update AFTER UPDATE OF location ON ` + t.table + `
+			BEGIN
+				DELETE FROM ` + t.index + ` WHERE id = OLD.rowid;
+				INSERT INTO ` + t.index + `
+				SELECT NEW.rowid, ` + lat + `, ` + lat + `, ` + lon + `, ` + lon + `
+				WHERE ` + lat + ` IS NOT NULL AND ` + lon + ` IS NOT NULL;
+			END`,
+			`CREATE TRIGGER IF NOT EXISTS ` + t.table + `_location_delete AFTER DELETE ON ` + t.table + `
+			BEGIN
+				DELETE FROM ` + t.index + ` WHERE id = OLD.rowid;
+			END`,
+			`INSERT INTO ` + t.index + `
+			SELECT rowid,
+			       json_extract(location, '$.latitude'), json_extract(location, '$.latitude'),
+			       json_extract(location, '$.longitude'), json_extract(location, '$.longitude')
+			FROM ` + t.table + `
+			WHERE json_extract(location, '$.latitude') IS NOT NULL
+			  AND json_extract(location, '$.longitude') IS NOT NULL
+			  AND rowid NOT IN (SELECT id FROM ` + t.index + `)`,
+		}
+
+		for _, stmt := range statements {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -16,20 +17,28 @@ type Opportunity struct {
 	DescriptionRU      string     `json:"description_ru,omitempty"`
 	Icon               string     `json:"icon"`
 	Color              string     `json:"color"`
+	Position           int        `json:"position,omitempty"`
+	ArchivedAt         *time.Time `json:"archived_at,omitempty"`
 	CreatedAt          time.Time  `json:"created_at"`
 	Embedding          []float64  `json:"-"`
 	EmbeddingUpdatedAt *time.Time `json:"-"`
 } // @Name Opportunity
 
-// ListOpportunities lists all opportunities
-func (s *Storage) ListOpportunities(ctx context.Context) ([]Opportunity, error) {
+// ListOpportunities lists opportunities in display order. Archived ones are
+// left out of pickers and only listed with includeArchived.
+func (s *Storage) ListOpportunities(ctx context.Context, includeArchived bool) ([]Opportunity, error) {
 	query := `
 		SELECT id, text_en, text_ru, description_en, description_ru, 
-		       icon, color, created_at
+		       icon, color, position, archived_at, created_at
 		FROM opportunities
-		ORDER BY created_at DESC
 	`
 
+	if !includeArchived {
+		query += ` WHERE archived_at IS NULL`
+	}
+
+	query += ` ORDER BY position, created_at DESC`
+
 	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find opportunities: %w", err)
@@ -38,18 +47,7 @@ func (s *Storage) ListOpportunities(ctx context.Context) ([]Opportunity, error)
 
 	var opportunities []Opportunity
 	for rows.Next() {
-		var opp Opportunity
-
-		err := rows.Scan(
-			&opp.ID,
-			&opp.Text,
-			&opp.TextRU,
-			&opp.Description,
-			&opp.DescriptionRU,
-			&opp.Icon,
-			&opp.Color,
-			&opp.CreatedAt,
-		)
+		opp, err := scanOpportunity(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan opportunity: %w", err)
 		}
@@ -64,13 +62,61 @@ func (s *Storage) ListOpportunities(ctx context.Context) ([]Opportunity, error)
 	return opportunities, nil
 }
 
-// CreateOpportunity creates a new opportunity
+// GetOpportunityByID returns an opportunity, archived or not
+func (s *Storage) GetOpportunityByID(ctx context.Context, id string) (Opportunity, error) {
+	return getOpportunity(ctx, s.db, id)
+}
+
+func getOpportunity(ctx context.Context, q interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}, id string) (Opportunity, error) {
+	row := q.QueryRowContext(ctx, `
+		SELECT id, text_en, text_ru, description_en, description_ru,
+		       icon, color, position, archived_at, created_at
+		FROM opportunities
+		WHERE id = ?
+	`, id)
+
+	opp, err := scanOpportunity(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Opportunity{}, ErrNotFound
+	} else if err != nil {
+		return Opportunity{}, fmt.Errorf("failed to get opportunity: %w", err)
+	}
+
+	return opp, nil
+}
+
+func scanOpportunity(scanner interface{ Scan(...any) error }) (Opportunity, error) {
+	var opp Opportunity
+	var descEN, descRU sql.NullString
+
+	err := scanner.Scan(
+		&opp.ID,
+		&opp.Text,
+		&opp.TextRU,
+		&descEN,
+		&descRU,
+		&opp.Icon,
+		&opp.Color,
+		&opp.Position,
+		&opp.ArchivedAt,
+		&opp.CreatedAt,
+	)
+
+	opp.Description = descEN.String
+	opp.DescriptionRU = descRU.String
+
+	return opp, err
+}
+
+// CreateOpportunity creates a new opportunity at the end of the list
 func (s *Storage) CreateOpportunity(ctx context.Context, opp Opportunity) error {
 	query := `
 		INSERT INTO opportunities (
 			id, text_en, text_ru, description_en, description_ru, 
-			icon, color, created_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			icon, color, position, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, (SELECT COALESCE(MAX(position), 0) + 1 FROM opportunities), ?)
 	`
 
 	_, err := s.db.ExecContext(ctx, query,
@@ -94,18 +140,176 @@ func (s *Storage) CreateOpportunity(ctx context.Context, opp Opportunity) error
 	return nil
 }
 
-func (s *Storage) fetchOpportunityTx(ctx context.Context, tx *sql.Tx, id string) (*Opportunity, error) {
+// UpdateOpportunity updates an opportunity's texts and look, along with the
+// copies stored on users and collaborations
+func (s *Storage) UpdateOpportunity(ctx context.Context, opp Opportunity) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE opportunities SET
+			text_en = ?, text_ru = ?, description_en = ?, description_ru = ?,
+			icon = ?, color = ?
+		WHERE id = ?
+	`, opp.Text, opp.TextRU, opp.Description, opp.DescriptionRU, opp.Icon, opp.Color, opp.ID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return ErrNotFound
+	}
+
+	// Archived opportunities are still on profiles, so their copies are refreshed too
+	updated, err := getOpportunity(ctx, tx, opp.ID)
+	if err != nil {
+		return err
+	}
+
+	// Copies don't keep the list order or archive state
+	updated.Position = 0
+	updated.ArchivedAt = nil
+
+	if err := refreshOpportunitySnapshotsTx(ctx, tx, updated); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// refreshOpportunitySnapshotsTx rewrites the denormalised copies of opp
+func refreshOpportunitySnapshotsTx(ctx context.Context, tx *sql.Tx, opp Opportunity) error {
+	oppJSON, _ := json.Marshal(opp)
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE collaborations SET opportunity = ?
+		WHERE json_extract(opportunity, '$.id') = ?
+	`, oppJSON, opp.ID); err != nil {
+		return fmt.Errorf("failed to update collaborations: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT u.id, u.opportunities FROM users u
+		WHERE EXISTS (SELECT 1 FROM json_each(u.opportunities) WHERE json_extract(value, '$.id') = ?)
+	`, opp.ID)
+	if err != nil {
+		return err
+	}
+
+	snapshots := make(map[string][]Opportunity)
+	for rows.Next() {
+		var id string
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			rows.Close()
+			return err
+		}
+
+		var opps []Opportunity
+		if err := json.Unmarshal(data, &opps); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to decode opportunities of %s: %w", id, err)
+		}
+		snapshots[id] = opps
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, opps := range snapshots {
+		for i := range opps {
+			if opps[i].ID == opp.ID {
+				opps[i] = opp
+			}
+		}
+
+		data, _ := json.Marshal(opps)
+		if _, err := tx.ExecContext(ctx, `UPDATE users SET opportunities = ? WHERE id = ?`, data, id); err != nil {
+			return fmt.Errorf("failed to update users: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// SetOpportunityArchived hides an opportunity from pickers, or brings it back.
+// Profiles and collaborations that already have it keep rendering it.
+func (s *Storage) SetOpportunityArchived(ctx context.Context, id string, archived bool) error {
+	var archivedAt *time.Time
+	if archived {
+		now := time.Now()
+		archivedAt = &now
+	}
+
+	res, err := s.db.ExecContext(ctx, `UPDATE opportunities SET archived_at = ? WHERE id = ?`, archivedAt, id)
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ReorderOpportunities puts the given opportunities first, in that order,
+// followed by the rest in their current order
+func (s *Storage) ReorderOpportunities(ctx context.Context, ids []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	var count int
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM opportunities WHERE id IN (%s)`, placeholders(len(ids)))
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return err
+	}
+	if count != len(ids) {
+		return ErrNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE opportunities SET position = position + ?`, len(ids)); err != nil {
+		return err
+	}
+
+	for i, id := range ids {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE opportunities SET position = ? WHERE id = ?`, i, id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// fetchOpportunityTx loads an active opportunity, or an archived one that
+// collaborationID already has
+func (s *Storage) fetchOpportunityTx(ctx context.Context, tx *sql.Tx, id, collaborationID string) (*Opportunity, error) {
 	query := `
 		SELECT id, text_en, text_ru, description_en, description_ru, 
 		       icon, color, created_at
 		FROM opportunities
 		WHERE id = ?
+		  AND (archived_at IS NULL OR id = (
+		      SELECT json_extract(opportunity, '$.id') FROM collaborations WHERE id = ?))
 	`
 
 	var opp Opportunity
 	var descEN, descRU sql.NullString
 
-	err := tx.QueryRowContext(ctx, query, id).Scan(
+	err := tx.QueryRowContext(ctx, query, id, collaborationID).Scan(
 		&opp.ID,
 		&opp.Text,
 		&opp.TextRU,
@@ -133,12 +337,16 @@ func (s *Storage) fetchOpportunityTx(ctx context.Context, tx *sql.Tx, id string)
 	return &opp, nil
 }
 
-func (s *Storage) fetchOpportunitiesTx(ctx context.Context, tx *sql.Tx, ids []string) ([]Opportunity, error) {
+// fetchOpportunitiesTx loads active opportunities, plus archived ones userID
+// already has so saving a profile doesn't drop them
+func (s *Storage) fetchOpportunitiesTx(ctx context.Context, tx *sql.Tx, ids []string, userID string) ([]Opportunity, error) {
 	queryTemplate := `
 		SELECT id, text_en, text_ru, description_en, description_ru,
 		       icon, color, created_at
 		FROM opportunities
 		WHERE id IN (%s)
+		  AND (archived_at IS NULL OR id IN (
+		      SELECT json_extract(value, '$.id') FROM users, json_each(users.opportunities) WHERE users.id = ?))
 	`
 
 	return fetchItemsByID(ctx, tx, queryTemplate, ids, func(rows *sql.Rows) (Opportunity, error) {
@@ -154,5 +362,5 @@ func (s *Storage) fetchOpportunitiesTx(ctx context.Context, tx *sql.Tx, ids []st
 			&op.CreatedAt,
 		)
 		return op, err
-	})
+	}, userID)
 }
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/peatch-io/peatch/internal/ratelimit"
+)
+
+// RateLimitRetention is how long idle rate limit buckets are kept
+const RateLimitRetention = 24 * time.Hour
+
+// TakeToken implements ratelimit.Store so limits survive restarts. The
+// write lock is taken before the bucket is read, so concurrent requests
+// can't both spend the same token.
+func (s *Storage) TakeToken(ctx context.Context, key string, perSecond float64, burst int, now time.Time) (time.Duration, error) {
+	// database/sql only starts deferred transactions
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `BEGIN IMMEDIATE`); err != nil {
+		return 0, err
+	}
+
+	wait, err := takeToken(ctx, conn, key, perSecond, burst, now)
+	if err != nil {
+		conn.ExecContext(context.WithoutCancel(ctx), `ROLLBACK`)
+		return 0, err
+	}
+
+	if _, err := conn.ExecContext(ctx, `COMMIT`); err != nil {
+		conn.ExecContext(context.WithoutCancel(ctx), `ROLLBACK`)
+		return 0, err
+	}
+
+	return wait, nil
+}
+
+func takeToken(ctx context.Context, conn *sql.Conn, key string, perSecond float64, burst int, now time.Time) (time.Duration, error) {
+	tokens := float64(burst)
+	updatedAt := now
+
+	err := conn.QueryRowContext(ctx,
+		`SELECT tokens, updated_at FROM rate_limit_buckets WHERE key = ?`, key).Scan(&tokens, &updatedAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	tokens, wait := ratelimit.Take(tokens, updatedAt, now, perSecond, burst)
+
+	if _, err := conn.ExecContext(ctx, `
+		INSERT INTO rate_limit_buckets (key, tokens, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET tokens = excluded.tokens, updated_at = excluded.updated_at
+	`, key, tokens, now); err != nil {
+		return 0, err
+	}
+
+	return wait, nil
+}
+
+// IncrementQuota implements ratelimit.Store
+func (s *Storage) IncrementQuota(ctx context.Context, key, day string, limit int) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO rate_limit_quotas (key, day, count) VALUES (?, ?, 1)
+		ON CONFLICT (key, day) DO UPDATE SET count = count + 1 WHERE count < ?
+	`, key, day, limit)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// ReturnToken implements ratelimit.Store
+func (s *Storage) ReturnToken(ctx context.Context, key string, burst int) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE rate_limit_buckets SET tokens = MIN(tokens + 1, ?) WHERE key = ?`, burst, key)
+
+	return err
+}
+
+// DecrementQuota implements ratelimit.Store
+func (s *Storage) DecrementQuota(ctx context.Context, key, day string) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE rate_limit_quotas SET count = count - 1 WHERE key = ? AND day = ? AND count > 0`, key, day)
+
+	return err
+}
+
+// PruneRateLimits deletes idle buckets, which are full again long before the
+// retention period, and quotas of past days
+func (s *Storage) PruneRateLimits(ctx context.Context, now time.Time) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`DELETE FROM rate_limit_buckets WHERE updated_at < ?`, now.Add(-RateLimitRetention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune rate_limit_buckets: %w", err)
+	}
+	buckets, _ := result.RowsAffected()
+
+	result, err = tx.ExecContext(ctx,
+		`DELETE FROM rate_limit_quotas WHERE day < ?`, now.UTC().Add(-24*time.Hour).Format(time.DateOnly))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune rate_limit_quotas: %w", err)
+	}
+	quotas, _ := result.RowsAffected()
+
+	return buckets + quotas, tx.Commit()
+}
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SavedCollaboration is a collaboration a user bookmarked. Bookmarks are kept
+// when the collaboration is closed or hidden so the list can say so.
+type SavedCollaboration struct {
+	Collaboration Collaboration `json:"collaboration"`
+	SavedAt       time.Time     `json:"saved_at"`
+	Hidden        bool          `json:"hidden"` // Hidden or no longer verified, only the owner can open it
+} // @Name SavedCollaboration
+
+// SaveCollaboration bookmarks a collaboration the user can see. Saving twice is a no-op.
+func (s *Storage) SaveCollaboration(ctx context.Context, userID, collabID string) error {
+	var visible bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM collaborations
+			WHERE id = ? AND (user_id = ? OR (verification_status = 'verified' AND hidden_at IS NULL))
+		)
+	`, collabID, userID).Scan(&visible)
+	if err != nil {
+		return err
+	}
+	if !visible {
+		return ErrNotFound
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO saved_collaborations (user_id, collaboration_id, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (user_id, collaboration_id) DO NOTHING
+	`, userID, collabID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save collaboration: %w", err)
+	}
+
+	return nil
+}
+
+// UnsaveCollaboration removes a bookmark. Removing a missing one is a no-op.
+func (s *Storage) UnsaveCollaboration(ctx context.Context, userID, collabID string) error {
+	_, err := s.db.ExecContext(ctx,
+		`DELETE FROM saved_collaborations WHERE user_id = ? AND collaboration_id = ?`,
+		userID, collabID)
+	if err != nil {
+		return fmt.Errorf("failed to unsave collaboration: %w", err)
+	}
+
+	return nil
+}
+
+// ListSavedCollaborations lists the user's bookmarks, most recently saved first
+func (s *Storage) ListSavedCollaborations(ctx context.Context, userID string, page, perPage int) ([]SavedCollaboration, error) {
+	query := `
+		SELECT 
+			c.id, c.user_id, c.title, c.description, c.is_payable,
+			c.created_at, c.updated_at, c.hidden_at,
+			c.location, c.links, c.badges, c.opportunity,
+			c.verification_status, c.verified_at,
+			c.denial_reason, c.denial_comment,
+			c.status, c.expires_at, c.compensation,
+			u.id, u.name, u.username, u.avatar_url, u.title,
+			u.verification_status, u.verified_at, u.language_code,
+			sc.created_at
+		FROM saved_collaborations sc
+		JOIN collaborations c ON c.id = sc.collaboration_id
+		LEFT JOIN users u ON c.user_id = u.id
+		WHERE sc.user_id = ?
+		ORDER BY sc.created_at DESC
+	`
+	args := []interface{}{userID}
+
+	if page > 0 && perPage > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, perPage, (page-1)*perPage)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	saved := []SavedCollaboration{}
+	for rows.Next() {
+		var savedAt time.Time
+		collab, err := scanCollaboration(extraColumns{rows, []interface{}{&savedAt}})
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		collab.IsSaved = true
+
+		hidden := collab.UserID != userID &&
+			(collab.HiddenAt != nil || collab.VerificationStatus != VerificationStatusVerified)
+
+		saved = append(saved, SavedCollaboration{Collaboration: collab, SavedAt: savedAt, Hidden: hidden})
+	}
+
+	return saved, rows.Err()
+}
+
+// savedCollaborationIDs returns which of the given collaborations the user saved
+func (s *Storage) savedCollaborationIDs(ctx context.Context, userID string, collabIDs []string) (map[string]bool, error) {
+	saved := make(map[string]bool)
+	if userID == "" || len(collabIDs) == 0 {
+		return saved, nil
+	}
+
+	args := []interface{}{userID}
+	for _, id := range collabIDs {
+		args = append(args, id)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT collaboration_id FROM saved_collaborations
+		WHERE user_id = ? AND collaboration_id IN (`+placeholders(len(collabIDs))+`)
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved collaborations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		saved[id] = true
+	}
+
+	return saved, rows.Err()
+}
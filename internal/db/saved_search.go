@@ -0,0 +1,226 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	nanoid "github.com/matoous/go-nanoid/v2"
+)
+
+// MaxSavedSearches is how many searches a user can keep alerts for
+const MaxSavedSearches = 10
+
+// ErrSavedSearchLimit is returned when the user already has MaxSavedSearches
+var ErrSavedSearchLimit = errors.New("saved search limit reached")
+
+// SavedSearchFilters are the feed filters a saved search keeps, see CollaborationQuery
+type SavedSearchFilters struct {
+	OpportunityIDs    []string           `json:"opportunity_ids,omitempty"`
+	BadgeIDs          []string           `json:"badge_ids,omitempty"`
+	MatchAllBadges    bool               `json:"match_all_badges,omitempty"`
+	CountryCode       string             `json:"country_code,omitempty"`
+	CityID            string             `json:"city_id,omitempty"`
+	PayableOnly       bool               `json:"payable_only,omitempty"`
+	CompensationTypes []CompensationType `json:"compensation_types,omitempty"` // Compensated in any of these ways
+} // @Name SavedSearchFilters
+
+// SavedSearch is a feed search the user gets alerts for when a new
+// collaboration matching it is verified
+type SavedSearch struct {
+	ID        string             `json:"id"`
+	UserID    string             `json:"user_id"`
+	Name      string             `json:"name"`
+	Query     string             `json:"query"`
+	Filters   SavedSearchFilters `json:"filters"`
+	CreatedAt time.Time          `json:"created_at"`
+} // @Name SavedSearch
+
+// SavedSearchMatch is a user to alert about a collaboration, with the first
+// of their searches it matched
+type SavedSearchMatch struct {
+	Search SavedSearch
+	User   User
+}
+
+func (s SavedSearch) collaborationQuery() CollaborationQuery {
+	return CollaborationQuery{
+		Search:            s.Query,
+		OpportunityIDs:    s.Filters.OpportunityIDs,
+		BadgeIDs:          s.Filters.BadgeIDs,
+		MatchAllBadges:    s.Filters.MatchAllBadges,
+		CountryCode:       s.Filters.CountryCode,
+		CityID:            s.Filters.CityID,
+		PayableOnly:       s.Filters.PayableOnly,
+		CompensationTypes: s.Filters.CompensationTypes,
+	}
+}
+
+// CreateSavedSearch saves a search for the user, up to MaxSavedSearches
+func (s *Storage) CreateSavedSearch(ctx context.Context, search SavedSearch) (SavedSearch, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return SavedSearch{}, err
+	}
+	defer tx.Rollback()
+
+	var count int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM saved_searches WHERE user_id = ?`, search.UserID).Scan(&count)
+	if err != nil {
+		return SavedSearch{}, err
+	}
+	if count >= MaxSavedSearches {
+		return SavedSearch{}, ErrSavedSearchLimit
+	}
+
+	filtersJSON, _ := json.Marshal(search.Filters)
+
+	search.ID = nanoid.Must()
+	search.CreatedAt = time.Now()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO saved_searches (id, user_id, name, query, filters, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, search.ID, search.UserID, search.Name, search.Query, string(filtersJSON), search.CreatedAt)
+	if err != nil {
+		return SavedSearch{}, fmt.Errorf("failed to create saved search: %w", err)
+	}
+
+	return search, tx.Commit()
+}
+
+// ListSavedSearches lists the user's saved searches, oldest first
+func (s *Storage) ListSavedSearches(ctx context.Context, userID string) ([]SavedSearch, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id, name, COALESCE(query, ''), filters, created_at
+		FROM saved_searches
+		WHERE user_id = ?
+		ORDER BY created_at
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	searches := []SavedSearch{}
+	for rows.Next() {
+		var search SavedSearch
+		var filtersJSON sql.NullString
+
+		if err := rows.Scan(&search.ID, &search.UserID, &search.Name, &search.Query, &filtersJSON, &search.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		if filtersJSON.Valid && filtersJSON.String != "" {
+			json.Unmarshal([]byte(filtersJSON.String), &search.Filters)
+		}
+
+		searches = append(searches, search)
+	}
+
+	return searches, rows.Err()
+}
+
+// DeleteSavedSearch deletes one of the user's saved searches
+func (s *Storage) DeleteSavedSearch(ctx context.Context, userID, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM saved_searches WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete saved search: %w", err)
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// MatchSavedSearches finds the verified users with a saved search the
+// collaboration matches. Every user comes up once, the owner never does.
+// Searches are narrowed down by their simple filters in one query, the ones
+// left go through the feed filter. A search that fails to match is logged and
+// skipped, so it can't hold up the alerts of others.
+func (s *Storage) MatchSavedSearches(ctx context.Context, collabID string) ([]SavedSearchMatch, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		WITH searches AS (
+			SELECT id, user_id, name, COALESCE(query, '') AS query, filters, created_at,
+				CASE WHEN json_valid(filters) THEN filters ELSE '{}' END AS f
+			FROM saved_searches
+		)
+		SELECT ss.id, ss.user_id, ss.name, ss.query, ss.filters, ss.created_at,
+			u.id, u.chat_id, u.name, u.username, u.language_code
+		FROM searches ss
+		JOIN users u ON u.id = ss.user_id
+		JOIN collaborations c ON c.id = ?
+		WHERE u.hidden_at IS NULL
+		AND u.verification_status = 'verified'
+		AND u.id != c.user_id
+		AND (ss.query = '' OR c.title LIKE '%' || ss.query || '%' OR c.description LIKE '%' || ss.query || '%')
+		AND (json_extract(ss.f, '$.opportunity_ids') IS NULL
+			OR EXISTS (SELECT 1 FROM json_each(ss.f, '$.opportunity_ids') WHERE value = json_extract(c.opportunity, '$.id')))
+		AND (json_extract(ss.f, '$.country_code') IS NULL
+			OR upper(json_extract(ss.f, '$.country_code')) = json_extract(c.location, '$.country_code'))
+		AND (json_extract(ss.f, '$.city_id') IS NULL OR json_extract(ss.f, '$.city_id') = json_extract(c.location, '$.id'))
+		AND (COALESCE(json_extract(ss.f, '$.payable_only'), 0) = 0 OR c.is_payable = 1)
+		AND (json_extract(ss.f, '$.compensation_types') IS NULL
+			OR EXISTS (SELECT 1 FROM json_each(ss.f, '$.compensation_types') WHERE value = json_extract(c.compensation, '$.type')))
+		ORDER BY ss.created_at
+	`, collabID)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	var candidates []SavedSearchMatch
+	for rows.Next() {
+		var match SavedSearchMatch
+		var filtersJSON sql.NullString
+
+		err := rows.Scan(
+			&match.Search.ID, &match.Search.UserID, &match.Search.Name, &match.Search.Query, &filtersJSON, &match.Search.CreatedAt,
+			&match.User.ID, &match.User.ChatID, &match.User.Name, &match.User.Username, &match.User.LanguageCode,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		if filtersJSON.Valid && filtersJSON.String != "" {
+			json.Unmarshal([]byte(filtersJSON.String), &match.Search.Filters)
+		}
+
+		candidates = append(candidates, match)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// The rest of the filters, badges and status, are checked like in the feed
+	var matches []SavedSearchMatch
+	matched := make(map[string]bool)
+	for _, candidate := range candidates {
+		if matched[candidate.User.ID] {
+			continue
+		}
+
+		where, args := collaborationFilter(candidate.Search.collaborationQuery())
+
+		var ok bool
+		err := s.db.QueryRowContext(ctx,
+			`SELECT EXISTS(SELECT 1 FROM collaborations c WHERE c.id = ? AND `+where+`)`,
+			append([]interface{}{collabID}, args...)...).Scan(&ok)
+		if err != nil {
+			log.Printf("failed to match saved search %s: %v", candidate.Search.ID, err)
+			continue
+		}
+
+		if ok {
+			matched[candidate.User.ID] = true
+			matches = append(matches, candidate)
+		}
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,227 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrSessionRevoked is returned when a refresh token belongs to a revoked or
+// expired session, or was already rotated away
+var ErrSessionRevoked = errors.New("session revoked")
+
+// SessionRetention is how long revoked and expired sessions are kept
+const SessionRetention = 7 * 24 * time.Hour
+
+type Session struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Device     string     `json:"device"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	Country    string     `json:"country"`
+	City       string     `json:"city"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+} // @Name Session
+
+// CreateSession stores a new session for a hashed refresh token
+func (s *Storage) CreateSession(ctx context.Context, session Session, tokenHash string) error {
+	now := time.Now()
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO sessions (id, user_id, token_hash, device, user_agent, ip, country, city,
+		                      created_at, last_used_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		session.ID,
+		session.UserID,
+		tokenHash,
+		session.Device,
+		session.UserAgent,
+		session.IP,
+		session.Country,
+		session.City,
+		now,
+		now,
+		session.ExpiresAt,
+	)
+
+	return err
+}
+
+const sessionColumns = `
+	id, user_id, device, user_agent, ip, country, city, created_at, last_used_at, expires_at, revoked_at
+`
+
+func scanSession(scanner interface{ Scan(...any) error }) (Session, error) {
+	var session Session
+	err := scanner.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.Device,
+		&session.UserAgent,
+		&session.IP,
+		&session.Country,
+		&session.City,
+		&session.CreatedAt,
+		&session.LastUsedAt,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+	)
+
+	return session, err
+}
+
+// RotateSession swaps a session's refresh token hash for a new one and
+// extends it. Presenting a token that was already rotated away revokes the
+// session, since it means the token leaked.
+func (s *Storage) RotateSession(ctx context.Context, tokenHash, newTokenHash string, expiresAt time.Time) (Session, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Session{}, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+
+	session, err := scanSession(tx.QueryRowContext(ctx,
+		`SELECT `+sessionColumns+` FROM sessions WHERE token_hash = ?`, tokenHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		result, err := tx.ExecContext(ctx,
+			`UPDATE sessions SET revoked_at = ? WHERE previous_token_hash = ? AND revoked_at IS NULL`,
+			now, tokenHash)
+		if err != nil {
+			return Session{}, err
+		}
+
+		if rowsAffected, _ := result.RowsAffected(); rowsAffected > 0 {
+			if err := tx.Commit(); err != nil {
+				return Session{}, err
+			}
+			return Session{}, ErrSessionRevoked
+		}
+
+		return Session{}, ErrNotFound
+	} else if err != nil {
+		return Session{}, err
+	}
+
+	if session.RevokedAt != nil || session.ExpiresAt.Before(now) {
+		return Session{}, ErrSessionRevoked
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE sessions
+		SET token_hash = ?, previous_token_hash = token_hash, last_used_at = ?, expires_at = ?
+		WHERE id = ?
+	`, newTokenHash, now, expiresAt, session.ID); err != nil {
+		return Session{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Session{}, err
+	}
+
+	session.LastUsedAt = now
+	session.ExpiresAt = expiresAt
+
+	return session, nil
+}
+
+// RevokeSessionByToken revokes the session a refresh token belongs to and
+// returns its ID
+func (s *Storage) RevokeSessionByToken(ctx context.Context, tokenHash string) (string, error) {
+	var sessionID string
+	err := s.db.QueryRowContext(ctx,
+		`UPDATE sessions SET revoked_at = ? WHERE token_hash = ? AND revoked_at IS NULL RETURNING id`,
+		time.Now(), tokenHash).Scan(&sessionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+
+	return sessionID, err
+}
+
+// IsSessionActive reports whether a session is neither revoked nor expired.
+// Sessions cleaned up after their retention period are not found.
+func (s *Storage) IsSessionActive(ctx context.Context, sessionID string) (bool, error) {
+	var active bool
+	err := s.db.QueryRowContext(ctx,
+		`SELECT revoked_at IS NULL AND expires_at > ? FROM sessions WHERE id = ?`,
+		time.Now(), sessionID).Scan(&active)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, ErrNotFound
+	}
+
+	return active, err
+}
+
+// ListUserSessions lists a user's active sessions, most recently used first
+func (s *Storage) ListUserSessions(ctx context.Context, userID string) ([]Session, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+sessionColumns+` FROM sessions
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?
+		ORDER BY last_used_at DESC
+	`, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// RevokeUserSession revokes one of a user's sessions
+func (s *Storage) RevokeUserSession(ctx context.Context, userID, sessionID string) error {
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE sessions SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL`,
+		time.Now(), sessionID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// RevokeUserSessions revokes all of a user's sessions except keepSessionID,
+// which may be empty. It returns the number of sessions revoked.
+func (s *Storage) RevokeUserSessions(ctx context.Context, userID, keepSessionID string) (int64, error) {
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE sessions SET revoked_at = ? WHERE user_id = ? AND id != ? AND revoked_at IS NULL`,
+		time.Now(), userID, keepSessionID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// PruneSessions deletes sessions revoked or expired before the retention
+// period. Until then reused refresh tokens are still recognised.
+func (s *Storage) PruneSessions(ctx context.Context, now time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx,
+		`DELETE FROM sessions WHERE COALESCE(revoked_at, expires_at) < ?`, now.Add(-SessionRetention))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
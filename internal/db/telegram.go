@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// TelegramUpdateRetention is how long processed update IDs are remembered
+const TelegramUpdateRetention = 24 * time.Hour
+
+// IsUpdateProcessed reports whether a bot already handled a Telegram update
+func (s *Storage) IsUpdateProcessed(ctx context.Context, bot string, updateID int64) (bool, error) {
+	var exists bool
+	err := s.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM telegram_updates WHERE bot = ? AND update_id = ?)`,
+		bot, updateID).Scan(&exists)
+
+	return exists, err
+}
+
+// MarkUpdateProcessed records a Telegram update ID for a bot. It returns
+// false if the update was already seen. Update IDs are only unique per bot.
+func (s *Storage) MarkUpdateProcessed(ctx context.Context, bot string, updateID int64) (bool, error) {
+	result, err := s.db.ExecContext(ctx,
+		`INSERT OR IGNORE INTO telegram_updates (bot, update_id, processed_at) VALUES (?, ?, ?)`,
+		bot, updateID, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// PruneTelegramUpdates forgets updates processed before the retention
+// period, Telegram stops redelivering them after a day
+func (s *Storage) PruneTelegramUpdates(ctx context.Context, now time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx,
+		`DELETE FROM telegram_updates WHERE processed_at < ?`, now.Add(-TelegramUpdateRetention))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// GetLastUpdateID returns the last update ID polled by a bot, 0 if none
+func (s *Storage) GetLastUpdateID(ctx context.Context, bot string) (int64, error) {
+	var updateID int64
+	err := s.db.QueryRowContext(ctx,
+		`SELECT update_id FROM telegram_offsets WHERE bot = ?`, bot).Scan(&updateID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	return updateID, err
+}
+
+// SaveLastUpdateID stores the last update ID polled by a bot
+func (s *Storage) SaveLastUpdateID(ctx context.Context, bot string, updateID int64) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO telegram_offsets (bot, update_id, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT (bot) DO UPDATE SET update_id = excluded.update_id, updated_at = excluded.updated_at
+	`, bot, updateID, time.Now())
+
+	return err
+}
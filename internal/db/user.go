@@ -85,6 +85,9 @@ type User struct {
 	VerificationStatus     VerificationStatus `json:"verification_status"`
 	VerifiedAt             *time.Time         `json:"verified_at"`
 	EmbeddingUpdatedAt     *time.Time         `json:"-"`
+	DenialReason           *DenialReason      `json:"denial_reason"`
+	DenialComment          *string            `json:"denial_comment"`
+	DistanceKm             *float64           `json:"distance_km,omitempty"` // Set when listing near a city
 } // @Name User
 
 func (u *User) ToString() string {
@@ -175,10 +178,12 @@ func (u *User) IsProfileComplete() bool {
 }
 
 type ListUsersOptions struct {
-	SearchQuery string
-	Offset      int
-	Limit       int
-	UserID      string // Optional viewer ID
+	SearchQuery    string
+	Offset         int
+	Limit          int
+	UserID         string     // Optional viewer ID
+	Near           *GeoFilter // Only users located within the radius
+	SortByDistance bool       // Nearest first, requires Near
 }
 
 // ListUsers lists users with pagination and search
@@ -188,7 +193,8 @@ func (s *Storage) ListUsers(ctx context.Context, params ListUsersOptions) ([]Use
 		       notifications_enabled_at, hidden_at, avatar_url, title, 
 		       description, language_code, last_active_at,
 		       verification_status, verified_at, embedding_updated_at,
-		       login_metadata, location, links, badges, opportunities
+		       login_metadata, location, links, badges, opportunities,
+		       denial_reason, denial_comment
 		FROM users
 		WHERE verification_status = 'verified' AND hidden_at IS NULL AND id != ?
 	`
@@ -202,8 +208,20 @@ func (s *Storage) ListUsers(ctx context.Context, params ListUsersOptions) ([]Use
 		args = append(args, searchPattern, searchPattern, searchPattern, searchPattern)
 	}
 
+	if params.Near != nil {
+		condition, geoArgs := params.Near.geoCondition("users", "user_locations")
+		query += ` AND ` + condition
+		args = append(args, geoArgs...)
+	}
+
 	// Add ordering and pagination
-	query += ` ORDER BY created_at DESC LIMIT ? OFFSET ?`
+	if params.Near != nil && params.SortByDistance {
+		query += ` ORDER BY ` + distanceExpr("users") + ` ASC, created_at DESC`
+		args = append(args, params.Near.Latitude, params.Near.Longitude)
+	} else {
+		query += ` ORDER BY created_at DESC`
+	}
+	query += ` LIMIT ? OFFSET ?`
 	args = append(args, params.Limit, params.Offset)
 
 	rows, err := s.db.QueryContext(ctx, query, args...)
@@ -218,6 +236,7 @@ func (s *Storage) ListUsers(ctx context.Context, params ListUsersOptions) ([]Use
 		if err != nil {
 			return nil, err
 		}
+		user.DistanceKm = params.Near.DistanceTo(user.Location)
 		users = append(users, user)
 	}
 
@@ -231,7 +250,8 @@ func (s *Storage) GetUserByChatID(ctx context.Context, chatID int64) (User, erro
 		       notifications_enabled_at, hidden_at, avatar_url, title, 
 		       description, language_code, last_active_at,
 		       verification_status, verified_at, embedding_updated_at,
-		       login_metadata, location, links, badges, opportunities
+		       login_metadata, location, links, badges, opportunities,
+		       denial_reason, denial_comment
 		FROM users
 		WHERE chat_id = ?
 	`
@@ -245,7 +265,8 @@ func (s *Storage) GetUserByID(ctx context.Context, id string) (User, error) {
 		       notifications_enabled_at, hidden_at, avatar_url, title, 
 		       description, language_code, last_active_at,
 		       verification_status, verified_at, embedding_updated_at,
-		       login_metadata, location, links, badges, opportunities
+		       login_metadata, location, links, badges, opportunities,
+		       denial_reason, denial_comment
 		FROM users
 		WHERE id = ?
 	`
@@ -259,7 +280,8 @@ func (s *Storage) GetUserByUsername(ctx context.Context, username string) (User,
 		       notifications_enabled_at, hidden_at, avatar_url, title, 
 		       description, language_code, last_active_at,
 		       verification_status, verified_at, embedding_updated_at,
-		       login_metadata, location, links, badges, opportunities
+		       login_metadata, location, links, badges, opportunities,
+		       denial_reason, denial_comment
 		FROM users
 		WHERE username = ?
 	`
@@ -285,7 +307,7 @@ func (s *Storage) CreateUser(ctx context.Context, params UpdateUserParams) error
 	var locationJSON, linksJSON, badgesJSON, oppsJSON *[]byte
 
 	if len(params.BadgeIDs) > 0 {
-		badges, err := s.fetchBadgesTx(ctx, tx, params.BadgeIDs)
+		badges, err := s.fetchBadgesTx(ctx, tx, params.BadgeIDs, params.User.ID)
 		if err != nil {
 			return err
 		}
@@ -295,7 +317,7 @@ func (s *Storage) CreateUser(ctx context.Context, params UpdateUserParams) error
 	}
 
 	if len(params.OpportunityIDs) > 0 {
-		opportunities, err := s.fetchOpportunitiesTx(ctx, tx, params.OpportunityIDs)
+		opportunities, err := s.fetchOpportunitiesTx(ctx, tx, params.OpportunityIDs, params.User.ID)
 		if err != nil {
 			return err
 		}
@@ -377,7 +399,7 @@ func (s *Storage) UpdateUser(
 	var locationJSON, badgesJSON, oppsJSON *[]byte
 
 	if len(params.BadgeIDs) > 0 {
-		badges, err := s.fetchBadgesTx(ctx, tx, params.BadgeIDs)
+		badges, err := s.fetchBadgesTx(ctx, tx, params.BadgeIDs, params.User.ID)
 		if err != nil {
 			return err
 		}
@@ -387,7 +409,7 @@ func (s *Storage) UpdateUser(
 	}
 
 	if len(params.OpportunityIDs) > 0 {
-		opportunities, err := s.fetchOpportunitiesTx(ctx, tx, params.OpportunityIDs)
+		opportunities, err := s.fetchOpportunitiesTx(ctx, tx, params.OpportunityIDs, params.User.ID)
 		if err != nil {
 			return err
 		}
@@ -460,7 +482,8 @@ func (s *Storage) GetUsersByVerificationStatus(ctx context.Context, status strin
 		       notifications_enabled_at, hidden_at, avatar_url, title, 
 		       description, language_code, last_active_at,
 		       verification_status, verified_at, embedding_updated_at,
-		       login_metadata, location, links, badges, opportunities
+		       login_metadata, location, links, badges, opportunities,
+		       denial_reason, denial_comment
 		FROM users
 	`
 
@@ -553,6 +576,17 @@ func (s *Storage) UpdateUserAvatarURL(ctx context.Context, userID, avatarURL str
 	return nil
 }
 
+// SetUserReferrer records who invited the user. The first referrer wins and
+// users can't refer themselves.
+func (s *Storage) SetUserReferrer(ctx context.Context, userID, referrerID string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE users SET referred_by = ?, updated_at = ?
+		WHERE id = ? AND id != ? AND referred_by IS NULL
+	`, referrerID, time.Now(), userID, referrerID)
+
+	return err
+}
+
 // UpdateUserLoginMetadata updates login metadata
 func (s *Storage) UpdateUserLoginMetadata(ctx context.Context, userID string, metadata LoginMeta) error {
 	metaJSON, _ := json.Marshal(metadata)
@@ -580,7 +614,8 @@ func (s *Storage) UpdateUserLoginMetadata(ctx context.Context, userID string, me
 	return nil
 }
 
-// UpdateUserVerificationStatus updates verification status
+// UpdateUserVerificationStatus updates verification status.
+// Any stored denial reason is cleared once the status leaves denied.
 func (s *Storage) UpdateUserVerificationStatus(ctx context.Context, userID string, status VerificationStatus) error {
 	var verifiedAt *time.Time
 	if status == VerificationStatusVerified {
@@ -592,9 +627,11 @@ func (s *Storage) UpdateUserVerificationStatus(ctx context.Context, userID strin
 		UPDATE users SET 
 			verification_status = ?, 
 			verified_at = ?,
-			updated_at = ?
+			updated_at = ?,
+			denial_reason = CASE WHEN ? = 'denied' THEN denial_reason END,
+			denial_comment = CASE WHEN ? = 'denied' THEN denial_comment END
 		WHERE id = ?
-	`, status, verifiedAt, time.Now(), userID)
+	`, status, verifiedAt, time.Now(), status, status, userID)
 
 	if err != nil {
 		return err
@@ -655,7 +692,7 @@ func (s *Storage) getUserByQuery(ctx context.Context, query string, args ...inte
 
 func scanUser(rows *sql.Rows) (User, error) {
 	var user User
-	var loginMetaJSON, locationJSON, linksJSON, badgesJSON, oppsJSON sql.NullString
+	var loginMetaJSON, locationJSON, linksJSON, badgesJSON, oppsJSON, denialReasonJSON sql.NullString
 
 	err := rows.Scan(
 		&user.ID, &user.Name, &user.ChatID, &user.Username,
@@ -664,6 +701,7 @@ func scanUser(rows *sql.Rows) (User, error) {
 		&user.LanguageCode, &user.LastActiveAt,
 		&user.VerificationStatus, &user.VerifiedAt, &user.EmbeddingUpdatedAt,
 		&loginMetaJSON, &locationJSON, &linksJSON, &badgesJSON, &oppsJSON,
+		&denialReasonJSON, &user.DenialComment,
 	)
 	if err != nil {
 		return User{}, err
@@ -685,13 +723,16 @@ func scanUser(rows *sql.Rows) (User, error) {
 	if oppsJSON.Valid && oppsJSON.String != "" {
 		json.Unmarshal([]byte(oppsJSON.String), &user.Opportunities)
 	}
+	if denialReasonJSON.Valid && denialReasonJSON.String != "" {
+		json.Unmarshal([]byte(denialReasonJSON.String), &user.DenialReason)
+	}
 
 	return user, nil
 }
 
 func scanUserRow(row *sql.Row) (User, error) {
 	var user User
-	var loginMetaJSON, locationJSON, linksJSON, badgesJSON, oppsJSON sql.NullString
+	var loginMetaJSON, locationJSON, linksJSON, badgesJSON, oppsJSON, denialReasonJSON sql.NullString
 
 	err := row.Scan(
 		&user.ID, &user.Name, &user.ChatID, &user.Username,
@@ -700,6 +741,7 @@ func scanUserRow(row *sql.Row) (User, error) {
 		&user.LanguageCode, &user.LastActiveAt,
 		&user.VerificationStatus, &user.VerifiedAt, &user.EmbeddingUpdatedAt,
 		&loginMetaJSON, &locationJSON, &linksJSON, &badgesJSON, &oppsJSON,
+		&denialReasonJSON, &user.DenialComment,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -724,6 +766,9 @@ func scanUserRow(row *sql.Row) (User, error) {
 	if oppsJSON.Valid && oppsJSON.String != "" {
 		json.Unmarshal([]byte(oppsJSON.String), &user.Opportunities)
 	}
+	if denialReasonJSON.Valid && denialReasonJSON.String != "" {
+		json.Unmarshal([]byte(denialReasonJSON.String), &user.DenialReason)
+	}
 
 	return user, nil
 }
@@ -742,7 +787,8 @@ func (s *Storage) GetUserProfile(ctx context.Context, viewerID string, idOrUsern
 		       notifications_enabled_at, hidden_at, avatar_url, title, 
 		       description, language_code, last_active_at,
 		       verification_status, verified_at, embedding_updated_at,
-		       login_metadata, location, links, badges, opportunities
+		       login_metadata, location, links, badges, opportunities,
+		       denial_reason, denial_comment
 		FROM users
 		WHERE id = ? OR username = ?
 	`
@@ -826,6 +872,11 @@ func (s *Storage) deleteUserCompletelyTx(ctx context.Context, userID string) err
 		return fmt.Errorf("failed to delete follower relationships: %w", err)
 	}
 
+	// Delete sessions
+	if _, err := tx.ExecContext(ctx, `DELETE FROM sessions WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to delete sessions: %w", err)
+	}
+
 	// Delete the user record
 	deleteUserQuery := `DELETE FROM users WHERE id = ?`
 	if _, err := tx.ExecContext(ctx, deleteUserQuery, userID); err != nil {
@@ -839,3 +890,15 @@ func (s *Storage) deleteUserCompletelyTx(ctx context.Context, userID string) err
 
 	return nil
 }
+
+// GetUserVerificationStatus returns just the user's verification status
+func (s *Storage) GetUserVerificationStatus(ctx context.Context, userID string) (VerificationStatus, error) {
+	var status VerificationStatus
+	err := s.db.QueryRowContext(ctx,
+		`SELECT verification_status FROM users WHERE id = ?`, userID).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+
+	return status, err
+}
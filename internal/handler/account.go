@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/peatch-io/peatch/internal/contract"
+	"github.com/peatch-io/peatch/internal/db"
+)
+
+// Error codes returned in contract.ErrorResponse.Code
+const (
+	ErrCodeUserBlocked     = "user_blocked"
+	ErrCodeAccountNotFound = "account_not_found"
+	ErrCodeSessionRevoked  = "session_revoked"
+	ErrCodeRateLimited     = "rate_limited"
+	ErrCodeDuplicateBadge  = "duplicate_badge"
+)
+
+const (
+	// accountStatusKey holds the caller's verification status in the echo context
+	accountStatusKey = "account_status"
+
+	// accountStateTTL is how long a user's verification status is cached
+	accountStateTTL = 30 * time.Second
+)
+
+type cachedAccountState struct {
+	status    db.VerificationStatus
+	expiresAt time.Time
+}
+
+// accountStates caches verification statuses
+type accountStates struct {
+	mu     sync.Mutex
+	states map[string]cachedAccountState
+}
+
+func newAccountStates() *accountStates {
+	return &accountStates{
+		states: make(map[string]cachedAccountState),
+	}
+}
+
+func (a *accountStates) get(userID string) (db.VerificationStatus, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	state, ok := a.states[userID]
+	if !ok || time.Now().After(state.expiresAt) {
+		delete(a.states, userID)
+		return "", false
+	}
+
+	return state.status, true
+}
+
+func (a *accountStates) set(userID string, status db.VerificationStatus) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.states[userID] = cachedAccountState{status: status, expiresAt: time.Now().Add(accountStateTTL)}
+}
+
+// invalidate drops the cached state so the next request sees a status change
+func (a *accountStates) invalidate(userID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.states, userID)
+}
+
+func blockedUserError() *echo.HTTPError {
+	return echo.NewHTTPError(http.StatusForbidden, contract.ErrorResponse{
+		Error: "user is blocked",
+		Code:  ErrCodeUserBlocked,
+	})
+}
+
+// getAccountState returns the user's verification status, from cache when fresh
+func (h *Handler) getAccountState(c echo.Context, userID string) (db.VerificationStatus, error) {
+	if status, ok := h.accounts.get(userID); ok {
+		return status, nil
+	}
+
+	status, err := h.storage.GetUserVerificationStatus(c.Request().Context(), userID)
+	if err != nil {
+		return "", err
+	}
+
+	h.accounts.set(userID, status)
+
+	return status, nil
+}
+
+// requireActiveAccount rejects access tokens of revoked sessions, loads the
+// caller's account state for every API request and rejects writes from
+// blocked users
+func (h *Handler) requireActiveAccount(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID := getUserID(c)
+
+		active, err := h.isSessionActive(c, getSessionID(c))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to get session").WithInternal(err)
+		}
+
+		if !active {
+			return echo.NewHTTPError(http.StatusUnauthorized, contract.ErrorResponse{
+				Error: "session revoked",
+				Code:  ErrCodeSessionRevoked,
+			})
+		}
+
+		status, err := h.getAccountState(c, userID)
+		if err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				return echo.NewHTTPError(http.StatusUnauthorized, contract.ErrorResponse{
+					Error: "account not found",
+					Code:  ErrCodeAccountNotFound,
+				})
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to get account state").WithInternal(err)
+		}
+
+		c.Set(accountStatusKey, status)
+
+		if status == db.VerificationStatusBlocked && c.Request().Method != http.MethodGet {
+			return blockedUserError()
+		}
+
+		return next(c)
+	}
+}
+
+// limitUnverifiedSocialActions applies the shared social action limit to
+// users who aren't verified. It must run after requireActiveAccount.
+func (h *Handler) limitUnverifiedSocialActions(next echo.HandlerFunc) echo.HandlerFunc {
+	limited := h.rateLimit(RateLimitUnverifiedSocial)(next)
+
+	return func(c echo.Context) error {
+		if status, _ := c.Get(accountStatusKey).(db.VerificationStatus); status == db.VerificationStatusVerified {
+			return next(c)
+		}
+
+		return limited(c)
+	}
+}
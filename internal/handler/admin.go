@@ -259,6 +259,11 @@ func (h *Handler) handleAdminUpdateUserVerification(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").WithInternal(err)
 	}
 
+	reason, err := h.getDenialReason(c.Request().Context(), req.ReasonCode)
+	if err != nil {
+		return err
+	}
+
 	if err := h.storage.UpdateUserVerificationStatus(c.Request().Context(), userID, req.Status); err != nil {
 		if errors.Is(err, db.ErrNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "user not found")
@@ -266,13 +271,31 @@ func (h *Handler) handleAdminUpdateUserVerification(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update user verification status").WithInternal(err)
 	}
 
+	h.accounts.invalidate(userID)
+
+	if req.Status == db.VerificationStatusBlocked {
+		if _, err := h.storage.RevokeUserSessions(c.Request().Context(), userID, ""); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to revoke user sessions").WithInternal(err)
+		}
+		h.sessions.invalidateUser(userID)
+	}
+
+	if req.Status == db.VerificationStatusDenied {
+		if err := h.storage.UpdateUserDenialReason(c.Request().Context(), userID, reason, req.Comment); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to update user denial reason").WithInternal(err)
+		}
+
+		user.DenialReason = reason
+		user.DenialComment = req.Comment
+	}
+
 	if req.Status == db.VerificationStatusVerified && needNotify {
 		go func() {
 			if err := h.notificationService.NotifyUserVerified(user); err != nil {
 				h.logger.Error("failed to notify user verified", slog.String("error", err.Error()))
 			}
 		}()
-	} else if req.Status == db.VerificationStatusDenied && previousStatus != db.VerificationStatusDenied {
+	} else if req.Status == db.VerificationStatusDenied && (previousStatus != db.VerificationStatusDenied || reason != nil || req.Comment != nil) {
 		go func() {
 			_ = h.notificationService.NotifyUserVerificationDenied(user)
 		}()
@@ -329,6 +352,11 @@ func (h *Handler) handleAdminUpdateCollaborationVerification(c echo.Context) err
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").WithInternal(err)
 	}
 
+	reason, err := h.getDenialReason(c.Request().Context(), req.ReasonCode)
+	if err != nil {
+		return err
+	}
+
 	if err := h.storage.UpdateCollaborationVerificationStatus(c.Request().Context(), collabID, req.Status); err != nil {
 		if errors.Is(err, db.ErrNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "collaboration not found")
@@ -336,7 +364,16 @@ func (h *Handler) handleAdminUpdateCollaborationVerification(c echo.Context) err
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update collaboration verification status").WithInternal(err)
 	}
 
-	if req.Status == db.VerificationStatusDenied && previousStatus != db.VerificationStatusDenied {
+	if req.Status == db.VerificationStatusDenied {
+		if err := h.storage.UpdateCollaborationDenialReason(c.Request().Context(), collabID, reason, req.Comment); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to update collaboration denial reason").WithInternal(err)
+		}
+
+		collab.DenialReason = reason
+		collab.DenialComment = req.Comment
+	}
+
+	if req.Status == db.VerificationStatusDenied && (previousStatus != db.VerificationStatusDenied || reason != nil || req.Comment != nil) {
 		go func() {
 			_ = h.notificationService.NotifyCollaborationVerificationDenied(collab)
 		}()
@@ -352,12 +389,20 @@ func (h *Handler) handleAdminUpdateCollaborationVerification(c echo.Context) err
 					h.logger.Error("failed to send collaboration to community chat", slog.String("error", err.Error()))
 				}
 
+				// Saved search alerts skip users the embedding match already reached
+				notified := make(map[string]bool)
+
 				users, err := h.storage.GetMatchingUsersForCollaboration(ctx, collab.ID, 100)
 				if err != nil {
 					h.logger.Error("failed to get users with opportunity", slog.String("error", err.Error()))
-					return
+				} else {
+					for _, user := range users {
+						notified[user.ID] = true
+					}
+					_ = h.notificationService.NotifyUsersWithMatchingOpportunity(collab, users)
 				}
-				_ = h.notificationService.NotifyUsersWithMatchingOpportunity(collab, users)
+
+				h.notifySavedSearchMatches(ctx, collab, notified)
 			}()
 		}
 	}
@@ -516,11 +561,17 @@ func (h *Handler) handleAdminCreateCollaboration(c echo.Context) error {
 // @Tags admin
 // @Accept json
 // @Produce json
+// @Param status query string false "Filter by status" Enums(pending, approved)
 // @Success 200 {array} db.Badge
 // @Security ApiKeyAuth
 // @Router /admin/badges [get]
 func (h *Handler) handleAdminListBadges(c echo.Context) error {
-	badges, err := h.storage.ListBadges(c.Request().Context(), "")
+	status := db.BadgeStatus(c.QueryParam("status"))
+	if status != "" && status != db.BadgeStatusPending && status != db.BadgeStatusApproved {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid status")
+	}
+
+	badges, err := h.storage.ListBadgesByStatus(c.Request().Context(), status)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get badges").WithInternal(err)
 	}
@@ -560,6 +611,92 @@ func (h *Handler) handleAdminCreateBadge(c echo.Context) error {
 	return c.JSON(http.StatusCreated, badge)
 }
 
+// @ID admin-approve-badge
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Badge ID"
+// @Success 200 {object} contract.StatusResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/badges/{id}/approve [post]
+func (h *Handler) handleAdminApproveBadge(c echo.Context) error {
+	if err := h.storage.ApproveBadge(c.Request().Context(), c.Param("id")); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "badge not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to approve badge").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, contract.StatusResponse{Success: true})
+}
+
+// handleAdminMergeBadge folds a duplicate badge into another one
+// @Summary Merge badge
+// @Description Replace the badge with the target in every user and collaboration, then delete it
+// @ID admin-merge-badge
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Duplicate badge ID"
+// @Param request body contract.MergeBadgeRequest true "Badge to keep"
+// @Success 200 {object} db.BadgeRewriteResult
+// @Failure 400 {object} contract.ErrorResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Failure 409 {object} contract.ErrorResponse "Target badge is not approved"
+// @Security ApiKeyAuth
+// @Router /admin/badges/{id}/merge [post]
+func (h *Handler) handleAdminMergeBadge(c echo.Context) error {
+	var req contract.MergeBadgeRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").WithInternal(err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").WithInternal(err)
+	}
+
+	sourceID := c.Param("id")
+	if sourceID == req.TargetID {
+		return echo.NewHTTPError(http.StatusBadRequest, "cannot merge a badge into itself")
+	}
+
+	result, err := h.storage.MergeBadges(c.Request().Context(), sourceID, req.TargetID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "badge not found")
+		} else if errors.Is(err, db.ErrBadgeNotApproved) {
+			return echo.NewHTTPError(http.StatusConflict, "target badge is not approved")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to merge badges").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// handleAdminDeleteBadge rejects a badge, removing it from everyone who picked it
+// @Summary Delete badge
+// @ID admin-delete-badge
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Badge ID"
+// @Success 200 {object} db.BadgeRewriteResult
+// @Failure 404 {object} contract.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/badges/{id} [delete]
+func (h *Handler) handleAdminDeleteBadge(c echo.Context) error {
+	result, err := h.storage.DeleteBadge(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "badge not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to delete badge").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
 // @ID admin-list-opportunities
 // @Tags admin
 // @Accept json
@@ -568,7 +705,150 @@ func (h *Handler) handleAdminCreateBadge(c echo.Context) error {
 // @Security ApiKeyAuth
 // @Router /admin/opportunities [get]
 func (h *Handler) handleAdminListOpportunities(c echo.Context) error {
-	opportunities, err := h.storage.ListOpportunities(c.Request().Context())
+	opportunities, err := h.storage.ListOpportunities(c.Request().Context(), true)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get opportunities").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, opportunities)
+}
+
+// @ID admin-create-opportunity
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body contract.OpportunityRequest true "Opportunity data"
+// @Success 201 {object} db.Opportunity
+// @Failure 400 {object} contract.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/opportunities [post]
+func (h *Handler) handleAdminCreateOpportunity(c echo.Context) error {
+	var req contract.OpportunityRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").WithInternal(err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").WithInternal(err)
+	}
+
+	opportunity := req.ToOpportunity(nanoid.Must())
+
+	if err := h.storage.CreateOpportunity(c.Request().Context(), opportunity); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create opportunity").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusCreated, opportunity)
+}
+
+// handleAdminUpdateOpportunity updates an opportunity and every profile and collaboration showing it
+// @ID admin-update-opportunity
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Opportunity ID"
+// @Param request body contract.OpportunityRequest true "Opportunity data"
+// @Success 200 {object} db.Opportunity
+// @Failure 400 {object} contract.ErrorResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/opportunities/{id} [put]
+func (h *Handler) handleAdminUpdateOpportunity(c echo.Context) error {
+	var req contract.OpportunityRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").WithInternal(err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").WithInternal(err)
+	}
+
+	opportunity := req.ToOpportunity(c.Param("id"))
+
+	if err := h.storage.UpdateOpportunity(c.Request().Context(), opportunity); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "opportunity not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update opportunity").WithInternal(err)
+	}
+
+	// The request doesn't carry the position, archive state or creation time
+	updated, err := h.storage.GetOpportunityByID(c.Request().Context(), opportunity.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get opportunity").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, updated)
+}
+
+// handleAdminArchiveOpportunity removes an opportunity from pickers; existing profiles keep it
+// @ID admin-archive-opportunity
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Opportunity ID"
+// @Success 200 {object} contract.StatusResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/opportunities/{id}/archive [post]
+func (h *Handler) handleAdminArchiveOpportunity(c echo.Context) error {
+	return h.setOpportunityArchived(c, true)
+}
+
+// @ID admin-restore-opportunity
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Opportunity ID"
+// @Success 200 {object} contract.StatusResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/opportunities/{id}/restore [post]
+func (h *Handler) handleAdminRestoreOpportunity(c echo.Context) error {
+	return h.setOpportunityArchived(c, false)
+}
+
+func (h *Handler) setOpportunityArchived(c echo.Context, archived bool) error {
+	if err := h.storage.SetOpportunityArchived(c.Request().Context(), c.Param("id"), archived); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "opportunity not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update opportunity").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, contract.StatusResponse{Success: true})
+}
+
+// @ID admin-reorder-opportunities
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body contract.ReorderOpportunitiesRequest true "New order"
+// @Success 200 {array} db.Opportunity
+// @Failure 400 {object} contract.ErrorResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/opportunities/order [put]
+func (h *Handler) handleAdminReorderOpportunities(c echo.Context) error {
+	var req contract.ReorderOpportunitiesRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").WithInternal(err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").WithInternal(err)
+	}
+
+	ctx := c.Request().Context()
+
+	if err := h.storage.ReorderOpportunities(ctx, req.IDs); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "opportunity not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to reorder opportunities").WithInternal(err)
+	}
+
+	opportunities, err := h.storage.ListOpportunities(ctx, true)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get opportunities").WithInternal(err)
 	}
@@ -709,6 +989,8 @@ func (h *Handler) handleAdminDeleteUser(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to delete user").WithInternal(err)
 	}
 
+	h.accounts.invalidate(userID)
+
 	return c.JSON(http.StatusOK, contract.StatusResponse{
 		Success: true,
 	})
@@ -764,3 +1046,99 @@ func (h *Handler) handleAdminDeleteCollaboration(c echo.Context) error {
 		Success: true,
 	})
 }
+
+// getDenialReason resolves a reason code from the catalogue. An empty code
+// means the admin didn't pick a reason.
+func (h *Handler) getDenialReason(ctx context.Context, code string) (*db.DenialReason, error) {
+	if code == "" {
+		return nil, nil
+	}
+
+	reason, err := h.storage.GetDenialReasonByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "unknown denial reason code").WithInternal(err)
+		}
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "failed to get denial reason").WithInternal(err)
+	}
+
+	return &reason, nil
+}
+
+// @ID admin-list-denial-reasons
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {array} db.DenialReason
+// @Security ApiKeyAuth
+// @Router /admin/denial-reasons [get]
+func (h *Handler) handleAdminListDenialReasons(c echo.Context) error {
+	reasons, err := h.storage.ListDenialReasons(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get denial reasons").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, reasons)
+}
+
+// @ID admin-create-denial-reason
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body contract.CreateDenialReasonRequest true "Denial reason data"
+// @Success 201 {object} db.DenialReason
+// @Failure 400 {object} contract.ErrorResponse
+// @Failure 409 {object} contract.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/denial-reasons [post]
+func (h *Handler) handleAdminCreateDenialReason(c echo.Context) error {
+	var req contract.CreateDenialReasonRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").WithInternal(err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").WithInternal(err)
+	}
+
+	reason := db.DenialReason{
+		ID:     nanoid.Must(),
+		Code:   req.Code,
+		Text:   req.TextEN,
+		TextRU: req.TextRU,
+	}
+
+	if err := h.storage.CreateDenialReason(c.Request().Context(), reason); err != nil {
+		if errors.Is(err, db.ErrAlreadyExists) {
+			return echo.NewHTTPError(http.StatusConflict, "denial reason already exists").WithInternal(err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create denial reason").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusCreated, reason)
+}
+
+// @ID admin-delete-denial-reason
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Denial reason ID"
+// @Success 200 {object} contract.StatusResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/denial-reasons/{id} [delete]
+func (h *Handler) handleAdminDeleteDenialReason(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "denial reason ID is required")
+	}
+
+	if err := h.storage.DeleteDenialReason(c.Request().Context(), id); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "denial reason not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to delete denial reason").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, contract.StatusResponse{Success: true})
+}
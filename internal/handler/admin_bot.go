@@ -0,0 +1,329 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	telegram "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/labstack/echo/v4"
+	"github.com/peatch-io/peatch/internal/db"
+	"github.com/peatch-io/peatch/internal/nanoid"
+)
+
+const (
+	AdminCommandPending   = "pending"
+	AdminCommandStats     = "stats"
+	AdminCommandUser      = "user"
+	AdminCommandCollab    = "collab"
+	AdminCommandBroadcast = "broadcast"
+)
+
+// Callback data prefixes, followed by the broadcast ID
+const (
+	callbackBroadcastSend   = "broadcast:send:"
+	callbackBroadcastCancel = "broadcast:cancel:"
+)
+
+const (
+	adminPendingLimit = 10
+	// broadcastInterval keeps deliveries under Telegram's 30 messages per second
+	broadcastInterval = 40 * time.Millisecond
+)
+
+var adminCommandDescriptions = []models.BotCommand{
+	{Command: AdminCommandPending, Description: "Profiles and collaborations awaiting review"},
+	{Command: AdminCommandStats, Description: "User and collaboration counts"},
+	{Command: AdminCommandUser, Description: "Look up a user by username"},
+	{Command: AdminCommandCollab, Description: "Look up a collaboration by ID"},
+	{Command: AdminCommandBroadcast, Description: "Message all verified users"},
+}
+
+const adminHelpText = "Available commands:\n" +
+	"/pending - profiles and collaborations awaiting review\n" +
+	"/stats - user and collaboration counts\n" +
+	"/user <username> - look up a user\n" +
+	"/collab <id> - look up a collaboration\n" +
+	"/broadcast <text> - message all verified users"
+
+func (h *Handler) HandleAdminWebhook(c echo.Context) error {
+	return h.handleWebhookRequest(c, h.config.AdminWebhookSecret, adminBotKey, h.handleAdminUpdate)
+}
+
+func (h *Handler) setAdminBotCommands(ctx context.Context) {
+	if _, err := h.adminBot.SetMyCommands(ctx, &telegram.SetMyCommandsParams{Commands: adminCommandDescriptions}); err != nil {
+		h.logger.Error("failed to set admin bot commands", slog.String("error", err.Error()))
+	}
+}
+
+// handleAdminUpdate dispatches an admin bot update. Only chats registered in
+// the admins table are served.
+func (h *Handler) handleAdminUpdate(ctx context.Context, update models.Update) {
+	var chatID int64
+	switch {
+	case update.Message != nil:
+		chatID = update.Message.Chat.ID
+	case update.CallbackQuery != nil:
+		chatID = update.CallbackQuery.From.ID
+	default:
+		return
+	}
+
+	admin, err := h.storage.GetAdminByChatID(ctx, chatID)
+	if errors.Is(err, db.ErrNotFound) {
+		h.logger.Info("ignoring admin bot update from unknown chat", slog.Int64("chat_id", chatID))
+		return
+	} else if err != nil {
+		h.logger.Error("failed to get admin", slog.Int64("chat_id", chatID), slog.String("error", err.Error()))
+		return
+	}
+
+	if update.CallbackQuery != nil {
+		err = h.handleAdminCallback(ctx, admin, update.CallbackQuery)
+	} else {
+		err = h.handleAdminCommand(ctx, admin, parseBotCommand(update.Message.Text))
+	}
+
+	if err != nil {
+		h.logger.Error("handle admin bot update failed",
+			slog.String("admin_id", admin.ID),
+			slog.String("error", err.Error()))
+	}
+}
+
+func (h *Handler) handleAdminCommand(ctx context.Context, admin db.Admin, cmd botCommand) error {
+	switch cmd.Name {
+	case AdminCommandPending:
+		return h.handleAdminPendingCommand(ctx, admin)
+	case AdminCommandStats:
+		return h.handleAdminStatsCommand(ctx, admin)
+	case AdminCommandUser:
+		return h.handleAdminUserCommand(ctx, admin, strings.TrimPrefix(cmd.Args, "@"))
+	case AdminCommandCollab:
+		return h.handleAdminCollabCommand(ctx, admin, cmd.Args)
+	case AdminCommandBroadcast:
+		return h.handleAdminBroadcastCommand(ctx, admin, cmd.Args)
+	default:
+		return h.sendAdminMessage(ctx, admin.ChatID, adminHelpText, nil)
+	}
+}
+
+func (h *Handler) handleAdminPendingCommand(ctx context.Context, admin db.Admin) error {
+	users, err := h.storage.GetUsersByVerificationStatus(ctx, string(db.VerificationStatusPending), 0, adminPendingLimit)
+	if err != nil {
+		return fmt.Errorf("failed to get pending users: %w", err)
+	}
+
+	collabs, err := h.storage.GetCollaborationsByVerificationStatus(ctx, string(db.VerificationStatusPending), 1, adminPendingLimit)
+	if err != nil {
+		return fmt.Errorf("failed to get pending collaborations: %w", err)
+	}
+
+	if len(users) == 0 && len(collabs) == 0 {
+		return h.sendAdminMessage(ctx, admin.ChatID, "Nothing is waiting for review 🎉", nil)
+	}
+
+	// The lists stop at adminPendingLimit, the totals are counted
+	stats, err := h.storage.GetAdminStats(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count pending: %w", err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "👤 Pending users (%d):\n", stats.UsersPending)
+	for _, user := range users {
+		fmt.Fprintf(&sb, "• %s @%s\n", userDisplayName(user), user.Username)
+	}
+
+	fmt.Fprintf(&sb, "\n📋 Pending collaborations (%d):\n", stats.CollaborationsPending)
+	for _, collab := range collabs {
+		fmt.Fprintf(&sb, "• %s by @%s\n  /collab %s\n", collab.Title, collab.User.Username, collab.ID)
+	}
+
+	return h.sendAdminMessage(ctx, admin.ChatID, sb.String(), nil)
+}
+
+func (h *Handler) handleAdminStatsCommand(ctx context.Context, admin db.Admin) error {
+	stats, err := h.storage.GetAdminStats(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get stats: %w", err)
+	}
+
+	text := fmt.Sprintf("📊 Stats\n\n"+
+		"Users: %d\n• verified: %d\n• pending: %d\n• new this week: %d\n\n"+
+		"Collaborations: %d\n• verified: %d\n• pending: %d",
+		stats.UsersTotal, stats.UsersVerified, stats.UsersPending, stats.UsersNewThisWeek,
+		stats.CollaborationsTotal, stats.CollaborationsVerified, stats.CollaborationsPending)
+
+	return h.sendAdminMessage(ctx, admin.ChatID, text, nil)
+}
+
+func (h *Handler) handleAdminUserCommand(ctx context.Context, admin db.Admin, username string) error {
+	if username == "" {
+		return h.sendAdminMessage(ctx, admin.ChatID, "Usage: /user <username>", nil)
+	}
+
+	user, err := h.storage.GetUserByUsername(ctx, username)
+	if errors.Is(err, db.ErrNotFound) {
+		return h.sendAdminMessage(ctx, admin.ChatID, fmt.Sprintf("User @%s not found", username), nil)
+	} else if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	lines := []string{
+		fmt.Sprintf("%s @%s", userDisplayName(user), user.Username),
+		"ID: " + user.ID,
+		fmt.Sprintf("Chat ID: %d", user.ChatID),
+		"Status: " + string(user.VerificationStatus),
+		"Joined: " + user.CreatedAt.Format(time.DateOnly),
+	}
+	if user.LastActiveAt != nil {
+		lines = append(lines, "Last active: "+user.LastActiveAt.Format(time.DateTime))
+	}
+	if user.HiddenAt != nil {
+		lines = append(lines, "Hidden since: "+user.HiddenAt.Format(time.DateOnly))
+	}
+	if user.DenialReason != nil {
+		lines = append(lines, "Denial reason: "+user.DenialReason.Code)
+	}
+	lines = append(lines, "", userCardText(user, db.LanguageEN))
+
+	return h.sendAdminMessage(ctx, admin.ChatID, strings.Join(lines, "\n"), nil)
+}
+
+func (h *Handler) handleAdminCollabCommand(ctx context.Context, admin db.Admin, collabID string) error {
+	if collabID == "" {
+		return h.sendAdminMessage(ctx, admin.ChatID, "Usage: /collab <id>", nil)
+	}
+
+	// Fetch as the owner so pending and hidden collaborations are visible too
+	ownerID, err := h.storage.GetCollaborationOwnerID(ctx, collabID)
+	if errors.Is(err, db.ErrNotFound) {
+		return h.sendAdminMessage(ctx, admin.ChatID, fmt.Sprintf("Collaboration %s not found", collabID), nil)
+	} else if err != nil {
+		return fmt.Errorf("failed to get collaboration owner: %w", err)
+	}
+
+	collab, err := h.storage.GetCollaborationByID(ctx, ownerID, collabID)
+	if err != nil {
+		return fmt.Errorf("failed to get collaboration: %w", err)
+	}
+
+	lines := []string{
+		"ID: " + collab.ID,
+		fmt.Sprintf("Owner: %s @%s", userDisplayName(collab.User), collab.User.Username),
+		"Status: " + string(collab.VerificationStatus),
+		"Lifecycle: " + string(collab.Status),
+		"Created: " + collab.CreatedAt.Format(time.DateOnly),
+	}
+	if collab.ExpiresAt != nil {
+		lines = append(lines, "Expires: "+collab.ExpiresAt.Format(time.DateOnly))
+	}
+	if collab.HiddenAt != nil {
+		lines = append(lines, "Hidden since: "+collab.HiddenAt.Format(time.DateOnly))
+	}
+	if collab.DenialReason != nil {
+		lines = append(lines, "Denial reason: "+collab.DenialReason.Code)
+	}
+	lines = append(lines, "", collaborationCardText(collab, db.LanguageEN))
+
+	return h.sendAdminMessage(ctx, admin.ChatID, strings.Join(lines, "\n"), nil)
+}
+
+// handleAdminBroadcastCommand stores the text as a draft broadcast to all
+// verified users and shows a preview with Send and Cancel buttons
+func (h *Handler) handleAdminBroadcastCommand(ctx context.Context, admin db.Admin, text string) error {
+	if text == "" {
+		return h.sendAdminMessage(ctx, admin.ChatID, "Usage: /broadcast <text>", nil)
+	}
+
+	segment := db.BroadcastSegment{Statuses: []db.VerificationStatus{db.VerificationStatusVerified}}
+
+	count, err := h.storage.CountBroadcastAudience(ctx, segment)
+	if err != nil {
+		return fmt.Errorf("failed to count broadcast audience: %w", err)
+	}
+
+	broadcast := db.Broadcast{
+		ID:      nanoid.Must(),
+		AdminID: admin.ID,
+		Text:    text,
+		Segment: segment,
+	}
+
+	if err := h.storage.CreateBroadcast(ctx, broadcast); err != nil {
+		return fmt.Errorf("failed to create broadcast: %w", err)
+	}
+
+	keyboard := &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{
+				{Text: fmt.Sprintf("Send to %d users", count), CallbackData: callbackBroadcastSend + broadcast.ID},
+				{Text: "Cancel", CallbackData: callbackBroadcastCancel + broadcast.ID},
+			},
+		},
+	}
+
+	return h.sendAdminMessage(ctx, admin.ChatID, "Broadcast preview:\n\n"+text, keyboard)
+}
+
+func (h *Handler) handleAdminCallback(ctx context.Context, admin db.Admin, query *models.CallbackQuery) error {
+	var answer string
+
+	switch {
+	case strings.HasPrefix(query.Data, callbackBroadcastSend):
+		_, err := h.startBroadcast(ctx, admin, strings.TrimPrefix(query.Data, callbackBroadcastSend))
+		switch {
+		case errors.Is(err, db.ErrNotFound), errors.Is(err, db.ErrAlreadyExists):
+			answer = "Nothing to send"
+		case err != nil:
+			return fmt.Errorf("failed to start broadcast: %w", err)
+		default:
+			answer = "Sending…"
+		}
+	case strings.HasPrefix(query.Data, callbackBroadcastCancel):
+		err := h.storage.CancelBroadcast(ctx, strings.TrimPrefix(query.Data, callbackBroadcastCancel))
+		if err != nil && !errors.Is(err, db.ErrNotFound) {
+			return fmt.Errorf("failed to cancel broadcast: %w", err)
+		}
+		answer = "Cancelled"
+	}
+
+	_, err := h.adminBot.AnswerCallbackQuery(ctx, &telegram.AnswerCallbackQueryParams{
+		CallbackQueryID: query.ID,
+		Text:            answer,
+	})
+
+	return err
+}
+
+func (h *Handler) sendAdminMessage(ctx context.Context, chatID int64, text string, keyboard *models.InlineKeyboardMarkup) error {
+	params := &telegram.SendMessageParams{
+		ChatID: chatID,
+		Text:   text,
+	}
+
+	if keyboard != nil {
+		params.ReplyMarkup = keyboard
+	}
+
+	if _, err := h.adminBot.SendMessage(ctx, params); err != nil {
+		h.logger.Error("failed to send admin message",
+			slog.Int64("chat_id", chatID),
+			slog.String("error", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+func userDisplayName(user db.User) string {
+	if user.Name != nil && *user.Name != "" {
+		return *user.Name
+	}
+	return user.Username
+}
@@ -0,0 +1,113 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/peatch-io/peatch/internal/contract"
+	"github.com/peatch-io/peatch/internal/db"
+	"github.com/peatch-io/peatch/internal/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminDenyUser_WithReason(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	adminToken := testutils.AdminAuthHelper(t, ts.Storage, 900001, "moderator")
+
+	authResp, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "testuser", "Test")
+	if err != nil {
+		t.Fatalf("failed to authenticate user: %v", err)
+	}
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/denial-reasons",
+		`{"code": "no_photo", "text_en": "Please add a profile photo", "text_ru": "Пожалуйста, добавьте фото профиля"}`,
+		adminToken, http.StatusCreated)
+	reason := testutils.ParseResponse[db.DenialReason](t, rec)
+	assert.Equal(t, "no_photo", reason.Code)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/admin/users/"+authResp.User.ID+"/verify",
+		`{"status": "denied", "reason_code": "no_photo", "comment": "Selfies are fine"}`,
+		adminToken, http.StatusOK)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me", "", authResp.Token, http.StatusOK)
+	user := testutils.ParseResponse[contract.UserResponse](t, rec)
+
+	assert.Equal(t, db.VerificationStatusDenied, user.VerificationStatus)
+	if assert.NotNil(t, user.DenialReason) {
+		assert.Equal(t, "no_photo", user.DenialReason.Code)
+		assert.Equal(t, "Пожалуйста, добавьте фото профиля", user.DenialReason.Text) // test user is ru
+	}
+	if assert.NotNil(t, user.DenialComment) {
+		assert.Equal(t, "Selfies are fine", *user.DenialComment)
+	}
+
+	// Verifying clears the reason
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/admin/users/"+authResp.User.ID+"/verify",
+		`{"status": "verified"}`, adminToken, http.StatusOK)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me", "", authResp.Token, http.StatusOK)
+	user = testutils.ParseResponse[contract.UserResponse](t, rec)
+	assert.Nil(t, user.DenialReason)
+	assert.Nil(t, user.DenialComment)
+}
+
+func TestAdminDenyUser_InvalidReason(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	adminToken := testutils.AdminAuthHelper(t, ts.Storage, 900001, "moderator")
+
+	authResp, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "testuser", "Test")
+	if err != nil {
+		t.Fatalf("failed to authenticate user: %v", err)
+	}
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/admin/users/"+authResp.User.ID+"/verify",
+		`{"status": "denied", "reason_code": "unknown"}`, adminToken, http.StatusBadRequest)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/admin/users/"+authResp.User.ID+"/verify",
+		`{"status": "verified", "comment": "not allowed"}`, adminToken, http.StatusBadRequest)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/denial-reasons",
+		`{"code": "spam", "text_en": "Spam", "text_ru": "Спам"}`, adminToken, http.StatusCreated)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/denial-reasons",
+		`{"code": "spam", "text_en": "Spam", "text_ru": "Спам"}`, adminToken, http.StatusConflict)
+}
+
+func TestAdminDenyCollaboration_ReasonInList(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	adminToken := testutils.AdminAuthHelper(t, ts.Storage, 900001, "moderator")
+
+	authResp, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "testuser", "Test")
+	if err != nil {
+		t.Fatalf("failed to authenticate user: %v", err)
+	}
+
+	badges, opps, _ := setupTestRecords(ts.Storage, t)
+	err = ts.Storage.CreateCollaboration(context.Background(), db.CreateCollaborationParams{
+		Collaboration: db.Collaboration{ID: "collab-1", UserID: authResp.User.ID, Title: "Band", Description: "Description"},
+		BadgeIDs:      badges,
+		OpportunityID: opps[0],
+	})
+	if err != nil {
+		t.Fatalf("failed to create collaboration: %v", err)
+	}
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/denial-reasons",
+		`{"code": "vague", "text_en": "Please describe the role", "text_ru": "Пожалуйста, опишите роль"}`,
+		adminToken, http.StatusCreated)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/admin/users/"+authResp.User.ID+"/collaborations/collab-1/verify",
+		`{"status": "denied", "reason_code": "vague"}`, adminToken, http.StatusOK)
+
+	// Lists localize the reason to the owner's language too, not only the single view
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations", "", authResp.Token, http.StatusOK)
+	collabs := testutils.ParseResponse[[]contract.CollaborationResponse](t, rec)
+	if assert.Len(t, collabs, 1) && assert.NotNil(t, collabs[0].DenialReason) {
+		assert.Equal(t, "Пожалуйста, опишите роль", collabs[0].DenialReason.Text) // test user is ru
+	}
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/peatch-io/peatch/internal/contract"
+	"github.com/peatch-io/peatch/internal/db"
+)
+
+// handleListMyApplications godoc
+// @Summary List my applications
+// @Description Applications the current user sent, newest first, with their status
+// @Tags applications
+// @Produce json
+// @Param page query int false "Page"
+// @Param limit query int false "Limit"
+// @Success 200 {array} contract.ApplicationResponse
+// @Router /api/users/me/applications [get]
+func (h *Handler) handleListMyApplications(c echo.Context) error {
+	page := parseIntQuery(c, "page", 1)
+	limit := parseIntQuery(c, "limit", 20)
+
+	applications, err := h.storage.ListUserApplications(c.Request().Context(), getUserID(c), page, limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get applications").WithInternal(err)
+	}
+
+	resp := make([]contract.ApplicationResponse, len(applications))
+	for i, application := range applications {
+		resp[i] = contract.ToApplicationResponse(application)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// handleListCollaborationInterests godoc
+// @Summary List applications to a collaboration
+// @Description Collaboration owner only. Includes answered and withdrawn applications, with how well each applicant matches the collaboration.
+// @Tags applications
+// @Produce json
+// @Param id path string true "Collaboration ID"
+// @Param page query int false "Page"
+// @Param limit query int false "Limit"
+// @Success 200 {array} contract.ApplicationResponse
+// @Failure 404 {object} contract.ErrorResponse "Collaboration not found or not owned"
+// @Router /api/collaborations/{id}/interests [get]
+func (h *Handler) handleListCollaborationInterests(c echo.Context) error {
+	page := parseIntQuery(c, "page", 1)
+	limit := parseIntQuery(c, "limit", 20)
+
+	applications, err := h.storage.ListCollaborationApplications(c.Request().Context(), getUserID(c), c.Param("id"), page, limit)
+	if errors.Is(err, db.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "collaboration not found")
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get applications").WithInternal(err)
+	}
+
+	resp := make([]contract.ApplicationResponse, len(applications))
+	for i, application := range applications {
+		resp[i] = contract.ToApplicationResponse(application)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// handleAcceptApplication godoc
+// @Summary Accept application
+// @Description Collaboration owner only. The applicant is notified.
+// @Tags applications
+// @Produce json
+// @Param id path string true "Application ID"
+// @Success 200 {object} contract.ApplicationResponse
+// @Failure 409 {object} contract.ErrorResponse "Application was withdrawn"
+// @Router /api/applications/{id}/accept [post]
+func (h *Handler) handleAcceptApplication(c echo.Context) error {
+	return h.answerApplication(c, db.ApplicationStatusAccepted)
+}
+
+// handleShortlistApplication godoc
+// @Summary Shortlist application
+// @Description Collaboration owner only. The applicant is notified.
+// @Tags applications
+// @Produce json
+// @Param id path string true "Application ID"
+// @Success 200 {object} contract.ApplicationResponse
+// @Failure 409 {object} contract.ErrorResponse "Application was withdrawn"
+// @Router /api/applications/{id}/shortlist [post]
+func (h *Handler) handleShortlistApplication(c echo.Context) error {
+	return h.answerApplication(c, db.ApplicationStatusShortlisted)
+}
+
+// handleDeclineApplication godoc
+// @Summary Decline application
+// @Description Collaboration owner only. The applicant is notified.
+// @Tags applications
+// @Produce json
+// @Param id path string true "Application ID"
+// @Success 200 {object} contract.ApplicationResponse
+// @Failure 409 {object} contract.ErrorResponse "Application was withdrawn"
+// @Router /api/applications/{id}/decline [post]
+func (h *Handler) handleDeclineApplication(c echo.Context) error {
+	return h.answerApplication(c, db.ApplicationStatusDeclined)
+}
+
+func (h *Handler) answerApplication(c echo.Context, status db.ApplicationStatus) error {
+	application, err := h.storage.AnswerApplication(c.Request().Context(), getUserID(c), c.Param("id"), status)
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "application not found")
+	case errors.Is(err, db.ErrApplicationClosed):
+		return echo.NewHTTPError(http.StatusConflict, "application was withdrawn")
+	case err != nil:
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update application").WithInternal(err)
+	}
+
+	go h.notifyApplicationStatusChanged(application)
+
+	return c.JSON(http.StatusOK, contract.ToApplicationResponse(application))
+}
+
+// handleWithdrawApplication godoc
+// @Summary Withdraw application
+// @Tags applications
+// @Produce json
+// @Param id path string true "Application ID"
+// @Success 200 {object} contract.ApplicationResponse
+// @Failure 409 {object} contract.ErrorResponse "Application was already withdrawn"
+// @Router /api/applications/{id}/withdraw [post]
+func (h *Handler) handleWithdrawApplication(c echo.Context) error {
+	application, err := h.storage.WithdrawApplication(c.Request().Context(), getUserID(c), c.Param("id"))
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "application not found")
+	case errors.Is(err, db.ErrApplicationClosed):
+		return echo.NewHTTPError(http.StatusConflict, "application was already withdrawn")
+	case err != nil:
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to withdraw application").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, contract.ToApplicationResponse(application))
+}
+
+func (h *Handler) notifyApplicationStatusChanged(application db.Application) {
+	if err := h.notificationService.NotifyApplicationStatusChanged(application); err != nil {
+		h.logger.Error("failed to send application status notification",
+			slog.String("application_id", application.ID),
+			slog.String("error", err.Error()))
+	}
+}
+
+// answerApplicationFromBot handles the owner's buttons on a new application
+// notification and returns the text to answer the callback with
+func (h *Handler) answerApplicationFromBot(ctx context.Context, owner db.User, applicationID string, status db.ApplicationStatus) (string, error) {
+	msgs := messages[owner.LanguageCode]
+	if msgs == nil {
+		msgs = messages[db.LanguageEN]
+	}
+
+	application, err := h.storage.AnswerApplication(ctx, owner.ID, applicationID, status)
+	switch {
+	case errors.Is(err, db.ErrNotFound), errors.Is(err, db.ErrApplicationClosed):
+		return msgs[MsgKeyApplicationUnavailable], nil
+	case err != nil:
+		return "", err
+	}
+
+	go h.notifyApplicationStatusChanged(application)
+
+	return msgs[MsgKeyApplicationAnswered], nil
+}
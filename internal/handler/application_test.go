@@ -0,0 +1,152 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/peatch-io/peatch/internal/contract"
+	"github.com/peatch-io/peatch/internal/db"
+	"github.com/peatch-io/peatch/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplications(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	ctx := context.Background()
+
+	owner, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "owner", "Owner")
+	require.NoError(t, err)
+	applicant, err := testutils.AuthHelper(t, ts.Echo, 100001, "applicant", "Applicant")
+	require.NoError(t, err)
+	require.NoError(t, ts.Storage.UpdateUserVerificationStatus(ctx, applicant.User.ID, db.VerificationStatusVerified))
+
+	badges, opps, _ := setupTestRecords(ts.Storage, t)
+	require.NoError(t, ts.Storage.CreateCollaboration(ctx, db.CreateCollaborationParams{
+		Collaboration: db.Collaboration{ID: "collab-1", UserID: owner.User.ID, Title: "Band", Description: "Description"},
+		BadgeIDs:      badges,
+		OpportunityID: opps[0],
+	}))
+	require.NoError(t, ts.Storage.UpdateCollaborationVerificationStatus(ctx, "collab-1", db.VerificationStatusVerified))
+
+	body := `{"message": "I play bass", "links": [{"url": "https://example.com", "label": "Demo", "type": "website"}]}`
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/interest", body, applicant.Token, http.StatusOK)
+	assert.True(t, ts.MockNotifier.CollabInterestRecord.Called)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/interest", body, applicant.Token, http.StatusBadRequest)
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me/applications", "", applicant.Token, http.StatusOK)
+	applications := testutils.ParseResponse[[]contract.ApplicationResponse](t, rec)
+	require.Len(t, applications, 1)
+	application := applications[0]
+	assert.Equal(t, "I play bass", application.Message)
+	assert.Equal(t, db.ApplicationStatusPending, application.Status)
+	assert.Equal(t, "Band", application.Collaboration.Title)
+	require.Len(t, application.Links, 1)
+
+	// Only the owner answers
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/applications/"+application.ID+"/accept", "", applicant.Token, http.StatusNotFound)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/applications/"+application.ID+"/shortlist", "", owner.Token, http.StatusOK)
+	assert.Equal(t, db.ApplicationStatusShortlisted, testutils.ParseResponse[contract.ApplicationResponse](t, rec).Status)
+
+	require.Eventually(t, func() bool {
+		return ts.MockNotifier.ApplicationStatusRecord.Called
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, application.ID, ts.MockNotifier.ApplicationStatusRecord.ToFollowID)
+
+	// Only the applicant withdraws
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/applications/"+application.ID+"/withdraw", "", owner.Token, http.StatusNotFound)
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/applications/"+application.ID+"/withdraw", "", applicant.Token, http.StatusOK)
+	assert.Equal(t, db.ApplicationStatusWithdrawn, testutils.ParseResponse[contract.ApplicationResponse](t, rec).Status)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/applications/"+application.ID+"/withdraw", "", applicant.Token, http.StatusConflict)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/applications/"+application.ID+"/accept", "", owner.Token, http.StatusConflict)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations/collab-1", "", applicant.Token, http.StatusOK)
+	assert.False(t, testutils.ParseResponse[contract.CollaborationResponse](t, rec).HasInterest)
+
+	// Applying again after withdrawing starts over
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/interest", `{"message": "Still keen"}`, applicant.Token, http.StatusOK)
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me/applications", "", applicant.Token, http.StatusOK)
+	applications = testutils.ParseResponse[[]contract.ApplicationResponse](t, rec)
+	require.Len(t, applications, 1)
+	assert.Equal(t, db.ApplicationStatusPending, applications[0].Status)
+	assert.Equal(t, "Still keen", applications[0].Message)
+	assert.Empty(t, applications[0].Links)
+}
+
+func TestApplications_InvalidPitch(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	applicant, err := testutils.AuthHelper(t, ts.Echo, 100001, "applicant", "Applicant")
+	require.NoError(t, err)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/interest",
+		`{"links": [{"url": "https://example.com"}]}`, applicant.Token, http.StatusBadRequest)
+}
+
+func TestListCollaborationInterests(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	ctx := context.Background()
+
+	owner, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "owner", "Owner")
+	require.NoError(t, err)
+	matching, err := testutils.AuthHelper(t, ts.Echo, 100001, "matching", "Matching")
+	require.NoError(t, err)
+	other, err := testutils.AuthHelper(t, ts.Echo, 100002, "other", "Other")
+	require.NoError(t, err)
+	for _, user := range []string{matching.User.ID, other.User.ID} {
+		require.NoError(t, ts.Storage.UpdateUserVerificationStatus(ctx, user, db.VerificationStatusVerified))
+	}
+
+	badges, opps, _ := setupTestRecords(ts.Storage, t)
+	require.NoError(t, ts.Storage.CreateCollaboration(ctx, db.CreateCollaborationParams{
+		Collaboration: db.Collaboration{ID: "collab-1", UserID: owner.User.ID, Title: "Band", Description: "Description"},
+		BadgeIDs:      badges,
+		OpportunityID: opps[0],
+	}))
+	require.NoError(t, ts.Storage.UpdateCollaborationVerificationStatus(ctx, "collab-1", db.VerificationStatusVerified))
+
+	vector := make([]float64, 1536)
+	vector[0] = 1
+	require.NoError(t, ts.Storage.UpdateCollaborationEmbedding(ctx, "collab-1", vector))
+	require.NoError(t, ts.Storage.UpdateUserEmbedding(ctx, matching.User.ID, vector))
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/interest", `{"message": "Pick me"}`, matching.Token, http.StatusOK)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/interest", "", other.Token, http.StatusOK)
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me/applications", "", other.Token, http.StatusOK)
+	withdrawn := testutils.ParseResponse[[]contract.ApplicationResponse](t, rec)[0]
+	assert.Nil(t, withdrawn.MatchScore)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/applications/"+withdrawn.ID+"/withdraw", "", other.Token, http.StatusOK)
+
+	// History survives the cleanup of expired records
+	require.NoError(t, ts.Storage.CleanupExpiredRecords(ctx))
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations/collab-1/interests", "", matching.Token, http.StatusNotFound)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations/collab-1/interests", "", owner.Token, http.StatusOK)
+	applications := testutils.ParseResponse[[]contract.ApplicationResponse](t, rec)
+	require.Len(t, applications, 2)
+
+	byUser := map[string]contract.ApplicationResponse{}
+	for _, application := range applications {
+		byUser[application.User.ID] = application
+	}
+
+	assert.Equal(t, "Pick me", byUser[matching.User.ID].Message)
+	assert.Equal(t, db.ApplicationStatusPending, byUser[matching.User.ID].Status)
+	require.NotNil(t, byUser[matching.User.ID].MatchScore)
+	assert.InDelta(t, 1, *byUser[matching.User.ID].MatchScore, 0.0001)
+
+	assert.Equal(t, db.ApplicationStatusWithdrawn, byUser[other.User.ID].Status)
+	assert.Nil(t, byUser[other.User.ID].MatchScore)
+	assert.NotNil(t, byUser[other.User.ID].UpdatedAt)
+}
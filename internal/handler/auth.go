@@ -26,9 +26,11 @@ const (
 // @Description Authenticate user via Telegram using init data
 // @Tags auth
 // @Param request body contract.AuthTelegramRequest true "Telegram Auth Request"
-// @UserStatus 200 {object} contract.AuthResponse
+// @Success 200 {object} contract.AuthResponse
 // @Failure 400 {object} contract.ErrorResponse
+// @Failure 403 {object} contract.ErrorResponse "User is blocked (code user_blocked)"
 // @Failure 500 {object} contract.ErrorResponse
+// @Failure 429 {object} contract.ErrorResponse "Rate limited (code rate_limited), see Retry-After"
 // @Router /auth/telegram [post]
 func (h *Handler) TelegramAuth(c echo.Context) error {
 	var req contract.AuthTelegramRequest
@@ -105,39 +107,40 @@ func (h *Handler) TelegramAuth(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get user").WithInternal(err)
 	}
 
-	cfCountry := c.Request().Header.Get("CF-IPCountry")
-	cfCity := c.Request().Header.Get("CF-IPCity")
-	ua := c.Request().UserAgent()
+	if user.VerificationStatus == db.VerificationStatusBlocked {
+		return blockedUserError()
+	}
 
-	if err := h.storage.UpdateUserLoginMetadata(c.Request().Context(), user.ID, db.LoginMeta{
-		Country:   cfCountry,
-		City:      cfCity,
-		UserAgent: ua,
-	}); err != nil {
-		h.logger.Warn("failed to update login metadata", slog.String("user_id", user.ID), slog.Any("error", err))
+	meta := db.LoginMeta{
+		IP:        c.RealIP(),
+		Country:   c.Request().Header.Get("CF-IPCountry"),
+		City:      c.Request().Header.Get("CF-IPCity"),
+		UserAgent: c.Request().UserAgent(),
 	}
 
-	token, err := generateJWT(user.ID, user.ChatID, string(user.LanguageCode), h.config.JWTSecret)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "jwt library error").WithInternal(err)
+	if err := h.storage.UpdateUserLoginMetadata(c.Request().Context(), user.ID, meta); err != nil {
+		h.logger.Warn("failed to update login metadata", slog.String("user_id", user.ID), slog.Any("error", err))
 	}
 
-	resp := contract.AuthResponse{
-		Token: token,
-		User:  contract.ToUserResponse(user),
+	h.recordDeepLink(c.Request().Context(), user.ID, data.StartParam)
+
+	resp, err := h.createSession(c.Request().Context(), user, meta)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create session").WithInternal(err)
 	}
 
 	return c.JSON(http.StatusOK, resp)
 }
 
-func generateJWT(userID string, telegramID int64, lang string, secretKey string) (string, error) {
+func generateJWT(userID string, telegramID int64, lang, sessionID string, expiresAt time.Time, secretKey string) (string, error) {
 	claims := &contract.JWTClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 		},
-		UID:    userID,
-		ChatID: telegramID,
-		Lang:   lang,
+		UID:       userID,
+		ChatID:    telegramID,
+		Lang:      lang,
+		SessionID: sessionID,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -2,6 +2,7 @@ package handler_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/peatch-io/peatch/internal/db"
 	"github.com/peatch-io/peatch/internal/handler"
 	"github.com/peatch-io/peatch/internal/testutils"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestTelegramAuth_Success(t *testing.T) {
@@ -68,3 +70,113 @@ func TestTelegramAuth_MissingQuery(t *testing.T) {
 		t.Errorf("Expected error '%s', got '%s'", handler.ErrInvalidRequest, resp.Error)
 	}
 }
+
+func TestRefreshToken_Rotation(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	login, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "mkkksim", "Maksim")
+	if err != nil {
+		t.Fatalf("Failed to authenticate: %v", err)
+	}
+	assert.NotEmpty(t, login.RefreshToken)
+
+	body := fmt.Sprintf(`{"refresh_token": "%s"}`, login.RefreshToken)
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/auth/refresh", body, "", http.StatusOK)
+	refreshed := testutils.ParseResponse[contract.AuthResponse](t, rec)
+
+	assert.NotEmpty(t, refreshed.Token)
+	assert.NotEqual(t, login.RefreshToken, refreshed.RefreshToken)
+	assert.Equal(t, login.User.ID, refreshed.User.ID)
+
+	// The new access token works
+	testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me", "", refreshed.Token, http.StatusOK)
+
+	// Reusing the rotated token revokes the whole session
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/auth/refresh", body, "", http.StatusUnauthorized)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/auth/refresh",
+		fmt.Sprintf(`{"refresh_token": "%s"}`, refreshed.RefreshToken), "", http.StatusUnauthorized)
+}
+
+func TestLogout(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	login, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "mkkksim", "Maksim")
+	if err != nil {
+		t.Fatalf("Failed to authenticate: %v", err)
+	}
+
+	body := fmt.Sprintf(`{"refresh_token": "%s"}`, login.RefreshToken)
+	testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me", "", login.Token, http.StatusOK)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/auth/logout", body, "", http.StatusOK)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/auth/refresh", body, "", http.StatusUnauthorized)
+
+	// The access token dies with its session
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me", "", login.Token, http.StatusUnauthorized)
+	assert.Equal(t, handler.ErrCodeSessionRevoked, testutils.ParseResponse[contract.ErrorResponse](t, rec).Code)
+}
+
+func TestSessions_ListAndRevoke(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	first, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "mkkksim", "Maksim")
+	if err != nil {
+		t.Fatalf("Failed to authenticate: %v", err)
+	}
+	second, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "mkkksim", "Maksim")
+	if err != nil {
+		t.Fatalf("Failed to authenticate: %v", err)
+	}
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me/sessions", "", second.Token, http.StatusOK)
+	sessions := testutils.ParseResponse[[]contract.SessionResponse](t, rec)
+	if !assert.Len(t, sessions, 2) {
+		return
+	}
+
+	var current, other contract.SessionResponse
+	for _, s := range sessions {
+		if s.Current {
+			current = s
+		} else {
+			other = s
+		}
+	}
+	assert.NotEmpty(t, current.ID)
+	assert.NotEmpty(t, other.ID)
+
+	// The revoked session's access token was in use and is refused from now on
+	testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me", "", first.Token, http.StatusOK)
+	testutils.PerformRequest(t, ts.Echo, http.MethodDelete, "/api/users/me/sessions/"+other.ID, "", second.Token, http.StatusOK)
+	testutils.PerformRequest(t, ts.Echo, http.MethodDelete, "/api/users/me/sessions/"+other.ID, "", second.Token, http.StatusNotFound)
+	testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me", "", first.Token, http.StatusUnauthorized)
+	testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me", "", second.Token, http.StatusOK)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/auth/refresh",
+		fmt.Sprintf(`{"refresh_token": "%s"}`, first.RefreshToken), "", http.StatusUnauthorized)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/auth/refresh",
+		fmt.Sprintf(`{"refresh_token": "%s"}`, second.RefreshToken), "", http.StatusOK)
+}
+
+func TestAdminBlock_RevokesSessions(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	adminToken := testutils.AdminAuthHelper(t, ts.Storage, 900001, "moderator")
+
+	login, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "mkkksim", "Maksim")
+	if err != nil {
+		t.Fatalf("Failed to authenticate: %v", err)
+	}
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/admin/users/"+login.User.ID+"/verify",
+		`{"status": "blocked"}`, adminToken, http.StatusOK)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me", "", login.Token, http.StatusUnauthorized)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/auth/refresh",
+		fmt.Sprintf(`{"refresh_token": "%s"}`, login.RefreshToken), "", http.StatusUnauthorized)
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"github.com/labstack/echo/v4"
 	"github.com/peatch-io/peatch/internal/contract"
 	"github.com/peatch-io/peatch/internal/db"
@@ -10,15 +11,17 @@ import (
 
 // handleListBadges godoc
 // @Summary List badges
+// @Description Approved badges plus the caller's own badges still pending review
 // @Tags badges
 // @Accept  json
 // @Produce  json
+// @Param search query string false "Search by text"
 // @Success 200 {array} contract.BadgeResponse
 // @Router /api/badges [get]
 func (h *Handler) handleListBadges(c echo.Context) error {
 	query := c.QueryParam("search")
 
-	badges, err := h.storage.ListBadges(c.Request().Context(), query)
+	badges, err := h.storage.ListBadges(c.Request().Context(), query, getUserID(c))
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get badges").WithInternal(err)
 	}
@@ -26,14 +29,17 @@ func (h *Handler) handleListBadges(c echo.Context) error {
 	return c.JSON(http.StatusOK, contract.ToBadgeResponseList(badges))
 }
 
-// handleGetBadge godoc
-// @Summary Get badge
+// handleCreateBadge godoc
+// @Summary Create badge
+// @Description The badge stays pending, and visible only to its creator, until an admin approves it
 // @Tags badges
 // @Accept  json
 // @Produce  json
-// @Param id path int true "Badge ID"
-// @Success 200 {object} contract.BadgeResponse
-// @Router /api/badges/{id} [get]
+// @Param request body contract.CreateBadgeRequest true "Badge data"
+// @Success 201 {object} contract.BadgeResponse
+// @Failure 409 {object} contract.DuplicateBadgeResponse
+// @Failure 429 {object} contract.ErrorResponse
+// @Router /api/badges [post]
 func (h *Handler) handleCreateBadge(c echo.Context) error {
 	var req contract.CreateBadgeRequest
 	if err := c.Bind(&req); err != nil {
@@ -44,14 +50,30 @@ func (h *Handler) handleCreateBadge(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
 	}
 
+	ctx := c.Request().Context()
+	userID := getUserID(c)
+
+	existing, err := h.storage.FindSimilarBadge(ctx, req.Text, userID)
+	if err == nil {
+		return echo.NewHTTPError(http.StatusConflict, contract.DuplicateBadgeResponse{
+			Error: "a similar badge already exists",
+			Code:  ErrCodeDuplicateBadge,
+			Badge: contract.ToBadgeResponse(existing),
+		})
+	} else if !errors.Is(err, db.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to check badge").WithInternal(err)
+	}
+
 	badge := db.Badge{
-		ID:    nanoid.Must(),
-		Text:  req.Text,
-		Icon:  req.Icon,
-		Color: req.Color,
+		ID:        nanoid.Must(),
+		Text:      req.Text,
+		Icon:      req.Icon,
+		Color:     req.Color,
+		Status:    db.BadgeStatusPending,
+		CreatedBy: &userID,
 	}
 
-	if err := h.storage.CreateBadge(c.Request().Context(), badge); err != nil {
+	if err := h.storage.CreateBadge(ctx, badge); err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create badge").WithInternal(err)
 	}
 
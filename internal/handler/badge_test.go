@@ -3,7 +3,11 @@ package handler_test
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"github.com/peatch-io/peatch/internal/handler"
 	"github.com/peatch-io/peatch/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"net/http"
 	"testing"
 	"time"
@@ -177,3 +181,139 @@ func TestCreateBadge_ValidationError(t *testing.T) {
 		t.Errorf("expected validation error message, got empty")
 	}
 }
+
+func TestCreateBadge_RateLimited(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	authResp, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "user1", "First")
+	if err != nil {
+		t.Fatalf("failed to authenticate: %v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		body := fmt.Sprintf(`{"text": "Badge %d", "icon": "e4b4", "color": "0000ff"}`, i)
+		testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/badges", body, authResp.Token, http.StatusCreated)
+	}
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/badges",
+		`{"text": "One too many", "icon": "e4b4", "color": "0000ff"}`, authResp.Token, http.StatusTooManyRequests)
+
+	resp := testutils.ParseResponse[contract.ErrorResponse](t, rec)
+	if resp.Code != handler.ErrCodeRateLimited {
+		t.Errorf("expected code %s, got %s", handler.ErrCodeRateLimited, resp.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Errorf("expected Retry-After header")
+	}
+}
+
+func TestCreateBadge_PendingUntilApproved(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	creator, err := testutils.AuthHelper(t, ts.Echo, 100001, "creator", "Creator")
+	require.NoError(t, err)
+	other, err := testutils.AuthHelper(t, ts.Echo, 100002, "other", "Other")
+	require.NoError(t, err)
+	adminToken := testutils.AdminAuthHelper(t, ts.Storage, 900001, "moderator")
+
+	_, opps, locationID := setupTestRecords(ts.Storage, t)
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/badges",
+		`{"text": "Sound Design", "icon": "e4b4", "color": "0000ff"}`, creator.Token, http.StatusCreated)
+	created := testutils.ParseResponse[contract.BadgeResponse](t, rec)
+	assert.Equal(t, db.BadgeStatusPending, created.Status)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/badges?search=Sound", "", creator.Token, http.StatusOK)
+	assert.Len(t, testutils.ParseResponse[[]contract.BadgeResponse](t, rec), 1)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/badges?search=Sound", "", other.Token, http.StatusOK)
+	assert.Empty(t, testutils.ParseResponse[[]contract.BadgeResponse](t, rec))
+
+	// Nobody else can pick it either
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/api/users",
+		fmt.Sprintf(`{"name": "Other", "title": "Producer", "description": "Other description", "location_id": "%s", "badge_ids": ["%s"], "opportunity_ids": ["%s"]}`,
+			locationID, created.ID, opps[0]),
+		other.Token, http.StatusOK)
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me", "", other.Token, http.StatusOK)
+	assert.Empty(t, testutils.ParseResponse[contract.UserResponse](t, rec).Badges)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/badges/"+created.ID+"/approve", "", adminToken, http.StatusOK)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/badges?search=Sound", "", other.Token, http.StatusOK)
+	badges := testutils.ParseResponse[[]contract.BadgeResponse](t, rec)
+	require.Len(t, badges, 1)
+	assert.Equal(t, db.BadgeStatusApproved, badges[0].Status)
+}
+
+func TestCreateBadge_NearDuplicate(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	authResp, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "user1", "First")
+	require.NoError(t, err)
+
+	require.NoError(t, ts.Storage.CreateBadge(context.Background(), db.Badge{ID: "frontend", Text: "Frontend Dev", Icon: "e4b4", Color: "0000ff"}))
+	require.NoError(t, ts.Storage.CreateBadge(context.Background(), db.Badge{ID: "uiux", Text: "UI / UX", Icon: "e4b4", Color: "0000ff"}))
+
+	duplicates := map[string]string{
+		"frontend dev":    "frontend",
+		"  FRONTEND-DEV ": "frontend",
+		"Фронтенд Дев":    "frontend",
+		"ui-ux":           "uiux",
+		"UI/UX":           "uiux",
+	}
+	for text, id := range duplicates {
+		rec := testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/badges",
+			fmt.Sprintf(`{"text": %q, "icon": "e4b4", "color": "0000ff"}`, text), authResp.Token, http.StatusConflict)
+
+		resp := testutils.ParseResponse[contract.DuplicateBadgeResponse](t, rec)
+		assert.Equal(t, handler.ErrCodeDuplicateBadge, resp.Code, text)
+		assert.Equal(t, id, resp.Badge.ID, text)
+	}
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/badges",
+		`{"text": "Backend Dev", "icon": "e4b4", "color": "0000ff"}`, authResp.Token, http.StatusCreated)
+}
+
+func TestAdminMergeBadge_RewritesReferences(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	authResp, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "user1", "First")
+	require.NoError(t, err)
+	adminToken := testutils.AdminAuthHelper(t, ts.Storage, 900001, "moderator")
+
+	badges, opps, locationID := setupTestRecords(ts.Storage, t)
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/badges",
+		`{"text": "Test Badge One", "icon": "e4b4", "color": "0000ff"}`, authResp.Token, http.StatusCreated)
+	duplicate := testutils.ParseResponse[contract.BadgeResponse](t, rec)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/api/users",
+		fmt.Sprintf(`{"name": "First", "title": "Developer", "description": "Description", "location_id": "%s", "badge_ids": ["%s", "%s", "%s"], "opportunity_ids": ["%s"]}`,
+			locationID, badges[0], duplicate.ID, badges[1], opps[0]),
+		authResp.Token, http.StatusOK)
+
+	// Pending badges aren't merge targets
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/badges/"+badges[1]+"/merge",
+		fmt.Sprintf(`{"target_id": "%s"}`, duplicate.ID), adminToken, http.StatusConflict)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/badges/"+duplicate.ID+"/merge",
+		fmt.Sprintf(`{"target_id": "%s"}`, badges[0]), adminToken, http.StatusOK)
+	result := testutils.ParseResponse[db.BadgeRewriteResult](t, rec)
+	assert.Equal(t, 1, result.Users)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me", "", authResp.Token, http.StatusOK)
+	user := testutils.ParseResponse[contract.UserResponse](t, rec)
+
+	ids := make([]string, 0, len(user.Badges))
+	for _, badge := range user.Badges {
+		ids = append(ids, badge.ID)
+	}
+	assert.ElementsMatch(t, []string{badges[0], badges[1]}, ids)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/admin/badges?status=pending", "", adminToken, http.StatusOK)
+	assert.Empty(t, testutils.ParseResponse[[]db.Badge](t, rec))
+}
@@ -0,0 +1,398 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/peatch-io/peatch/internal/contract"
+	"github.com/peatch-io/peatch/internal/db"
+	"github.com/peatch-io/peatch/internal/nanoid"
+)
+
+// broadcastBatchSize is how many pending recipients are loaded at a time
+const broadcastBatchSize = 100
+
+func (h *Handler) currentAdmin(c echo.Context) (db.Admin, error) {
+	claims := getAdminClaims(c)
+	if claims == nil {
+		return db.Admin{}, echo.NewHTTPError(http.StatusUnauthorized, "unauthorized: invalid token")
+	}
+
+	admin, err := h.storage.GetAdminByID(c.Request().Context(), claims.AdminID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return db.Admin{}, echo.NewHTTPError(http.StatusNotFound, "admin not found").WithInternal(err)
+		}
+		return db.Admin{}, echo.NewHTTPError(http.StatusInternalServerError, "failed to get admin").WithInternal(err)
+	}
+
+	return admin, nil
+}
+
+func (h *Handler) getBroadcast(c echo.Context) (db.Broadcast, error) {
+	broadcast, err := h.storage.GetBroadcastByID(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return db.Broadcast{}, echo.NewHTTPError(http.StatusNotFound, "broadcast not found")
+		}
+		return db.Broadcast{}, echo.NewHTTPError(http.StatusInternalServerError, "failed to get broadcast").WithInternal(err)
+	}
+
+	return broadcast, nil
+}
+
+// @Summary Preview broadcast audience
+// @Description Count the users a segment would reach
+// @ID admin-preview-broadcast
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body contract.BroadcastSegmentRequest true "Audience segment"
+// @Success 200 {object} contract.BroadcastPreviewResponse
+// @Failure 400 {object} contract.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/broadcasts/preview [post]
+func (h *Handler) handleAdminPreviewBroadcast(c echo.Context) error {
+	var req contract.BroadcastSegmentRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").WithInternal(err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").WithInternal(err)
+	}
+
+	count, err := h.storage.CountBroadcastAudience(c.Request().Context(), req.ToSegment())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to count audience").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, contract.BroadcastPreviewResponse{Recipients: count})
+}
+
+// @Summary Create broadcast
+// @Description Create a draft broadcast for a user segment
+// @ID admin-create-broadcast
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body contract.CreateBroadcastRequest true "Broadcast data"
+// @Success 201 {object} db.Broadcast
+// @Failure 400 {object} contract.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/broadcasts [post]
+func (h *Handler) handleAdminCreateBroadcast(c echo.Context) error {
+	admin, err := h.currentAdmin(c)
+	if err != nil {
+		return err
+	}
+
+	var req contract.CreateBroadcastRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").WithInternal(err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request").WithInternal(err)
+	}
+
+	broadcast := db.Broadcast{
+		ID:           nanoid.Must(),
+		AdminID:      admin.ID,
+		Text:         req.TextEN,
+		TextRU:       req.TextRU,
+		ImageURL:     req.ImageURL,
+		ButtonText:   req.ButtonTextEN,
+		ButtonTextRU: req.ButtonTextRU,
+		ButtonPath:   req.ButtonPath,
+		Segment:      req.Segment.ToSegment(),
+	}
+
+	if err := h.storage.CreateBroadcast(c.Request().Context(), broadcast); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create broadcast").WithInternal(err)
+	}
+
+	created, err := h.storage.GetBroadcastByID(c.Request().Context(), broadcast.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get broadcast").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// @Summary List broadcasts
+// @ID admin-list-broadcasts
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number (default: 1)"
+// @Param per_page query int false "Items per page (default: 20, max: 100)"
+// @Success 200 {array} db.Broadcast
+// @Security ApiKeyAuth
+// @Router /admin/broadcasts [get]
+func (h *Handler) handleAdminListBroadcasts(c echo.Context) error {
+	page := max(parseIntQuery(c, "page", 1), 1)
+	perPage := min(parseIntQuery(c, "per_page", 20), 100)
+
+	broadcasts, err := h.storage.ListBroadcasts(c.Request().Context(), page, perPage)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get broadcasts").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, broadcasts)
+}
+
+// @Summary Get broadcast
+// @Description Get a broadcast with its delivery progress
+// @ID admin-get-broadcast
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Broadcast ID"
+// @Success 200 {object} db.Broadcast
+// @Failure 404 {object} contract.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/broadcasts/{id} [get]
+func (h *Handler) handleAdminGetBroadcast(c echo.Context) error {
+	broadcast, err := h.getBroadcast(c)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, broadcast)
+}
+
+// @Summary Test broadcast
+// @Description Send the broadcast to the requesting admin in every language it has
+// @ID admin-test-broadcast
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Broadcast ID"
+// @Success 200 {object} contract.StatusResponse
+// @Failure 400 {object} contract.ErrorResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/broadcasts/{id}/test [post]
+func (h *Handler) handleAdminTestBroadcast(c echo.Context) error {
+	admin, err := h.currentAdmin(c)
+	if err != nil {
+		return err
+	}
+
+	if admin.ChatID == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "admin has no telegram chat")
+	}
+
+	broadcast, err := h.getBroadcast(c)
+	if err != nil {
+		return err
+	}
+
+	languages := []db.LanguageCode{db.LanguageEN}
+	if broadcast.TextRU != "" {
+		languages = append(languages, db.LanguageRU)
+	}
+
+	for _, lang := range languages {
+		if err := h.notificationService.SendBroadcast(admin.ChatID, lang, broadcast); err != nil {
+			return echo.NewHTTPError(http.StatusBadGateway, "failed to send test message").WithInternal(err)
+		}
+	}
+
+	return c.JSON(http.StatusOK, contract.StatusResponse{Success: true})
+}
+
+// @Summary Send broadcast
+// @Description Start delivering a draft broadcast. Delivery runs in the background; poll the broadcast for progress.
+// @ID admin-send-broadcast
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Broadcast ID"
+// @Success 202 {object} db.Broadcast
+// @Failure 404 {object} contract.ErrorResponse
+// @Failure 409 {object} contract.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/broadcasts/{id}/send [post]
+func (h *Handler) handleAdminSendBroadcast(c echo.Context) error {
+	admin, err := h.currentAdmin(c)
+	if err != nil {
+		return err
+	}
+
+	broadcast, err := h.startBroadcast(c.Request().Context(), admin, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "broadcast not found")
+		} else if errors.Is(err, db.ErrAlreadyExists) {
+			return echo.NewHTTPError(http.StatusConflict, "broadcast is not a draft")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to start broadcast").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusAccepted, broadcast)
+}
+
+// @Summary Cancel broadcast
+// @Description Cancel a draft broadcast
+// @ID admin-cancel-broadcast
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Broadcast ID"
+// @Success 200 {object} contract.StatusResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/broadcasts/{id}/cancel [post]
+func (h *Handler) handleAdminCancelBroadcast(c echo.Context) error {
+	if err := h.storage.CancelBroadcast(c.Request().Context(), c.Param("id")); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "draft broadcast not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to cancel broadcast").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, contract.StatusResponse{Success: true})
+}
+
+// @Summary List broadcast recipients
+// @Description Per-recipient delivery results
+// @ID admin-list-broadcast-recipients
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Broadcast ID"
+// @Param status query string false "Delivery status (pending, sent, failed)"
+// @Param page query int false "Page number (default: 1)"
+// @Param per_page query int false "Items per page (default: 50, max: 500)"
+// @Success 200 {array} db.BroadcastRecipient
+// @Failure 400 {object} contract.ErrorResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/broadcasts/{id}/recipients [get]
+func (h *Handler) handleAdminListBroadcastRecipients(c echo.Context) error {
+	broadcast, err := h.getBroadcast(c)
+	if err != nil {
+		return err
+	}
+
+	status := db.BroadcastRecipientStatus(c.QueryParam("status"))
+	switch status {
+	case "", db.BroadcastRecipientPending, db.BroadcastRecipientSent, db.BroadcastRecipientFailed:
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid delivery status")
+	}
+
+	page := max(parseIntQuery(c, "page", 1), 1)
+	perPage := min(parseIntQuery(c, "per_page", 50), 500)
+
+	recipients, err := h.storage.ListBroadcastRecipients(c.Request().Context(), broadcast.ID, status, page, perPage)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get recipients").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, recipients)
+}
+
+// startBroadcast snapshots the audience and delivers in the background
+func (h *Handler) startBroadcast(ctx context.Context, admin db.Admin, id string) (db.Broadcast, error) {
+	broadcast, err := h.storage.StartBroadcast(ctx, id)
+	if err != nil {
+		return db.Broadcast{}, err
+	}
+
+	go h.runBroadcast(context.Background(), admin, broadcast)
+
+	return broadcast, nil
+}
+
+// ResumeBroadcasts picks up the deliveries a restart cut short. Recipients
+// already sent to are skipped, the rest get the broadcast.
+func (h *Handler) ResumeBroadcasts(ctx context.Context) error {
+	broadcasts, err := h.storage.ListSendingBroadcasts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list sending broadcasts: %w", err)
+	}
+
+	for _, broadcast := range broadcasts {
+		// The report is skipped for admins that were removed meanwhile
+		admin, err := h.storage.GetAdminByID(ctx, broadcast.AdminID)
+		if err != nil && !errors.Is(err, db.ErrNotFound) {
+			return fmt.Errorf("failed to get admin: %w", err)
+		}
+
+		h.logger.Info("resuming broadcast", slog.String("broadcast_id", broadcast.ID))
+
+		go h.runBroadcast(context.Background(), admin, broadcast)
+	}
+
+	return nil
+}
+
+// runBroadcast delivers to every pending recipient, throttled to stay under
+// Telegram's limits, then reports the result to the admin
+func (h *Handler) runBroadcast(ctx context.Context, admin db.Admin, broadcast db.Broadcast) {
+	logger := h.logger.With(slog.String("broadcast_id", broadcast.ID))
+
+	for {
+		recipients, err := h.storage.ListBroadcastRecipients(ctx, broadcast.ID, db.BroadcastRecipientPending, 1, broadcastBatchSize)
+		if err != nil {
+			logger.Error("failed to list pending recipients", slog.String("error", err.Error()))
+			return
+		}
+
+		if len(recipients) == 0 {
+			break
+		}
+
+		for _, r := range recipients {
+			status := db.BroadcastRecipientSent
+			var errMsg *string
+
+			if err := h.notificationService.SendBroadcast(r.ChatID, r.LanguageCode, broadcast); err != nil {
+				status = db.BroadcastRecipientFailed
+				msg := err.Error()
+				errMsg = &msg
+				logger.Info("broadcast delivery failed",
+					slog.String("user_id", r.UserID),
+					slog.String("error", msg))
+			}
+
+			if err := h.storage.UpdateBroadcastRecipient(ctx, broadcast.ID, r.UserID, status, errMsg); err != nil {
+				logger.Error("failed to record delivery",
+					slog.String("user_id", r.UserID),
+					slog.String("error", err.Error()))
+				return
+			}
+
+			time.Sleep(broadcastInterval)
+		}
+	}
+
+	if err := h.storage.FinishBroadcast(ctx, broadcast.ID); err != nil {
+		logger.Error("failed to finish broadcast", slog.String("error", err.Error()))
+		return
+	}
+
+	finished, err := h.storage.GetBroadcastByID(ctx, broadcast.ID)
+	if err != nil {
+		logger.Error("failed to get broadcast", slog.String("error", err.Error()))
+		return
+	}
+
+	logger.Info("broadcast finished",
+		slog.String("admin_id", admin.ID),
+		slog.Int("sent", finished.Sent),
+		slog.Int("failed", finished.Failed))
+
+	if h.adminBot != nil && admin.ChatID != 0 {
+		_ = h.sendAdminMessage(ctx, admin.ChatID,
+			fmt.Sprintf("Broadcast %s finished: %d sent, %d failed", finished.ID, finished.Sent, finished.Failed), nil)
+	}
+}
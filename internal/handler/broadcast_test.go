@@ -0,0 +1,176 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/peatch-io/peatch/internal/contract"
+	"github.com/peatch-io/peatch/internal/db"
+	"github.com/peatch-io/peatch/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminBroadcast_SegmentDelivery(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	adminToken := testutils.AdminAuthHelper(t, ts.Storage, 900001, "moderator")
+
+	verified, err := testutils.AuthHelper(t, ts.Echo, 100001, "verified", "Verified")
+	require.NoError(t, err)
+	_, err = testutils.AuthHelper(t, ts.Echo, 100002, "unverified", "Unverified")
+	require.NoError(t, err)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/admin/users/"+verified.User.ID+"/verify",
+		`{"status": "verified"}`, adminToken, http.StatusOK)
+
+	var mu sync.Mutex
+	delivered := map[int64]db.LanguageCode{}
+	ts.MockNotifier.SendBroadcastFunc = func(chatID int64, lang db.LanguageCode, b db.Broadcast) error {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered[chatID] = lang
+		return nil
+	}
+
+	segment := `{"statuses": ["verified"], "languages": ["ru"]}`
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/broadcasts/preview", segment, adminToken, http.StatusOK)
+	preview := testutils.ParseResponse[contract.BroadcastPreviewResponse](t, rec)
+	assert.Equal(t, 1, preview.Recipients)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/broadcasts",
+		fmt.Sprintf(`{"text_en": "Hello", "text_ru": "Привет", "button_text_en": "Open", "button_path": "/collaborations", "segment": %s}`, segment),
+		adminToken, http.StatusCreated)
+	broadcast := testutils.ParseResponse[db.Broadcast](t, rec)
+	assert.Equal(t, db.BroadcastStatusDraft, broadcast.Status)
+
+	// Test sends go to the admin in both languages and don't touch the draft
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/broadcasts/"+broadcast.ID+"/test", "", adminToken, http.StatusOK)
+	assert.Contains(t, delivered, int64(900001))
+	delete(delivered, 900001)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/broadcasts/"+broadcast.ID+"/send", "", adminToken, http.StatusAccepted)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/broadcasts/"+broadcast.ID+"/send", "", adminToken, http.StatusConflict)
+
+	require.Eventually(t, func() bool {
+		rec := testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/admin/broadcasts/"+broadcast.ID, "", adminToken, http.StatusOK)
+		broadcast = testutils.ParseResponse[db.Broadcast](t, rec)
+		return broadcast.Status == db.BroadcastStatusCompleted
+	}, 5*time.Second, 20*time.Millisecond)
+
+	assert.Equal(t, 1, broadcast.Total)
+	assert.Equal(t, 1, broadcast.Sent)
+	assert.Equal(t, 0, broadcast.Failed)
+
+	mu.Lock()
+	assert.Equal(t, map[int64]db.LanguageCode{100001: db.LanguageRU}, delivered)
+	mu.Unlock()
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/admin/broadcasts/"+broadcast.ID+"/recipients?status=sent", "", adminToken, http.StatusOK)
+	recipients := testutils.ParseResponse[[]db.BroadcastRecipient](t, rec)
+	if assert.Len(t, recipients, 1) {
+		assert.Equal(t, verified.User.ID, recipients[0].UserID)
+		assert.NotNil(t, recipients[0].SentAt)
+	}
+}
+
+func TestAdminBroadcast_ResumesAfterRestart(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	ctx := context.Background()
+	adminToken := testutils.AdminAuthHelper(t, ts.Storage, 900001, "moderator")
+
+	first, err := testutils.AuthHelper(t, ts.Echo, 100001, "first", "First")
+	require.NoError(t, err)
+	_, err = testutils.AuthHelper(t, ts.Echo, 100002, "second", "Second")
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var delivered []int64
+	ts.MockNotifier.SendBroadcastFunc = func(chatID int64, lang db.LanguageCode, b db.Broadcast) error {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, chatID)
+		return nil
+	}
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/broadcasts",
+		`{"text_en": "Hello", "segment": {}}`, adminToken, http.StatusCreated)
+	broadcast := testutils.ParseResponse[db.Broadcast](t, rec)
+
+	// The last instance started sending and went down after the first recipient
+	_, err = ts.Storage.StartBroadcast(ctx, broadcast.ID)
+	require.NoError(t, err)
+	require.NoError(t, ts.Storage.UpdateBroadcastRecipient(ctx, broadcast.ID, first.User.ID, db.BroadcastRecipientSent, nil))
+
+	require.NoError(t, ts.Handler.ResumeBroadcasts(ctx))
+
+	require.Eventually(t, func() bool {
+		rec := testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/admin/broadcasts/"+broadcast.ID, "", adminToken, http.StatusOK)
+		broadcast = testutils.ParseResponse[db.Broadcast](t, rec)
+		return broadcast.Status == db.BroadcastStatusCompleted
+	}, 5*time.Second, 20*time.Millisecond)
+
+	assert.Equal(t, 2, broadcast.Total)
+	assert.Equal(t, 2, broadcast.Sent)
+
+	mu.Lock()
+	assert.Equal(t, []int64{100002}, delivered)
+	mu.Unlock()
+
+	// Finished broadcasts aren't picked up again
+	require.NoError(t, ts.Handler.ResumeBroadcasts(ctx))
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	assert.Len(t, delivered, 1)
+	mu.Unlock()
+}
+
+func TestAdminBroadcast_InvalidRequest(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	adminToken := testutils.AdminAuthHelper(t, ts.Storage, 900001, "moderator")
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/broadcasts",
+		`{"text_ru": "Привет"}`, adminToken, http.StatusBadRequest)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/broadcasts",
+		`{"text_en": "Hello", "button_path": "collaborations", "button_text_en": "Open"}`, adminToken, http.StatusBadRequest)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/broadcasts/preview",
+		`{"statuses": ["nobody"]}`, adminToken, http.StatusBadRequest)
+}
+
+func TestAdminBroadcast_SkipsBlockedAndHiddenUsers(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	ctx := context.Background()
+	adminToken := testutils.AdminAuthHelper(t, ts.Storage, 900001, "moderator")
+
+	ids := map[string]string{}
+	for i, name := range []string{"active", "blocked", "hidden"} {
+		resp, err := testutils.AuthHelper(t, ts.Echo, int64(100001+i), name, name)
+		require.NoError(t, err)
+		require.NoError(t, ts.Storage.UpdateUserVerificationStatus(ctx, resp.User.ID, db.VerificationStatusVerified))
+		ids[name] = resp.User.ID
+	}
+
+	require.NoError(t, ts.Storage.UpdateUserVerificationStatus(ctx, ids["blocked"], db.VerificationStatusBlocked))
+	_, err := ts.Storage.DB().ExecContext(ctx, `UPDATE users SET hidden_at = CURRENT_TIMESTAMP WHERE id = ?`, ids["hidden"])
+	require.NoError(t, err)
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/broadcasts/preview", `{}`, adminToken, http.StatusOK)
+	assert.Equal(t, 1, testutils.ParseResponse[contract.BroadcastPreviewResponse](t, rec).Recipients)
+
+	// Even when the segment asks for them
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/broadcasts/preview", `{"statuses": ["blocked"]}`, adminToken, http.StatusOK)
+	assert.Equal(t, 0, testutils.ParseResponse[contract.BroadcastPreviewResponse](t, rec).Recipients)
+}
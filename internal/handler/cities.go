@@ -1,10 +1,22 @@
 package handler
 
 import (
+	"errors"
 	"github.com/labstack/echo/v4"
 	"github.com/peatch-io/peatch/internal/contract"
+	"github.com/peatch-io/peatch/internal/db"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRadiusKm = 50
+	maxRadiusKm     = 1000
+
+	// sortDistance lists the nearest results first, together with near
+	sortDistance = "distance"
 )
 
 func parseIntQuery(c echo.Context, key string, defaultValue int) int {
@@ -15,11 +27,105 @@ func parseIntQuery(c echo.Context, key string, defaultValue int) int {
 	return value
 }
 
+// parseGeoFilter reads the near=<city_id>&radius_km= filter, nil when near is absent
+func (h *Handler) parseGeoFilter(c echo.Context) (*db.GeoFilter, error) {
+	cityID := c.QueryParam("near")
+	if cityID == "" {
+		if c.QueryParam("radius_km") != "" {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "radius_km requires near")
+		}
+		return nil, nil
+	}
+
+	radius := float64(defaultRadiusKm)
+	if value := c.QueryParam("radius_km"); value != "" {
+		var err error
+		radius, err = strconv.ParseFloat(value, 64)
+		if err != nil || radius <= 0 || radius > maxRadiusKm {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "radius_km must be between 0 and 1000")
+		}
+	}
+
+	city, err := h.storage.GetCityByID(c.Request().Context(), cityID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "unknown city").WithInternal(err)
+		}
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "failed to get city").WithInternal(err)
+	}
+
+	return &db.GeoFilter{
+		Latitude:  city.Latitude,
+		Longitude: city.Longitude,
+		RadiusKm:  radius,
+	}, nil
+}
+
+// parseDistanceSort reports whether sort=distance was asked for, which only
+// makes sense together with near
+func parseDistanceSort(c echo.Context, near *db.GeoFilter) (bool, error) {
+	switch c.QueryParam("sort") {
+	case "":
+		return false, nil
+	case sortDistance:
+		if near == nil {
+			return false, echo.NewHTTPError(http.StatusBadRequest, "sort=distance requires near")
+		}
+		return true, nil
+	default:
+		return false, echo.NewHTTPError(http.StatusBadRequest, "invalid sort")
+	}
+}
+
+// parseCollaborationSort reads the sort of the collaboration feed
+func parseCollaborationSort(c echo.Context, near *db.GeoFilter) (db.CollaborationSort, error) {
+	switch sort := db.CollaborationSort(c.QueryParam("sort")); sort {
+	case "", db.CollaborationSortNewest:
+		return db.CollaborationSortNewest, nil
+	case db.CollaborationSortInterest, db.CollaborationSortMatch:
+		return sort, nil
+	case db.CollaborationSortDistance:
+		if near == nil {
+			return "", echo.NewHTTPError(http.StatusBadRequest, "sort=distance requires near")
+		}
+		return sort, nil
+	default:
+		return "", echo.NewHTTPError(http.StatusBadRequest, "invalid sort")
+	}
+}
+
+// parseListQuery reads a list given either as repeated parameters or comma
+// separated, e.g. ids=a,b&ids=c
+func parseListQuery(c echo.Context, key string) []string {
+	var values []string
+	for _, param := range c.QueryParams()[key] {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// parseTimeQuery accepts a full RFC 3339 timestamp or a bare date, taken as
+// midnight UTC
+func parseTimeQuery(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
 // handleSearchLocations godoc
 // @Summary List cities
+// @Description Cities matching the name or an alternate name, most populated first
 // @Tags cities
 // @Accept  json
 // @Produce  json
+// @Param search query string false "City name, in English or Russian"
+// @Param limit query int false "Page size"
+// @Param page query int false "Page number"
 // @Success 200 {array} contract.CityResponse
 // @Router /api/locations [get]
 func (h *Handler) handleSearchLocations(c echo.Context) error {
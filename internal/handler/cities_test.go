@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func insertTestLocations(storage *db.Storage, t *testing.T) {
@@ -55,3 +56,118 @@ func TestSearchCities(t *testing.T) {
 	assert.Equal(t, "2", resp[0].ID)
 	assert.Equal(t, "CityB", resp[0].Name)
 }
+
+func TestSearchCities_AlternateNamesByPopulation(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	auth, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "tester", "Tester")
+	if err != nil {
+		t.Fatalf("Failed to authenticate: %v", err)
+	}
+
+	stats, err := ts.Storage.UpsertCities(context.Background(), []db.CityRecord{
+		{
+			City:           db.City{ID: "524901", Name: "Moscow", CountryCode: "RU", CountryName: "Russia", Population: 10381222},
+			AlternateNames: []string{"Moskva", "Москва"},
+		},
+		{
+			City:           db.City{ID: "4601692", Name: "Moscow", CountryCode: "US", CountryName: "United States", Population: 25435},
+			AlternateNames: []string{"Moscow Idaho"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to import cities: %v", err)
+	}
+	assert.Equal(t, db.CityImportStats{Inserted: 2}, stats)
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/locations?search=%D0%BC%D0%BE%D1%81%D0%BA%D0%B2%D0%B0", "", auth.Token, http.StatusOK)
+	resp := testutils.ParseResponse[[]contract.CityResponse](t, rec)
+	assert.Len(t, resp, 1, "expected 'москва' to match the alternate name")
+	assert.Equal(t, "524901", resp[0].ID)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/locations?search=moscow", "", auth.Token, http.StatusOK)
+	resp = testutils.ParseResponse[[]contract.CityResponse](t, rec)
+	assert.Len(t, resp, 2)
+	assert.Equal(t, "524901", resp[0].ID, "expected the most populated city first")
+
+	stats, err = ts.Storage.UpsertCities(context.Background(), []db.CityRecord{
+		{
+			City:           db.City{ID: "524901", Name: "Moscow", CountryCode: "RU", CountryName: "Russia", Population: 10381222},
+			AlternateNames: []string{"Moskva", "Москва"},
+		},
+		{
+			City:           db.City{ID: "4601692", Name: "Moscow", CountryCode: "US", CountryName: "United States", Population: 25500},
+			AlternateNames: []string{"Moscow Idaho"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to import cities: %v", err)
+	}
+	assert.Equal(t, db.CityImportStats{Updated: 1, Unchanged: 1}, stats)
+}
+
+func TestUpsertCities_RefreshesLocations(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	ctx := context.Background()
+
+	viewer, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "viewer", "Viewer")
+	require.NoError(t, err)
+
+	badges, opps, _ := setupTestRecords(ts.Storage, t)
+	createGeoCities(t, ts.Storage)
+
+	require.NoError(t, ts.Storage.CreateUser(ctx, db.UpdateUserParams{
+		User: db.User{
+			ID:                 "user-spb",
+			ChatID:             200,
+			Username:           "spb",
+			Name:               strPtr("User in spb"),
+			Title:              strPtr("Developer"),
+			Description:        strPtr("Description"),
+			VerificationStatus: db.VerificationStatusVerified,
+		},
+		BadgeIDs:       badges[:1],
+		OpportunityIDs: opps[:1],
+		LocationID:     "spb",
+	}))
+	require.NoError(t, ts.Storage.CreateCollaboration(ctx, db.CreateCollaborationParams{
+		Collaboration: db.Collaboration{ID: "collab-spb", UserID: viewer.User.ID, Title: "Collab in spb", Description: "Description"},
+		BadgeIDs:      badges[:1],
+		OpportunityID: opps[0],
+		LocationID:    strPtr("spb"),
+	}))
+
+	// A city moved by the import takes its users and collaborations along
+	stats, err := ts.Storage.UpsertCities(ctx, []db.CityRecord{
+		{City: db.City{ID: "spb", Name: "Saint-Petersburg", CountryCode: "RU", CountryName: "Russia", Latitude: 55.7, Longitude: 37.6}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, db.CityImportStats{Updated: 1, Users: 1, Collaborations: 1}, stats)
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users?near=moscow", "", viewer.Token, http.StatusOK)
+	users := testutils.ParseResponse[[]contract.UserProfileResponse](t, rec)
+	require.Len(t, users, 1)
+	assert.Equal(t, "user-spb", users[0].ID)
+	require.NotNil(t, users[0].Location)
+	assert.Equal(t, "Saint-Petersburg", users[0].Location.Name)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations?near=moscow", "", viewer.Token, http.StatusOK)
+	collabs := testutils.ParseResponse[[]contract.CollaborationResponse](t, rec)
+	require.Len(t, collabs, 1)
+	assert.Equal(t, "collab-spb", collabs[0].ID)
+	require.NotNil(t, collabs[0].Location)
+	assert.Equal(t, "Saint-Petersburg", collabs[0].Location.Name)
+
+	// New alternate names alone don't touch the snapshots
+	stats, err = ts.Storage.UpsertCities(ctx, []db.CityRecord{
+		{
+			City:           db.City{ID: "spb", Name: "Saint-Petersburg", CountryCode: "RU", CountryName: "Russia", Latitude: 55.7, Longitude: 37.6},
+			AlternateNames: []string{"Санкт-Петербург"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, db.CityImportStats{Updated: 1}, stats)
+}
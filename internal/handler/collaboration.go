@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/labstack/echo/v4"
 	"github.com/peatch-io/peatch/internal/contract"
 	"github.com/peatch-io/peatch/internal/db"
@@ -11,9 +12,17 @@ import (
 	"log"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 )
 
+// duplicateLookback is how far back collaborations of other authors are
+// compared with a new one
+const duplicateLookback = 30 * 24 * time.Hour
+
+// DenialReasonDuplicate is the catalogue code reposts are denied with
+const DenialReasonDuplicate = "duplicate"
+
 // handleListCollaborations godoc
 // @Summary List collaborations
 // @Tags collaborations
@@ -21,8 +30,23 @@ import (
 // @Produce  json
 // @Param page query int false "Page"
 // @Param limit query int false "Limit"
-// @Param order query string false "Order by"
+// @Param search query string false "Text in the title or description"
+// @Param opportunity_ids query []string false "Looking for any of these opportunities" collectionFormat(csv)
+// @Param badge_ids query []string false "Tagged with these badges" collectionFormat(csv)
+// @Param badge_match query string false "Whether any or all of badge_ids must match, any by default" Enums(any, all)
+// @Param country_code query string false "ISO 3166-1 alpha-2 country code of the location"
+// @Param city_id query string false "City ID of the location"
+// @Param payable query bool false "Only paid collaborations"
+// @Param compensation_types query []string false "Compensated in any of these ways" collectionFormat(csv) Enums(paid, equity, revshare, volunteer, barter)
+// @Param min_amount query number false "Paying at least this much by the top of the range, requires currency"
+// @Param currency query string false "ISO 4217 currency of min_amount"
+// @Param created_after query string false "Created at or after, RFC 3339 or YYYY-MM-DD"
+// @Param status query []string false "Lifecycle statuses, open by default" collectionFormat(csv) Enums(open, in_progress, filled, closed, expired)
+// @Param near query string false "City ID to search around"
+// @Param radius_km query number false "Search radius around near, 50 km by default"
+// @Param sort query string false "Sort order, newest by default. match ranks by badges and opportunities shared with the viewer, distance requires near" Enums(newest, interest, match, distance)
 // @Success 200 {array} contract.CollaborationResponse
+// @Failure 400 {object} contract.ErrorResponse
 // @Router /api/collaborations [get]
 func (h *Handler) handleListCollaborations(c echo.Context) error {
 	page := parseIntQuery(c, "page", 1)
@@ -30,11 +54,85 @@ func (h *Handler) handleListCollaborations(c echo.Context) error {
 	search := c.QueryParam("search")
 	uid := getUserID(c)
 
+	near, err := h.parseGeoFilter(c)
+	if err != nil {
+		return err
+	}
+
+	sort, err := parseCollaborationSort(c, near)
+	if err != nil {
+		return err
+	}
+
 	query := db.CollaborationQuery{
-		Page:     page,
-		Limit:    limit,
-		Search:   search,
-		ViewerID: uid,
+		Page:           page,
+		Limit:          limit,
+		Search:         search,
+		ViewerID:       uid,
+		Near:           near,
+		OpportunityIDs: parseListQuery(c, "opportunity_ids"),
+		BadgeIDs:       parseListQuery(c, "badge_ids"),
+		CountryCode:    c.QueryParam("country_code"),
+		CityID:         c.QueryParam("city_id"),
+		Sort:           sort,
+	}
+
+	for _, status := range parseListQuery(c, "status") {
+		switch status := db.CollaborationStatus(status); status {
+		case db.CollaborationStatusOpen, db.CollaborationStatusInProgress, db.CollaborationStatusFilled,
+			db.CollaborationStatusClosed, db.CollaborationStatusExpired:
+			query.Statuses = append(query.Statuses, status)
+		default:
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid status")
+		}
+	}
+
+	switch c.QueryParam("badge_match") {
+	case "", "any":
+	case "all":
+		query.MatchAllBadges = true
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "badge_match must be any or all")
+	}
+
+	if query.CountryCode != "" && len(query.CountryCode) != 2 {
+		return echo.NewHTTPError(http.StatusBadRequest, "country_code must be a two-letter code")
+	}
+
+	if value := c.QueryParam("payable"); value != "" {
+		payable, err := strconv.ParseBool(value)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "payable must be a boolean").WithInternal(err)
+		}
+		query.PayableOnly = payable
+	}
+
+	for _, t := range parseListQuery(c, "compensation_types") {
+		compensationType := db.CompensationType(t)
+		if !db.IsValidCompensationType(compensationType) {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid compensation type")
+		}
+		query.CompensationTypes = append(query.CompensationTypes, compensationType)
+	}
+
+	if value := c.QueryParam("min_amount"); value != "" {
+		minAmount, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "min_amount must be a number").WithInternal(err)
+		}
+		query.Currency = c.QueryParam("currency")
+		if len(query.Currency) != 3 {
+			return echo.NewHTTPError(http.StatusBadRequest, "min_amount requires a three-letter currency")
+		}
+		query.MinAmount = &minAmount
+	}
+
+	if value := c.QueryParam("created_after"); value != "" {
+		createdAfter, err := parseTimeQuery(value)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "created_after must be RFC 3339 or YYYY-MM-DD").WithInternal(err)
+		}
+		query.CreatedAfter = &createdAfter
 	}
 
 	collaborations, err := h.storage.ListCollaborations(c.Request().Context(), query)
@@ -71,6 +169,13 @@ func (h *Handler) handleGetCollaboration(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get collaboration").WithInternal(err)
 	}
 
+	h.recordEvent(c.Request().Context(), db.AnalyticsEvent{
+		Kind:     db.AnalyticsEventView,
+		Target:   db.AnalyticsTargetCollaboration,
+		TargetID: collaboration.ID,
+		ViewerID: uid,
+	})
+
 	return c.JSON(http.StatusOK, contract.ToCollaborationResponse(collaboration))
 }
 
@@ -81,6 +186,7 @@ func (h *Handler) handleGetCollaboration(c echo.Context) error {
 // @Produce  json
 // @Param collaboration body contract.CreateCollaboration true "Collaboration data"
 // @Success 201 {object} contract.CollaborationResponse
+// @Failure 429 {object} contract.ErrorResponse "Rate limited (code rate_limited), see Retry-After"
 // @Router /api/collaborations [post]
 func (h *Handler) handleCreateCollaboration(c echo.Context) error {
 	var req contract.CreateCollaboration
@@ -94,15 +200,18 @@ func (h *Handler) handleCreateCollaboration(c echo.Context) error {
 
 	uid := getUserID(c)
 	now := time.Now()
+	compensation := req.ToCompensation()
 
 	collaboration := db.Collaboration{
-		ID:          nanoid.Must(),
-		UserID:      uid,
-		Title:       req.Title,
-		Description: req.Description,
-		IsPayable:   req.IsPayable,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		ID:           nanoid.Must(),
+		UserID:       uid,
+		Title:        req.Title,
+		Description:  req.Description,
+		IsPayable:    compensation.IsPaid(),
+		Compensation: compensation,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		ExpiresAt:    req.ExpiresAt,
 	}
 
 	params := db.CreateCollaborationParams{
@@ -124,33 +233,100 @@ func (h *Handler) handleCreateCollaboration(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusNotFound, "collaboration not found")
 	}
 
-	// notify the user about the new collaboration
-	go func() {
-		if err := h.notificationService.NotifyNewPendingCollaboration(res); err != nil {
-			h.logger.Error("failed to send collaboration created notification", "error", err)
-		}
-	}()
-
-	go generateCollaborationEmbedding(h, res)
+	go h.reviewNewCollaboration(res)
 
 	return c.JSON(http.StatusCreated, contract.ToCollaborationResponse(res))
 }
 
-func generateCollaborationEmbedding(h *Handler, collab db.Collaboration) {
+// generateCollaborationEmbedding stores the embedding of a collaboration and
+// returns the near-duplicates among the author's collaborations and everyone's
+// recent ones
+func generateCollaborationEmbedding(h *Handler, collab db.Collaboration) []db.SimilarCollaboration {
 	ctx := context.Background()
 
 	embeddingVector, err := h.embeddingService.GenerateEmbedding(ctx, collab.ToString())
 	if err != nil {
 		log.Printf("failed to generate embedding for collaboration %s: %v", collab.ID, err)
-		return
+		return nil
 	}
 
 	if err := h.storage.UpdateCollaborationEmbedding(ctx, collab.ID, embeddingVector); err != nil {
-		log.Printf("failed to update user embedding: %v", err)
-		return
+		log.Printf("failed to update collaboration embedding: %v", err)
+		return nil
 	}
 
-	return
+	duplicates, err := h.storage.FindSimilarCollaborations(ctx, collab.ID, time.Now().Add(-duplicateLookback), db.DuplicateSimilarity, 5)
+	if err != nil {
+		log.Printf("failed to find duplicates of collaboration %s: %v", collab.ID, err)
+		return nil
+	}
+
+	return duplicates
+}
+
+// reviewNewCollaboration sends a new collaboration to the admins with the
+// posts it looks copied from. Reposts of the author's own collaborations
+// within the repost window are denied right away instead.
+func (h *Handler) reviewNewCollaboration(collab db.Collaboration) {
+	duplicates := generateCollaborationEmbedding(h, collab)
+
+	if original, ok := h.findRepost(collab, duplicates); ok {
+		if err := h.denyRepost(collab, original); err != nil {
+			h.logger.Error("failed to deny repost",
+				slog.String("collaboration_id", collab.ID),
+				slog.String("error", err.Error()))
+		} else {
+			return
+		}
+	}
+
+	if err := h.notificationService.NotifyNewPendingCollaboration(collab, duplicates); err != nil {
+		h.logger.Error("failed to send collaboration created notification", "error", err)
+	}
+}
+
+// findRepost returns the author's own collaboration the new one repeats, if
+// it was posted within the repost window
+func (h *Handler) findRepost(collab db.Collaboration, duplicates []db.SimilarCollaboration) (db.SimilarCollaboration, bool) {
+	if h.config.RepostWindow <= 0 {
+		return db.SimilarCollaboration{}, false
+	}
+
+	for _, dup := range duplicates {
+		if dup.UserID == collab.UserID && collab.CreatedAt.Sub(dup.CreatedAt) < h.config.RepostWindow {
+			return dup, true
+		}
+	}
+
+	return db.SimilarCollaboration{}, false
+}
+
+// denyRepost denies a repost with the duplicate reason when the catalogue has
+// one, and tells the author which collaboration it repeats
+func (h *Handler) denyRepost(collab db.Collaboration, original db.SimilarCollaboration) error {
+	ctx := context.Background()
+
+	var reason *db.DenialReason
+	if r, err := h.storage.GetDenialReasonByCode(ctx, DenialReasonDuplicate); err == nil {
+		reason = &r
+	} else if !errors.Is(err, db.ErrNotFound) {
+		return err
+	}
+
+	comment := fmt.Sprintf("Repost of %q", original.Title)
+
+	if err := h.storage.UpdateCollaborationVerificationStatus(ctx, collab.ID, db.VerificationStatusDenied); err != nil {
+		return err
+	}
+	if err := h.storage.UpdateCollaborationDenialReason(ctx, collab.ID, reason, &comment); err != nil {
+		return err
+	}
+
+	collab.VerificationStatus = db.VerificationStatusDenied
+	collab.DenialReason = reason
+	collab.DenialComment = &comment
+
+	return h.notificationService.NotifyCollaborationVerificationDenied(collab)
 }
 
 // handleUpdateCollaboration godoc
@@ -174,12 +350,15 @@ func (h *Handler) handleUpdateCollaboration(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
 	}
 
+	compensation := req.ToCompensation()
+
 	collab := db.Collaboration{
-		ID:          cid,
-		UserID:      uid,
-		Title:       req.Title,
-		Description: req.Description,
-		IsPayable:   req.IsPayable,
+		ID:           cid,
+		UserID:       uid,
+		Title:        req.Title,
+		Description:  req.Description,
+		IsPayable:    compensation.IsPaid(),
+		Compensation: compensation,
 	}
 
 	params := db.CreateCollaborationParams{
@@ -192,7 +371,9 @@ func (h *Handler) handleUpdateCollaboration(c echo.Context) error {
 	if err := h.storage.UpdateCollaboration(
 		c.Request().Context(),
 		params,
-	); err != nil {
+	); errors.Is(err, db.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "collaboration not found")
+	} else if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "update failed").WithInternal(err)
 	}
 
@@ -208,14 +389,92 @@ func (h *Handler) handleUpdateCollaboration(c echo.Context) error {
 	return c.JSON(http.StatusOK, contract.ToCollaborationResponse(collaboration))
 }
 
+// handleCloseCollaboration godoc
+// @Summary Close collaboration
+// @Description Takes the collaboration out of the feed, as in progress, filled or closed
+// @Tags collaborations
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Collaboration ID"
+// @Param request body contract.CloseCollaborationRequest false "Status, closed by default"
+// @Success 200 {object} contract.CollaborationResponse
+// @Router /api/collaborations/{id}/close [post]
+func (h *Handler) handleCloseCollaboration(c echo.Context) error {
+	cid := c.Param("id")
+	uid := getUserID(c)
+
+	var req contract.CloseCollaborationRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
+	}
+
+	if err := h.storage.CloseCollaboration(c.Request().Context(), uid, cid, req.TargetStatus()); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "collaboration not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to close collaboration").WithInternal(err)
+	}
+
+	return h.respondWithOwnCollaboration(c, uid, cid)
+}
+
+// handleReopenCollaboration godoc
+// @Summary Reopen collaboration
+// @Description Puts a closed or expired collaboration back in the feed. An expiry that has passed is dropped unless a new one is given.
+// @Tags collaborations
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Collaboration ID"
+// @Param request body contract.ReopenCollaborationRequest false "New expiry"
+// @Success 200 {object} contract.CollaborationResponse
+// @Router /api/collaborations/{id}/reopen [post]
+func (h *Handler) handleReopenCollaboration(c echo.Context) error {
+	cid := c.Param("id")
+	uid := getUserID(c)
+
+	var req contract.ReopenCollaborationRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
+	}
+
+	if err := h.storage.ReopenCollaboration(c.Request().Context(), uid, cid, req.ExpiresAt); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "collaboration not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to reopen collaboration").WithInternal(err)
+	}
+
+	return h.respondWithOwnCollaboration(c, uid, cid)
+}
+
+func (h *Handler) respondWithOwnCollaboration(c echo.Context, uid, cid string) error {
+	collaboration, err := h.storage.GetCollaborationByID(c.Request().Context(), uid, cid)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get collaboration").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, contract.ToCollaborationResponse(collaboration))
+}
+
 // handleExpressInterest godoc
-// @Summary Express interest in a collaboration
+// @Summary Apply to a collaboration
+// @Description Sends an application with an optional pitch to the owner
 // @Tags collaborations
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Collaboration ID"
-// @Success 204
+// @Param request body contract.ApplyRequest false "Pitch"
+// @Success 200 {object} contract.StatusResponse
 // @Success 200 {object} contract.BotBlockedResponse "When user has blocked the bot, returns username for direct Telegram navigation"
+// @Failure 429 {object} contract.ErrorResponse "Rate limited (code rate_limited), see Retry-After"
 // @Router /api/collaborations/{id}/interest [post]
 func (h *Handler) handleExpressInterest(c echo.Context) error {
 	collabID := c.Param("id")
@@ -225,16 +484,17 @@ func (h *Handler) handleExpressInterest(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "user id is required")
 	}
 
-	if exist, err := h.storage.HasExpressedInterest(c.Request().Context(), userID, collabID); err != nil || exist {
-		return echo.NewHTTPError(http.StatusBadRequest, "already expressed interest").WithInternal(err)
+	var req contract.ApplyRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
 	}
 
-	var botBlockedError bool
-	var collaborationOwnerUsername string
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
+	}
 
-	user, err := h.storage.GetUserByID(c.Request().Context(), userID)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get user").WithInternal(err)
+	if exist, err := h.storage.HasExpressedInterest(c.Request().Context(), userID, collabID); err != nil || exist {
+		return echo.NewHTTPError(http.StatusBadRequest, "already expressed interest").WithInternal(err)
 	}
 
 	collab, err := h.storage.GetCollaborationByID(c.Request().Context(), userID, collabID)
@@ -244,37 +504,55 @@ func (h *Handler) handleExpressInterest(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get collaboration").WithInternal(err)
 	}
 
-	if collab.UserID == userID {
+	if collab.MemberRole(userID) != "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "cannot express interest in your own collaboration")
 	}
 
-	if err := h.notificationService.NotifyCollabInterest(collab, user); err != nil {
-		h.logger.Error("failed to send collaboration interest notification", "error", err)
+	if collab.Status != db.CollaborationStatusOpen {
+		return echo.NewHTTPError(http.StatusBadRequest, "collaboration is not open")
+	}
+
+	application, err := h.storage.CreateApplication(c.Request().Context(), db.Application{
+		CollaborationID: collabID,
+		UserID:          userID,
+		Message:         req.Message,
+		Links:           req.ToLinks(),
+	})
+	if errors.Is(err, db.ErrAlreadyExists) {
+		return echo.NewHTTPError(http.StatusBadRequest, "already expressed interest")
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to express interest").WithInternal(err)
+	}
 
-		if errors.Is(err, notification.ErrUserBlockedBot) {
-			botBlockedError = true
+	h.recordEvent(c.Request().Context(), db.AnalyticsEvent{
+		Kind:     db.AnalyticsEventInterest,
+		Target:   db.AnalyticsTargetCollaboration,
+		TargetID: collabID,
+		ViewerID: userID,
+	})
+
+	// Everyone on the team hears about it, the applicant is only pointed to
+	// Telegram when the creator can't be reached
+	creatorBlocked := false
+	for _, member := range collab.Team {
+		if err := h.notificationService.NotifyCollabInterest(member.User, collab, application); err != nil {
+			h.logger.Error("failed to send collaboration interest notification", "error", err, "user_id", member.User.ID)
+
+			if member.User.ID == collab.UserID && errors.Is(err, notification.ErrUserBlockedBot) {
+				creatorBlocked = true
+			}
 		}
 	}
 
-	if botBlockedError {
+	if creatorBlocked {
 		resp := contract.BotBlockedResponse{
 			Status:   "bot_blocked",
-			Username: collaborationOwnerUsername,
+			Username: collab.User.Username,
 			Message:  "User has blocked the bot, direct Telegram contact required",
 		}
 		return c.JSON(http.StatusOK, resp)
 	}
 
-	expirationDuration := 7 * 24 * time.Hour // 1 week expiration
-	if err := h.storage.ExpressInterest(
-		c.Request().Context(),
-		collabID,
-		userID,
-		expirationDuration,
-	); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "failed to express interest").WithInternal(err)
-	}
-
 	return c.JSON(http.StatusOK, contract.StatusResponse{Success: true})
 }
 
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/peatch-io/peatch/internal/contract"
+	"github.com/peatch-io/peatch/internal/db"
+)
+
+// handleSetCollaborationMember godoc
+// @Summary Set collaboration member
+// @Description Changes the role of a team member, or invites a verified user to join the team with it. Invited users join once they accept. Owners can edit, close and manage the team, editors can only edit. Only owners can call this.
+// @Tags collaborations
+// @Accept json
+// @Produce json
+// @Param id path string true "Collaboration ID"
+// @Param user_id path string true "User ID"
+// @Param request body contract.CollaborationMemberRequest true "Role"
+// @Success 200 {object} contract.CollaborationResponse
+// @Failure 400 {object} contract.ErrorResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Router /api/collaborations/{id}/members/{user_id} [put]
+func (h *Handler) handleSetCollaborationMember(c echo.Context) error {
+	cid := c.Param("id")
+	uid := getUserID(c)
+
+	var req contract.CollaborationMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
+	}
+
+	memberID := c.Param("user_id")
+
+	invited, err := h.storage.SetCollaborationMember(c.Request().Context(), uid, cid, memberID, req.Role)
+	if err != nil {
+		return collaborationMemberError(err)
+	}
+
+	if invited {
+		h.notifyCollaborationInvite(c, cid, memberID, req.Role)
+	}
+
+	return h.respondWithOwnCollaboration(c, uid, cid)
+}
+
+func (h *Handler) notifyCollaborationInvite(c echo.Context, cid, inviteeID string, role db.CollaborationRole) {
+	ctx := c.Request().Context()
+
+	invitee, err := h.storage.GetUserByID(ctx, inviteeID)
+	if err != nil {
+		h.logger.Error("failed to get invitee", "error", err, "user_id", inviteeID)
+		return
+	}
+
+	collab, err := h.storage.GetCollaborationByID(ctx, getUserID(c), cid)
+	if err != nil {
+		h.logger.Error("failed to get collaboration", "error", err, "collaboration_id", cid)
+		return
+	}
+
+	if err := h.notificationService.NotifyCollaborationInvite(invitee, collab, role); err != nil {
+		h.logger.Error("failed to send collaboration invite notification", "error", err, "user_id", inviteeID)
+	}
+}
+
+// handleRemoveCollaborationMember godoc
+// @Summary Remove collaboration member
+// @Description Owners can remove anyone but the creator and withdraw invitations, other members can remove themselves to leave the team
+// @Tags collaborations
+// @Produce json
+// @Param id path string true "Collaboration ID"
+// @Param user_id path string true "User ID"
+// @Success 200 {object} contract.StatusResponse
+// @Failure 400 {object} contract.ErrorResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Router /api/collaborations/{id}/members/{user_id} [delete]
+func (h *Handler) handleRemoveCollaborationMember(c echo.Context) error {
+	err := h.storage.RemoveCollaborationMember(c.Request().Context(), getUserID(c), c.Param("id"), c.Param("user_id"))
+	if err != nil {
+		return collaborationMemberError(err)
+	}
+
+	return c.JSON(http.StatusOK, contract.StatusResponse{Success: true})
+}
+
+// handleListCollaborationInvites godoc
+// @Summary List team invitations
+// @Description Invitations to join the team of a collaboration, newest first
+// @Tags collaborations
+// @Produce json
+// @Success 200 {array} contract.CollaborationInviteResponse
+// @Router /api/users/me/invites [get]
+func (h *Handler) handleListCollaborationInvites(c echo.Context) error {
+	invites, err := h.storage.ListCollaborationInvites(c.Request().Context(), getUserID(c))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get invites").WithInternal(err)
+	}
+
+	resp := make([]contract.CollaborationInviteResponse, len(invites))
+	for i, invite := range invites {
+		resp[i] = contract.ToCollaborationInviteResponse(invite)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// handleAcceptCollaborationInvite godoc
+// @Summary Accept team invitation
+// @Description Joins the team with the role of the invitation
+// @Tags collaborations
+// @Produce json
+// @Param id path string true "Collaboration ID"
+// @Success 200 {object} contract.CollaborationResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Router /api/collaborations/{id}/invite/accept [post]
+func (h *Handler) handleAcceptCollaborationInvite(c echo.Context) error {
+	cid := c.Param("id")
+	uid := getUserID(c)
+
+	if err := h.storage.AcceptCollaborationInvite(c.Request().Context(), uid, cid); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "invite not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to accept invite").WithInternal(err)
+	}
+
+	return h.respondWithOwnCollaboration(c, uid, cid)
+}
+
+// handleDeclineCollaborationInvite godoc
+// @Summary Decline team invitation
+// @Tags collaborations
+// @Produce json
+// @Param id path string true "Collaboration ID"
+// @Success 200 {object} contract.StatusResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Router /api/collaborations/{id}/invite [delete]
+func (h *Handler) handleDeclineCollaborationInvite(c echo.Context) error {
+	if err := h.storage.DeclineCollaborationInvite(c.Request().Context(), getUserID(c), c.Param("id")); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "invite not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to decline invite").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, contract.StatusResponse{Success: true})
+}
+
+func collaborationMemberError(err error) error {
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "collaboration or member not found")
+	case errors.Is(err, db.ErrCollaborationCreator):
+		return echo.NewHTTPError(http.StatusBadRequest, "the creator of a collaboration stays an owner")
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update team").WithInternal(err)
+	}
+}
@@ -0,0 +1,191 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/peatch-io/peatch/internal/contract"
+	"github.com/peatch-io/peatch/internal/db"
+	"github.com/peatch-io/peatch/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollaborationTeam(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	ctx := context.Background()
+
+	owner, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "owner", "Owner")
+	require.NoError(t, err)
+	editor, err := testutils.AuthHelper(t, ts.Echo, 100001, "editor", "Editor")
+	require.NoError(t, err)
+	outsider, err := testutils.AuthHelper(t, ts.Echo, 100002, "outsider", "Outsider")
+	require.NoError(t, err)
+	applicant, err := testutils.AuthHelper(t, ts.Echo, 100003, "applicant", "Applicant")
+	require.NoError(t, err)
+	require.NoError(t, ts.Storage.UpdateUserVerificationStatus(ctx, applicant.User.ID, db.VerificationStatusVerified))
+
+	badges, opps, _ := setupTestRecords(ts.Storage, t)
+	require.NoError(t, ts.Storage.CreateCollaboration(ctx, db.CreateCollaborationParams{
+		Collaboration: db.Collaboration{ID: "collab-1", UserID: owner.User.ID, Title: "Startup", Description: "Description"},
+		BadgeIDs:      badges,
+		OpportunityID: opps[0],
+	}))
+
+	memberURL := "/api/collaborations/collab-1/members/" + editor.User.ID
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, memberURL, `{"role": "editor"}`, outsider.Token, http.StatusNotFound)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, memberURL, `{"role": "admin"}`, owner.Token, http.StatusBadRequest)
+
+	// Only verified users can be invited, and they join once they accept
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, memberURL, `{"role": "editor"}`, owner.Token, http.StatusNotFound)
+	require.NoError(t, ts.Storage.UpdateUserVerificationStatus(ctx, editor.User.ID, db.VerificationStatusVerified))
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodPut, memberURL, `{"role": "editor"}`, owner.Token, http.StatusOK)
+	assert.Len(t, testutils.ParseResponse[contract.CollaborationResponse](t, rec).Team, 1)
+	assert.Equal(t, []string{editor.User.ID}, ts.MockNotifier.CollabInviteRecipients)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me/invites", "", editor.Token, http.StatusOK)
+	invites := testutils.ParseResponse[[]contract.CollaborationInviteResponse](t, rec)
+	require.Len(t, invites, 1)
+	assert.Equal(t, "collab-1", invites[0].CollaborationID)
+	assert.Equal(t, db.CollaborationRoleEditor, invites[0].Role)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/invite/accept", "", outsider.Token, http.StatusNotFound)
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/invite/accept", "", editor.Token, http.StatusOK)
+	team := testutils.ParseResponse[contract.CollaborationResponse](t, rec).Team
+	require.Len(t, team, 2)
+	assert.Equal(t, owner.User.ID, team[0].User.ID)
+	assert.Equal(t, db.CollaborationRoleOwner, team[0].Role)
+	assert.Equal(t, editor.User.ID, team[1].User.ID)
+	assert.Equal(t, db.CollaborationRoleEditor, team[1].Role)
+
+	// The creator can't be demoted or removed
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/api/collaborations/collab-1/members/"+owner.User.ID,
+		`{"role": "editor"}`, owner.Token, http.StatusBadRequest)
+	testutils.PerformRequest(t, ts.Echo, http.MethodDelete, "/api/collaborations/collab-1/members/"+owner.User.ID,
+		"", owner.Token, http.StatusBadRequest)
+
+	// Editors see the pending collaboration and can edit it, but not close it or manage the team
+	testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations/collab-1", "", editor.Token, http.StatusOK)
+	update := `{"title": "Startup, edited", "description": "Description", "badge_ids": ["badge1"], "opportunity_id": "opp1"}`
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/api/collaborations/collab-1", update, editor.Token, http.StatusOK)
+	assert.Equal(t, "Startup, edited", testutils.ParseResponse[contract.CollaborationResponse](t, rec).Title)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/api/collaborations/collab-1", update, outsider.Token, http.StatusNotFound)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/close", "", editor.Token, http.StatusNotFound)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/api/collaborations/collab-1/members/"+outsider.User.ID,
+		`{"role": "editor"}`, editor.Token, http.StatusNotFound)
+
+	// Everyone on the team is told about applications and can see them
+	require.NoError(t, ts.Storage.UpdateCollaborationVerificationStatus(ctx, "collab-1", db.VerificationStatusVerified))
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/interest", `{"message": "Hi"}`, editor.Token, http.StatusBadRequest)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/interest", `{"message": "Hi"}`, applicant.Token, http.StatusOK)
+	assert.ElementsMatch(t, []string{owner.User.ID, editor.User.ID}, ts.MockNotifier.CollabInterestRecipients)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations/collab-1/interests", "", editor.Token, http.StatusOK)
+	assert.Len(t, testutils.ParseResponse[[]contract.ApplicationResponse](t, rec), 1)
+
+	// Members can leave
+	testutils.PerformRequest(t, ts.Echo, http.MethodDelete, memberURL, "", editor.Token, http.StatusOK)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/api/collaborations/collab-1", update, editor.Token, http.StatusNotFound)
+	testutils.PerformRequest(t, ts.Echo, http.MethodDelete, memberURL, "", owner.Token, http.StatusNotFound)
+}
+
+func TestCollaborationTeam_EditorKeepsCreatorPendingBadges(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	ctx := context.Background()
+
+	owner, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "owner", "Owner")
+	require.NoError(t, err)
+	editor, err := testutils.AuthHelper(t, ts.Echo, 100001, "editor", "Editor")
+	require.NoError(t, err)
+
+	badges, opps, _ := setupTestRecords(ts.Storage, t)
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/badges",
+		`{"text": "Synth Wave", "icon": "e4b4", "color": "0000ff"}`, owner.Token, http.StatusCreated)
+	pending := testutils.ParseResponse[contract.BadgeResponse](t, rec)
+
+	require.NoError(t, ts.Storage.CreateCollaboration(ctx, db.CreateCollaborationParams{
+		Collaboration: db.Collaboration{ID: "collab-1", UserID: owner.User.ID, Title: "Startup", Description: "Description"},
+		BadgeIDs:      []string{badges[0], pending.ID},
+		OpportunityID: opps[0],
+	}))
+	require.NoError(t, ts.Storage.UpdateUserVerificationStatus(ctx, editor.User.ID, db.VerificationStatusVerified))
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/api/collaborations/collab-1/members/"+editor.User.ID,
+		`{"role": "editor"}`, owner.Token, http.StatusOK)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/invite/accept", "", editor.Token, http.StatusOK)
+
+	update := `{"title": "Startup, edited", "description": "Description", "badge_ids": ["` + badges[0] + `", "` + pending.ID + `"], "opportunity_id": "opp1"}`
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/api/collaborations/collab-1", update, editor.Token, http.StatusOK)
+	collab := testutils.ParseResponse[contract.CollaborationResponse](t, rec)
+
+	ids := make([]string, 0, len(collab.Badges))
+	for _, badge := range collab.Badges {
+		ids = append(ids, badge.ID)
+	}
+	assert.ElementsMatch(t, []string{badges[0], pending.ID}, ids)
+}
+
+func TestCollaborationTeam_Invites(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	ctx := context.Background()
+
+	owner, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "owner", "Owner")
+	require.NoError(t, err)
+	invitee, err := testutils.AuthHelper(t, ts.Echo, 100001, "invitee", "Invitee")
+	require.NoError(t, err)
+	blocked, err := testutils.AuthHelper(t, ts.Echo, 100002, "blocked", "Blocked")
+	require.NoError(t, err)
+	hidden, err := testutils.AuthHelper(t, ts.Echo, 100003, "hidden", "Hidden")
+	require.NoError(t, err)
+	applicant, err := testutils.AuthHelper(t, ts.Echo, 100004, "applicant", "Applicant")
+	require.NoError(t, err)
+
+	for _, id := range []string{invitee.User.ID, hidden.User.ID, applicant.User.ID} {
+		require.NoError(t, ts.Storage.UpdateUserVerificationStatus(ctx, id, db.VerificationStatusVerified))
+	}
+	require.NoError(t, ts.Storage.UpdateUserVerificationStatus(ctx, blocked.User.ID, db.VerificationStatusBlocked))
+	_, err = ts.Storage.DB().ExecContext(ctx, `UPDATE users SET hidden_at = CURRENT_TIMESTAMP WHERE id = ?`, hidden.User.ID)
+	require.NoError(t, err)
+
+	badges, opps, _ := setupTestRecords(ts.Storage, t)
+	require.NoError(t, ts.Storage.CreateCollaboration(ctx, db.CreateCollaborationParams{
+		Collaboration: db.Collaboration{ID: "collab-1", UserID: owner.User.ID, Title: "Startup", Description: "Description"},
+		BadgeIDs:      badges,
+		OpportunityID: opps[0],
+	}))
+	require.NoError(t, ts.Storage.UpdateCollaborationVerificationStatus(ctx, "collab-1", db.VerificationStatusVerified))
+
+	// Blocked, hidden and unknown users can't be invited
+	for _, id := range []string{blocked.User.ID, hidden.User.ID, "missing"} {
+		testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/api/collaborations/collab-1/members/"+id,
+			`{"role": "editor"}`, owner.Token, http.StatusNotFound)
+	}
+	assert.Empty(t, ts.MockNotifier.CollabInviteRecipients)
+
+	memberURL := "/api/collaborations/collab-1/members/" + invitee.User.ID
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, memberURL, `{"role": "owner"}`, owner.Token, http.StatusOK)
+
+	// Until they accept, invitees aren't on the team
+	update := `{"title": "Startup, edited", "description": "Description", "badge_ids": ["badge1"], "opportunity_id": "opp1"}`
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/api/collaborations/collab-1", update, invitee.Token, http.StatusNotFound)
+	testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations/collab-1/interests", "", invitee.Token, http.StatusNotFound)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/interest", `{"message": "Hi"}`, applicant.Token, http.StatusOK)
+	assert.Equal(t, []string{owner.User.ID}, ts.MockNotifier.CollabInterestRecipients)
+
+	// Declining drops the invitation, owners can withdraw one too
+	testutils.PerformRequest(t, ts.Echo, http.MethodDelete, "/api/collaborations/collab-1/invite", "", invitee.Token, http.StatusOK)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/invite/accept", "", invitee.Token, http.StatusNotFound)
+	testutils.PerformRequest(t, ts.Echo, http.MethodDelete, "/api/collaborations/collab-1/invite", "", invitee.Token, http.StatusNotFound)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, memberURL, `{"role": "editor"}`, owner.Token, http.StatusOK)
+	testutils.PerformRequest(t, ts.Echo, http.MethodDelete, memberURL, "", owner.Token, http.StatusOK)
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me/invites", "", invitee.Token, http.StatusOK)
+	assert.Empty(t, testutils.ParseResponse[[]contract.CollaborationInviteResponse](t, rec))
+}
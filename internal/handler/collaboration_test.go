@@ -7,8 +7,11 @@ import (
 	"github.com/peatch-io/peatch/internal/contract"
 	"github.com/peatch-io/peatch/internal/db"
 	"github.com/peatch-io/peatch/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"net/http"
 	"testing"
+	"time"
 )
 
 func setupTestRecords(storage *db.Storage, t *testing.T) ([]string, []string, string) {
@@ -477,3 +480,372 @@ func TestExpressInterest_OwnCollaboration(t *testing.T) {
 		t.Errorf("expected error message, got empty")
 	}
 }
+
+func TestListCollaborations_NearCity(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	authResp, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "user1", "First")
+	require.NoError(t, err)
+
+	badges, opps, _ := setupTestRecords(ts.Storage, t)
+	createGeoCities(t, ts.Storage)
+
+	for _, locationID := range []string{"spb", "tver", "podolsk"} {
+		require.NoError(t, ts.Storage.CreateCollaboration(context.Background(), db.CreateCollaborationParams{
+			Collaboration: db.Collaboration{ID: "collab-" + locationID, UserID: authResp.User.ID, Title: "Collab in " + locationID, Description: "Description"},
+			BadgeIDs:      badges[:1],
+			OpportunityID: opps[0],
+			LocationID:    strPtr(locationID),
+		}))
+	}
+	require.NoError(t, ts.Storage.CreateCollaboration(context.Background(), db.CreateCollaborationParams{
+		Collaboration: db.Collaboration{ID: "collab-remote", UserID: authResp.User.ID, Title: "Remote", Description: "Description"},
+		BadgeIDs:      badges[:1],
+		OpportunityID: opps[0],
+	}))
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations?near=moscow&radius_km=700&sort=distance", "", authResp.Token, http.StatusOK)
+	collabs := testutils.ParseResponse[[]contract.CollaborationResponse](t, rec)
+	require.Len(t, collabs, 3)
+	assert.Equal(t, []string{"collab-podolsk", "collab-tver", "collab-spb"}, []string{collabs[0].ID, collabs[1].ID, collabs[2].ID})
+	require.NotNil(t, collabs[2].DistanceKm)
+	assert.InDelta(t, 634, *collabs[2].DistanceKm, 5)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations?near=moscow&radius_km=200", "", authResp.Token, http.StatusOK)
+	assert.Len(t, testutils.ParseResponse[[]contract.CollaborationResponse](t, rec), 2)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations", "", authResp.Token, http.StatusOK)
+	collabs = testutils.ParseResponse[[]contract.CollaborationResponse](t, rec)
+	assert.Len(t, collabs, 4)
+	assert.Nil(t, collabs[0].DistanceKm)
+}
+
+func TestListCollaborations_Filters(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	authResp, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "user1", "First")
+	require.NoError(t, err)
+	other, err := testutils.AuthHelper(t, ts.Echo, 100001, "other", "Other")
+	require.NoError(t, err)
+
+	badges, opps, loc := setupTestRecords(ts.Storage, t)
+	createGeoCities(t, ts.Storage)
+
+	ctx := context.Background()
+	for _, params := range []db.CreateCollaborationParams{
+		{
+			Collaboration: db.Collaboration{ID: "collab-a", UserID: authResp.User.ID, Title: "A", Description: "Description", IsPayable: true},
+			BadgeIDs:      badges[:1],
+			OpportunityID: opps[0],
+			LocationID:    strPtr(loc),
+		},
+		{
+			Collaboration: db.Collaboration{ID: "collab-b", UserID: authResp.User.ID, Title: "B", Description: "Description"},
+			BadgeIDs:      badges,
+			OpportunityID: opps[1],
+		},
+		{
+			Collaboration: db.Collaboration{ID: "collab-c", UserID: authResp.User.ID, Title: "C", Description: "Description"},
+			BadgeIDs:      badges[1:],
+			OpportunityID: opps[0],
+			LocationID:    strPtr("spb"),
+		},
+	} {
+		require.NoError(t, ts.Storage.CreateCollaboration(ctx, params))
+	}
+
+	_, err = ts.Storage.DB().Exec(`UPDATE collaborations SET created_at = ? WHERE id = 'collab-c'`, time.Now().AddDate(0, 0, -10))
+	require.NoError(t, err)
+
+	expires := time.Now().Add(time.Hour)
+	for i, interest := range [][2]string{
+		{authResp.User.ID, "collab-c"},
+		{other.User.ID, "collab-c"},
+		{other.User.ID, "collab-a"},
+	} {
+		_, err = ts.Storage.DB().Exec(`INSERT INTO collaboration_interests (id, user_id, collaboration_id, expires_at) VALUES (?, ?, ?, ?)`,
+			fmt.Sprintf("interest-%d", i), interest[0], interest[1], expires)
+		require.NoError(t, err)
+	}
+
+	list := func(query string) []string {
+		rec := testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations?"+query, "", authResp.Token, http.StatusOK)
+		collabs := testutils.ParseResponse[[]contract.CollaborationResponse](t, rec)
+		ids := make([]string, len(collabs))
+		for i, collab := range collabs {
+			ids[i] = collab.ID
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"collab-b"}, list("opportunity_ids=opp2"))
+	assert.ElementsMatch(t, []string{"collab-a", "collab-b", "collab-c"}, list("badge_ids=badge1,badge2"))
+	assert.Equal(t, []string{"collab-b"}, list("badge_ids=badge1&badge_ids=badge2&badge_match=all"))
+	assert.Equal(t, []string{"collab-a"}, list("country_code=tc"))
+	assert.Equal(t, []string{"collab-c"}, list("city_id=spb"))
+	assert.Equal(t, []string{"collab-a"}, list("payable=true"))
+	assert.ElementsMatch(t, []string{"collab-a", "collab-b"}, list("created_after="+time.Now().AddDate(0, 0, -5).Format(time.DateOnly)))
+	assert.Equal(t, []string{"collab-c", "collab-a", "collab-b"}, list("sort=interest"))
+
+	// The viewer offers opp2 and has badge2
+	reqBody, _ := json.Marshal(contract.UpdateUserRequest{
+		Name:           "First",
+		Title:          "Musician",
+		Description:    "Description",
+		BadgeIDs:       badges[1:],
+		OpportunityIDs: opps[1:],
+		LocationID:     loc,
+	})
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/api/users", string(reqBody), authResp.Token, http.StatusOK)
+	assert.Equal(t, []string{"collab-b", "collab-c", "collab-a"}, list("sort=match"))
+
+	for _, query := range []string{"badge_match=some", "sort=popular", "sort=distance", "created_after=yesterday", "payable=maybe", "country_code=RUS"} {
+		testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations?"+query, "", authResp.Token, http.StatusBadRequest)
+	}
+}
+
+func TestCloseAndReopenCollaboration(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	owner, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "owner", "Owner")
+	require.NoError(t, err)
+	other, err := testutils.AuthHelper(t, ts.Echo, 100001, "other", "Other")
+	require.NoError(t, err)
+
+	badges, opps, _ := setupTestRecords(ts.Storage, t)
+
+	ctx := context.Background()
+	require.NoError(t, ts.Storage.CreateCollaboration(ctx, db.CreateCollaborationParams{
+		Collaboration: db.Collaboration{ID: "collab-1", UserID: owner.User.ID, Title: "Band", Description: "Description"},
+		BadgeIDs:      badges,
+		OpportunityID: opps[0],
+	}))
+	require.NoError(t, ts.Storage.UpdateCollaborationVerificationStatus(ctx, "collab-1", db.VerificationStatusVerified))
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/close", `{"status":"open"}`, owner.Token, http.StatusBadRequest)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/close", "", other.Token, http.StatusNotFound)
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/close", `{"status":"filled"}`, owner.Token, http.StatusOK)
+	assert.Equal(t, db.CollaborationStatusFilled, testutils.ParseResponse[contract.CollaborationResponse](t, rec).Status)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations", "", other.Token, http.StatusOK)
+	assert.Empty(t, testutils.ParseResponse[[]contract.CollaborationResponse](t, rec))
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations?status=filled,closed", "", other.Token, http.StatusOK)
+	assert.Len(t, testutils.ParseResponse[[]contract.CollaborationResponse](t, rec), 1)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/interest", "", other.Token, http.StatusBadRequest)
+
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/reopen", `{"expires_at":"`+past+`"}`, owner.Token, http.StatusBadRequest)
+
+	expiresAt := time.Now().Add(10 * 24 * time.Hour).Truncate(time.Second)
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/reopen", `{"expires_at":"`+expiresAt.Format(time.RFC3339)+`"}`, owner.Token, http.StatusOK)
+	reopened := testutils.ParseResponse[contract.CollaborationResponse](t, rec)
+	assert.Equal(t, db.CollaborationStatusOpen, reopened.Status)
+	require.NotNil(t, reopened.ExpiresAt)
+	assert.True(t, expiresAt.Equal(*reopened.ExpiresAt))
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations", "", other.Token, http.StatusOK)
+	assert.Len(t, testutils.ParseResponse[[]contract.CollaborationResponse](t, rec), 1)
+}
+
+func TestCollaborationExpiry(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	owner, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "owner", "Owner")
+	require.NoError(t, err)
+
+	badges, opps, _ := setupTestRecords(ts.Storage, t)
+
+	reqBody, _ := json.Marshal(contract.CreateCollaboration{
+		OpportunityID: opps[0],
+		Title:         "Band",
+		Description:   "Description",
+		BadgeIDs:      badges,
+		ExpiresAt:     func() *time.Time { past := time.Now().Add(-time.Hour); return &past }(),
+	})
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations", string(reqBody), owner.Token, http.StatusBadRequest)
+
+	ctx := context.Background()
+	expiresAt := time.Now().Add(24 * time.Hour)
+	require.NoError(t, ts.Storage.CreateCollaboration(ctx, db.CreateCollaborationParams{
+		Collaboration: db.Collaboration{ID: "collab-1", UserID: owner.User.ID, Title: "Band", Description: "Description", ExpiresAt: &expiresAt},
+		BadgeIDs:      badges,
+		OpportunityID: opps[0],
+	}))
+
+	expiring, err := ts.Storage.ListCollaborationsExpiringBefore(ctx, time.Now().Add(3*24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, expiring, 1)
+	assert.Equal(t, int64(testutils.TelegramTestUserID), expiring[0].User.ChatID)
+
+	require.NoError(t, ts.Storage.MarkCollaborationExpiryReminded(ctx, "collab-1"))
+	expiring, err = ts.Storage.ListCollaborationsExpiringBefore(ctx, time.Now().Add(3*24*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, expiring)
+
+	expired, err := ts.Storage.ExpireCollaborations(ctx, time.Now().Add(48*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), expired)
+
+	collab, err := ts.Storage.GetCollaborationByID(ctx, owner.User.ID, "collab-1")
+	require.NoError(t, err)
+	assert.Equal(t, db.CollaborationStatusExpired, collab.Status)
+
+	newExpiresAt, err := ts.Storage.ExtendCollaboration(ctx, owner.User.ID, "collab-1", db.CollaborationExtension)
+	require.NoError(t, err)
+	assert.WithinDuration(t, expiresAt.Add(db.CollaborationExtension), newExpiresAt, time.Minute)
+
+	collab, err = ts.Storage.GetCollaborationByID(ctx, owner.User.ID, "collab-1")
+	require.NoError(t, err)
+	assert.Equal(t, db.CollaborationStatusOpen, collab.Status)
+
+	require.NoError(t, ts.Storage.CloseCollaboration(ctx, owner.User.ID, "collab-1", db.CollaborationStatusClosed))
+	_, err = ts.Storage.ExtendCollaboration(ctx, owner.User.ID, "collab-1", db.CollaborationExtension)
+	assert.ErrorIs(t, err, db.ErrNotFound)
+}
+
+func TestCollaborationExpiry_NonUTCOffsets(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	owner, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "owner", "Owner")
+	require.NoError(t, err)
+
+	badges, opps, _ := setupTestRecords(ts.Storage, t)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	// Two hours from now, written west of UTC, reads earlier than now as text
+	west := now.Add(2 * time.Hour).In(time.FixedZone("UTC-12", -12*60*60))
+	reqBody, _ := json.Marshal(contract.CreateCollaboration{
+		OpportunityID: opps[0],
+		Title:         "Band",
+		Description:   "Description",
+		BadgeIDs:      badges,
+		ExpiresAt:     &west,
+	})
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations", string(reqBody), owner.Token, http.StatusCreated)
+	collabID := testutils.ParseResponse[contract.CollaborationResponse](t, rec).ID
+
+	expiring, err := ts.Storage.ListCollaborationsExpiringBefore(ctx, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, expiring)
+
+	expired, err := ts.Storage.ExpireCollaborations(ctx, now)
+	require.NoError(t, err)
+	assert.Zero(t, expired)
+
+	expired, err = ts.Storage.ExpireCollaborations(ctx, now.Add(3*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), expired)
+
+	// Two hours from now, written east of UTC, reads later than three hours from now
+	east := now.Add(2 * time.Hour).In(time.FixedZone("UTC+14", 14*60*60)).Truncate(time.Second)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/"+collabID+"/reopen",
+		`{"expires_at":"`+east.Format(time.RFC3339)+`"}`, owner.Token, http.StatusOK)
+
+	expired, err = ts.Storage.ExpireCollaborations(ctx, now.Add(3*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), expired)
+}
+
+func TestCollaborationCompensation(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	ctx := context.Background()
+
+	authResp, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "user1", "First")
+	require.NoError(t, err)
+
+	badges, opps, _ := setupTestRecords(ts.Storage, t)
+
+	for _, compensation := range []string{
+		`{"type": "salary"}`,
+		`{"type": "volunteer", "min_amount": 100, "currency": "EUR"}`,
+		`{"type": "paid", "min_amount": 1000, "max_amount": 500, "currency": "EUR"}`,
+		`{"type": "paid", "min_amount": 500}`,
+		`{"type": "paid", "min_amount": 500, "currency": "EUR", "period": "fortnight"}`,
+	} {
+		body := `{"opportunity_id": "opp1", "title": "Title", "description": "Description", "badge_ids": ["badge1"], "compensation": ` + compensation + `}`
+		testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations", body, authResp.Token, http.StatusBadRequest)
+	}
+
+	for _, params := range []db.CreateCollaborationParams{
+		{
+			Collaboration: db.Collaboration{ID: "collab-paid", UserID: authResp.User.ID, Title: "Paid", Description: "Description",
+				Compensation: &db.Compensation{Type: db.CompensationPaid, MinAmount: floatPtr(500), MaxAmount: floatPtr(1000), Currency: "EUR", Period: db.CompensationPerMonth}},
+			BadgeIDs:      badges,
+			OpportunityID: opps[0],
+		},
+		{
+			Collaboration: db.Collaboration{ID: "collab-equity", UserID: authResp.User.ID, Title: "Equity", Description: "Description",
+				IsPayable: true, Compensation: &db.Compensation{Type: db.CompensationEquity}},
+			BadgeIDs:      badges,
+			OpportunityID: opps[0],
+		},
+		{
+			Collaboration: db.Collaboration{ID: "collab-legacy", UserID: authResp.User.ID, Title: "Legacy", Description: "Description"},
+			BadgeIDs:      badges,
+			OpportunityID: opps[0],
+		},
+	} {
+		require.NoError(t, ts.Storage.CreateCollaboration(ctx, params))
+	}
+
+	// Collaborations from before compensation only have is_payable
+	_, err = ts.Storage.DB().Exec(`UPDATE collaborations SET is_payable = 1 WHERE id = 'collab-legacy'`)
+	require.NoError(t, err)
+	require.NoError(t, ts.Storage.InitSchema())
+
+	get := func(id string) contract.CollaborationResponse {
+		rec := testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations/"+id, "", authResp.Token, http.StatusOK)
+		return testutils.ParseResponse[contract.CollaborationResponse](t, rec)
+	}
+
+	paid := get("collab-paid")
+	require.NotNil(t, paid.Compensation)
+	assert.True(t, paid.IsPayable)
+	assert.Equal(t, db.CompensationPerMonth, paid.Compensation.Period)
+
+	equity := get("collab-equity")
+	assert.False(t, equity.IsPayable)
+	assert.Equal(t, db.CompensationEquity, equity.Compensation.Type)
+
+	legacy := get("collab-legacy")
+	require.NotNil(t, legacy.Compensation)
+	assert.Equal(t, db.CompensationPaid, legacy.Compensation.Type)
+
+	collab, err := ts.Storage.GetCollaborationByID(ctx, authResp.User.ID, "collab-paid")
+	require.NoError(t, err)
+	assert.Contains(t, collab.ToString(), "Compensation: Paid, 500-1000 EUR / month")
+
+	list := func(query string) []string {
+		rec := testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations?"+query, "", authResp.Token, http.StatusOK)
+		var ids []string
+		for _, collab := range testutils.ParseResponse[[]contract.CollaborationResponse](t, rec) {
+			ids = append(ids, collab.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"collab-equity"}, list("compensation_types=equity"))
+	assert.ElementsMatch(t, []string{"collab-paid", "collab-legacy"}, list("payable=true"))
+	assert.Equal(t, []string{"collab-paid"}, list("min_amount=800&currency=eur"))
+	assert.Empty(t, list("min_amount=1500&currency=EUR"))
+
+	for _, query := range []string{"compensation_types=salary", "min_amount=800", "min_amount=lots&currency=EUR"} {
+		testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations?"+query, "", authResp.Token, http.StatusBadRequest)
+	}
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
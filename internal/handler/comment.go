@@ -0,0 +1,312 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/peatch-io/peatch/internal/contract"
+	"github.com/peatch-io/peatch/internal/db"
+)
+
+// handleListComments godoc
+// @Summary List collaboration comments
+// @Description Questions and answers on a collaboration, pinned first and then oldest first, each with its replies. Comments of blocked or hidden users are left out.
+// @Tags comments
+// @Produce json
+// @Param id path string true "Collaboration ID"
+// @Param page query int false "Page"
+// @Param limit query int false "Limit"
+// @Success 200 {array} contract.CommentResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Router /api/collaborations/{id}/comments [get]
+func (h *Handler) handleListComments(c echo.Context) error {
+	page := parseIntQuery(c, "page", 1)
+	limit := parseIntQuery(c, "limit", 20)
+
+	comments, err := h.storage.ListComments(c.Request().Context(), getUserID(c), c.Param("id"), page, limit)
+	if errors.Is(err, db.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "collaboration not found")
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get comments").WithInternal(err)
+	}
+
+	resp := make([]contract.CommentResponse, len(comments))
+	for i, comment := range comments {
+		resp[i] = contract.ToCommentResponse(comment)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// handleCreateComment godoc
+// @Summary Comment on collaboration
+// @Description Asks a question, or replies in a thread with parent_id. The collaboration team is notified, and so is the author of the thread on a reply.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Collaboration ID"
+// @Param request body contract.CommentRequest true "Comment"
+// @Success 201 {object} contract.CommentResponse
+// @Failure 400 {object} contract.ErrorResponse
+// @Failure 404 {object} contract.ErrorResponse "Collaboration or parent comment not found"
+// @Router /api/collaborations/{id}/comments [post]
+func (h *Handler) handleCreateComment(c echo.Context) error {
+	var req contract.CommentRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
+	}
+
+	comment, err := h.storage.CreateComment(c.Request().Context(), db.Comment{
+		CollaborationID: c.Param("id"),
+		ParentID:        req.ParentID,
+		UserID:          getUserID(c),
+		Body:            req.Body,
+	})
+	if errors.Is(err, db.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "collaboration not found")
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create comment").WithInternal(err)
+	}
+
+	go h.notifyNewComment(comment)
+
+	return c.JSON(http.StatusCreated, contract.ToCommentResponse(comment))
+}
+
+// notifyNewComment tells the collaboration team about a comment, and the
+// author of the thread about a reply, leaving out whoever wrote it
+func (h *Handler) notifyNewComment(comment db.Comment) {
+	ctx := context.Background()
+
+	collab, err := h.storage.GetCollaborationByID(ctx, comment.UserID, comment.CollaborationID)
+	if err != nil {
+		h.logger.Error("failed to get collaboration for comment notification",
+			slog.String("comment_id", comment.ID),
+			slog.String("error", err.Error()))
+		return
+	}
+
+	recipients := make([]db.User, 0, len(collab.Team)+1)
+	for _, member := range collab.Team {
+		recipients = append(recipients, member.User)
+	}
+
+	if comment.ParentID != nil {
+		parent, err := h.storage.GetComment(ctx, *comment.ParentID)
+		if err != nil {
+			h.logger.Error("failed to get parent comment", slog.String("error", err.Error()))
+		} else if collab.MemberRole(parent.UserID) == "" {
+			recipients = append(recipients, parent.User)
+		}
+	}
+
+	for _, recipient := range recipients {
+		if recipient.ID == comment.UserID {
+			continue
+		}
+		if err := h.notificationService.NotifyNewComment(recipient, collab, comment); err != nil {
+			h.logger.Error("failed to send comment notification",
+				slog.String("user_id", recipient.ID),
+				slog.String("error", err.Error()))
+		}
+	}
+}
+
+// handleUpdateComment godoc
+// @Summary Edit comment
+// @Description Authors can edit their own comments until deleted
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Param request body contract.UpdateCommentRequest true "Comment"
+// @Success 200 {object} contract.CommentResponse
+// @Failure 400 {object} contract.ErrorResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Router /api/comments/{id} [put]
+func (h *Handler) handleUpdateComment(c echo.Context) error {
+	var req contract.UpdateCommentRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
+	}
+
+	comment, err := h.storage.UpdateComment(c.Request().Context(), getUserID(c), c.Param("id"), req.Body)
+	if errors.Is(err, db.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "comment not found")
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update comment").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, contract.ToCommentResponse(comment))
+}
+
+// handleDeleteComment godoc
+// @Summary Delete comment
+// @Description Authors can delete their comments, collaboration owners any comment on it
+// @Tags comments
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Success 200 {object} contract.StatusResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Router /api/comments/{id} [delete]
+func (h *Handler) handleDeleteComment(c echo.Context) error {
+	err := h.storage.DeleteComment(c.Request().Context(), getUserID(c), c.Param("id"))
+	if errors.Is(err, db.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "comment not found")
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to delete comment").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, contract.StatusResponse{Success: true})
+}
+
+// handlePinComment godoc
+// @Summary Pin comment
+// @Description Collaboration owners pin questions with their answers to the top of the thread list
+// @Tags comments
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Success 200 {object} contract.CommentResponse
+// @Failure 404 {object} contract.ErrorResponse "Comment not found, a reply or not owned"
+// @Router /api/comments/{id}/pin [post]
+func (h *Handler) handlePinComment(c echo.Context) error {
+	return h.pinComment(c, true)
+}
+
+// handleUnpinComment godoc
+// @Summary Unpin comment
+// @Tags comments
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Success 200 {object} contract.CommentResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Router /api/comments/{id}/pin [delete]
+func (h *Handler) handleUnpinComment(c echo.Context) error {
+	return h.pinComment(c, false)
+}
+
+func (h *Handler) pinComment(c echo.Context, pinned bool) error {
+	comment, err := h.storage.PinComment(c.Request().Context(), getUserID(c), c.Param("id"), pinned)
+	if errors.Is(err, db.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "comment not found")
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to pin comment").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, contract.ToCommentResponse(comment))
+}
+
+// handleReportComment godoc
+// @Summary Report comment
+// @Description Flags a comment to the moderators. Reporting twice counts once, and a comment with enough reports is hidden until reviewed.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Param request body contract.ReportCommentRequest false "Reason"
+// @Success 200 {object} contract.StatusResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Router /api/comments/{id}/report [post]
+func (h *Handler) handleReportComment(c echo.Context) error {
+	var req contract.ReportCommentRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
+	}
+
+	comment, reported, err := h.storage.ReportComment(c.Request().Context(), getUserID(c), c.Param("id"), req.Reason)
+	if errors.Is(err, db.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "comment not found")
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to report comment").WithInternal(err)
+	}
+
+	if reported {
+		go func() {
+			if err := h.notificationService.NotifyCommentReported(comment); err != nil {
+				h.logger.Error("failed to send comment report notification",
+					slog.String("comment_id", comment.ID),
+					slog.String("error", err.Error()))
+			}
+		}()
+	}
+
+	return c.JSON(http.StatusOK, contract.StatusResponse{Success: true})
+}
+
+// handleAdminListReportedComments lists reported comments, most reported first
+// @ID admin-list-reported-comments
+// @Tags admin
+// @Produce json
+// @Param page query int false "Page"
+// @Param limit query int false "Limit"
+// @Success 200 {array} contract.AdminCommentResponse
+// @Security ApiKeyAuth
+// @Router /admin/comments/reported [get]
+func (h *Handler) handleAdminListReportedComments(c echo.Context) error {
+	page := parseIntQuery(c, "page", 1)
+	limit := parseIntQuery(c, "limit", 20)
+
+	comments, err := h.storage.ListReportedComments(c.Request().Context(), page, limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get comments").WithInternal(err)
+	}
+
+	resp := make([]contract.AdminCommentResponse, len(comments))
+	for i, comment := range comments {
+		resp[i] = contract.ToAdminCommentResponse(comment)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// handleAdminHideComment takes a comment down and clears it from the queue
+// @ID admin-hide-comment
+// @Tags admin
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Success 200 {object} contract.StatusResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/comments/{id}/hide [post]
+func (h *Handler) handleAdminHideComment(c echo.Context) error {
+	return h.setCommentHidden(c, true)
+}
+
+// handleAdminRestoreComment puts a reported comment back and clears its reports
+// @ID admin-restore-comment
+// @Tags admin
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Success 200 {object} contract.StatusResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /admin/comments/{id}/restore [post]
+func (h *Handler) handleAdminRestoreComment(c echo.Context) error {
+	return h.setCommentHidden(c, false)
+}
+
+func (h *Handler) setCommentHidden(c echo.Context, hidden bool) error {
+	if err := h.storage.SetCommentHidden(c.Request().Context(), c.Param("id"), hidden); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "comment not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update comment").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, contract.StatusResponse{Success: true})
+}
@@ -0,0 +1,166 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/peatch-io/peatch/internal/contract"
+	"github.com/peatch-io/peatch/internal/db"
+	"github.com/peatch-io/peatch/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollaborationComments(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	ctx := context.Background()
+
+	notified := make(chan string, 10)
+	ts.MockNotifier.NewCommentFunc = func(recipient db.User, collab db.Collaboration, comment db.Comment) error {
+		notified <- recipient.ID
+		return nil
+	}
+	reported := make(chan db.Comment, 10)
+	ts.MockNotifier.CommentReportedFunc = func(comment db.Comment) error {
+		reported <- comment
+		return nil
+	}
+
+	owner, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "owner", "Owner")
+	require.NoError(t, err)
+	asker, err := testutils.AuthHelper(t, ts.Echo, 100001, "asker", "Asker")
+	require.NoError(t, err)
+	other, err := testutils.AuthHelper(t, ts.Echo, 100002, "other", "Other")
+	require.NoError(t, err)
+	spammer, err := testutils.AuthHelper(t, ts.Echo, 100003, "spammer", "Spammer")
+	require.NoError(t, err)
+	reporter, err := testutils.AuthHelper(t, ts.Echo, 100004, "reporter", "Reporter")
+	require.NoError(t, err)
+
+	badges, opps, _ := setupTestRecords(ts.Storage, t)
+	for _, id := range []string{"collab-1", "collab-2"} {
+		require.NoError(t, ts.Storage.CreateCollaboration(ctx, db.CreateCollaborationParams{
+			Collaboration: db.Collaboration{ID: id, UserID: owner.User.ID, Title: "Band", Description: "Description"},
+			BadgeIDs:      badges,
+			OpportunityID: opps[0],
+		}))
+	}
+	require.NoError(t, ts.Storage.UpdateCollaborationVerificationStatus(ctx, "collab-1", db.VerificationStatusVerified))
+
+	post := func(token, body string, status int) contract.CommentResponse {
+		rec := testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/comments", body, token, status)
+		if status != http.StatusCreated {
+			return contract.CommentResponse{}
+		}
+		return testutils.ParseResponse[contract.CommentResponse](t, rec)
+	}
+	list := func(token, query string) []contract.CommentResponse {
+		rec := testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations/collab-1/comments"+query, "", token, http.StatusOK)
+		return testutils.ParseResponse[[]contract.CommentResponse](t, rec)
+	}
+	expectNotified := func(userID string) {
+		select {
+		case id := <-notified:
+			assert.Equal(t, userID, id)
+		case <-time.After(time.Second):
+			t.Fatalf("%s was not notified", userID)
+		}
+	}
+
+	// Pending collaborations of others can't be commented on
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-2/comments", `{"body": "Hi?"}`, asker.Token, http.StatusNotFound)
+	post(asker.Token, `{"body": "  "}`, http.StatusBadRequest)
+
+	question := post(asker.Token, `{"body": "Is it remote?"}`, http.StatusCreated)
+	expectNotified(owner.User.ID)
+	second := post(other.Token, `{"body": "Which genre?"}`, http.StatusCreated)
+	expectNotified(owner.User.ID)
+
+	// Replies notify the author of the thread, replies to replies stay in the thread
+	answer := post(owner.Token, `{"body": "Yes", "parent_id": "`+question.ID+`"}`, http.StatusCreated)
+	require.NotNil(t, answer.ParentID)
+	expectNotified(asker.User.ID)
+	followUp := post(asker.Token, `{"body": "Great", "parent_id": "`+answer.ID+`"}`, http.StatusCreated)
+	require.NotNil(t, followUp.ParentID)
+	assert.Equal(t, question.ID, *followUp.ParentID)
+	expectNotified(owner.User.ID)
+	post(asker.Token, `{"body": "?", "parent_id": "missing"}`, http.StatusNotFound)
+
+	// Comments of blocked users are left out
+	post(spammer.Token, `{"body": "Buy now"}`, http.StatusCreated)
+	expectNotified(owner.User.ID)
+	require.NoError(t, ts.Storage.UpdateUserVerificationStatus(ctx, spammer.User.ID, db.VerificationStatusBlocked))
+
+	comments := list(asker.Token, "")
+	require.Len(t, comments, 2)
+	assert.Equal(t, question.ID, comments[0].ID)
+	require.Len(t, comments[0].Replies, 2)
+	assert.Equal(t, answer.ID, comments[0].Replies[0].ID)
+
+	// Only owners pin, pinned questions come first
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/comments/"+second.ID+"/pin", "", asker.Token, http.StatusNotFound)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/comments/"+answer.ID+"/pin", "", owner.Token, http.StatusNotFound)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/comments/"+second.ID+"/pin", "", owner.Token, http.StatusOK)
+
+	comments = list(asker.Token, "")
+	assert.Equal(t, second.ID, comments[0].ID)
+	assert.True(t, comments[0].IsPinned)
+	comments = list(asker.Token, "?page=2&limit=1")
+	require.Len(t, comments, 1)
+	assert.Equal(t, question.ID, comments[0].ID)
+
+	// Authors edit, authors and owners delete
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/api/comments/"+question.ID, `{"body": "Is it remote?!"}`, other.Token, http.StatusNotFound)
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/api/comments/"+question.ID, `{"body": "Is it remote?!"}`, asker.Token, http.StatusOK)
+	edited := testutils.ParseResponse[contract.CommentResponse](t, rec)
+	assert.Equal(t, "Is it remote?!", edited.Body)
+	assert.NotNil(t, edited.EditedAt)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodDelete, "/api/comments/"+second.ID, "", asker.Token, http.StatusNotFound)
+	testutils.PerformRequest(t, ts.Echo, http.MethodDelete, "/api/comments/"+second.ID, "", other.Token, http.StatusOK)
+	testutils.PerformRequest(t, ts.Echo, http.MethodDelete, "/api/comments/"+question.ID, "", owner.Token, http.StatusOK)
+
+	// A deleted question stays while it has replies
+	comments = list(other.Token, "")
+	require.Len(t, comments, 1)
+	assert.True(t, comments[0].IsDeleted)
+	assert.Empty(t, comments[0].Body)
+	assert.Len(t, comments[0].Replies, 2)
+
+	// Reports take a comment down until a moderator restores it
+	spam := post(other.Token, `{"body": "Check my channel"}`, http.StatusCreated)
+	expectNotified(owner.User.ID)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/comments/"+spam.ID+"/report", "", other.Token, http.StatusNotFound)
+	for _, token := range []string{asker.Token, asker.Token, owner.Token, reporter.Token} {
+		testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/comments/"+spam.ID+"/report", `{"reason": "spam"}`, token, http.StatusOK)
+	}
+	var counts []int
+	for range db.CommentReportsToHide {
+		select {
+		case comment := <-reported:
+			counts = append(counts, comment.ReportCount)
+		case <-time.After(time.Second):
+			t.Fatal("admins were not notified")
+		}
+	}
+	assert.ElementsMatch(t, []int{1, 2, 3}, counts, "one notification per reporter")
+
+	assert.Len(t, list(asker.Token, ""), 1)
+	assert.Len(t, list(other.Token, ""), 2, "authors still see their comments")
+
+	adminToken := testutils.AdminAuthHelper(t, ts.Storage, 900001, "moderator")
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/admin/comments/reported", "", adminToken, http.StatusOK)
+	queue := testutils.ParseResponse[[]contract.AdminCommentResponse](t, rec)
+	require.Len(t, queue, 1)
+	assert.Equal(t, db.CommentReportsToHide, queue[0].ReportCount)
+	assert.NotNil(t, queue[0].HiddenAt)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/comments/"+spam.ID+"/restore", "", adminToken, http.StatusOK)
+	assert.Len(t, list(asker.Token, ""), 2)
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/admin/comments/reported", "", adminToken, http.StatusOK)
+	assert.Empty(t, testutils.ParseResponse[[]contract.AdminCommentResponse](t, rec))
+}
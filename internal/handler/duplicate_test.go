@@ -0,0 +1,176 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peatch-io/peatch/internal/contract"
+	"github.com/peatch-io/peatch/internal/db"
+	"github.com/peatch-io/peatch/internal/handler"
+	"github.com/peatch-io/peatch/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pendingCollaboration struct {
+	collab     db.Collaboration
+	duplicates []db.SimilarCollaboration
+}
+
+func TestDuplicateCollaborations(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t, func(cfg *handler.Config) {
+		cfg.RepostWindow = 7 * 24 * time.Hour
+	})
+	defer ts.Teardown()
+
+	ctx := context.Background()
+
+	// Posts about a band point one way, everything else another
+	ts.MockEmbeddingService.GenerateEmbeddingFunc = func(ctx context.Context, text string) ([]float64, error) {
+		embedding := make([]float64, 1536)
+		if strings.Contains(text, "Band") {
+			embedding[0] = 1
+		} else {
+			embedding[1] = 1
+		}
+		return embedding, nil
+	}
+
+	pending := make(chan pendingCollaboration, 5)
+	ts.MockNotifier.NewPendingCollaborationFunc = func(collab db.Collaboration, duplicates []db.SimilarCollaboration) error {
+		pending <- pendingCollaboration{collab, duplicates}
+		return nil
+	}
+	denied := make(chan db.Collaboration, 5)
+	ts.MockNotifier.CollaborationVerificationDeniedFunc = func(collab db.Collaboration) error {
+		denied <- collab
+		return nil
+	}
+
+	author, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "author", "Author")
+	require.NoError(t, err)
+	copycat, err := testutils.AuthHelper(t, ts.Echo, 100001, "copycat", "Copycat")
+	require.NoError(t, err)
+
+	setupTestRecords(ts.Storage, t)
+
+	create := func(token, title string) contract.CollaborationResponse {
+		body, _ := json.Marshal(contract.CreateCollaboration{
+			OpportunityID: "opp1",
+			Title:         title,
+			Description:   "Looking for a drummer",
+			BadgeIDs:      []string{"badge1"},
+		})
+		rec := testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations", string(body), token, http.StatusCreated)
+		return testutils.ParseResponse[contract.CollaborationResponse](t, rec)
+	}
+	expectPending := func(id string) []db.SimilarCollaboration {
+		select {
+		case p := <-pending:
+			assert.Equal(t, id, p.collab.ID)
+			return p.duplicates
+		case <-time.After(time.Second):
+			t.Fatalf("admins were not asked to review %s", id)
+			return nil
+		}
+	}
+
+	original := create(author.Token, "Band")
+	assert.Empty(t, expectPending(original.ID))
+
+	// Copies of someone else's post go to the admins with a link to the original
+	copied := create(copycat.Token, "Band")
+	duplicates := expectPending(copied.ID)
+	require.Len(t, duplicates, 1)
+	assert.Equal(t, original.ID, duplicates[0].ID)
+	assert.InDelta(t, 1, duplicates[0].Similarity, 0.001)
+
+	// Reposts of one's own post within the window are denied
+	repost := create(author.Token, "Band")
+	select {
+	case collab := <-denied:
+		assert.Equal(t, repost.ID, collab.ID)
+		require.NotNil(t, collab.DenialComment)
+		assert.Contains(t, *collab.DenialComment, "Band")
+	case <-time.After(time.Second):
+		t.Fatal("repost was not denied")
+	}
+
+	stored, err := ts.Storage.GetCollaborationByID(ctx, author.User.ID, repost.ID)
+	require.NoError(t, err)
+	assert.Equal(t, db.VerificationStatusDenied, stored.VerificationStatus)
+
+	// Unrelated posts aren't flagged
+	other := create(author.Token, "Photo shoot")
+	assert.Empty(t, expectPending(other.ID))
+	assert.Empty(t, pending)
+}
+
+func TestDuplicateCollaborations_InactiveOriginals(t *testing.T) {
+	tests := []struct {
+		name   string
+		update string
+	}{
+		{"denied", `UPDATE collaborations SET verification_status = 'denied' WHERE id = ?`},
+		{"hidden", `UPDATE collaborations SET hidden_at = CURRENT_TIMESTAMP WHERE id = ?`},
+		{"closed", `UPDATE collaborations SET status = 'filled' WHERE id = ?`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := testutils.SetupTestEnvironment(t, func(cfg *handler.Config) {
+				cfg.RepostWindow = 7 * 24 * time.Hour
+			})
+			defer ts.Teardown()
+
+			ts.MockEmbeddingService.GenerateEmbeddingFunc = func(ctx context.Context, text string) ([]float64, error) {
+				embedding := make([]float64, 1536)
+				embedding[0] = 1
+				return embedding, nil
+			}
+
+			pending := make(chan pendingCollaboration, 5)
+			ts.MockNotifier.NewPendingCollaborationFunc = func(collab db.Collaboration, duplicates []db.SimilarCollaboration) error {
+				pending <- pendingCollaboration{collab, duplicates}
+				return nil
+			}
+			denied := make(chan db.Collaboration, 5)
+			ts.MockNotifier.CollaborationVerificationDeniedFunc = func(collab db.Collaboration) error {
+				denied <- collab
+				return nil
+			}
+
+			author, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "author", "Author")
+			require.NoError(t, err)
+
+			setupTestRecords(ts.Storage, t)
+
+			body := `{"opportunity_id": "opp1", "title": "Band", "description": "Looking for a drummer", "badge_ids": ["badge1"]}`
+			create := func() string {
+				rec := testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations", body, author.Token, http.StatusCreated)
+				id := testutils.ParseResponse[contract.CollaborationResponse](t, rec).ID
+
+				select {
+				case p := <-pending:
+					assert.Equal(t, id, p.collab.ID)
+					assert.Empty(t, p.duplicates)
+				case <-time.After(time.Second):
+					t.Fatalf("admins were not asked to review %s", id)
+				}
+				return id
+			}
+
+			original := create()
+			_, err = ts.Storage.DB().ExecContext(context.Background(), tt.update, original)
+			require.NoError(t, err)
+
+			// Posting again isn't a repost of one that no longer counts
+			create()
+			assert.Empty(t, denied)
+		})
+	}
+}
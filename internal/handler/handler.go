@@ -16,6 +16,7 @@ import (
 	"github.com/peatch-io/peatch/internal/db"
 	"github.com/peatch-io/peatch/internal/interfaces"
 	"github.com/peatch-io/peatch/internal/middleware"
+	"github.com/peatch-io/peatch/internal/ratelimit"
 )
 
 type Handler struct {
@@ -24,8 +25,12 @@ type Handler struct {
 	s3Client            s3Client
 	logger              *slog.Logger
 	bot                 *telegram.Bot
+	adminBot            *telegram.Bot
 	notificationService interfaces.NotificationService
 	embeddingService    embeddingService
+	accounts            *accountStates
+	sessions            *sessionStates
+	rateLimiter         *ratelimit.Limiter
 }
 
 type s3Client interface {
@@ -37,16 +42,22 @@ type embeddingService interface {
 }
 
 type Config struct {
-	TelegramBotToken string
-	AdminBotToken    string
-	JWTSecret        string
-	AssetsURL        string
-	WebhookURL       string
-	WebAppURL        string
-	AdminChatID      int64
-	CommunityChatID  int64
-	BotWebApp        string
-	ImageServiceURL  string
+	TelegramBotToken   string
+	TelegramAPIURL     string // Bot API server the bots talk to, api.telegram.org if empty
+	AdminBotToken      string
+	JWTSecret          string
+	AssetsURL          string
+	WebhookURL         string
+	WebhookSecret      string
+	AdminWebhookSecret string
+	WebAppURL          string
+	AdminChatID        int64
+	CommunityChatID    int64
+	BotWebApp          string
+	ImageServiceURL    string
+	RateLimitStore     string                      // memory (default) or sqlite
+	RateLimits         map[string]ratelimit.Policy // Overrides of the default per-route limits
+	RepostWindow       time.Duration               // Reposts of an own collaboration within it are denied, 0 allows them
 }
 
 type storager interface {
@@ -55,12 +66,14 @@ type storager interface {
 	GetUserByChatID(ctx context.Context, chatID int64) (db.User, error)
 	GetUserByID(ctx context.Context, ID string) (db.User, error)
 	GetUserByUsername(ctx context.Context, username string) (db.User, error)
+	GetUserVerificationStatus(ctx context.Context, userID string) (db.VerificationStatus, error)
 	CreateUser(ctx context.Context, params db.UpdateUserParams) error
 	GetUserProfile(ctx context.Context, viewerID string, id string) (db.User, error)
 	UpdateUser(ctx context.Context, params db.UpdateUserParams) error
 	UpdateUserLinks(ctx context.Context, userID string, links []db.Link) error
 	UpdateUserLoginMetadata(ctx context.Context, userID string, metadata db.LoginMeta) error
 	UpdateUserAvatarURL(ctx context.Context, userID, avatarURL string) error
+	SetUserReferrer(ctx context.Context, userID, referrerID string) error
 	UpdateUserVerificationStatus(ctx context.Context, userID string, status db.VerificationStatus) error
 	PublishUserProfile(ctx context.Context, userID string) error
 	FollowUser(ctx context.Context, userID, followerID string, ttlDuration time.Duration) error
@@ -74,40 +87,137 @@ type storager interface {
 	UpdateCollaboration(ctx context.Context, params db.CreateCollaborationParams) error
 	UpdateCollaborationVerificationStatus(ctx context.Context, collaborationID string, status db.VerificationStatus) error
 	GetCollaborationsByVerificationStatus(ctx context.Context, status string, page, perPage int) ([]db.Collaboration, error)
-	ExpressInterest(ctx context.Context, userID string, collabID string, ttlDuration time.Duration) error
+	CreateApplication(ctx context.Context, app db.Application) (db.Application, error)
+	GetApplicationByID(ctx context.Context, viewerID, id string) (db.Application, error)
+	ListUserApplications(ctx context.Context, userID string, page, perPage int) ([]db.Application, error)
+	ListCollaborationApplications(ctx context.Context, memberID, collabID string, page, perPage int) ([]db.Application, error)
+	SaveCollaboration(ctx context.Context, userID, collabID string) error
+	UnsaveCollaboration(ctx context.Context, userID, collabID string) error
+	ListSavedCollaborations(ctx context.Context, userID string, page, perPage int) ([]db.SavedCollaboration, error)
+	CreateSavedSearch(ctx context.Context, search db.SavedSearch) (db.SavedSearch, error)
+	ListSavedSearches(ctx context.Context, userID string) ([]db.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, userID, id string) error
+	MatchSavedSearches(ctx context.Context, collabID string) ([]db.SavedSearchMatch, error)
+	AnswerApplication(ctx context.Context, memberID, id string, status db.ApplicationStatus) (db.Application, error)
+	WithdrawApplication(ctx context.Context, userID, id string) (db.Application, error)
 	HasExpressedInterest(ctx context.Context, userID string, collabID string) (bool, error)
 	GetUserCollaborations(ctx context.Context, userID string) ([]db.Collaboration, error)
 	DeleteCollaboration(ctx context.Context, collaborationID string) error
+	CloseCollaboration(ctx context.Context, ownerID, collabID string, status db.CollaborationStatus) error
+	ReopenCollaboration(ctx context.Context, ownerID, collabID string, expiresAt *time.Time) error
+	ExtendCollaboration(ctx context.Context, ownerID, collabID string, by time.Duration) (time.Time, error)
+	RecordAnalyticsEvent(ctx context.Context, event db.AnalyticsEvent) error
+	GetDailyStats(ctx context.Context, target db.AnalyticsTarget, targetIDs []string, since, now time.Time) ([]db.DailyStats, error)
+	CreateComment(ctx context.Context, comment db.Comment) (db.Comment, error)
+	GetComment(ctx context.Context, id string) (db.Comment, error)
+	ListComments(ctx context.Context, viewerID, collabID string, page, perPage int) ([]db.Comment, error)
+	UpdateComment(ctx context.Context, userID, id, body string) (db.Comment, error)
+	DeleteComment(ctx context.Context, userID, id string) error
+	PinComment(ctx context.Context, ownerID, id string, pinned bool) (db.Comment, error)
+	ReportComment(ctx context.Context, userID, id, reason string) (db.Comment, bool, error)
+	ListReportedComments(ctx context.Context, page, perPage int) ([]db.Comment, error)
+	SetCommentHidden(ctx context.Context, id string, hidden bool) error
+	SetCollaborationMember(ctx context.Context, ownerID, collabID, userID string, role db.CollaborationRole) (bool, error)
+	ListCollaborationInvites(ctx context.Context, userID string) ([]db.CollaborationInvite, error)
+	AcceptCollaborationInvite(ctx context.Context, userID, collabID string) error
+	DeclineCollaborationInvite(ctx context.Context, userID, collabID string) error
+	RemoveCollaborationMember(ctx context.Context, actorID, collabID, userID string) error
 
 	// Admin-related operations
 	CreateAdmin(ctx context.Context, admin db.Admin) (db.Admin, error)
 	GetAdminByChatID(ctx context.Context, chatID int64) (db.Admin, error)
 	GetAdminByAPIToken(ctx context.Context, apiToken string) (db.Admin, error)
 	GetAdminByID(ctx context.Context, id string) (db.Admin, error)
+	GetAdminStats(ctx context.Context) (db.AdminStats, error)
+	GetCollaborationOwnerID(ctx context.Context, collabID string) (string, error)
+
+	// Broadcasts
+	CountBroadcastAudience(ctx context.Context, segment db.BroadcastSegment) (int, error)
+	CreateBroadcast(ctx context.Context, b db.Broadcast) error
+	GetBroadcastByID(ctx context.Context, id string) (db.Broadcast, error)
+	ListBroadcasts(ctx context.Context, page, perPage int) ([]db.Broadcast, error)
+	ListSendingBroadcasts(ctx context.Context) ([]db.Broadcast, error)
+	CancelBroadcast(ctx context.Context, id string) error
+	StartBroadcast(ctx context.Context, id string) (db.Broadcast, error)
+	ListBroadcastRecipients(ctx context.Context, broadcastID string, status db.BroadcastRecipientStatus, page, perPage int) ([]db.BroadcastRecipient, error)
+	UpdateBroadcastRecipient(ctx context.Context, broadcastID, userID string, status db.BroadcastRecipientStatus, errMsg *string) error
+	FinishBroadcast(ctx context.Context, id string) error
+
+	// Denial reasons catalogue
+	ListDenialReasons(ctx context.Context) ([]db.DenialReason, error)
+	GetDenialReasonByCode(ctx context.Context, code string) (db.DenialReason, error)
+	CreateDenialReason(ctx context.Context, reason db.DenialReason) error
+	DeleteDenialReason(ctx context.Context, id string) error
+	UpdateUserDenialReason(ctx context.Context, userID string, reason *db.DenialReason, comment *string) error
+	UpdateCollaborationDenialReason(ctx context.Context, collabID string, reason *db.DenialReason, comment *string) error
+
+	// Sessions
+	CreateSession(ctx context.Context, session db.Session, tokenHash string) error
+	RotateSession(ctx context.Context, tokenHash, newTokenHash string, expiresAt time.Time) (db.Session, error)
+	RevokeSessionByToken(ctx context.Context, tokenHash string) (string, error)
+	IsSessionActive(ctx context.Context, sessionID string) (bool, error)
+	ListUserSessions(ctx context.Context, userID string) ([]db.Session, error)
+	RevokeUserSession(ctx context.Context, userID, sessionID string) error
+	RevokeUserSessions(ctx context.Context, userID, keepSessionID string) (int64, error)
+
+	// Rate limiting
+	ratelimit.Store
+
+	// Telegram updates
+	IsUpdateProcessed(ctx context.Context, bot string, updateID int64) (bool, error)
+	MarkUpdateProcessed(ctx context.Context, bot string, updateID int64) (bool, error)
+	GetLastUpdateID(ctx context.Context, bot string) (int64, error)
+	SaveLastUpdateID(ctx context.Context, bot string, updateID int64) error
 
 	// Miscellaneous operations
-	ListOpportunities(ctx context.Context) ([]db.Opportunity, error)
-	ListBadges(ctx context.Context, search string) ([]db.Badge, error)
+	ListOpportunities(ctx context.Context, includeArchived bool) ([]db.Opportunity, error)
+	CreateOpportunity(ctx context.Context, opp db.Opportunity) error
+	UpdateOpportunity(ctx context.Context, opp db.Opportunity) error
+	GetOpportunityByID(ctx context.Context, id string) (db.Opportunity, error)
+	SetOpportunityArchived(ctx context.Context, id string, archived bool) error
+	ReorderOpportunities(ctx context.Context, ids []string) error
+	ListBadges(ctx context.Context, search, viewerID string) ([]db.Badge, error)
+	ListBadgesByStatus(ctx context.Context, status db.BadgeStatus) ([]db.Badge, error)
 	CreateBadge(ctx context.Context, badge db.Badge) error
+	FindSimilarBadge(ctx context.Context, text, viewerID string) (db.Badge, error)
+	ApproveBadge(ctx context.Context, id string) error
+	MergeBadges(ctx context.Context, sourceID, targetID string) (db.BadgeRewriteResult, error)
+	DeleteBadge(ctx context.Context, id string) (db.BadgeRewriteResult, error)
 	SearchCities(ctx context.Context, query string, limit, skip int) ([]db.City, error)
 	Health() (db.HealthStats, error)
 	GetCityByName(ctx context.Context, name string) (db.City, error)
+	GetCityByID(ctx context.Context, id string) (db.City, error)
 
 	// Embedding-related operations
 	UpdateUserEmbedding(ctx context.Context, userID string, embeddingVector []float64) error
 	GetMatchingUsersForCollaboration(ctx context.Context, opportunityID string, limit int) ([]db.User, error)
 	UpdateCollaborationEmbedding(ctx context.Context, collaborationID string, embeddingVector []float64) error
+	FindSimilarCollaborations(ctx context.Context, collabID string, since time.Time, minSimilarity float64, limit int) ([]db.SimilarCollaboration, error)
 }
 
-func New(storage storager, config Config, s3Client s3Client, logger *slog.Logger, bot *telegram.Bot, n interfaces.NotificationService, es embeddingService) *Handler {
+func New(storage storager, config Config, s3Client s3Client, logger *slog.Logger, bot, adminBot *telegram.Bot, n interfaces.NotificationService, es embeddingService) *Handler {
+	if config.WebhookSecret == "" {
+		config.WebhookSecret = deriveWebhookSecret(config.TelegramBotToken)
+	}
+	if config.AdminWebhookSecret == "" {
+		config.AdminWebhookSecret = deriveWebhookSecret(config.AdminBotToken)
+	}
+	if config.TelegramAPIURL == "" {
+		config.TelegramAPIURL = DefaultTelegramAPIURL
+	}
+
 	return &Handler{
 		storage:             storage,
 		config:              config,
 		s3Client:            s3Client,
 		logger:              logger,
 		bot:                 bot,
+		adminBot:            adminBot,
 		notificationService: n,
 		embeddingService:    es,
+		accounts:            newAccountStates(),
+		sessions:            newSessionStates(),
+		rateLimiter:         newRateLimiter(config, storage),
 	}
 }
 
@@ -116,14 +226,33 @@ func (h *Handler) SetupWebhook(ctx context.Context) error {
 		return errors.New("bot is not initialized")
 	}
 
-	webhookURL := fmt.Sprintf("%s/tg/webhook", h.config.WebhookURL)
+	if err := h.registerWebhook(ctx, h.bot, "/tg/webhook", h.config.WebhookSecret); err != nil {
+		return err
+	}
+
+	h.setBotCommands(ctx)
+
+	if h.adminBot != nil {
+		if err := h.registerWebhook(ctx, h.adminBot, "/tg/admin/webhook", h.config.AdminWebhookSecret); err != nil {
+			return fmt.Errorf("admin bot: %w", err)
+		}
+
+		h.setAdminBotCommands(ctx)
+	}
+
+	return nil
+}
+
+func (h *Handler) registerWebhook(ctx context.Context, bot *telegram.Bot, path, secret string) error {
+	webhookURL := h.config.WebhookURL + path
 
 	whParams := telegram.SetWebhookParams{
 		DropPendingUpdates: true,
 		URL:                webhookURL,
+		SecretToken:        secret,
 	}
 
-	ok, err := h.bot.SetWebhook(ctx, &whParams)
+	ok, err := bot.SetWebhook(ctx, &whParams)
 
 	if err != nil {
 		return fmt.Errorf("failed to set webhook: %w", err)
@@ -134,13 +263,17 @@ func (h *Handler) SetupWebhook(ctx context.Context) error {
 	}
 
 	h.logger.Info("telegram webhook set successfully", slog.String("url", webhookURL))
+
 	return nil
 }
 
 func (h *Handler) SetupRoutes(e *echo.Echo) {
 	// Public routes
-	e.POST("/auth/telegram", h.TelegramAuth)
+	e.POST("/auth/telegram", h.TelegramAuth, h.rateLimit(RateLimitAuth))
+	e.POST("/auth/refresh", h.handleRefreshToken, h.rateLimit(RateLimitAuth))
+	e.POST("/auth/logout", h.handleLogout)
 	e.POST("/tg/webhook", h.HandleWebhook)
+	e.POST("/tg/admin/webhook", h.HandleAdminWebhook)
 	e.GET("/", h.handleIndex)
 	e.GET("/avatar", h.getRandomAvatar)
 
@@ -150,27 +283,61 @@ func (h *Handler) SetupRoutes(e *echo.Echo) {
 	// Regular API routes (require JWT auth)
 	api := e.Group("/api")
 	api.Use(echojwt.WithConfig(middleware.GetUserAuthConfig(h.config.JWTSecret)))
+	api.Use(h.requireActiveAccount)
 
 	api.GET("/users", h.handleListUsers)
 	api.GET("/users/me", h.handleGetMe)
+	api.GET("/users/me/stats", h.handleGetMyStats)
+	api.GET("/users/me/sessions", h.handleListSessions)
+	api.GET("/users/me/applications", h.handleListMyApplications)
+	api.GET("/users/me/saved", h.handleListSavedCollaborations)
+	api.GET("/users/me/invites", h.handleListCollaborationInvites)
+	api.GET("/users/me/searches", h.handleListSavedSearches)
+	api.POST("/users/me/searches", h.handleCreateSavedSearch)
+	api.DELETE("/users/me/searches/:id", h.handleDeleteSavedSearch)
+	api.DELETE("/users/me/sessions", h.handleRevokeOtherSessions)
+	api.DELETE("/users/me/sessions/:id", h.handleRevokeSession)
 	api.POST("/users/avatar", h.handleUserAvatar)
 	api.POST("/users/publish", h.handlePublishProfile)
 	api.GET("/users/:id", h.handleGetUser)
-	api.POST("/users/:id/follow", h.handleFollowUser)
+	api.POST("/users/:id/follow", h.handleFollowUser, h.rateLimit(RateLimitFollow), h.limitUnverifiedSocialActions)
 	api.PUT("/users", h.handleUpdateUser)
 	api.PUT("/users/links", h.handleUpdateUserLinks)
 
 	api.GET("/opportunities", h.handleListOpportunities)
 	api.GET("/badges", h.handleListBadges)
-	api.POST("/badges", h.handleCreateBadge)
+	api.POST("/badges", h.handleCreateBadge, h.rateLimit(RateLimitCreateBadge))
 
 	api.GET("/collaborations", h.handleListCollaborations)
 	api.GET("/collaborations/:id", h.handleGetCollaboration)
-	api.POST("/collaborations", h.handleCreateCollaboration)
+	api.POST("/collaborations", h.handleCreateCollaboration, h.rateLimit(RateLimitCreateCollaboration), h.limitUnverifiedSocialActions)
 	api.PUT("/collaborations/:id", h.handleUpdateCollaboration)
-	api.POST("/collaborations/:id/interest", h.handleExpressInterest)
+	api.POST("/collaborations/:id/close", h.handleCloseCollaboration)
+	api.POST("/collaborations/:id/reopen", h.handleReopenCollaboration)
+	api.POST("/collaborations/:id/interest", h.handleExpressInterest, h.rateLimit(RateLimitInterest), h.limitUnverifiedSocialActions)
+	api.GET("/collaborations/:id/interests", h.handleListCollaborationInterests)
+	api.POST("/collaborations/:id/save", h.handleSaveCollaboration)
+	api.DELETE("/collaborations/:id/save", h.handleUnsaveCollaboration)
+	api.PUT("/collaborations/:id/members/:user_id", h.handleSetCollaborationMember)
+	api.DELETE("/collaborations/:id/members/:user_id", h.handleRemoveCollaborationMember)
+	api.POST("/collaborations/:id/invite/accept", h.handleAcceptCollaborationInvite)
+	api.DELETE("/collaborations/:id/invite", h.handleDeclineCollaborationInvite)
+	api.GET("/collaborations/:id/stats", h.handleGetCollaborationStats)
+	api.GET("/collaborations/:id/comments", h.handleListComments)
+	api.POST("/collaborations/:id/comments", h.handleCreateComment, h.rateLimit(RateLimitComment), h.limitUnverifiedSocialActions)
 	api.GET("/collaborations/profiles/:id", h.HandleGetMatchingProfiles)
 
+	api.PUT("/comments/:id", h.handleUpdateComment)
+	api.DELETE("/comments/:id", h.handleDeleteComment)
+	api.POST("/comments/:id/pin", h.handlePinComment)
+	api.DELETE("/comments/:id/pin", h.handleUnpinComment)
+	api.POST("/comments/:id/report", h.handleReportComment)
+
+	api.POST("/applications/:id/accept", h.handleAcceptApplication)
+	api.POST("/applications/:id/shortlist", h.handleShortlistApplication)
+	api.POST("/applications/:id/decline", h.handleDeclineApplication)
+	api.POST("/applications/:id/withdraw", h.handleWithdrawApplication)
+
 	api.GET("/locations", h.handleSearchLocations)
 
 	admin := e.Group("/admin")
@@ -190,7 +357,15 @@ func (h *Handler) SetupRoutes(e *echo.Echo) {
 	admin.PUT("/users/:id/verify", h.handleAdminUpdateUserVerification)
 	admin.GET("/badges", h.handleAdminListBadges)
 	admin.POST("/badges", h.handleAdminCreateBadge)
+	admin.POST("/badges/:id/approve", h.handleAdminApproveBadge)
+	admin.POST("/badges/:id/merge", h.handleAdminMergeBadge)
+	admin.DELETE("/badges/:id", h.handleAdminDeleteBadge)
 	admin.GET("/opportunities", h.handleAdminListOpportunities)
+	admin.POST("/opportunities", h.handleAdminCreateOpportunity)
+	admin.PUT("/opportunities/order", h.handleAdminReorderOpportunities)
+	admin.PUT("/opportunities/:id", h.handleAdminUpdateOpportunity)
+	admin.POST("/opportunities/:id/archive", h.handleAdminArchiveOpportunity)
+	admin.POST("/opportunities/:id/restore", h.handleAdminRestoreOpportunity)
 	admin.GET("/cities/:name", h.handleAdminGetCityByName)
 	admin.GET("/users/chat/:id", h.handleAdminGetUserByChatID)
 	admin.GET("/users/:username", h.handleAdminGetUserByUsername)
@@ -202,6 +377,25 @@ func (h *Handler) SetupRoutes(e *echo.Echo) {
 	admin.POST("/collaborations", h.handleAdminCreateCollaboration)
 	admin.PUT("/users/:uid/collaborations/:cid/verify", h.handleAdminUpdateCollaborationVerification)
 	admin.DELETE("/collaborations/:id", h.handleAdminDeleteCollaboration)
+
+	// Denial reasons catalogue
+	admin.GET("/comments/reported", h.handleAdminListReportedComments)
+	admin.POST("/comments/:id/hide", h.handleAdminHideComment)
+	admin.POST("/comments/:id/restore", h.handleAdminRestoreComment)
+
+	admin.GET("/denial-reasons", h.handleAdminListDenialReasons)
+	admin.POST("/denial-reasons", h.handleAdminCreateDenialReason)
+	admin.DELETE("/denial-reasons/:id", h.handleAdminDeleteDenialReason)
+
+	// Broadcasts
+	admin.GET("/broadcasts", h.handleAdminListBroadcasts)
+	admin.POST("/broadcasts", h.handleAdminCreateBroadcast)
+	admin.POST("/broadcasts/preview", h.handleAdminPreviewBroadcast)
+	admin.GET("/broadcasts/:id", h.handleAdminGetBroadcast)
+	admin.POST("/broadcasts/:id/test", h.handleAdminTestBroadcast)
+	admin.POST("/broadcasts/:id/send", h.handleAdminSendBroadcast)
+	admin.POST("/broadcasts/:id/cancel", h.handleAdminCancelBroadcast)
+	admin.GET("/broadcasts/:id/recipients", h.handleAdminListBroadcastRecipients)
 }
 
 func (h *Handler) handleIndex(c echo.Context) error {
@@ -14,7 +14,7 @@ import (
 // @Success 200 {array} contract.OpportunityResponse
 // @Router /api/opportunities [get]
 func (h *Handler) handleListOpportunities(c echo.Context) error {
-	res, err := h.storage.ListOpportunities(c.Request().Context())
+	res, err := h.storage.ListOpportunities(c.Request().Context(), false)
 
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get opportunities").WithInternal(err)
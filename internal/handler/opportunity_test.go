@@ -3,8 +3,11 @@ package handler_test
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/peatch-io/peatch/internal/contract"
 	"github.com/peatch-io/peatch/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"net/http"
 	"testing"
 	"time"
@@ -82,3 +85,139 @@ func TestListOpportunities_Success(t *testing.T) {
 		t.Error("Opportunity 'Тестовый Бейдж 2' not found in response")
 	}
 }
+
+func TestAdminUpdateOpportunity_PropagatesToSnapshots(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	authResp, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "user1", "First")
+	require.NoError(t, err)
+	adminToken := testutils.AdminAuthHelper(t, ts.Storage, 900001, "moderator")
+
+	badges, opps, locationID := setupTestRecords(ts.Storage, t)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/api/users",
+		fmt.Sprintf(`{"name": "First", "title": "Developer", "description": "Description", "location_id": "%s", "badge_ids": ["%s"], "opportunity_ids": ["%s", "%s"]}`,
+			locationID, badges[0], opps[0], opps[1]),
+		authResp.Token, http.StatusOK)
+
+	collab := db.Collaboration{ID: "collab1", UserID: authResp.User.ID, Title: "Collab", Description: "Some description"}
+	require.NoError(t, ts.Storage.CreateCollaboration(context.Background(), db.CreateCollaborationParams{
+		Collaboration: collab,
+		BadgeIDs:      badges[:1],
+		OpportunityID: opps[0],
+		LocationID:    &locationID,
+	}))
+
+	require.NoError(t, ts.Storage.SetOpportunityArchived(context.Background(), opps[0], true))
+	before, err := ts.Storage.GetOpportunityByID(context.Background(), opps[0])
+	require.NoError(t, err)
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/admin/opportunities/"+opps[0],
+		`{"text": "Mentoring", "text_ru": "Менторство", "description": "Help others grow", "description_ru": "Помогать другим расти", "icon": "E4B4", "color": "FF8800"}`,
+		adminToken, http.StatusOK)
+	saved := testutils.ParseResponse[db.Opportunity](t, rec)
+	assert.Equal(t, "Mentoring", saved.Text)
+	assert.Equal(t, before.Position, saved.Position)
+	assert.Equal(t, before.CreatedAt.Unix(), saved.CreatedAt.Unix())
+	assert.NotNil(t, saved.ArchivedAt, "expected the stored archive state")
+	require.NoError(t, ts.Storage.SetOpportunityArchived(context.Background(), opps[0], false))
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me", "", authResp.Token, http.StatusOK)
+	user := testutils.ParseResponse[contract.UserResponse](t, rec)
+	require.Len(t, user.Opportunities, 2)
+	assert.Equal(t, "Менторство", user.Opportunities[0].Text)
+	assert.Equal(t, "ff8800", user.Opportunities[0].Color)
+	assert.Equal(t, opps[1], user.Opportunities[1].ID)
+
+	updated, err := ts.Storage.GetCollaborationByID(context.Background(), authResp.User.ID, collab.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Mentoring", updated.Opportunity.Text)
+	assert.Equal(t, "Менторство", updated.Opportunity.TextRU)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/admin/opportunities/"+opps[0],
+		`{"text": "Mentoring", "text_ru": "Менторство", "description": "Help", "description_ru": "Помощь", "icon": "zzzz", "color": "ff8800"}`,
+		adminToken, http.StatusBadRequest)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/admin/opportunities/missing",
+		`{"text": "Mentoring", "text_ru": "Менторство", "description": "Help", "description_ru": "Помощь", "icon": "e4b4", "color": "ff8800"}`,
+		adminToken, http.StatusNotFound)
+}
+
+func TestAdminArchiveOpportunity_HiddenFromPickers(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	authResp, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "user1", "First")
+	require.NoError(t, err)
+	other, err := testutils.AuthHelper(t, ts.Echo, 100002, "other", "Other")
+	require.NoError(t, err)
+	adminToken := testutils.AdminAuthHelper(t, ts.Storage, 900001, "moderator")
+
+	badges, opps, locationID := setupTestRecords(ts.Storage, t)
+
+	profile := func(oppIDs string) string {
+		return fmt.Sprintf(`{"name": "Name", "title": "Developer", "description": "Description", "location_id": "%s", "badge_ids": ["%s"], "opportunity_ids": [%s]}`,
+			locationID, badges[0], oppIDs)
+	}
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/api/users",
+		profile(fmt.Sprintf(`"%s", "%s"`, opps[0], opps[1])), authResp.Token, http.StatusOK)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/opportunities/"+opps[0]+"/archive", "", adminToken, http.StatusOK)
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/opportunities", "", authResp.Token, http.StatusOK)
+	picker := testutils.ParseResponse[[]contract.OpportunityResponse](t, rec)
+	require.Len(t, picker, 1)
+	assert.Equal(t, opps[1], picker[0].ID)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/admin/opportunities", "", adminToken, http.StatusOK)
+	assert.Len(t, testutils.ParseResponse[[]db.Opportunity](t, rec), 2)
+
+	// Saving an old profile keeps the archived opportunity
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/api/users",
+		profile(fmt.Sprintf(`"%s", "%s"`, opps[0], opps[1])), authResp.Token, http.StatusOK)
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me", "", authResp.Token, http.StatusOK)
+	assert.Len(t, testutils.ParseResponse[contract.UserResponse](t, rec).Opportunities, 2)
+
+	// But nobody can pick it anew
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/api/users",
+		profile(fmt.Sprintf(`"%s", "%s"`, opps[0], opps[1])), other.Token, http.StatusOK)
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me", "", other.Token, http.StatusOK)
+	otherOpps := testutils.ParseResponse[contract.UserResponse](t, rec).Opportunities
+	require.Len(t, otherOpps, 1)
+	assert.Equal(t, opps[1], otherOpps[0].ID)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/opportunities/"+opps[0]+"/restore", "", adminToken, http.StatusOK)
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/opportunities", "", authResp.Token, http.StatusOK)
+	assert.Len(t, testutils.ParseResponse[[]contract.OpportunityResponse](t, rec), 2)
+}
+
+func TestAdminReorderOpportunities(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	authResp, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "user1", "First")
+	require.NoError(t, err)
+	adminToken := testutils.AdminAuthHelper(t, ts.Storage, 900001, "moderator")
+
+	var ids []string
+	for _, text := range []string{"First", "Second", "Third"} {
+		rec := testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/admin/opportunities",
+			fmt.Sprintf(`{"text": %q, "text_ru": %q, "description": "Description", "description_ru": "Описание", "icon": "e4b4", "color": "ff8800"}`, text, text),
+			adminToken, http.StatusCreated)
+		ids = append(ids, testutils.ParseResponse[db.Opportunity](t, rec).ID)
+	}
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/admin/opportunities/order",
+		fmt.Sprintf(`{"ids": ["%s", "%s"]}`, ids[2], ids[0]), adminToken, http.StatusOK)
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/opportunities", "", authResp.Token, http.StatusOK)
+	listed := testutils.ParseResponse[[]contract.OpportunityResponse](t, rec)
+	require.Len(t, listed, 3)
+	assert.Equal(t, []string{ids[2], ids[0], ids[1]}, []string{listed[0].ID, listed[1].ID, listed[2].ID})
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/admin/opportunities/order",
+		fmt.Sprintf(`{"ids": ["%s", "%s"]}`, ids[0], ids[0]), adminToken, http.StatusBadRequest)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/admin/opportunities/order",
+		`{"ids": ["missing"]}`, adminToken, http.StatusNotFound)
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"maps"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+
+	"github.com/peatch-io/peatch/internal/contract"
+	"github.com/peatch-io/peatch/internal/ratelimit"
+)
+
+// Rate limited routes. Names are the keys of Config.RateLimits.
+const (
+	RateLimitAuth                = "auth"
+	RateLimitFollow              = "follow"
+	RateLimitInterest            = "interest"
+	RateLimitCreateCollaboration = "create_collaboration"
+	RateLimitCreateBadge         = "create_badge"
+	RateLimitComment             = "comment"
+	// RateLimitUnverifiedSocial is shared by all social actions of users
+	// who aren't verified yet
+	RateLimitUnverifiedSocial = "unverified_social"
+)
+
+const (
+	RateLimitStoreMemory = "memory"
+	RateLimitStoreSQLite = "sqlite"
+)
+
+var defaultRateLimits = map[string]ratelimit.Policy{
+	RateLimitAuth: {
+		IP: ratelimit.Rule{PerMinute: 20, Burst: 20},
+	},
+	RateLimitFollow: {
+		User:  ratelimit.Rule{PerMinute: 10, Burst: 10},
+		IP:    ratelimit.Rule{PerMinute: 60, Burst: 60},
+		Daily: 100,
+	},
+	RateLimitInterest: {
+		User:  ratelimit.Rule{PerMinute: 5, Burst: 10},
+		IP:    ratelimit.Rule{PerMinute: 30, Burst: 30},
+		Daily: 50,
+	},
+	RateLimitCreateCollaboration: {
+		User:  ratelimit.Rule{PerMinute: 2, Burst: 5},
+		IP:    ratelimit.Rule{PerMinute: 10, Burst: 10},
+		Daily: 10,
+	},
+	RateLimitCreateBadge: {
+		User:  ratelimit.Rule{PerMinute: 5, Burst: 10},
+		IP:    ratelimit.Rule{PerMinute: 20, Burst: 20},
+		Daily: 30,
+	},
+	RateLimitComment: {
+		User:  ratelimit.Rule{PerMinute: 5, Burst: 10},
+		IP:    ratelimit.Rule{PerMinute: 30, Burst: 30},
+		Daily: 100,
+	},
+	RateLimitUnverifiedSocial: {
+		User: ratelimit.Rule{PerMinute: 10.0 / 60, Burst: 10},
+	},
+}
+
+// newRateLimiter builds the limiter from the defaults overridden by config
+func newRateLimiter(config Config, storage storager) *ratelimit.Limiter {
+	policies := maps.Clone(defaultRateLimits)
+	maps.Copy(policies, config.RateLimits)
+
+	var store ratelimit.Store = ratelimit.NewMemoryStore()
+	if config.RateLimitStore == RateLimitStoreSQLite {
+		store = storage
+	}
+
+	return ratelimit.New(store, policies)
+}
+
+// rateLimit limits a route per user, when authenticated, and per client IP.
+// Requests the handler turns down with a client error are refunded, except
+// for authentication failures so guessing credentials stays limited.
+func (h *Handler) rateLimit(route string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var userID string
+			if token, ok := c.Get("user").(*jwt.Token); ok {
+				if claims, ok := token.Claims.(*contract.JWTClaims); ok {
+					userID = claims.UID
+				}
+			}
+
+			result, err := h.rateLimiter.Allow(c.Request().Context(), route, userID, c.RealIP())
+			if err != nil {
+				// Don't lock users out because the limiter's store is unavailable
+				h.logger.Error("rate limit check failed", slog.String("route", route), slog.String("error", err.Error()))
+				return next(c)
+			}
+
+			if !result.Allowed {
+				c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+				return echo.NewHTTPError(http.StatusTooManyRequests, contract.ErrorResponse{
+					Error: result.Reason,
+					Code:  ErrCodeRateLimited,
+				})
+			}
+
+			err = next(c)
+
+			if isRefundable(c, err) {
+				if err := h.rateLimiter.Refund(c.Request().Context(), route, userID, c.RealIP()); err != nil {
+					h.logger.Error("rate limit refund failed", slog.String("route", route), slog.String("error", err.Error()))
+				}
+			}
+
+			return err
+		}
+	}
+}
+
+// isRefundable tells whether a request was rejected without an effect that
+// should count against the caller's limits
+func isRefundable(c echo.Context, err error) bool {
+	status := c.Response().Status
+	if err != nil {
+		var httpErr *echo.HTTPError
+		if !errors.As(err, &httpErr) {
+			return false
+		}
+		status = httpErr.Code
+	}
+
+	return status >= 400 && status < 500 && status != http.StatusUnauthorized
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/peatch-io/peatch/internal/contract"
+	"github.com/peatch-io/peatch/internal/db"
+)
+
+// handleSaveCollaboration godoc
+// @Summary Save collaboration
+// @Description Bookmarks a collaboration to come back to later. Saving twice is fine.
+// @Tags collaborations
+// @Produce json
+// @Param id path string true "Collaboration ID"
+// @Success 200 {object} contract.StatusResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Router /api/collaborations/{id}/save [post]
+func (h *Handler) handleSaveCollaboration(c echo.Context) error {
+	err := h.storage.SaveCollaboration(c.Request().Context(), getUserID(c), c.Param("id"))
+	if errors.Is(err, db.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "collaboration not found")
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to save collaboration").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, contract.StatusResponse{Success: true})
+}
+
+// handleUnsaveCollaboration godoc
+// @Summary Unsave collaboration
+// @Tags collaborations
+// @Produce json
+// @Param id path string true "Collaboration ID"
+// @Success 200 {object} contract.StatusResponse
+// @Router /api/collaborations/{id}/save [delete]
+func (h *Handler) handleUnsaveCollaboration(c echo.Context) error {
+	if err := h.storage.UnsaveCollaboration(c.Request().Context(), getUserID(c), c.Param("id")); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to unsave collaboration").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, contract.StatusResponse{Success: true})
+}
+
+// handleListSavedCollaborations godoc
+// @Summary List saved collaborations
+// @Description Most recently saved first. Closed and hidden collaborations stay in the list and are flagged.
+// @Tags collaborations
+// @Produce json
+// @Param page query int false "Page"
+// @Param limit query int false "Limit"
+// @Success 200 {array} contract.SavedCollaborationResponse
+// @Router /api/users/me/saved [get]
+func (h *Handler) handleListSavedCollaborations(c echo.Context) error {
+	page := parseIntQuery(c, "page", 1)
+	limit := parseIntQuery(c, "limit", 20)
+
+	saved, err := h.storage.ListSavedCollaborations(c.Request().Context(), getUserID(c), page, limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get saved collaborations").WithInternal(err)
+	}
+
+	resp := make([]contract.SavedCollaborationResponse, len(saved))
+	for i, item := range saved {
+		resp[i] = contract.ToSavedCollaborationResponse(item)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
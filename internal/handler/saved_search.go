@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/peatch-io/peatch/internal/contract"
+	"github.com/peatch-io/peatch/internal/db"
+)
+
+// handleListSavedSearches godoc
+// @Summary List saved searches
+// @Tags saved searches
+// @Produce json
+// @Success 200 {array} contract.SavedSearchResponse
+// @Router /api/users/me/searches [get]
+func (h *Handler) handleListSavedSearches(c echo.Context) error {
+	searches, err := h.storage.ListSavedSearches(c.Request().Context(), getUserID(c))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get saved searches").WithInternal(err)
+	}
+
+	resp := make([]contract.SavedSearchResponse, len(searches))
+	for i, search := range searches {
+		resp[i] = contract.ToSavedSearchResponse(search)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// handleCreateSavedSearch godoc
+// @Summary Save a search
+// @Description Saves the search text and feed filters. The user gets a bot message when a new collaboration matching them is verified.
+// @Tags saved searches
+// @Accept json
+// @Produce json
+// @Param request body contract.SavedSearchRequest true "Search"
+// @Success 201 {object} contract.SavedSearchResponse
+// @Failure 400 {object} contract.ErrorResponse "Invalid search or too many saved searches"
+// @Router /api/users/me/searches [post]
+func (h *Handler) handleCreateSavedSearch(c echo.Context) error {
+	var req contract.SavedSearchRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
+	}
+
+	search, err := h.storage.CreateSavedSearch(c.Request().Context(), req.ToSavedSearch(getUserID(c)))
+	if errors.Is(err, db.ErrSavedSearchLimit) {
+		return echo.NewHTTPError(http.StatusBadRequest, "too many saved searches")
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to save search").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusCreated, contract.ToSavedSearchResponse(search))
+}
+
+// handleDeleteSavedSearch godoc
+// @Summary Delete a saved search
+// @Tags saved searches
+// @Produce json
+// @Param id path string true "Saved search ID"
+// @Success 200 {object} contract.StatusResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Router /api/users/me/searches/{id} [delete]
+func (h *Handler) handleDeleteSavedSearch(c echo.Context) error {
+	err := h.storage.DeleteSavedSearch(c.Request().Context(), getUserID(c), c.Param("id"))
+	if errors.Is(err, db.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "saved search not found")
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to delete saved search").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, contract.StatusResponse{Success: true})
+}
+
+// notifySavedSearchMatches alerts users whose saved searches match a newly
+// verified collaboration, except the ones in notified
+func (h *Handler) notifySavedSearchMatches(ctx context.Context, collab db.Collaboration, notified map[string]bool) {
+	matches, err := h.storage.MatchSavedSearches(ctx, collab.ID)
+	if err != nil {
+		h.logger.Error("failed to match saved searches",
+			slog.String("collaboration_id", collab.ID),
+			slog.String("error", err.Error()))
+		return
+	}
+
+	pending := make([]db.SavedSearchMatch, 0, len(matches))
+	for _, match := range matches {
+		if !notified[match.User.ID] {
+			pending = append(pending, match)
+		}
+	}
+
+	if err := h.notificationService.NotifySavedSearchMatches(collab, pending); err != nil {
+		h.logger.Error("failed to send saved search notifications",
+			slog.String("collaboration_id", collab.ID),
+			slog.String("error", err.Error()))
+	}
+}
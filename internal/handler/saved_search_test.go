@@ -0,0 +1,187 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/peatch-io/peatch/internal/contract"
+	"github.com/peatch-io/peatch/internal/db"
+	"github.com/peatch-io/peatch/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavedSearches(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	authResp, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "testuser", "Test")
+	require.NoError(t, err)
+	other, err := testutils.AuthHelper(t, ts.Echo, 100001, "other", "Other")
+	require.NoError(t, err)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/users/me/searches", `{"name": "Nothing"}`, authResp.Token, http.StatusBadRequest)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/users/me/searches", `{"name": "Bad", "payable": true, "badge_match": "some"}`, authResp.Token, http.StatusBadRequest)
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/users/me/searches",
+		`{"name": "Paid design", "query": "poster", "badge_ids": ["badge1", "badge2"], "badge_match": "all", "country_code": "tc", "payable": true}`,
+		authResp.Token, http.StatusCreated)
+	search := testutils.ParseResponse[contract.SavedSearchResponse](t, rec)
+	assert.Equal(t, "Paid design", search.Name)
+	assert.Equal(t, "all", search.BadgeMatch)
+	assert.Equal(t, "TC", search.CountryCode)
+	assert.True(t, search.Payable)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me/searches", "", authResp.Token, http.StatusOK)
+	assert.Len(t, testutils.ParseResponse[[]contract.SavedSearchResponse](t, rec), 1)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodDelete, "/api/users/me/searches/"+search.ID, "", other.Token, http.StatusNotFound)
+	testutils.PerformRequest(t, ts.Echo, http.MethodDelete, "/api/users/me/searches/"+search.ID, "", authResp.Token, http.StatusOK)
+
+	for i := 0; i < db.MaxSavedSearches; i++ {
+		testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/users/me/searches",
+			fmt.Sprintf(`{"name": "Search %d", "query": "q%d"}`, i, i), authResp.Token, http.StatusCreated)
+	}
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/users/me/searches", `{"name": "One more", "query": "q"}`, authResp.Token, http.StatusBadRequest)
+}
+
+func TestSavedSearches_NotifyOnVerification(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	ctx := context.Background()
+	adminToken := testutils.AdminAuthHelper(t, ts.Storage, 900001, "moderator")
+
+	owner, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "owner", "Owner")
+	require.NoError(t, err)
+
+	users := map[string]*contract.AuthResponse{}
+	for i, name := range []string{"searcher", "embedded", "unmatched"} {
+		resp, err := testutils.AuthHelper(t, ts.Echo, int64(100001+i), name, name)
+		require.NoError(t, err)
+		require.NoError(t, ts.Storage.UpdateUserVerificationStatus(ctx, resp.User.ID, db.VerificationStatusVerified))
+		users[name] = &resp
+	}
+
+	badges, opps, loc := setupTestRecords(ts.Storage, t)
+	require.NoError(t, ts.Storage.CreateCollaboration(ctx, db.CreateCollaborationParams{
+		Collaboration: db.Collaboration{ID: "collab-1", UserID: owner.User.ID, Title: "Tour poster", Description: "Description", IsPayable: true},
+		BadgeIDs:      badges,
+		OpportunityID: opps[0],
+		LocationID:    strPtr(loc),
+	}))
+
+	matching := `{"name": "Posters", "query": "poster", "badge_ids": ["badge1"], "city_id": "` + loc + `", "payable": true}`
+	for _, token := range []string{owner.Token, users["searcher"].Token, users["searcher"].Token, users["embedded"].Token} {
+		testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/users/me/searches", matching, token, http.StatusCreated)
+	}
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/users/me/searches",
+		`{"name": "Free gigs", "query": "poster", "opportunity_ids": ["opp2"]}`, users["unmatched"].Token, http.StatusCreated)
+
+	// The embedding match reaches this user first
+	vector := make([]float64, 1536)
+	vector[0] = 1
+	require.NoError(t, ts.Storage.UpdateCollaborationEmbedding(ctx, "collab-1", vector))
+	require.NoError(t, ts.Storage.UpdateUserEmbedding(ctx, users["embedded"].User.ID, vector))
+
+	embeddingMatches := make(chan []db.User, 1)
+	ts.MockNotifier.MatchingOpportunityFunc = func(collab db.Collaboration, users []db.User) error {
+		embeddingMatches <- users
+		return nil
+	}
+	searchMatches := make(chan []db.SavedSearchMatch, 1)
+	ts.MockNotifier.SavedSearchMatchesFunc = func(collab db.Collaboration, matches []db.SavedSearchMatch) error {
+		searchMatches <- matches
+		return nil
+	}
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/admin/users/"+owner.User.ID+"/collaborations/collab-1/verify",
+		`{"status": "verified"}`, adminToken, http.StatusOK)
+
+	select {
+	case matched := <-embeddingMatches:
+		require.Len(t, matched, 1)
+		assert.Equal(t, users["embedded"].User.ID, matched[0].ID)
+	case <-time.After(2 * time.Second):
+		t.Fatal("embedding matches were not notified")
+	}
+
+	select {
+	case matches := <-searchMatches:
+		require.Len(t, matches, 1)
+		assert.Equal(t, users["searcher"].User.ID, matches[0].User.ID)
+		assert.Equal(t, "Posters", matches[0].Search.Name)
+	case <-time.After(2 * time.Second):
+		t.Fatal("saved search matches were not notified")
+	}
+}
+
+func TestMatchSavedSearches_Filters(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	ctx := context.Background()
+
+	owner, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "owner", "Owner")
+	require.NoError(t, err)
+
+	badges, opps, loc := setupTestRecords(ts.Storage, t)
+	require.NoError(t, ts.Storage.CreateCollaboration(ctx, db.CreateCollaborationParams{
+		Collaboration: db.Collaboration{ID: "collab-1", UserID: owner.User.ID, Title: "Tour poster", Description: "Description", IsPayable: true},
+		BadgeIDs:      badges[:1],
+		OpportunityID: opps[0],
+		LocationID:    strPtr(loc),
+	}))
+
+	searches := []struct {
+		name    string
+		query   string
+		filters db.SavedSearchFilters
+		matches bool
+	}{
+		{"query", "POSTER", db.SavedSearchFilters{}, true},
+		{"other query", "violin", db.SavedSearchFilters{}, false},
+		{"opportunity", "", db.SavedSearchFilters{OpportunityIDs: []string{opps[1], opps[0]}}, true},
+		{"other opportunity", "", db.SavedSearchFilters{OpportunityIDs: []string{opps[1]}}, false},
+		{"country", "", db.SavedSearchFilters{CountryCode: "tc"}, true},
+		{"other country", "", db.SavedSearchFilters{CountryCode: "RU"}, false},
+		{"city", "", db.SavedSearchFilters{CityID: loc}, true},
+		{"other city", "", db.SavedSearchFilters{CityID: "moscow"}, false},
+		{"payable", "", db.SavedSearchFilters{PayableOnly: true}, true},
+		{"compensation", "", db.SavedSearchFilters{CompensationTypes: []db.CompensationType{db.CompensationPaid}}, false},
+		{"badge", "", db.SavedSearchFilters{BadgeIDs: badges}, true},
+		{"all badges", "", db.SavedSearchFilters{BadgeIDs: badges, MatchAllBadges: true}, false},
+		{"malformed filters", "poster", db.SavedSearchFilters{}, true},
+	}
+
+	var want []string
+	for i, search := range searches {
+		user, err := testutils.AuthHelper(t, ts.Echo, int64(100001+i), fmt.Sprintf("searcher%d", i), search.name)
+		require.NoError(t, err)
+		require.NoError(t, ts.Storage.UpdateUserVerificationStatus(ctx, user.User.ID, db.VerificationStatusVerified))
+
+		saved, err := ts.Storage.CreateSavedSearch(ctx, db.SavedSearch{UserID: user.User.ID, Name: search.name, Query: search.query, Filters: search.filters})
+		require.NoError(t, err)
+
+		if search.name == "malformed filters" {
+			_, err := ts.Storage.DB().ExecContext(ctx, `UPDATE saved_searches SET filters = '{"city_id":' WHERE id = ?`, saved.ID)
+			require.NoError(t, err)
+		}
+
+		if search.matches {
+			want = append(want, search.name)
+		}
+	}
+
+	matches, err := ts.Storage.MatchSavedSearches(ctx, "collab-1")
+	require.NoError(t, err)
+
+	var got []string
+	for _, match := range matches {
+		got = append(got, match.Search.Name)
+	}
+	assert.Equal(t, want, got)
+}
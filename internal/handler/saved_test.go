@@ -0,0 +1,84 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/peatch-io/peatch/internal/contract"
+	"github.com/peatch-io/peatch/internal/db"
+	"github.com/peatch-io/peatch/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavedCollaborations(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	ctx := context.Background()
+
+	owner, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "owner", "Owner")
+	require.NoError(t, err)
+	viewer, err := testutils.AuthHelper(t, ts.Echo, 100001, "viewer", "Viewer")
+	require.NoError(t, err)
+
+	badges, opps, _ := setupTestRecords(ts.Storage, t)
+	for _, id := range []string{"collab-a", "collab-b", "collab-c"} {
+		require.NoError(t, ts.Storage.CreateCollaboration(ctx, db.CreateCollaborationParams{
+			Collaboration: db.Collaboration{ID: id, UserID: owner.User.ID, Title: "Title " + id, Description: "Description"},
+			BadgeIDs:      badges,
+			OpportunityID: opps[0],
+		}))
+	}
+	for _, id := range []string{"collab-a", "collab-b"} {
+		require.NoError(t, ts.Storage.UpdateCollaborationVerificationStatus(ctx, id, db.VerificationStatusVerified))
+	}
+
+	// Pending collaborations of others can't be saved
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-c/save", "", viewer.Token, http.StatusNotFound)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-a/save", "", viewer.Token, http.StatusOK)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-b/save", "", viewer.Token, http.StatusOK)
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-b/save", "", viewer.Token, http.StatusOK)
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations/collab-a", "", viewer.Token, http.StatusOK)
+	assert.True(t, testutils.ParseResponse[contract.CollaborationResponse](t, rec).IsSaved)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations", "", viewer.Token, http.StatusOK)
+	for _, collab := range testutils.ParseResponse[[]contract.CollaborationResponse](t, rec) {
+		assert.True(t, collab.IsSaved, collab.ID)
+	}
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations/collab-a", "", owner.Token, http.StatusOK)
+	assert.False(t, testutils.ParseResponse[contract.CollaborationResponse](t, rec).IsSaved)
+
+	// Closed and hidden collaborations stay in the list, flagged
+	require.NoError(t, ts.Storage.CloseCollaboration(ctx, owner.User.ID, "collab-a", db.CollaborationStatusFilled))
+	require.NoError(t, ts.Storage.UpdateCollaborationVerificationStatus(ctx, "collab-b", db.VerificationStatusBlocked))
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me/saved", "", viewer.Token, http.StatusOK)
+	saved := testutils.ParseResponse[[]contract.SavedCollaborationResponse](t, rec)
+	require.Len(t, saved, 2)
+
+	byID := map[string]contract.SavedCollaborationResponse{}
+	for _, item := range saved {
+		byID[item.Collaboration.ID] = item
+	}
+
+	assert.True(t, byID["collab-a"].IsClosed)
+	assert.False(t, byID["collab-a"].IsHidden)
+	assert.Equal(t, "Description", byID["collab-a"].Collaboration.Description)
+
+	assert.True(t, byID["collab-b"].IsHidden)
+	assert.Equal(t, "Title collab-b", byID["collab-b"].Collaboration.Title)
+	assert.Empty(t, byID["collab-b"].Collaboration.Description)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodDelete, "/api/collaborations/collab-b/save", "", viewer.Token, http.StatusOK)
+	testutils.PerformRequest(t, ts.Echo, http.MethodDelete, "/api/collaborations/collab-b/save", "", viewer.Token, http.StatusOK)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me/saved?limit=1", "", viewer.Token, http.StatusOK)
+	saved = testutils.ParseResponse[[]contract.SavedCollaborationResponse](t, rec)
+	require.Len(t, saved, 1)
+	assert.Equal(t, "collab-a", saved[0].Collaboration.ID)
+}
@@ -0,0 +1,359 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+
+	"github.com/peatch-io/peatch/internal/contract"
+	"github.com/peatch-io/peatch/internal/db"
+	"github.com/peatch-io/peatch/internal/nanoid"
+)
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+const ErrInvalidRefreshToken = "invalid or expired refresh token"
+
+// sessionStateTTL is how long a session is trusted before access tokens check
+// for its revocation again. Logging out and revoking sessions take effect
+// right away.
+const sessionStateTTL = 30 * time.Second
+
+type cachedSessionState struct {
+	userID    string
+	active    bool
+	expiresAt time.Time
+}
+
+// sessionStates caches whether sessions are still active
+type sessionStates struct {
+	mu     sync.Mutex
+	states map[string]cachedSessionState
+}
+
+func newSessionStates() *sessionStates {
+	return &sessionStates{
+		states: make(map[string]cachedSessionState),
+	}
+}
+
+func (s *sessionStates) get(sessionID string) (bool, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.states[sessionID]
+	if !ok || time.Now().After(state.expiresAt) {
+		delete(s.states, sessionID)
+		return false, false
+	}
+
+	return state.active, true
+}
+
+func (s *sessionStates) set(sessionID, userID string, active bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states[sessionID] = cachedSessionState{userID: userID, active: active, expiresAt: time.Now().Add(sessionStateTTL)}
+}
+
+// invalidate drops a cached session so the next request sees its revocation
+func (s *sessionStates) invalidate(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.states, sessionID)
+}
+
+// invalidateUser drops the cached sessions of a user
+func (s *sessionStates) invalidateUser(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sessionID, state := range s.states {
+		if state.userID == userID {
+			delete(s.states, sessionID)
+		}
+	}
+}
+
+// isSessionActive tells whether the session of the caller's access token is
+// still active, from cache when fresh. Tokens without a session can't be
+// revoked and are refused.
+func (h *Handler) isSessionActive(c echo.Context, sessionID string) (bool, error) {
+	if sessionID == "" {
+		return false, nil
+	}
+
+	if active, ok := h.sessions.get(sessionID); ok {
+		return active, nil
+	}
+
+	active, err := h.storage.IsSessionActive(c.Request().Context(), sessionID)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return false, err
+	}
+
+	h.sessions.set(sessionID, getUserID(c), active)
+
+	return active, nil
+}
+
+// newRefreshToken returns a random refresh token and the hash stored for it
+func newRefreshToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashRefreshToken(token), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// deviceFromUserAgent derives a short device label for the sessions list
+func deviceFromUserAgent(ua string) string {
+	lower := strings.ToLower(ua)
+
+	switch {
+	case strings.Contains(lower, "iphone"):
+		return "iPhone"
+	case strings.Contains(lower, "ipad"):
+		return "iPad"
+	case strings.Contains(lower, "android"):
+		return "Android"
+	case strings.Contains(lower, "mac os"), strings.Contains(lower, "macintosh"):
+		return "Mac"
+	case strings.Contains(lower, "windows"):
+		return "Windows"
+	case strings.Contains(lower, "linux"):
+		return "Linux"
+	default:
+		return "Unknown device"
+	}
+}
+
+// createSession starts a session for a fresh login and issues its tokens
+func (h *Handler) createSession(ctx context.Context, user db.User, meta db.LoginMeta) (contract.AuthResponse, error) {
+	refreshToken, tokenHash, err := newRefreshToken()
+	if err != nil {
+		return contract.AuthResponse{}, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	session := db.Session{
+		ID:        nanoid.Must(),
+		UserID:    user.ID,
+		Device:    deviceFromUserAgent(meta.UserAgent),
+		UserAgent: meta.UserAgent,
+		IP:        meta.IP,
+		Country:   meta.Country,
+		City:      meta.City,
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+	}
+
+	if err := h.storage.CreateSession(ctx, session, tokenHash); err != nil {
+		return contract.AuthResponse{}, fmt.Errorf("failed to store session: %w", err)
+	}
+
+	return h.authResponse(user, session, refreshToken)
+}
+
+func (h *Handler) authResponse(user db.User, session db.Session, refreshToken string) (contract.AuthResponse, error) {
+	expiresAt := time.Now().Add(accessTokenTTL)
+
+	token, err := generateJWT(user.ID, user.ChatID, string(user.LanguageCode), session.ID, expiresAt, h.config.JWTSecret)
+	if err != nil {
+		return contract.AuthResponse{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return contract.AuthResponse{
+		Token:            token,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+		User:             contract.ToUserResponse(user),
+	}, nil
+}
+
+func getSessionID(c echo.Context) string {
+	user := c.Get("user").(*jwt.Token)
+	claims := user.Claims.(*contract.JWTClaims)
+	return claims.SessionID
+}
+
+// RefreshToken godoc
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access token. The refresh token is rotated; reusing an old one revokes the session.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body contract.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} contract.AuthResponse
+// @Failure 400 {object} contract.ErrorResponse
+// @Failure 401 {object} contract.ErrorResponse
+// @Failure 403 {object} contract.ErrorResponse
+// @Failure 429 {object} contract.ErrorResponse "Rate limited (code rate_limited), see Retry-After"
+// @Router /auth/refresh [post]
+func (h *Handler) handleRefreshToken(c echo.Context) error {
+	var req contract.RefreshTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
+	}
+
+	ctx := c.Request().Context()
+
+	refreshToken, tokenHash, err := newRefreshToken()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to generate refresh token").WithInternal(err)
+	}
+
+	session, err := h.storage.RotateSession(ctx, hashRefreshToken(req.RefreshToken), tokenHash, time.Now().Add(refreshTokenTTL))
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) || errors.Is(err, db.ErrSessionRevoked) {
+			return echo.NewHTTPError(http.StatusUnauthorized, ErrInvalidRefreshToken).WithInternal(err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to refresh session").WithInternal(err)
+	}
+
+	user, err := h.storage.GetUserByID(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusUnauthorized, ErrInvalidRefreshToken).WithInternal(err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get user").WithInternal(err)
+	}
+
+	if user.VerificationStatus == db.VerificationStatusBlocked {
+		if _, err := h.storage.RevokeUserSessions(ctx, user.ID, ""); err != nil {
+			h.logger.Error("failed to revoke sessions", slog.String("user_id", user.ID), slog.String("error", err.Error()))
+		}
+		h.sessions.invalidateUser(user.ID)
+		return blockedUserError()
+	}
+
+	resp, err := h.authResponse(user, session, refreshToken)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "jwt library error").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Logout godoc
+// @Summary Logout
+// @Description Revoke the session a refresh token belongs to
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body contract.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} contract.StatusResponse
+// @Failure 400 {object} contract.ErrorResponse
+// @Router /auth/logout [post]
+func (h *Handler) handleLogout(c echo.Context) error {
+	var req contract.RefreshTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, ErrInvalidRequest).WithInternal(err)
+	}
+
+	// Logging out of a session that is already gone is not an error
+	sessionID, err := h.storage.RevokeSessionByToken(c.Request().Context(), hashRefreshToken(req.RefreshToken))
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to revoke session").WithInternal(err)
+	}
+
+	h.sessions.invalidate(sessionID)
+
+	return c.JSON(http.StatusOK, contract.StatusResponse{Success: true})
+}
+
+// ListSessions godoc
+// @Summary List sessions
+// @Description List the current user's active sessions
+// @Tags users
+// @Produce json
+// @Success 200 {array} contract.SessionResponse
+// @Security ApiKeyAuth
+// @Router /api/users/me/sessions [get]
+func (h *Handler) handleListSessions(c echo.Context) error {
+	sessions, err := h.storage.ListUserSessions(c.Request().Context(), getUserID(c))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get sessions").WithInternal(err)
+	}
+
+	currentSessionID := getSessionID(c)
+
+	resp := make([]contract.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		resp[i] = contract.ToSessionResponse(session, currentSessionID)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// RevokeOtherSessions godoc
+// @Summary Revoke other sessions
+// @Description Log out everywhere except the current session
+// @Tags users
+// @Produce json
+// @Success 200 {object} contract.StatusResponse
+// @Security ApiKeyAuth
+// @Router /api/users/me/sessions [delete]
+func (h *Handler) handleRevokeOtherSessions(c echo.Context) error {
+	if _, err := h.storage.RevokeUserSessions(c.Request().Context(), getUserID(c), getSessionID(c)); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to revoke sessions").WithInternal(err)
+	}
+
+	h.sessions.invalidateUser(getUserID(c))
+
+	return c.JSON(http.StatusOK, contract.StatusResponse{Success: true})
+}
+
+// RevokeSession godoc
+// @Summary Revoke session
+// @Description Log out a single session
+// @Tags users
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} contract.StatusResponse
+// @Failure 404 {object} contract.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/users/me/sessions/{id} [delete]
+func (h *Handler) handleRevokeSession(c echo.Context) error {
+	if err := h.storage.RevokeUserSession(c.Request().Context(), getUserID(c), c.Param("id")); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "session not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to revoke session").WithInternal(err)
+	}
+
+	h.sessions.invalidate(c.Param("id"))
+
+	return c.JSON(http.StatusOK, contract.StatusResponse{Success: true})
+}
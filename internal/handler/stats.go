@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/peatch-io/peatch/internal/contract"
+	"github.com/peatch-io/peatch/internal/db"
+)
+
+// maxStatsDays is how far back the daily series go
+const maxStatsDays = 90
+
+// recordEvent records an analytics event, failures only get logged
+func (h *Handler) recordEvent(ctx context.Context, event db.AnalyticsEvent) {
+	if err := h.storage.RecordAnalyticsEvent(ctx, event); err != nil {
+		h.logger.Warn("failed to record analytics event",
+			slog.String("kind", string(event.Kind)),
+			slog.String("target_id", event.TargetID),
+			slog.String("error", err.Error()))
+	}
+}
+
+// recordDeepLink records the open of a c_<id> or u_<id> payload. Other
+// payloads aren't about a profile or collaboration and are skipped.
+func (h *Handler) recordDeepLink(ctx context.Context, viewerID, payload string) {
+	event := db.AnalyticsEvent{Kind: db.AnalyticsEventDeepLink, ViewerID: viewerID}
+
+	switch {
+	case strings.HasPrefix(payload, PayloadCollaboration):
+		event.Target = db.AnalyticsTargetCollaboration
+		event.TargetID = strings.TrimPrefix(payload, PayloadCollaboration)
+	case strings.HasPrefix(payload, PayloadUser):
+		event.Target = db.AnalyticsTargetUser
+		event.TargetID = strings.TrimPrefix(payload, PayloadUser)
+	default:
+		return
+	}
+
+	if event.TargetID != "" {
+		h.recordEvent(ctx, event)
+	}
+}
+
+// statsSince is the first day of a series of the days query param, 30 by default
+func statsSince(c echo.Context, now time.Time) time.Time {
+	days := min(max(parseIntQuery(c, "days", 30), 1), maxStatsDays)
+	return now.AddDate(0, 0, 1-days)
+}
+
+// handleGetMyStats godoc
+// @Summary My stats
+// @Description Daily views, deep-link opens and applications of the current user's profile and of the collaborations they posted. Views are counted once per viewer a day.
+// @Tags users
+// @Produce json
+// @Param days query int false "Days, 30 by default and at most 90"
+// @Success 200 {object} contract.UserStatsResponse
+// @Router /api/users/me/stats [get]
+func (h *Handler) handleGetMyStats(c echo.Context) error {
+	ctx := c.Request().Context()
+	uid := getUserID(c)
+	now := time.Now()
+	since := statsSince(c, now)
+
+	profile, err := h.storage.GetDailyStats(ctx, db.AnalyticsTargetUser, []string{uid}, since, now)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get stats").WithInternal(err)
+	}
+
+	collabs, err := h.storage.GetUserCollaborations(ctx, uid)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get collaborations").WithInternal(err)
+	}
+
+	ids := make([]string, len(collabs))
+	for i, collab := range collabs {
+		ids[i] = collab.ID
+	}
+
+	collabStats, err := h.storage.GetDailyStats(ctx, db.AnalyticsTargetCollaboration, ids, since, now)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get stats").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, contract.UserStatsResponse{
+		Profile:        contract.ToStatsResponse(profile),
+		Collaborations: contract.ToStatsResponse(collabStats),
+	})
+}
+
+// handleGetCollaborationStats godoc
+// @Summary Collaboration stats
+// @Description Daily views, deep-link opens and applications of a collaboration, for its team only. Views are counted once per viewer a day.
+// @Tags collaborations
+// @Produce json
+// @Param id path string true "Collaboration ID"
+// @Param days query int false "Days, 30 by default and at most 90"
+// @Success 200 {object} contract.StatsResponse
+// @Failure 404 {object} contract.ErrorResponse "Collaboration not found or not on the team"
+// @Router /api/collaborations/{id}/stats [get]
+func (h *Handler) handleGetCollaborationStats(c echo.Context) error {
+	ctx := c.Request().Context()
+	uid := getUserID(c)
+
+	collab, err := h.storage.GetCollaborationByID(ctx, uid, c.Param("id"))
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get collaboration").WithInternal(err)
+	}
+	if err != nil || collab.MemberRole(uid) == "" {
+		return echo.NewHTTPError(http.StatusNotFound, "collaboration not found")
+	}
+
+	now := time.Now()
+	stats, err := h.storage.GetDailyStats(ctx, db.AnalyticsTargetCollaboration, []string{collab.ID}, statsSince(c, now), now)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get stats").WithInternal(err)
+	}
+
+	return c.JSON(http.StatusOK, contract.ToStatsResponse(stats))
+}
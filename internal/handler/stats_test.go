@@ -0,0 +1,84 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/peatch-io/peatch/internal/contract"
+	"github.com/peatch-io/peatch/internal/db"
+	"github.com/peatch-io/peatch/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	ctx := context.Background()
+
+	owner, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "owner", "Owner")
+	require.NoError(t, err)
+	viewer, err := testutils.AuthHelper(t, ts.Echo, 100001, "viewer", "Viewer")
+	require.NoError(t, err)
+	other, err := testutils.AuthHelper(t, ts.Echo, 100002, "other", "Other")
+	require.NoError(t, err)
+
+	badges, opps, _ := setupTestRecords(ts.Storage, t)
+	require.NoError(t, ts.Storage.CreateCollaboration(ctx, db.CreateCollaborationParams{
+		Collaboration: db.Collaboration{ID: "collab-1", UserID: owner.User.ID, Title: "Band", Description: "Description"},
+		BadgeIDs:      badges,
+		OpportunityID: opps[0],
+	}))
+	require.NoError(t, ts.Storage.UpdateCollaborationVerificationStatus(ctx, "collab-1", db.VerificationStatusVerified))
+	require.NoError(t, ts.Storage.UpdateUserVerificationStatus(ctx, owner.User.ID, db.VerificationStatusVerified))
+	require.NoError(t, ts.Storage.UpdateUserVerificationStatus(ctx, viewer.User.ID, db.VerificationStatusVerified))
+
+	myStats := func() contract.UserStatsResponse {
+		rec := testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me/stats?days=7", "", owner.Token, http.StatusOK)
+		return testutils.ParseResponse[contract.UserStatsResponse](t, rec)
+	}
+
+	// Views count once per viewer a day, owners looking at their own don't count
+	for _, token := range []string{viewer.Token, viewer.Token, other.Token, owner.Token} {
+		testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations/collab-1", "", token, http.StatusOK)
+		testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/"+owner.User.ID, "", token, http.StatusOK)
+	}
+	testutils.PerformRequest(t, ts.Echo, http.MethodPost, "/api/collaborations/collab-1/interest", `{"message": "Hi"}`, viewer.Token, http.StatusOK)
+	require.NoError(t, ts.Storage.RecordAnalyticsEvent(ctx, db.AnalyticsEvent{
+		Kind: db.AnalyticsEventDeepLink, Target: db.AnalyticsTargetCollaboration, TargetID: "collab-1", ViewerID: other.User.ID,
+	}))
+
+	stats := myStats()
+	assert.Equal(t, 2, stats.Profile.Views)
+	assert.Equal(t, 2, stats.Collaborations.Views)
+	assert.Equal(t, 1, stats.Collaborations.Interests)
+	assert.Equal(t, 1, stats.Collaborations.DeepLinks)
+	require.Len(t, stats.Profile.Daily, 7)
+	assert.Equal(t, time.Now().UTC().Format("2006-01-02"), stats.Profile.Daily[6].Date)
+	assert.Equal(t, 2, stats.Profile.Daily[6].Views)
+
+	// Only the team sees the stats of a collaboration
+	testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations/collab-1/stats", "", viewer.Token, http.StatusNotFound)
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/collaborations/collab-1/stats", "", owner.Token, http.StatusOK)
+	collabStats := testutils.ParseResponse[contract.StatsResponse](t, rec)
+	assert.Equal(t, 2, collabStats.Views)
+	assert.Len(t, collabStats.Daily, 30)
+
+	// Rolled up days add up to the same totals, and later events still count
+	rolledUp, err := ts.Storage.RollupAnalytics(ctx, time.Now().Add(48*time.Hour))
+	require.NoError(t, err)
+	assert.EqualValues(t, 6, rolledUp)
+
+	require.NoError(t, ts.Storage.RecordAnalyticsEvent(ctx, db.AnalyticsEvent{
+		Kind: db.AnalyticsEventDeepLink, Target: db.AnalyticsTargetCollaboration, TargetID: "collab-1", ViewerID: viewer.User.ID,
+	}))
+
+	stats = myStats()
+	assert.Equal(t, 2, stats.Profile.Views)
+	assert.Equal(t, 2, stats.Collaborations.Views)
+	assert.Equal(t, 1, stats.Collaborations.Interests)
+	assert.Equal(t, 2, stats.Collaborations.DeepLinks)
+}
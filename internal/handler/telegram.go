@@ -2,17 +2,24 @@ package handler
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 
 	telegram "github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"github.com/labstack/echo/v4"
 	"github.com/peatch-io/peatch/internal/db"
 	"github.com/peatch-io/peatch/internal/nanoid"
+	"github.com/peatch-io/peatch/internal/notification"
 )
 
 type LocalizedMessages map[db.LanguageCode]map[string]string
@@ -22,6 +29,32 @@ const (
 	MsgKeyOpenWebApp     = "openWebApp"
 	MsgKeyLaunch         = "launch"
 	MsgKeyOpenWebAppMenu = "openWebAppMenu"
+
+	MsgKeyHelp                   = "help"
+	MsgKeyNotFound               = "notFound"
+	MsgKeyViewCollaboration      = "viewCollaboration"
+	MsgKeyViewProfile            = "viewProfile"
+	MsgKeyEditProfile            = "editProfile"
+	MsgKeyProfileIncomplete      = "profileIncomplete"
+	MsgKeySettings               = "settings"
+	MsgKeyOpenSettings           = "openSettings"
+	MsgKeyEnabled                = "enabled"
+	MsgKeyDisabled               = "disabled"
+	MsgKeySearchUsage            = "searchUsage"
+	MsgKeySearchEmpty            = "searchEmpty"
+	MsgKeySearchResults          = "searchResults"
+	MsgKeyMyEmpty                = "myEmpty"
+	MsgKeyMyList                 = "myList"
+	MsgKeyPublishCollaboration   = "publishCollaboration"
+	MsgKeyPayable                = "payable"
+	MsgKeyEquity                 = "equity"
+	MsgKeyRevShare               = "revShare"
+	MsgKeyVolunteer              = "volunteer"
+	MsgKeyBarter                 = "barter"
+	MsgKeyExtended               = "extended"
+	MsgKeyCannotExtend           = "cannotExtend"
+	MsgKeyApplicationAnswered    = "applicationAnswered"
+	MsgKeyApplicationUnavailable = "applicationUnavailable"
 )
 
 var messages = LocalizedMessages{
@@ -30,17 +63,110 @@ var messages = LocalizedMessages{
 		MsgKeyOpenWebApp:     "You can open the web app by tapping the button below.",
 		MsgKeyLaunch:         "Launch",
 		MsgKeyOpenWebAppMenu: "Open Web App",
+
+		MsgKeyHelp: "Here is what I can do:\n" +
+			"/profile - show your profile card\n" +
+			"/my - list your collaborations\n" +
+			"/search <text> - find collaborations\n" +
+			"/settings - show your settings\n" +
+			"/help - show this message",
+		MsgKeyNotFound:               "Sorry, this link is no longer available.",
+		MsgKeyViewCollaboration:      "View Collaboration",
+		MsgKeyViewProfile:            "View Profile",
+		MsgKeyEditProfile:            "Edit Profile",
+		MsgKeyProfileIncomplete:      "Your profile isn't complete yet. Add your name, title, description, location, badges and opportunities so people can find you.",
+		MsgKeySettings:               "Your settings:\nNotifications: %s\nProfile visibility: %s\nLanguage: %s",
+		MsgKeyOpenSettings:           "Change Settings",
+		MsgKeyEnabled:                "on",
+		MsgKeyDisabled:               "off",
+		MsgKeySearchUsage:            "Tell me what to look for, e.g. /search designer",
+		MsgKeySearchEmpty:            "Nothing found for \"%s\".",
+		MsgKeySearchResults:          "Collaborations matching \"%s\":",
+		MsgKeyMyEmpty:                "You haven't published any collaborations yet.",
+		MsgKeyMyList:                 "Your collaborations:",
+		MsgKeyPublishCollaboration:   "Publish Collaboration",
+		MsgKeyPayable:                "Paid",
+		MsgKeyEquity:                 "Equity",
+		MsgKeyRevShare:               "Revenue share",
+		MsgKeyVolunteer:              "Volunteer",
+		MsgKeyBarter:                 "Barter",
+		MsgKeyExtended:               "Extended until %s",
+		MsgKeyCannotExtend:           "This collaboration is closed and can't be extended",
+		MsgKeyApplicationAnswered:    "Done, the applicant has been notified",
+		MsgKeyApplicationUnavailable: "This application was withdrawn",
 	},
 	db.LanguageRU: {
 		MsgKeyWelcome:        "Привет!\n*Peatch* - социальная сеть для совместной работы. Кнопка ниже, откроет веб-приложение!",
 		MsgKeyOpenWebApp:     "Вы можете открыть веб-приложение, нажав кнопку ниже.",
 		MsgKeyLaunch:         "Запустить",
 		MsgKeyOpenWebAppMenu: "Открыть веб-app",
+
+		MsgKeyHelp: "Вот что я умею:\n" +
+			"/profile - показать ваш профиль\n" +
+			"/my - список ваших коллабораций\n" +
+			"/search <текст> - найти коллаборации\n" +
+			"/settings - показать настройки\n" +
+			"/help - показать это сообщение",
+		MsgKeyNotFound:               "Извините, эта ссылка больше недоступна.",
+		MsgKeyViewCollaboration:      "Посмотреть коллаборацию",
+		MsgKeyViewProfile:            "Посмотреть профиль",
+		MsgKeyEditProfile:            "Редактировать профиль",
+		MsgKeyProfileIncomplete:      "Ваш профиль ещё не заполнен. Добавьте имя, должность, описание, город, бейджи и возможности, чтобы вас могли найти.",
+		MsgKeySettings:               "Ваши настройки:\nУведомления: %s\nВидимость профиля: %s\nЯзык: %s",
+		MsgKeyOpenSettings:           "Изменить настройки",
+		MsgKeyEnabled:                "вкл",
+		MsgKeyDisabled:               "выкл",
+		MsgKeySearchUsage:            "Напишите, что искать, например /search дизайнер",
+		MsgKeySearchEmpty:            "По запросу \"%s\" ничего не найдено.",
+		MsgKeySearchResults:          "Коллаборации по запросу \"%s\":",
+		MsgKeyMyEmpty:                "Вы ещё не опубликовали ни одной коллаборации.",
+		MsgKeyMyList:                 "Ваши коллаборации:",
+		MsgKeyPublishCollaboration:   "Опубликовать проект",
+		MsgKeyPayable:                "Оплачивается",
+		MsgKeyEquity:                 "Доля в проекте",
+		MsgKeyRevShare:               "Доля от выручки",
+		MsgKeyVolunteer:              "Волонтёрство",
+		MsgKeyBarter:                 "Бартер",
+		MsgKeyExtended:               "Продлено до %s",
+		MsgKeyCannotExtend:           "Коллаборация закрыта, продлить её нельзя",
+		MsgKeyApplicationAnswered:    "Готово, мы сообщили об этом откликнувшемуся",
+		MsgKeyApplicationUnavailable: "Этот отклик был отозван",
 	},
 }
 
+// webhookSecretHeader carries the secret_token registered with setWebhook
+const webhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// Bot keys scope processed updates and polling offsets, since Telegram
+// numbers updates per bot
+const (
+	mainBotKey  = "main"
+	adminBotKey = "admin"
+)
+
+// updateDispatcher handles an update for one of the bots
+type updateDispatcher func(ctx context.Context, update models.Update)
+
+// deriveWebhookSecret builds a stable secret from the bot token, so every
+// instance registers and checks the same value without extra configuration
+func deriveWebhookSecret(botToken string) string {
+	mac := hmac.New(sha256.New, []byte(botToken))
+	mac.Write([]byte("webhook"))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 func (h *Handler) HandleWebhook(c echo.Context) error {
+	return h.handleWebhookRequest(c, h.config.WebhookSecret, mainBotKey, h.handleUpdate)
+}
+
+func (h *Handler) handleWebhookRequest(c echo.Context, expectedSecret, botKey string, dispatch updateDispatcher) error {
 	ctx := c.Request().Context()
+
+	secret := c.Request().Header.Get(webhookSecretHeader)
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(expectedSecret)) != 1 {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid webhook secret")
+	}
+
 	var update models.Update
 
 	if err := json.NewDecoder(c.Request().Body).Decode(&update); err != nil {
@@ -48,25 +174,124 @@ func (h *Handler) HandleWebhook(c echo.Context) error {
 		return c.NoContent(http.StatusOK)
 	}
 
+	if err := h.processUpdate(ctx, botKey, update, dispatch); err != nil {
+		h.logger.Error("failed to look up update",
+			slog.String("bot", botKey),
+			slog.Int64("update_id", update.ID),
+			slog.String("error", err.Error()))
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// processUpdate handles an update once, whether it came from the webhook or
+// long polling. The update is only marked after it was handled, so one cut
+// short by a crash or restart is handled again when Telegram redelivers it.
+// It only fails when the update couldn't be looked up, so the caller can have
+// Telegram deliver it again.
+func (h *Handler) processUpdate(ctx context.Context, botKey string, update models.Update, dispatch updateDispatcher) error {
+	processed, err := h.storage.IsUpdateProcessed(ctx, botKey, update.ID)
+	if err != nil {
+		return err
+	}
+
+	if processed {
+		h.logger.Info("skipping duplicate update", slog.String("bot", botKey), slog.Int64("update_id", update.ID))
+		return nil
+	}
+
+	dispatch(ctx, update)
+
+	// The update was handled already, asking for it again would only repeat it
+	if _, err := h.storage.MarkUpdateProcessed(context.WithoutCancel(ctx), botKey, update.ID); err != nil {
+		h.logger.Error("failed to mark update processed",
+			slog.String("bot", botKey),
+			slog.Int64("update_id", update.ID),
+			slog.String("error", err.Error()))
+	}
+
+	return nil
+}
+
+// handleUpdate dispatches a single bot update
+func (h *Handler) handleUpdate(ctx context.Context, update models.Update) {
+	if update.InlineQuery != nil {
+		if err := h.handleInlineQuery(ctx, update.InlineQuery); err != nil {
+			h.logger.Error("handle inline query failed", slog.String("error", err.Error()))
+		}
+		return
+	}
+
+	if update.CallbackQuery != nil {
+		if err := h.handleCallbackQuery(ctx, update.CallbackQuery); err != nil {
+			h.logger.Error("handle callback query failed", slog.String("error", err.Error()))
+		}
+		return
+	}
+
 	if update.Message == nil {
-		return c.NoContent(http.StatusOK)
+		return
 	}
 
 	if update.Message.Chat.Type != "private" {
 		h.logger.Info("ignoring non-private chat", slog.String("chat_type", update.Message.Chat.Type))
-		return c.NoContent(http.StatusOK)
+		return
 	}
 
 	if update.Message.From.IsBot {
 		h.logger.Info("ignoring message from bot", slog.String("username", update.Message.From.Username))
-		return c.NoContent(http.StatusOK)
+		return
 	}
 
 	if err := h.handleMessage(ctx, update); err != nil {
 		h.logger.Error("handle message failed", slog.String("error", err.Error()))
 	}
+}
 
-	return c.NoContent(http.StatusOK)
+// handleCallbackQuery handles the buttons of bot notifications
+func (h *Handler) handleCallbackQuery(ctx context.Context, query *models.CallbackQuery) error {
+	user, err := h.storage.GetUserByChatID(ctx, query.From.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	msgs := messages[user.LanguageCode]
+	if msgs == nil {
+		msgs = messages[db.LanguageEN]
+	}
+
+	var answer string
+
+	switch {
+	case strings.HasPrefix(query.Data, notification.CallbackExtendCollaboration):
+		collabID := strings.TrimPrefix(query.Data, notification.CallbackExtendCollaboration)
+		expiresAt, err := h.storage.ExtendCollaboration(ctx, user.ID, collabID, db.CollaborationExtension)
+		switch {
+		case errors.Is(err, db.ErrNotFound):
+			answer = msgs[MsgKeyCannotExtend]
+		case err != nil:
+			return fmt.Errorf("failed to extend collaboration: %w", err)
+		default:
+			answer = fmt.Sprintf(msgs[MsgKeyExtended], expiresAt.Format(time.DateOnly))
+		}
+	case strings.HasPrefix(query.Data, notification.CallbackApplicationAccept):
+		answer, err = h.answerApplicationFromBot(ctx, user, strings.TrimPrefix(query.Data, notification.CallbackApplicationAccept), db.ApplicationStatusAccepted)
+	case strings.HasPrefix(query.Data, notification.CallbackApplicationShortlist):
+		answer, err = h.answerApplicationFromBot(ctx, user, strings.TrimPrefix(query.Data, notification.CallbackApplicationShortlist), db.ApplicationStatusShortlisted)
+	case strings.HasPrefix(query.Data, notification.CallbackApplicationDecline):
+		answer, err = h.answerApplicationFromBot(ctx, user, strings.TrimPrefix(query.Data, notification.CallbackApplicationDecline), db.ApplicationStatusDeclined)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to answer application: %w", err)
+	}
+
+	_, err = h.bot.AnswerCallbackQuery(ctx, &telegram.AnswerCallbackQueryParams{
+		CallbackQueryID: query.ID,
+		Text:            answer,
+	})
+
+	return err
 }
 
 func (h *Handler) handleMessage(ctx context.Context, update models.Update) error {
@@ -78,8 +303,9 @@ func (h *Handler) handleMessage(ctx context.Context, update models.Update) error
 	webAppKeyboard := createWebAppKeyboard(msgs[MsgKeyLaunch], h.config.WebAppURL)
 
 	user, err := h.storage.GetUserByChatID(ctx, chatID)
+	isNewUser := errors.Is(err, db.ErrNotFound)
 
-	if errors.Is(err, db.ErrNotFound) {
+	if isNewUser {
 
 		h.logger.Info("creating new user",
 			slog.String("chat_id", fmt.Sprintf("%d", chatID)),
@@ -106,27 +332,19 @@ func (h *Handler) handleMessage(ctx context.Context, update models.Update) error
 			slog.String("error", err.Error()))
 		return err
 	} else {
-
 		h.logger.Info("existing user interaction",
 			slog.Int64("chat_id", chatID),
 			slog.String("user_id", user.ID))
+	}
 
-		params := &telegram.SendMessageParams{
-			ChatID:      chatID,
-			Text:        msgs[MsgKeyOpenWebApp],
-			ReplyMarkup: webAppKeyboard,
-			ParseMode:   "Markdown",
-		}
+	cmd := parseBotCommand(update.Message.Text)
 
-		if _, err := h.bot.SendMessage(ctx, params); err != nil {
-			h.logger.Error("failed to send message",
-				slog.Int64("chat_id", chatID),
-				slog.String("error", err.Error()))
-			return err
-		}
+	// New users have just been greeted, a plain /start needs nothing more
+	if isNewUser && (cmd.Name == "" || (cmd.Name == CommandStart && cmd.Args == "")) {
+		return nil
 	}
 
-	return nil
+	return h.handleCommand(ctx, user, isNewUser, lang, cmd)
 }
 
 func determineLanguage(langCode string) db.LanguageCode {
@@ -0,0 +1,459 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	telegram "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/peatch-io/peatch/internal/db"
+)
+
+const (
+	CommandStart    = "start"
+	CommandHelp     = "help"
+	CommandProfile  = "profile"
+	CommandSettings = "settings"
+	CommandSearch   = "search"
+	CommandMy       = "my"
+)
+
+// Deep-link payload prefixes, shared with the startapp links sent in notifications
+const (
+	PayloadCollaboration = "c_"
+	PayloadUser          = "u_"
+	PayloadReferral      = "ref_"
+)
+
+const (
+	defaultCardPhoto     = "https://assets.peatch.io/peatch-preview.png"
+	maxCaptionLength     = 1024
+	maxDescriptionInCard = 300
+	botSearchLimit       = 5
+	botListLimit         = 10
+)
+
+var commandDescriptions = map[db.LanguageCode][]models.BotCommand{
+	db.LanguageEN: {
+		{Command: CommandProfile, Description: "Show your profile"},
+		{Command: CommandMy, Description: "Your collaborations"},
+		{Command: CommandSearch, Description: "Find collaborations"},
+		{Command: CommandSettings, Description: "Your settings"},
+		{Command: CommandHelp, Description: "What the bot can do"},
+	},
+	db.LanguageRU: {
+		{Command: CommandProfile, Description: "Показать профиль"},
+		{Command: CommandMy, Description: "Ваши коллаборации"},
+		{Command: CommandSearch, Description: "Найти коллаборации"},
+		{Command: CommandSettings, Description: "Ваши настройки"},
+		{Command: CommandHelp, Description: "Что умеет бот"},
+	},
+}
+
+type botCommand struct {
+	Name string
+	Args string
+}
+
+// parseBotCommand splits "/cmd@bot_name args" into its parts. Plain text
+// yields an empty command.
+func parseBotCommand(text string) botCommand {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "/") {
+		return botCommand{}
+	}
+
+	name, args, _ := strings.Cut(text, " ")
+	name = strings.TrimPrefix(name, "/")
+	if at := strings.Index(name, "@"); at >= 0 {
+		name = name[:at]
+	}
+
+	return botCommand{
+		Name: strings.ToLower(name),
+		Args: strings.TrimSpace(args),
+	}
+}
+
+// setBotCommands registers the command menu, English being the default
+func (h *Handler) setBotCommands(ctx context.Context) {
+	for lang, commands := range commandDescriptions {
+		params := &telegram.SetMyCommandsParams{Commands: commands}
+		if lang != db.LanguageEN {
+			params.LanguageCode = string(lang)
+		}
+
+		if _, err := h.bot.SetMyCommands(ctx, params); err != nil {
+			h.logger.Error("failed to set bot commands",
+				slog.String("lang", string(lang)),
+				slog.String("error", err.Error()))
+		}
+	}
+}
+
+func (h *Handler) handleCommand(ctx context.Context, user db.User, isNewUser bool, lang db.LanguageCode, cmd botCommand) error {
+	switch cmd.Name {
+	case CommandStart:
+		return h.handleStartCommand(ctx, user, isNewUser, lang, cmd.Args)
+	case CommandHelp:
+		return h.sendBotMessage(ctx, user.ChatID, messages[lang][MsgKeyHelp], nil)
+	case CommandProfile:
+		return h.handleProfileCommand(ctx, user, lang)
+	case CommandSettings:
+		return h.handleSettingsCommand(ctx, user, lang)
+	case CommandSearch:
+		return h.handleSearchCommand(ctx, user, lang, cmd.Args)
+	case CommandMy:
+		return h.handleMyCommand(ctx, user, lang)
+	default:
+		msgs := messages[lang]
+		return h.sendBotMessage(ctx, user.ChatID, msgs[MsgKeyOpenWebApp], createWebAppKeyboard(msgs[MsgKeyLaunch], h.config.WebAppURL))
+	}
+}
+
+// handleStartCommand resolves /start deep-link payloads: c_<id> and u_<id>
+// reply with a card, ref_<code> records who invited a new user.
+// Anything else gets the regular web app prompt.
+func (h *Handler) handleStartCommand(ctx context.Context, user db.User, isNewUser bool, lang db.LanguageCode, payload string) error {
+	h.recordDeepLink(ctx, user.ID, payload)
+
+	switch {
+	case strings.HasPrefix(payload, PayloadCollaboration):
+		return h.sendCollaborationCard(ctx, user, lang, strings.TrimPrefix(payload, PayloadCollaboration))
+	case strings.HasPrefix(payload, PayloadUser):
+		return h.sendUserCard(ctx, user, lang, strings.TrimPrefix(payload, PayloadUser))
+	case strings.HasPrefix(payload, PayloadReferral) && isNewUser:
+		h.recordReferral(ctx, user, strings.TrimPrefix(payload, PayloadReferral))
+		return nil
+	}
+
+	msgs := messages[lang]
+	return h.sendBotMessage(ctx, user.ChatID, msgs[MsgKeyOpenWebApp], createWebAppKeyboard(msgs[MsgKeyLaunch], h.config.WebAppURL))
+}
+
+// recordReferral links a new user to the referrer identified by user ID or username
+func (h *Handler) recordReferral(ctx context.Context, user db.User, code string) {
+	referrer, err := h.storage.GetUserProfile(ctx, user.ID, code)
+	if err != nil {
+		h.logger.Info("unknown referral code",
+			slog.String("code", code),
+			slog.String("user_id", user.ID))
+		return
+	}
+
+	if referrer.ID == user.ID {
+		h.logger.Info("ignored self referral", slog.String("user_id", user.ID))
+		return
+	}
+
+	if err := h.storage.SetUserReferrer(ctx, user.ID, referrer.ID); err != nil {
+		h.logger.Error("failed to set user referrer",
+			slog.String("user_id", user.ID),
+			slog.String("referrer_id", referrer.ID),
+			slog.String("error", err.Error()))
+	}
+}
+
+func (h *Handler) sendCollaborationCard(ctx context.Context, viewer db.User, lang db.LanguageCode, collabID string) error {
+	msgs := messages[lang]
+
+	collab, err := h.storage.GetCollaborationByID(ctx, viewer.ID, collabID)
+	if errors.Is(err, db.ErrNotFound) {
+		return h.sendBotMessage(ctx, viewer.ChatID, msgs[MsgKeyNotFound], nil)
+	} else if err != nil {
+		return fmt.Errorf("failed to get collaboration: %w", err)
+	}
+
+	keyboard := createStartAppKeyboard(msgs[MsgKeyViewCollaboration], h.config.BotWebApp, PayloadCollaboration+collab.ID)
+
+	return h.sendCard(ctx, viewer.ChatID, avatarPhotoURL(collab.User.AvatarURL), collaborationCardText(collab, lang), keyboard)
+}
+
+func (h *Handler) sendUserCard(ctx context.Context, viewer db.User, lang db.LanguageCode, userID string) error {
+	msgs := messages[lang]
+
+	user, err := h.storage.GetUserProfile(ctx, viewer.ID, userID)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// Only public profiles are shared, except your own
+	isPublic := user.VerificationStatus == db.VerificationStatusVerified && user.HiddenAt == nil
+	if errors.Is(err, db.ErrNotFound) || (!isPublic && user.ID != viewer.ID) {
+		return h.sendBotMessage(ctx, viewer.ChatID, msgs[MsgKeyNotFound], nil)
+	}
+
+	keyboard := createStartAppKeyboard(msgs[MsgKeyViewProfile], h.config.BotWebApp, PayloadUser+user.ID)
+
+	return h.sendCard(ctx, viewer.ChatID, avatarPhotoURL(user.AvatarURL), userCardText(user, lang), keyboard)
+}
+
+func (h *Handler) handleProfileCommand(ctx context.Context, user db.User, lang db.LanguageCode) error {
+	msgs := messages[lang]
+
+	editKeyboard := createWebAppKeyboard(msgs[MsgKeyEditProfile], h.config.WebAppURL+"/users/edit")
+
+	if !user.IsProfileComplete() {
+		return h.sendBotMessage(ctx, user.ChatID, msgs[MsgKeyProfileIncomplete], editKeyboard)
+	}
+
+	keyboard := createStartAppKeyboard(msgs[MsgKeyViewProfile], h.config.BotWebApp, PayloadUser+user.ID)
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, editKeyboard.InlineKeyboard...)
+
+	return h.sendCard(ctx, user.ChatID, avatarPhotoURL(user.AvatarURL), userCardText(user, lang), keyboard)
+}
+
+func (h *Handler) handleSettingsCommand(ctx context.Context, user db.User, lang db.LanguageCode) error {
+	msgs := messages[lang]
+
+	notifications := msgs[MsgKeyDisabled]
+	if user.NotificationsEnabledAt != nil {
+		notifications = msgs[MsgKeyEnabled]
+	}
+
+	visibility := msgs[MsgKeyEnabled]
+	if user.HiddenAt != nil {
+		visibility = msgs[MsgKeyDisabled]
+	}
+
+	text := fmt.Sprintf(msgs[MsgKeySettings], notifications, visibility, user.LanguageCode)
+
+	return h.sendBotMessage(ctx, user.ChatID, text, createWebAppKeyboard(msgs[MsgKeyOpenSettings], h.config.WebAppURL+"/users/edit"))
+}
+
+func (h *Handler) handleSearchCommand(ctx context.Context, user db.User, lang db.LanguageCode, search string) error {
+	msgs := messages[lang]
+
+	if search == "" {
+		return h.sendBotMessage(ctx, user.ChatID, msgs[MsgKeySearchUsage], nil)
+	}
+
+	collabs, err := h.storage.ListCollaborations(ctx, db.CollaborationQuery{
+		Page:     1,
+		Limit:    botSearchLimit,
+		Search:   search,
+		ViewerID: user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to search collaborations: %w", err)
+	}
+
+	if len(collabs) == 0 {
+		return h.sendBotMessage(ctx, user.ChatID, fmt.Sprintf(msgs[MsgKeySearchEmpty], search), nil)
+	}
+
+	return h.sendBotMessage(ctx, user.ChatID, fmt.Sprintf(msgs[MsgKeySearchResults], search), h.collaborationListKeyboard(collabs))
+}
+
+func (h *Handler) handleMyCommand(ctx context.Context, user db.User, lang db.LanguageCode) error {
+	msgs := messages[lang]
+
+	collabs, err := h.storage.GetUserCollaborations(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user collaborations: %w", err)
+	}
+
+	if len(collabs) == 0 {
+		keyboard := createWebAppKeyboard(msgs[MsgKeyPublishCollaboration], h.config.WebAppURL+"/collaborations/edit")
+		return h.sendBotMessage(ctx, user.ChatID, msgs[MsgKeyMyEmpty], keyboard)
+	}
+
+	if len(collabs) > botListLimit {
+		collabs = collabs[:botListLimit]
+	}
+
+	return h.sendBotMessage(ctx, user.ChatID, msgs[MsgKeyMyList], h.collaborationListKeyboard(collabs))
+}
+
+// collaborationListKeyboard renders one startapp button per collaboration,
+// prefixed with its moderation state
+func (h *Handler) collaborationListKeyboard(collabs []db.Collaboration) *models.InlineKeyboardMarkup {
+	keyboard := &models.InlineKeyboardMarkup{}
+
+	for _, collab := range collabs {
+		icon := "✅"
+		switch {
+		case collab.HiddenAt != nil:
+			icon = "🙈"
+		case collab.VerificationStatus == db.VerificationStatusPending:
+			icon = "⏳"
+		case collab.VerificationStatus == db.VerificationStatusDenied:
+			icon = "❌"
+		}
+
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []models.InlineKeyboardButton{
+			{
+				Text: fmt.Sprintf("%s %s", icon, truncateText(collab.Title, 60)),
+				URL:  fmt.Sprintf("%s?startapp=%s%s", h.config.BotWebApp, PayloadCollaboration, collab.ID),
+			},
+		})
+	}
+
+	return keyboard
+}
+
+// sendCard sends a photo with caption, falling back to a text message when
+// Telegram can't fetch the photo
+func (h *Handler) sendCard(ctx context.Context, chatID int64, photoURL, text string, keyboard *models.InlineKeyboardMarkup) error {
+	_, err := h.bot.SendPhoto(ctx, &telegram.SendPhotoParams{
+		ChatID:      chatID,
+		Photo:       &models.InputFileString{Data: photoURL},
+		Caption:     truncateText(text, maxCaptionLength),
+		ReplyMarkup: keyboard,
+	})
+	if err == nil {
+		return nil
+	}
+
+	h.logger.Info("failed to send card photo, sending text",
+		slog.Int64("chat_id", chatID),
+		slog.String("error", err.Error()))
+
+	return h.sendBotMessage(ctx, chatID, text, keyboard)
+}
+
+func (h *Handler) sendBotMessage(ctx context.Context, chatID int64, text string, keyboard *models.InlineKeyboardMarkup) error {
+	params := &telegram.SendMessageParams{
+		ChatID: chatID,
+		Text:   text,
+	}
+
+	if keyboard != nil {
+		params.ReplyMarkup = keyboard
+	}
+
+	if _, err := h.bot.SendMessage(ctx, params); err != nil {
+		h.logger.Error("failed to send message",
+			slog.Int64("chat_id", chatID),
+			slog.String("error", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+func createStartAppKeyboard(buttonText, botWebApp, payload string) *models.InlineKeyboardMarkup {
+	return &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{
+				{
+					Text: buttonText,
+					URL:  fmt.Sprintf("%s?startapp=%s", botWebApp, payload),
+				},
+			},
+		},
+	}
+}
+
+func avatarPhotoURL(avatarURL *string) string {
+	if avatarURL == nil || *avatarURL == "" {
+		return defaultCardPhoto
+	}
+	return fmt.Sprintf("https://assets.peatch.io/cdn-cgi/image/width=400/%s", *avatarURL)
+}
+
+func collaborationCardText(collab db.Collaboration, lang db.LanguageCode) string {
+	msgs := messages[lang]
+
+	opportunity := collab.Opportunity.Text
+	if lang == db.LanguageRU && collab.Opportunity.TextRU != "" {
+		opportunity = collab.Opportunity.TextRU
+	}
+
+	lines := []string{collab.Title}
+	if opportunity != "" {
+		lines = append(lines, "🎯 "+opportunity)
+	}
+	if collab.Location != nil {
+		lines = append(lines, fmt.Sprintf("📍 %s, %s", collab.Location.Name, collab.Location.CountryName))
+	}
+	if compensation := compensationText(collab, msgs); compensation != "" {
+		lines = append(lines, "💰 "+compensation)
+	}
+	if collab.User.Name != nil {
+		lines = append(lines, "👤 "+*collab.User.Name)
+	}
+	if collab.Description != "" {
+		lines = append(lines, "", truncateText(collab.Description, maxDescriptionInCard))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+var compensationMsgKeys = map[db.CompensationType]string{
+	db.CompensationPaid:      MsgKeyPayable,
+	db.CompensationEquity:    MsgKeyEquity,
+	db.CompensationRevShare:  MsgKeyRevShare,
+	db.CompensationVolunteer: MsgKeyVolunteer,
+	db.CompensationBarter:    MsgKeyBarter,
+}
+
+func compensationText(collab db.Collaboration, msgs map[string]string) string {
+	if collab.Compensation == nil {
+		if collab.IsPayable {
+			return msgs[MsgKeyPayable]
+		}
+		return ""
+	}
+
+	text := msgs[compensationMsgKeys[collab.Compensation.Type]]
+	if amount := collab.Compensation.Amount(); amount != "" {
+		text += ", " + amount
+	}
+	return text
+}
+
+func userCardText(user db.User, lang db.LanguageCode) string {
+	name := user.Username
+	if user.Name != nil {
+		name = *user.Name
+	}
+
+	lines := []string{name}
+	if user.Title != nil && *user.Title != "" {
+		lines = append(lines, *user.Title)
+	}
+	if user.Location != nil {
+		lines = append(lines, fmt.Sprintf("📍 %s, %s", user.Location.Name, user.Location.CountryName))
+	}
+
+	if len(user.Badges) > 0 {
+		badges := make([]string, 0, len(user.Badges))
+		for _, badge := range user.Badges {
+			badges = append(badges, badge.Text)
+		}
+		lines = append(lines, "🏷 "+strings.Join(badges, ", "))
+	}
+
+	if len(user.Opportunities) > 0 {
+		opps := make([]string, 0, len(user.Opportunities))
+		for _, opp := range user.Opportunities {
+			text := opp.Text
+			if lang == db.LanguageRU && opp.TextRU != "" {
+				text = opp.TextRU
+			}
+			opps = append(opps, text)
+		}
+		lines = append(lines, "🎯 "+strings.Join(opps, ", "))
+	}
+
+	if user.Description != nil && *user.Description != "" {
+		lines = append(lines, "", truncateText(*user.Description, maxDescriptionInCard))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// truncateText cuts s to at most limit runes, ending with an ellipsis when shortened
+func truncateText(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+
+	runes := []rune(s)
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,171 @@
+package handler_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/peatch-io/peatch/internal/db"
+	"github.com/peatch-io/peatch/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// botMessageUpdate is a private message to the bot from an English speaker
+func botMessageUpdate(t *testing.T, updateID, chatID int64, text string) string {
+	body, err := json.Marshal(models.Update{
+		ID: updateID,
+		Message: &models.Message{
+			ID:   1,
+			Date: 1700000000,
+			Text: text,
+			Chat: models.Chat{ID: chatID, Type: "private", Username: fmt.Sprintf("tester%d", chatID)},
+			From: &models.User{ID: chatID, FirstName: "Tester", Username: fmt.Sprintf("tester%d", chatID), LanguageCode: "en"},
+		},
+	})
+	require.NoError(t, err)
+	return string(body)
+}
+
+// botReply is what the bot sent back, the text of a message or the caption of a card
+type botReply struct {
+	method  string
+	text    string
+	buttons string // JSON encoded reply_markup
+}
+
+func botReplies(fake *testutils.FakeTelegram) []botReply {
+	var replies []botReply
+	for _, call := range fake.Calls("sendMessage", "sendPhoto") {
+		text := call.Params["text"]
+		if call.Method == "sendPhoto" {
+			text = call.Params["caption"]
+		}
+		replies = append(replies, botReply{call.Method, text, call.Params["reply_markup"]})
+	}
+	return replies
+}
+
+func TestBotCommands(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	ctx := context.Background()
+
+	viewer, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "viewer", "Viewer")
+	require.NoError(t, err)
+	artist, err := testutils.AuthHelper(t, ts.Echo, 100001, "artist", "Artist")
+	require.NoError(t, err)
+	pending, err := testutils.AuthHelper(t, ts.Echo, 100002, "pending", "Pending")
+	require.NoError(t, err)
+	require.NoError(t, ts.Storage.UpdateUserVerificationStatus(ctx, artist.User.ID, db.VerificationStatusVerified))
+
+	badges, opps, _ := setupTestRecords(ts.Storage, t)
+	require.NoError(t, ts.Storage.CreateCollaboration(ctx, db.CreateCollaborationParams{
+		Collaboration: db.Collaboration{ID: "collab-1", UserID: artist.User.ID, Title: "Band rehearsal", Description: "Description"},
+		BadgeIDs:      badges,
+		OpportunityID: opps[0],
+	}))
+	require.NoError(t, ts.Storage.UpdateCollaborationVerificationStatus(ctx, "collab-1", db.VerificationStatusVerified))
+
+	tests := []struct {
+		name    string
+		chatID  int64
+		text    string
+		method  string
+		reply   string // Part of the text or caption
+		buttons string // Part of the keyboard
+	}{
+		{"help", testutils.TelegramTestUserID, "/help", "sendMessage", "/search <text>", ""},
+		{"command addressed to the bot", testutils.TelegramTestUserID, "/help@peatch_bot", "sendMessage", "/search <text>", ""},
+		{"incomplete profile", testutils.TelegramTestUserID, "/profile", "sendMessage", "Your profile isn't complete yet", "/users/edit"},
+		{"settings", testutils.TelegramTestUserID, "/settings", "sendMessage", "Your settings:", "/users/edit"},
+		{"search without text", testutils.TelegramTestUserID, "/search", "sendMessage", "Tell me what to look for", ""},
+		{"search", testutils.TelegramTestUserID, "/search band", "sendMessage", `Collaborations matching "band":`, "startapp=c_collab-1"},
+		{"search without results", testutils.TelegramTestUserID, "/search violin", "sendMessage", `Nothing found for "violin".`, ""},
+		{"no collaborations", testutils.TelegramTestUserID, "/my", "sendMessage", "You haven't published any collaborations yet.", "/collaborations/edit"},
+		{"own collaborations", 100001, "/my", "sendMessage", "Your collaborations:", "startapp=c_collab-1"},
+		{"unknown command", testutils.TelegramTestUserID, "/dance", "sendMessage", "You can open the web app", ""},
+		{"plain text", testutils.TelegramTestUserID, "hello", "sendMessage", "You can open the web app", ""},
+
+		// /start payloads
+		{"start without payload", testutils.TelegramTestUserID, "/start", "sendMessage", "You can open the web app", ""},
+		{"collaboration", testutils.TelegramTestUserID, "/start c_collab-1", "sendPhoto", "Band rehearsal", "startapp=c_collab-1"},
+		{"missing collaboration", testutils.TelegramTestUserID, "/start c_missing", "sendMessage", "no longer available", ""},
+		{"collaboration without id", testutils.TelegramTestUserID, "/start c_", "sendMessage", "no longer available", ""},
+		{"user", testutils.TelegramTestUserID, "/start u_" + artist.User.ID, "sendPhoto", "Artist", "startapp=u_" + artist.User.ID},
+		{"unverified user", testutils.TelegramTestUserID, "/start u_" + pending.User.ID, "sendMessage", "no longer available", ""},
+		{"own unverified profile", testutils.TelegramTestUserID, "/start u_" + viewer.User.ID, "sendPhoto", "Viewer", "startapp=u_" + viewer.User.ID},
+		{"user without id", testutils.TelegramTestUserID, "/start u_", "sendMessage", "no longer available", ""},
+		{"referral of existing user", testutils.TelegramTestUserID, "/start ref_artist", "sendMessage", "You can open the web app", ""},
+		{"unknown payload", testutils.TelegramTestUserID, "/start x_123", "sendMessage", "You can open the web app", ""},
+		{"payload with spaces", testutils.TelegramTestUserID, "/start c_collab-1 extra", "sendMessage", "no longer available", ""},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts.Telegram.Reset()
+
+			rec := performWebhookRequest(ts.Echo, botMessageUpdate(t, int64(5000+i), tt.chatID, tt.text), testutils.TestWebhookSecret)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			replies := botReplies(ts.Telegram)
+			require.Len(t, replies, 1)
+			assert.Equal(t, tt.method, replies[0].method)
+			assert.Contains(t, replies[0].text, tt.reply)
+			assert.Contains(t, replies[0].buttons, tt.buttons)
+		})
+	}
+}
+
+func TestBotStart_NewUsers(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	ctx := context.Background()
+
+	referrer, err := testutils.AuthHelper(t, ts.Echo, 100001, "artist", "Artist")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		text     string
+		replies  int    // The welcome card and whatever the payload adds
+		referrer string // Who the new user was referred by
+	}{
+		{"plain start", "/start", 1, ""},
+		{"referral by username", "/start ref_artist", 1, referrer.User.ID},
+		{"referral by id", "/start ref_" + referrer.User.ID, 1, referrer.User.ID},
+		{"unknown referral", "/start ref_nobody", 1, ""},
+		{"empty referral", "/start ref_", 1, ""},
+		{"collaboration", "/start c_missing", 2, ""},
+		{"plain text", "hi", 1, ""},
+		{"self referral", "/start ref_tester200008", 1, ""}, // The username of this chat
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts.Telegram.Reset()
+			chatID := int64(200001 + i)
+
+			rec := performWebhookRequest(ts.Echo, botMessageUpdate(t, int64(6000+i), chatID, tt.text), testutils.TestWebhookSecret)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			replies := botReplies(ts.Telegram)
+			require.Len(t, replies, tt.replies)
+			assert.Equal(t, "sendPhoto", replies[0].method)
+			assert.Contains(t, replies[0].text, "Welcome!")
+
+			user, err := ts.Storage.GetUserByChatID(ctx, chatID)
+			require.NoError(t, err)
+
+			var referredBy sql.NullString
+			require.NoError(t, ts.Storage.DB().QueryRow(`SELECT referred_by FROM users WHERE id = ?`, user.ID).Scan(&referredBy))
+			assert.Equal(t, tt.referrer, referredBy.String)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	telegram "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/peatch-io/peatch/internal/db"
+)
+
+const (
+	inlinePageSize  = 10
+	inlineCacheTime = 60
+)
+
+// handleInlineQuery answers "@bot <text>" with matching collaborations and
+// profiles. The offset is the next page number.
+func (h *Handler) handleInlineQuery(ctx context.Context, query *models.InlineQuery) error {
+	lang := determineLanguage(query.From.LanguageCode)
+
+	page := 1
+	if p, err := strconv.Atoi(query.Offset); err == nil && p > 1 {
+		page = p
+	}
+
+	viewer, err := h.storage.GetUserByChatID(ctx, query.From.ID)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if viewer.VerificationStatus == db.VerificationStatusBlocked {
+		_, err := h.bot.AnswerInlineQuery(ctx, &telegram.AnswerInlineQueryParams{
+			InlineQueryID: query.ID,
+			Results:       []models.InlineQueryResult{},
+			IsPersonal:    true,
+		})
+		return err
+	}
+
+	search := strings.TrimSpace(query.Query)
+
+	// No viewer ID, so only verified and visible collaborations are returned,
+	// leaving out the sharer's own drafts too. Both lists leave out blocked
+	// users in the query, so full pages mean there may be more.
+	collabs, err := h.storage.ListCollaborations(ctx, db.CollaborationQuery{
+		Page:   page,
+		Limit:  inlinePageSize,
+		Search: search,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list collaborations: %w", err)
+	}
+
+	users, err := h.storage.ListUsers(ctx, db.ListUsersOptions{
+		SearchQuery: search,
+		Offset:      (page - 1) * inlinePageSize,
+		Limit:       inlinePageSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list users: %w", err)
+	}
+
+	results := make([]models.InlineQueryResult, 0, len(collabs)+len(users))
+	for _, collab := range collabs {
+		results = append(results, h.collaborationInlineResult(collab, lang))
+	}
+	for _, user := range users {
+		results = append(results, h.userInlineResult(user, lang))
+	}
+
+	var nextOffset string
+	if len(collabs) == inlinePageSize || len(users) == inlinePageSize {
+		nextOffset = strconv.Itoa(page + 1)
+	}
+
+	_, err = h.bot.AnswerInlineQuery(ctx, &telegram.AnswerInlineQueryParams{
+		InlineQueryID: query.ID,
+		Results:       results,
+		CacheTime:     inlineCacheTime,
+		NextOffset:    nextOffset,
+	})
+
+	return err
+}
+
+func (h *Handler) collaborationInlineResult(collab db.Collaboration, lang db.LanguageCode) models.InlineQueryResult {
+	msgs := messages[lang]
+
+	description := collab.Opportunity.Text
+	if lang == db.LanguageRU && collab.Opportunity.TextRU != "" {
+		description = collab.Opportunity.TextRU
+	}
+	if collab.Location != nil {
+		description = strings.TrimPrefix(description+" · "+collab.Location.Name, " · ")
+	}
+
+	result := &models.InlineQueryResultArticle{
+		ID:          PayloadCollaboration + collab.ID,
+		Title:       collab.Title,
+		Description: description,
+		InputMessageContent: &models.InputTextMessageContent{
+			MessageText: collaborationCardText(collab, lang),
+		},
+		ReplyMarkup: createStartAppKeyboard(msgs[MsgKeyViewCollaboration], h.config.BotWebApp, PayloadCollaboration+collab.ID),
+	}
+
+	if collab.User.AvatarURL != nil && *collab.User.AvatarURL != "" {
+		result.ThumbnailURL = avatarPhotoURL(collab.User.AvatarURL)
+	}
+
+	return result
+}
+
+func (h *Handler) userInlineResult(user db.User, lang db.LanguageCode) models.InlineQueryResult {
+	msgs := messages[lang]
+
+	title := user.Username
+	if user.Name != nil {
+		title = *user.Name
+	}
+
+	var description string
+	if user.Title != nil {
+		description = *user.Title
+	}
+
+	keyboard := createStartAppKeyboard(msgs[MsgKeyViewProfile], h.config.BotWebApp, PayloadUser+user.ID)
+	text := userCardText(user, lang)
+
+	if user.AvatarURL != nil && *user.AvatarURL != "" {
+		photoURL := avatarPhotoURL(user.AvatarURL)
+		return &models.InlineQueryResultPhoto{
+			ID:           PayloadUser + user.ID,
+			PhotoURL:     photoURL,
+			ThumbnailURL: photoURL,
+			Title:        title,
+			Description:  description,
+			Caption:      truncateText(text, maxCaptionLength),
+			ReplyMarkup:  keyboard,
+		}
+	}
+
+	return &models.InlineQueryResultArticle{
+		ID:          PayloadUser + user.ID,
+		Title:       title,
+		Description: description,
+		InputMessageContent: &models.InputTextMessageContent{
+			MessageText: text,
+		},
+		ReplyMarkup: keyboard,
+	}
+}
@@ -0,0 +1,110 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/labstack/echo/v4"
+	"github.com/peatch-io/peatch/internal/db"
+	"github.com/peatch-io/peatch/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type inlineAnswer struct {
+	ids        []string
+	nextOffset string
+	isPersonal string
+}
+
+// askInline sends an inline query and returns the IDs of the results the bot answered with
+func askInline(t *testing.T, e *echo.Echo, fake *testutils.FakeTelegram, updateID, chatID int64, offset string) inlineAnswer {
+	fake.Reset()
+
+	body, err := json.Marshal(models.Update{
+		ID: updateID,
+		InlineQuery: &models.InlineQuery{
+			ID:     fmt.Sprintf("query-%d", updateID),
+			From:   &models.User{ID: chatID, FirstName: "Tester", LanguageCode: "en"},
+			Offset: offset,
+		},
+	})
+	require.NoError(t, err)
+
+	rec := performWebhookRequest(e, string(body), testutils.TestWebhookSecret)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	calls := fake.Calls("answerInlineQuery")
+	require.Len(t, calls, 1)
+
+	var results []struct {
+		ID string `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(calls[0].Params["results"]), &results))
+
+	answer := inlineAnswer{nextOffset: calls[0].Params["next_offset"], isPersonal: calls[0].Params["is_personal"]}
+	for _, result := range results {
+		answer.ids = append(answer.ids, result.ID)
+	}
+	return answer
+}
+
+func TestInlineQuery(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	ctx := context.Background()
+
+	viewer, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "viewer", "Viewer")
+	require.NoError(t, err)
+	owner, err := testutils.AuthHelper(t, ts.Echo, 100001, "owner", "Owner")
+	require.NoError(t, err)
+	spammer, err := testutils.AuthHelper(t, ts.Echo, 100002, "spammer", "Spammer")
+	require.NoError(t, err)
+	require.NoError(t, ts.Storage.UpdateUserVerificationStatus(ctx, owner.User.ID, db.VerificationStatusVerified))
+	require.NoError(t, ts.Storage.UpdateUserVerificationStatus(ctx, spammer.User.ID, db.VerificationStatusVerified))
+
+	badges, opps, _ := setupTestRecords(ts.Storage, t)
+	create := func(id, userID string) {
+		require.NoError(t, ts.Storage.CreateCollaboration(ctx, db.CreateCollaborationParams{
+			Collaboration: db.Collaboration{ID: id, UserID: userID, Title: "Band " + id, Description: "Description"},
+			BadgeIDs:      badges,
+			OpportunityID: opps[0],
+		}))
+		require.NoError(t, ts.Storage.UpdateCollaborationVerificationStatus(ctx, id, db.VerificationStatusVerified))
+	}
+	for i := range 11 {
+		create(fmt.Sprintf("collab-%02d", i), owner.User.ID)
+	}
+	create("spam", spammer.User.ID)
+	require.NoError(t, ts.Storage.UpdateUserVerificationStatus(ctx, spammer.User.ID, db.VerificationStatusBlocked))
+
+	// A full page of collaborations offers the next one
+	first := askInline(t, ts.Echo, ts.Telegram, 7001, viewer.User.ChatID, "")
+	assert.Len(t, first.ids, 11, "ten collaborations and the one public profile")
+	assert.Contains(t, first.ids, "u_"+owner.User.ID)
+	assert.Equal(t, "2", first.nextOffset)
+
+	second := askInline(t, ts.Echo, ts.Telegram, 7002, viewer.User.ChatID, first.nextOffset)
+	assert.Len(t, second.ids, 1)
+	assert.Empty(t, second.nextOffset)
+
+	// Blocked users and their collaborations show on no page
+	for _, id := range append(first.ids, second.ids...) {
+		assert.NotEqual(t, "c_spam", id)
+		assert.NotEqual(t, "u_"+spammer.User.ID, id)
+	}
+
+	// Malformed offsets start over
+	assert.Equal(t, first.ids, askInline(t, ts.Echo, ts.Telegram, 7003, viewer.User.ChatID, "abc").ids)
+
+	// Blocked viewers get nothing, and Telegram mustn't share the answer
+	require.NoError(t, ts.Storage.UpdateUserVerificationStatus(ctx, viewer.User.ID, db.VerificationStatusBlocked))
+	blocked := askInline(t, ts.Echo, ts.Telegram, 7004, viewer.User.ChatID, "")
+	assert.Empty(t, blocked.ids)
+	assert.Equal(t, "true", blocked.isPersonal)
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	telegram "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// DefaultTelegramAPIURL is the public Bot API server
+const DefaultTelegramAPIURL = "https://api.telegram.org"
+
+const (
+	pollingTimeout    = 30 * time.Second
+	maxPollingBackoff = 30 * time.Second
+)
+
+type getUpdatesRequest struct {
+	Offset  int64 `json:"offset,omitempty"`
+	Timeout int   `json:"timeout"`
+}
+
+type getUpdatesResponse struct {
+	OK          bool            `json:"ok"`
+	Result      []models.Update `json:"result"`
+	Description string          `json:"description"`
+}
+
+// StartPolling receives updates with getUpdates instead of the webhook, for
+// environments without a public URL. It blocks until ctx is cancelled.
+func (h *Handler) StartPolling(ctx context.Context) error {
+	if h.bot == nil {
+		return errors.New("bot is not initialized")
+	}
+
+	if h.adminBot != nil {
+		go func() {
+			if err := h.pollUpdates(ctx, h.adminBot, h.config.AdminBotToken, adminBotKey, h.handleAdminUpdate); err != nil {
+				h.logger.Error("admin bot polling stopped", slog.String("error", err.Error()))
+			}
+		}()
+		h.setAdminBotCommands(ctx)
+	}
+
+	h.setBotCommands(ctx)
+
+	return h.pollUpdates(ctx, h.bot, h.config.TelegramBotToken, mainBotKey, h.handleUpdate)
+}
+
+func (h *Handler) pollUpdates(ctx context.Context, bot *telegram.Bot, token, botKey string, dispatch updateDispatcher) error {
+	// getUpdates is refused while a webhook is set
+	if _, err := bot.DeleteWebhook(ctx, &telegram.DeleteWebhookParams{}); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	lastUpdateID, err := h.storage.GetLastUpdateID(ctx, botKey)
+	if err != nil {
+		return fmt.Errorf("failed to get last update id: %w", err)
+	}
+
+	h.logger.Info("telegram long polling started",
+		slog.String("bot", botKey),
+		slog.Int64("last_update_id", lastUpdateID))
+
+	client := &http.Client{Timeout: pollingTimeout + 10*time.Second}
+	var backoff time.Duration
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+
+		updates, err := getUpdates(ctx, client, h.config.TelegramAPIURL, token, lastUpdateID+1)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+
+			backoff = min(max(2*backoff, time.Second), maxPollingBackoff)
+			h.logger.Error("failed to get updates",
+				slog.String("bot", botKey),
+				slog.String("error", err.Error()),
+				slog.Duration("retry_in", backoff))
+			continue
+		}
+
+		backoff = 0
+
+		for _, update := range updates {
+			if err := h.processUpdate(ctx, botKey, update, dispatch); err != nil {
+				h.logger.Error("failed to look up update",
+					slog.String("bot", botKey),
+					slog.Int64("update_id", update.ID),
+					slog.String("error", err.Error()))
+				backoff = time.Second // fetch it again after a pause
+				break
+			}
+
+			lastUpdateID = update.ID
+			if err := h.storage.SaveLastUpdateID(ctx, botKey, lastUpdateID); err != nil {
+				h.logger.Error("failed to save last update id",
+					slog.String("bot", botKey),
+					slog.Int64("update_id", update.ID),
+					slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+func getUpdates(ctx context.Context, client *http.Client, apiURL, token string, offset int64) ([]models.Update, error) {
+	body, err := json.Marshal(getUpdatesRequest{
+		Offset:  offset,
+		Timeout: int(pollingTimeout.Seconds()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/bot%s/getUpdates", apiURL, token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result getUpdatesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode getUpdates response: %w", err)
+	}
+
+	if !result.OK {
+		return nil, fmt.Errorf("getUpdates failed: %s", result.Description)
+	}
+
+	return result.Result, nil
+}
@@ -0,0 +1,73 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/peatch-io/peatch/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func botMessage(t *testing.T, updateID, chatID int64, text string) models.Update {
+	var update models.Update
+	require.NoError(t, json.Unmarshal([]byte(botMessageUpdate(t, updateID, chatID, text)), &update))
+	return update
+}
+
+// mainBotPolls returns the getUpdates calls of the main bot, the admin bot polls too
+func mainBotPolls(fake *testutils.FakeTelegram) []testutils.TelegramCall {
+	var polls []testutils.TelegramCall
+	for _, call := range fake.Calls("getUpdates") {
+		if call.Token == testutils.TestBotToken {
+			polls = append(polls, call)
+		}
+	}
+	return polls
+}
+
+func TestStartPolling_PersistsOffset(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	ctx := context.Background()
+
+	// poll runs the bot until it saved the last update ID
+	poll := func(lastUpdateID int64) {
+		t.Helper()
+
+		pollCtx, cancel := context.WithCancel(ctx)
+		done := make(chan error)
+		go func() { done <- ts.Handler.StartPolling(pollCtx) }()
+
+		assert.Eventually(t, func() bool {
+			saved, err := ts.Storage.GetLastUpdateID(ctx, "main")
+			return err == nil && saved == lastUpdateID
+		}, 5*time.Second, 10*time.Millisecond)
+
+		cancel()
+		require.NoError(t, <-done)
+	}
+
+	ts.Telegram.QueueUpdates(testutils.TestBotToken, botMessage(t, 10, 100001, "/help"), botMessage(t, 11, 100002, "/help"))
+	poll(11)
+
+	polls := mainBotPolls(ts.Telegram)
+	assert.Equal(t, "1", polls[0].Params["offset"])
+	assert.Len(t, ts.Telegram.Calls("sendMessage"), 2)
+
+	// After a restart polling resumes from the stored offset
+	ts.Telegram.Reset()
+	ts.Telegram.QueueUpdates(testutils.TestBotToken, botMessage(t, 12, 100001, "/settings"))
+	poll(12)
+
+	polls = mainBotPolls(ts.Telegram)
+	assert.Equal(t, "12", polls[0].Params["offset"])
+
+	replies := botReplies(ts.Telegram)
+	require.Len(t, replies, 1)
+	assert.Contains(t, replies[0].text, "Your settings:")
+}
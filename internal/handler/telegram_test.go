@@ -0,0 +1,147 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/peatch-io/peatch/internal/db"
+	"github.com/peatch-io/peatch/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func performWebhookRequest(e *echo.Echo, body, secret string) *httptest.ResponseRecorder {
+	return performWebhookRequestTo(e, "/tg/webhook", body, secret)
+}
+
+func performWebhookRequestTo(e *echo.Echo, path, body, secret string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if secret != "" {
+		req.Header.Set("X-Telegram-Bot-Api-Secret-Token", secret)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandleWebhook_RejectsInvalidSecret(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	body := `{"update_id": 1001}`
+
+	rec := performWebhookRequest(ts.Echo, body, "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = performWebhookRequest(ts.Echo, body, "wrong-secret")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// Rejected updates must not be remembered
+	isNew, err := ts.Storage.MarkUpdateProcessed(context.Background(), "main", 1001)
+	assert.NoError(t, err)
+	assert.True(t, isNew)
+}
+
+func TestHandleWebhook_SkipsDuplicateUpdates(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	body := `{"update_id": 2002}`
+
+	rec := performWebhookRequest(ts.Echo, body, testutils.TestWebhookSecret)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = performWebhookRequest(ts.Echo, body, testutils.TestWebhookSecret)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	isNew, err := ts.Storage.MarkUpdateProcessed(context.Background(), "main", 2002)
+	assert.NoError(t, err)
+	assert.False(t, isNew)
+}
+
+func TestHandleWebhook_MarksUpdatesAfterHandling(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	ctx := context.Background()
+
+	rec := performWebhookRequest(ts.Echo, botMessageUpdate(t, 4004, 100001, "/help"), testutils.TestWebhookSecret)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, ts.Telegram.Calls("sendMessage"), 1)
+
+	processed, err := ts.Storage.IsUpdateProcessed(ctx, "main", 4004)
+	assert.NoError(t, err)
+	assert.True(t, processed)
+
+	// A redelivery isn't answered twice
+	rec = performWebhookRequest(ts.Echo, botMessageUpdate(t, 4004, 100001, "/help"), testutils.TestWebhookSecret)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, ts.Telegram.Calls("sendMessage"), 1)
+
+	// Updates are forgotten once Telegram stops redelivering them
+	pruned, err := ts.Storage.PruneTelegramUpdates(ctx, time.Now().Add(25*time.Hour))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, pruned)
+
+	processed, err = ts.Storage.IsUpdateProcessed(ctx, "main", 4004)
+	assert.NoError(t, err)
+	assert.False(t, processed)
+}
+
+func TestHandleAdminWebhook_IgnoresUnknownChats(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	body := `{"update_id": 3003, "message": {"message_id": 1, "date": 1700000000, "text": "/stats", "chat": {"id": 555, "type": "private"}, "from": {"id": 555, "is_bot": false, "first_name": "Eve"}}}`
+
+	// The public bot's secret doesn't open the admin webhook
+	rec := performWebhookRequestTo(ts.Echo, "/tg/admin/webhook", body, testutils.TestWebhookSecret)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = performWebhookRequestTo(ts.Echo, "/tg/admin/webhook", body, testutils.TestAdminWebhookSecret)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Update IDs are tracked per bot
+	isNew, err := ts.Storage.MarkUpdateProcessed(context.Background(), "admin", 3003)
+	assert.NoError(t, err)
+	assert.False(t, isNew)
+
+	isNew, err = ts.Storage.MarkUpdateProcessed(context.Background(), "main", 3003)
+	assert.NoError(t, err)
+	assert.True(t, isNew)
+}
+
+func TestAdminPendingCommand_CountsAll(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	testutils.AdminAuthHelper(t, ts.Storage, 900001, "moderator")
+
+	for i := 0; i < 12; i++ {
+		require.NoError(t, ts.Storage.CreateUser(context.Background(), db.UpdateUserParams{
+			User: db.User{
+				ID:                 fmt.Sprintf("pending-%d", i),
+				ChatID:             int64(300 + i),
+				Username:           fmt.Sprintf("pending%d", i),
+				VerificationStatus: db.VerificationStatusPending,
+			},
+		}))
+	}
+
+	body := `{"update_id": 3004, "message": {"message_id": 1, "date": 1700000000, "text": "/pending", "chat": {"id": 900001, "type": "private"}, "from": {"id": 900001, "is_bot": false, "first_name": "Mod"}}}`
+	rec := performWebhookRequestTo(ts.Echo, "/tg/admin/webhook", body, testutils.TestAdminWebhookSecret)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	calls := ts.Telegram.Calls("sendMessage")
+	require.Len(t, calls, 1)
+	text := calls[0].Params["text"]
+	assert.Contains(t, text, "Pending users (12):")
+	assert.Equal(t, 10, strings.Count(text, "@pending"), "expected the list to stop at the page size")
+}
@@ -24,6 +24,9 @@ import (
 // @Param order query string false "Order by"
 // @Param search query string false "Search"
 // @Param find_similar query bool false "Find similar"
+// @Param near query string false "City ID to search around"
+// @Param radius_km query number false "Search radius around near, 50 km by default"
+// @Param sort query string false "Sort order, distance requires near" Enums(distance)
 // @Success 200 {array} contract.UserProfileResponse
 // @Router /api/users [get]
 func (h *Handler) handleListUsers(c echo.Context) error {
@@ -35,11 +38,23 @@ func (h *Handler) handleListUsers(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "limit must be between 1 and 100")
 	}
 
+	near, err := h.parseGeoFilter(c)
+	if err != nil {
+		return err
+	}
+
+	sortByDistance, err := parseDistanceSort(c, near)
+	if err != nil {
+		return err
+	}
+
 	params := db.ListUsersOptions{
-		Limit:       limit,
-		Offset:      (page - 1) * limit,
-		SearchQuery: search,
-		UserID:      getUserID(c),
+		Limit:          limit,
+		Offset:         (page - 1) * limit,
+		SearchQuery:    search,
+		UserID:         getUserID(c),
+		Near:           near,
+		SortByDistance: sortByDistance,
 	}
 
 	users, err := h.storage.ListUsers(c.Request().Context(), params)
@@ -75,6 +90,13 @@ func (h *Handler) handleGetUser(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get user").WithInternal(err)
 	}
 
+	h.recordEvent(c.Request().Context(), db.AnalyticsEvent{
+		Kind:     db.AnalyticsEventView,
+		Target:   db.AnalyticsTargetUser,
+		TargetID: user.ID,
+		ViewerID: uid,
+	})
+
 	return c.JSON(http.StatusOK, contract.ToUserProfile(user))
 }
 
@@ -206,6 +228,7 @@ func updateUserEmbedding(h *Handler, user db.User) {
 // @Param id path string true "User ID to follow"
 // @Success 204
 // @Success 200 {object} contract.BotBlockedResponse "When user has blocked the bot, returns username for direct Telegram navigation"
+// @Failure 429 {object} contract.ErrorResponse "Rate limited (code rate_limited), see Retry-After"
 // @Router /api/users/{id}/follow [post]
 func (h *Handler) handleFollowUser(c echo.Context) error {
 	userIDToFollow := c.Param("id")
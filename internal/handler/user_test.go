@@ -7,6 +7,7 @@ import (
 	"github.com/peatch-io/peatch/internal/handler"
 	"github.com/peatch-io/peatch/internal/testutils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"net/http"
 	"strings"
 	"testing"
@@ -559,3 +560,144 @@ func TestFollowUser_Unauthorized(t *testing.T) {
 func strPtr(s string) *string {
 	return &s
 }
+
+func TestBlockedUser_WritesDenied(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	adminToken := testutils.AdminAuthHelper(t, ts.Storage, 900001, "moderator")
+
+	authResp, err := testutils.AuthHelper(t, ts.Echo, 11111, "blocked", "Blocked")
+	if err != nil {
+		t.Fatalf("failed to authenticate user: %v", err)
+	}
+	otherResp, err := testutils.AuthHelper(t, ts.Echo, 22222, "other", "Other")
+	if err != nil {
+		t.Fatalf("failed to authenticate user: %v", err)
+	}
+
+	// Prime the account state cache before blocking
+	testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me", "", authResp.Token, http.StatusOK)
+
+	// Blocking through the admin API ends the sessions right away
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/admin/users/"+authResp.User.ID+"/verify",
+		`{"status": "blocked"}`, adminToken, http.StatusOK)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/api/users/links", `{"links": []}`, authResp.Token, http.StatusUnauthorized)
+	testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me", "", authResp.Token, http.StatusUnauthorized)
+
+	// A blocked account whose session is still active can only read
+	if err := ts.Storage.UpdateUserVerificationStatus(context.Background(), otherResp.User.ID, db.VerificationStatusBlocked); err != nil {
+		t.Fatalf("failed to block user: %v", err)
+	}
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodPut, "/api/users/links", `{"links": []}`, otherResp.Token, http.StatusForbidden)
+	resp := testutils.ParseResponse[contract.ErrorResponse](t, rec)
+	assert.Equal(t, handler.ErrCodeUserBlocked, resp.Code)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users/me", "", otherResp.Token, http.StatusOK)
+}
+
+func TestUnverifiedUser_SocialActionsRateLimited(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	followerAuth, err := testutils.AuthHelper(t, ts.Echo, 11111, "follower", "Follower")
+	if err != nil {
+		t.Fatalf("failed to authenticate user: %v", err)
+	}
+
+	ownPath := fmt.Sprintf("/api/users/%s/follow", followerAuth.User.ID)
+
+	var path string
+	for i := 0; i < 11; i++ {
+		followedAuth, err := testutils.AuthHelper(t, ts.Echo, int64(22220+i), fmt.Sprintf("followed%d", i), "Followed")
+		if err != nil {
+			t.Fatalf("failed to authenticate user: %v", err)
+		}
+		path = fmt.Sprintf("/api/users/%s/follow", followedAuth.User.ID)
+
+		if i < 10 {
+			// Rejected attempts are refunded
+			testutils.PerformRequest(t, ts.Echo, http.MethodPost, ownPath, "", followerAuth.Token, http.StatusBadRequest)
+			testutils.PerformRequest(t, ts.Echo, http.MethodPost, path, "", followerAuth.Token, http.StatusOK)
+		}
+	}
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodPost, path, "", followerAuth.Token, http.StatusTooManyRequests)
+	resp := testutils.ParseResponse[contract.ErrorResponse](t, rec)
+	assert.Equal(t, handler.ErrCodeRateLimited, resp.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+}
+
+func createGeoCities(t *testing.T, storage *db.Storage) {
+	t.Helper()
+
+	cities := []db.City{
+		{ID: "moscow", Name: "Moscow", CountryCode: "RU", CountryName: "Russia", Latitude: 55.7522, Longitude: 37.6156},
+		{ID: "tver", Name: "Tver", CountryCode: "RU", CountryName: "Russia", Latitude: 56.8587, Longitude: 35.9176},
+		{ID: "podolsk", Name: "Podolsk", CountryCode: "RU", CountryName: "Russia", Latitude: 55.4242, Longitude: 37.5547},
+		{ID: "spb", Name: "Saint Petersburg", CountryCode: "RU", CountryName: "Russia", Latitude: 59.9386, Longitude: 30.3141},
+	}
+
+	for _, city := range cities {
+		require.NoError(t, storage.CreateCity(context.Background(), city))
+	}
+}
+
+func TestListUsers_NearCity(t *testing.T) {
+	ts := testutils.SetupTestEnvironment(t)
+	defer ts.Teardown()
+
+	viewer, err := testutils.AuthHelper(t, ts.Echo, testutils.TelegramTestUserID, "viewer", "Viewer")
+	require.NoError(t, err)
+
+	badges, opps, _ := setupTestRecords(ts.Storage, t)
+	createGeoCities(t, ts.Storage)
+
+	for i, locationID := range []string{"tver", "podolsk", "spb"} {
+		user := db.User{
+			ID:                 "user-" + locationID,
+			ChatID:             int64(200 + i),
+			Username:           locationID,
+			Name:               strPtr("User in " + locationID),
+			Title:              strPtr("Developer"),
+			Description:        strPtr("Description"),
+			VerificationStatus: db.VerificationStatusVerified,
+		}
+		require.NoError(t, ts.Storage.CreateUser(context.Background(), db.UpdateUserParams{
+			User:           user,
+			BadgeIDs:       badges[:1],
+			OpportunityIDs: opps[:1],
+			LocationID:     locationID,
+		}))
+	}
+
+	rec := testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users?near=moscow&radius_km=200&sort=distance", "", viewer.Token, http.StatusOK)
+	users := testutils.ParseResponse[[]contract.UserProfileResponse](t, rec)
+	require.Len(t, users, 2)
+	assert.Equal(t, "user-podolsk", users[0].ID)
+	assert.Equal(t, "user-tver", users[1].ID)
+	require.NotNil(t, users[0].DistanceKm)
+	assert.InDelta(t, 37, *users[0].DistanceKm, 1)
+	assert.InDelta(t, 162, *users[1].DistanceKm, 2)
+
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users?near=moscow", "", viewer.Token, http.StatusOK)
+	users = testutils.ParseResponse[[]contract.UserProfileResponse](t, rec)
+	require.Len(t, users, 1, "expected the default 50 km radius")
+	assert.Equal(t, "user-podolsk", users[0].ID)
+
+	// Moving updates the index
+	require.NoError(t, ts.Storage.UpdateUser(context.Background(), db.UpdateUserParams{
+		User:           db.User{ID: "user-spb", Name: strPtr("Moved"), Title: strPtr("Developer"), Description: strPtr("Description")},
+		BadgeIDs:       badges[:1],
+		OpportunityIDs: opps[:1],
+		LocationID:     "moscow",
+	}))
+	rec = testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users?near=moscow", "", viewer.Token, http.StatusOK)
+	assert.Len(t, testutils.ParseResponse[[]contract.UserProfileResponse](t, rec), 2)
+
+	testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users?near=unknown", "", viewer.Token, http.StatusBadRequest)
+	testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users?near=moscow&radius_km=5000", "", viewer.Token, http.StatusBadRequest)
+	testutils.PerformRequest(t, ts.Echo, http.MethodGet, "/api/users?sort=distance", "", viewer.Token, http.StatusBadRequest)
+}
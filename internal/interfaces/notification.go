@@ -11,9 +11,15 @@ type NotificationService interface {
 	NotifyUserVerificationDenied(user db.User) error
 	NotifyCollaborationVerificationDenied(collab db.Collaboration) error
 	NotifyNewPendingUser(user db.User) error
-	NotifyNewPendingCollaboration(collab db.Collaboration) error
+	NotifyNewPendingCollaboration(collab db.Collaboration, duplicates []db.SimilarCollaboration) error
 	NotifyUserFollow(userID db.User, follower db.User) error
-	NotifyCollabInterest(collab db.Collaboration, user db.User) error
+	NotifyCollabInterest(recipient db.User, collab db.Collaboration, application db.Application) error
+	NotifyApplicationStatusChanged(application db.Application) error
+	NotifyCollaborationInvite(invitee db.User, collab db.Collaboration, role db.CollaborationRole) error
+	NotifyNewComment(recipient db.User, collab db.Collaboration, comment db.Comment) error
+	NotifyCommentReported(comment db.Comment) error
 	SendCollaborationToCommunityChatWithImage(collab db.Collaboration) error
 	NotifyUsersWithMatchingOpportunity(collab db.Collaboration, users []db.User) error
+	NotifySavedSearchMatches(collab db.Collaboration, matches []db.SavedSearchMatch) error
+	SendBroadcast(chatID int64, lang db.LanguageCode, b db.Broadcast) error
 }
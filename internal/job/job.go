@@ -5,10 +5,79 @@ import (
 	"github.com/peatch-io/peatch/internal/db"
 	"github.com/peatch-io/peatch/internal/notification"
 	"log"
+	"time"
 )
 
+// expiryReminderLead is how long before expiry owners are offered to extend
+// their collaboration
+const expiryReminderLead = 3 * 24 * time.Hour
+
 func Run(ctx context.Context, storage *db.Storage, notifier *notification.Notifier) error {
 	log.Println("starting job")
 
+	pruned, err := storage.PruneTelegramUpdates(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if pruned > 0 {
+		log.Printf("pruned %d processed telegram updates", pruned)
+	}
+
+	pruned, err = storage.PruneSessions(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if pruned > 0 {
+		log.Printf("pruned %d dead sessions", pruned)
+	}
+
+	pruned, err = storage.PruneRateLimits(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if pruned > 0 {
+		log.Printf("pruned %d idle rate limit rows", pruned)
+	}
+
+	if err := remindExpiringCollaborations(ctx, storage, notifier); err != nil {
+		return err
+	}
+
+	expired, err := storage.ExpireCollaborations(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if expired > 0 {
+		log.Printf("expired %d collaborations", expired)
+	}
+
+	rolledUp, err := storage.RollupAnalytics(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if rolledUp > 0 {
+		log.Printf("rolled up %d analytics events", rolledUp)
+	}
+
+	return nil
+}
+
+func remindExpiringCollaborations(ctx context.Context, storage *db.Storage, notifier *notification.Notifier) error {
+	collabs, err := storage.ListCollaborationsExpiringBefore(ctx, time.Now().Add(expiryReminderLead))
+	if err != nil {
+		return err
+	}
+
+	for _, collab := range collabs {
+		if err := notifier.NotifyCollaborationExpiring(collab); err != nil {
+			log.Printf("failed to remind owner of collaboration %s: %v", collab.ID, err)
+		}
+
+		// Don't retry every run when the owner can't be reached
+		if err := storage.MarkCollaborationExpiryReminded(ctx, collab.ID); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,65 @@
+package job_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/peatch-io/peatch/internal/db"
+	"github.com/peatch-io/peatch/internal/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func count(t *testing.T, storage *db.Storage, table string) int {
+	t.Helper()
+
+	var n int
+	require.NoError(t, storage.DB().QueryRow(`SELECT COUNT(*) FROM `+table).Scan(&n))
+	return n
+}
+
+func TestRun_PrunesSessionsAndRateLimits(t *testing.T) {
+	storage, err := db.NewStorage(":memory:")
+	require.NoError(t, err)
+	defer storage.Close()
+	require.NoError(t, storage.InitSchema())
+
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, storage.CreateUser(ctx, db.UpdateUserParams{User: db.User{ID: "user-1", ChatID: 1, Username: "user1", VerificationStatus: db.VerificationStatusPending}}))
+
+	sessions := []struct {
+		id        string
+		expiresAt time.Time
+	}{
+		{"expired-long-ago", now.Add(-db.SessionRetention - time.Hour)},
+		{"expired-recently", now.Add(-time.Hour)},
+		{"active", now.Add(time.Hour)},
+	}
+	for _, session := range sessions {
+		require.NoError(t, storage.CreateSession(ctx, db.Session{ID: session.id, UserID: "user-1", ExpiresAt: session.expiresAt}, session.id))
+	}
+
+	_, err = storage.TakeToken(ctx, "follow:user:idle", 1, 5, now.Add(-db.RateLimitRetention-time.Hour))
+	require.NoError(t, err)
+	_, err = storage.TakeToken(ctx, "follow:user:busy", 1, 5, now)
+	require.NoError(t, err)
+
+	_, err = storage.IncrementQuota(ctx, "follow:user-1", now.UTC().AddDate(0, 0, -2).Format(time.DateOnly), 10)
+	require.NoError(t, err)
+	_, err = storage.IncrementQuota(ctx, "follow:user-1", now.UTC().Format(time.DateOnly), 10)
+	require.NoError(t, err)
+
+	// Nothing expires or needs a reminder, so no notifier is needed
+	require.NoError(t, job.Run(ctx, storage, nil))
+
+	assert.Equal(t, 2, count(t, storage, "sessions"), "expected recently dead sessions to be kept")
+	assert.Equal(t, 1, count(t, storage, "rate_limit_buckets"))
+	assert.Equal(t, 1, count(t, storage, "rate_limit_quotas"))
+
+	var key string
+	require.NoError(t, storage.DB().QueryRow(`SELECT key FROM rate_limit_buckets`).Scan(&key))
+	assert.Equal(t, "follow:user:busy", key)
+}
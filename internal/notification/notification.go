@@ -30,6 +30,7 @@ type NotifierConfig struct {
 
 type Notifier struct {
 	bot              *telegram.Bot
+	adminBot         *telegram.Bot
 	adminChatID      int64
 	communityChatID  int64
 	botWebApp        string
@@ -39,9 +40,16 @@ type Notifier struct {
 	testNotification bool
 }
 
-func NewNotifier(config NotifierConfig, bot *telegram.Bot) *Notifier {
+// NewNotifier creates a notifier. Moderator notifications go through
+// adminBot, or through bot when no admin bot is configured.
+func NewNotifier(config NotifierConfig, bot, adminBot *telegram.Bot) *Notifier {
+	if adminBot == nil {
+		adminBot = bot
+	}
+
 	return &Notifier{
 		bot:              bot,
+		adminBot:         adminBot,
 		adminChatID:      config.AdminChatID,
 		communityChatID:  config.CommunityChatID,
 		botWebApp:        config.BotWebApp,
@@ -76,10 +84,11 @@ type ImageRequest struct {
 }
 
 type CollaborationImageRequest struct {
-	Title    string   `json:"title"`
-	Subtitle string   `json:"subtitle"`
-	Tags     []Tag    `json:"tags"`
-	User     UserInfo `json:"user"`
+	Title        string   `json:"title"`
+	Subtitle     string   `json:"subtitle"`
+	Compensation string   `json:"compensation,omitempty"` // e.g. "Paid, 500-1000 EUR / month"
+	Tags         []Tag    `json:"tags"`
+	User         UserInfo `json:"user"`
 }
 
 type UserInfo struct {
@@ -390,9 +399,10 @@ func (n *Notifier) NotifyUsersWithMatchingOpportunity(collab db.Collaboration, u
 
 		// Create the image request
 		imageReq := CollaborationImageRequest{
-			Title:    collab.Title,
-			Subtitle: collab.Opportunity.Text,
-			Tags:     tags,
+			Title:        collab.Title,
+			Subtitle:     collab.Opportunity.Text,
+			Compensation: collab.Compensation.String(),
+			Tags:         tags,
 			User: UserInfo{
 				Avatar: userAvatarURL,
 				Name:   fullName,
@@ -489,6 +499,47 @@ func (n *Notifier) NotifyUsersWithMatchingOpportunity(collab db.Collaboration, u
 	return nil
 }
 
+// NotifySavedSearchMatches alerts users that a new collaboration matches one
+// of their saved searches, at most 30 messages per second
+func (n *Notifier) NotifySavedSearchMatches(collab db.Collaboration, matches []db.SavedSearchMatch) error {
+	if len(matches) == 0 {
+		return nil
+	}
+
+	rateLimiter := time.NewTicker(time.Second / 30)
+	defer rateLimiter.Stop()
+
+	for _, match := range matches {
+		<-rateLimiter.C
+
+		var msgText, btnText string
+		if match.User.LanguageCode == db.LanguageRU {
+			msgText = fmt.Sprintf("🔎 Новая коллаборация по вашему поиску \"%s\":\n\n%s", match.Search.Name, collab.Title)
+			btnText = "Посмотреть коллаборацию"
+		} else {
+			msgText = fmt.Sprintf("🔎 New collaboration for your saved search \"%s\":\n\n%s", match.Search.Name, collab.Title)
+			btnText = "View Collaboration"
+		}
+
+		keyboard := models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{{Text: btnText, URL: fmt.Sprintf("%s?startapp=c_%s", n.botWebApp, collab.ID)}},
+			},
+		}
+
+		_, err := n.bot.SendMessage(context.Background(), &telegram.SendMessageParams{
+			ChatID:      n.getChatID(match.User.ChatID),
+			Text:        msgText,
+			ReplyMarkup: &keyboard,
+		})
+		if err != nil {
+			log.Printf("Failed to send saved search notification to user %s: %v", match.User.ID, err)
+		}
+	}
+
+	return nil
+}
+
 func (n *Notifier) SendCollaborationToCommunityChatWithImage(collab db.Collaboration) error {
 	fullName := ""
 	if collab.User.Name != nil {
@@ -536,9 +587,10 @@ func (n *Notifier) SendCollaborationToCommunityChatWithImage(collab db.Collabora
 	}
 
 	imageReq := CollaborationImageRequest{
-		Title:    collab.Title,
-		Subtitle: collab.Opportunity.Text,
-		Tags:     tags,
+		Title:        collab.Title,
+		Subtitle:     collab.Opportunity.Text,
+		Compensation: collab.Compensation.String(),
+		Tags:         tags,
 		User: UserInfo{
 			Avatar: userAvatarURL,
 			Name:   fullName,
@@ -617,12 +669,14 @@ func (n *Notifier) NotifyNewPendingUser(user db.User) error {
 		ReplyMarkup: &keyboard,
 	}
 
-	_, err := n.bot.SendMessage(context.Background(), params)
+	_, err := n.adminBot.SendMessage(context.Background(), params)
 
 	return err
 }
 
-func (n *Notifier) NotifyNewPendingCollaboration(collab db.Collaboration) error {
+// NotifyNewPendingCollaboration asks admins to review a collaboration and
+// points out earlier ones it looks copied from
+func (n *Notifier) NotifyNewPendingCollaboration(collab db.Collaboration, duplicates []db.SimilarCollaboration) error {
 	user := collab.User
 
 	name := ""
@@ -644,13 +698,25 @@ func (n *Notifier) NotifyNewPendingCollaboration(collab db.Collaboration) error
 	msgText := fmt.Sprintf("🔔 New collaboration pending verification:\nTitle: %s\nBy: %s (@%s)",
 		collab.Title, name, user.Username)
 
+	if len(duplicates) > 0 {
+		msgText += "\n\n⚠️ Possible duplicate of:"
+		for _, dup := range duplicates {
+			owner := "another user"
+			if dup.UserID == collab.UserID {
+				owner = "same author"
+			}
+			msgText += fmt.Sprintf("\n• %s (%s, %.0f%% similar, %s)\n%s?startapp=c_%s",
+				dup.Title, owner, dup.Similarity*100, dup.CreatedAt.Format("2006-01-02"), n.botWebApp, dup.ID)
+		}
+	}
+
 	params := &telegram.SendMessageParams{
 		ChatID:      n.getChatID(n.adminChatID),
 		Text:        msgText,
 		ReplyMarkup: &keyboard,
 	}
 
-	_, err := n.bot.SendMessage(context.Background(), params)
+	_, err := n.adminBot.SendMessage(context.Background(), params)
 
 	return err
 }
@@ -720,6 +786,8 @@ func (n *Notifier) NotifyUserVerificationDenied(user db.User) error {
 		msgText = fmt.Sprintf("⚠️ Your profile didn’t pass verification.\nIt seems too empty or spammy. Add more details and sincerity, and try again!")
 	}
 
+	msgText += denialDetails(user.DenialReason, user.DenialComment, user.LanguageCode)
+
 	btnText := "Update Profile"
 	if user.LanguageCode == db.LanguageRU {
 		btnText = "Обновить профиль"
@@ -745,6 +813,24 @@ func (n *Notifier) NotifyUserVerificationDenied(user db.User) error {
 	return err
 }
 
+// denialDetails formats the admin's reason and comment for a denial message
+func denialDetails(reason *db.DenialReason, comment *string, lang db.LanguageCode) string {
+	reasonLabel, commentLabel := "Reason", "Moderator comment"
+	if lang == db.LanguageRU {
+		reasonLabel, commentLabel = "Причина", "Комментарий модератора"
+	}
+
+	var details string
+	if reason != nil {
+		details += fmt.Sprintf("\n\n%s: %s", reasonLabel, reason.LocalizedText(lang))
+	}
+	if comment != nil && *comment != "" {
+		details += fmt.Sprintf("\n%s: %s", commentLabel, *comment)
+	}
+
+	return details
+}
+
 func (n *Notifier) NotifyCollaborationVerificationDenied(collab db.Collaboration) error {
 	var msgText string
 	if collab.User.LanguageCode == db.LanguageRU {
@@ -753,6 +839,8 @@ func (n *Notifier) NotifyCollaborationVerificationDenied(collab db.Collaboration
 		msgText = fmt.Sprintf("⚠️ Your collaboration \"%s\" didn’t pass verification.\nThe description seems too vague or unconvincing. Add more details and specifics, and try again!", collab.Title)
 	}
 
+	msgText += denialDetails(collab.DenialReason, collab.DenialComment, collab.User.LanguageCode)
+
 	btnText := "Update Collaboration"
 	if collab.User.LanguageCode == db.LanguageRU {
 		btnText = "Обновить коллаборацию"
@@ -778,43 +866,64 @@ func (n *Notifier) NotifyCollaborationVerificationDenied(collab db.Collaboration
 	return err
 }
 
-func (n *Notifier) NotifyCollabInterest(collab db.Collaboration, user db.User) error {
-	if collab.User.ChatID == 0 {
-		return fmt.Errorf("collaboration owner %s has no chat ID", collab.User.ID)
+// Callback data prefixes of the owner's buttons on a new application,
+// followed by the application ID
+const (
+	CallbackApplicationAccept    = "app:accept:"
+	CallbackApplicationShortlist = "app:shortlist:"
+	CallbackApplicationDecline   = "app:decline:"
+)
+
+// NotifyCollabInterest tells a member of the collaboration team about a new application
+func (n *Notifier) NotifyCollabInterest(recipient db.User, collab db.Collaboration, application db.Application) error {
+	if recipient.ChatID == 0 {
+		return fmt.Errorf("collaboration member %s has no chat ID", recipient.ID)
 	}
 
+	user := application.User
 	userName := user.Username
 	if user.Name != nil && *user.Name != "" {
 		userName = *user.Name
 	}
 
+	ru := recipient.LanguageCode == db.LanguageRU
+
 	var msgText string
-	if collab.User.LanguageCode == db.LanguageRU {
-		msgText = fmt.Sprintf("🔔 [%s](https://t.me/%s) проявил интерес к вашему проекту \"%s\", напишите ему в Telegram\\!", telegram.EscapeMarkdown(userName), user.Username, collab.Title)
+	if ru {
+		msgText = fmt.Sprintf("🔔 [%s](https://t.me/%s) откликнулся на ваш проект \"%s\"", telegram.EscapeMarkdown(userName), user.Username, telegram.EscapeMarkdown(collab.Title))
 	} else {
-		msgText = fmt.Sprintf("🔔 [%s](https://t.me/%s) expressed interest in your project \"%s\", write to them in Telegram\\!", telegram.EscapeMarkdown(userName), user.Username, collab.Title)
+		msgText = fmt.Sprintf("🔔 [%s](https://t.me/%s) applied to your project \"%s\"", telegram.EscapeMarkdown(userName), user.Username, telegram.EscapeMarkdown(collab.Title))
 	}
 
-	btnText := "View Profile"
-	if collab.User.LanguageCode == db.LanguageRU {
-		btnText = "Посмотреть профиль"
+	if application.Message != "" {
+		msgText += "\n\n" + telegram.EscapeMarkdown(application.Message)
+	}
+	for _, link := range application.Links {
+		msgText += fmt.Sprintf("\n• %s: %s", telegram.EscapeMarkdown(link.Label), telegram.EscapeMarkdown(link.URL))
 	}
 
-	button := models.InlineKeyboardButton{
-		Text: btnText,
-		URL:  fmt.Sprintf("%s?startapp=u_%s", n.botWebApp, user.ID),
+	btnText, acceptText, shortlistText, declineText := "View Profile", "Accept", "Shortlist", "Decline"
+	if ru {
+		btnText, acceptText, shortlistText, declineText = "Посмотреть профиль", "Принять", "В шорт-лист", "Отклонить"
 	}
 
 	keyboard := models.InlineKeyboardMarkup{
 		InlineKeyboard: [][]models.InlineKeyboardButton{
-			{button},
+			{
+				{Text: acceptText, CallbackData: CallbackApplicationAccept + application.ID},
+				{Text: shortlistText, CallbackData: CallbackApplicationShortlist + application.ID},
+				{Text: declineText, CallbackData: CallbackApplicationDecline + application.ID},
+			},
+			{
+				{Text: btnText, URL: fmt.Sprintf("%s?startapp=u_%s", n.botWebApp, user.ID)},
+			},
 		},
 	}
 
 	disabled := true
 
 	_, err := n.bot.SendMessage(context.Background(), &telegram.SendMessageParams{
-		ChatID: n.getChatID(collab.User.ChatID),
+		ChatID: n.getChatID(recipient.ChatID),
 		LinkPreviewOptions: &models.LinkPreviewOptions{
 			IsDisabled: &disabled,
 		},
@@ -831,3 +940,259 @@ func (n *Notifier) NotifyCollabInterest(collab db.Collaboration, user db.User) e
 
 	return err
 }
+
+// NotifyApplicationStatusChanged tells the applicant the owner answered
+func (n *Notifier) NotifyApplicationStatusChanged(application db.Application) error {
+	applicant := application.User
+	if applicant.ChatID == 0 {
+		return fmt.Errorf("applicant %s has no chat ID", applicant.ID)
+	}
+
+	collab := application.Collaboration
+	ru := applicant.LanguageCode == db.LanguageRU
+
+	var msgText string
+	switch application.Status {
+	case db.ApplicationStatusAccepted:
+		if ru {
+			msgText = fmt.Sprintf("🎉 Ваш отклик на проект \"%s\" принят! Напишите автору: @%s", collab.Title, collab.User.Username)
+		} else {
+			msgText = fmt.Sprintf("🎉 Your application to \"%s\" was accepted! Write to the author: @%s", collab.Title, collab.User.Username)
+		}
+	case db.ApplicationStatusShortlisted:
+		if ru {
+			msgText = fmt.Sprintf("⭐ Ваш отклик на проект \"%s\" добавлен в шорт-лист.", collab.Title)
+		} else {
+			msgText = fmt.Sprintf("⭐ Your application to \"%s\" was shortlisted.", collab.Title)
+		}
+	case db.ApplicationStatusDeclined:
+		if ru {
+			msgText = fmt.Sprintf("Автор проекта \"%s\" выбрал другого участника. Не сдавайтесь, в Peatch много других проектов!", collab.Title)
+		} else {
+			msgText = fmt.Sprintf("The author of \"%s\" went with someone else. Don't give up, there are plenty of other projects on Peatch!", collab.Title)
+		}
+	default:
+		return nil
+	}
+
+	btnText := "View Collaboration"
+	if ru {
+		btnText = "Посмотреть коллаборацию"
+	}
+
+	keyboard := models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{Text: btnText, URL: fmt.Sprintf("%s?startapp=c_%s", n.botWebApp, collab.ID)}},
+		},
+	}
+
+	_, err := n.bot.SendMessage(context.Background(), &telegram.SendMessageParams{
+		ChatID:      n.getChatID(applicant.ChatID),
+		Text:        msgText,
+		ReplyMarkup: &keyboard,
+	})
+
+	if err != nil && strings.Contains(err.Error(), "Forbidden") &&
+		(strings.Contains(err.Error(), "bot was blocked by the user") ||
+			strings.Contains(err.Error(), "user is deactivated")) {
+		return ErrUserBlockedBot
+	}
+
+	return err
+}
+
+// NotifyNewComment tells a collaboration team member about a new question,
+// or someone who asked about a reply in their thread
+func (n *Notifier) NotifyNewComment(recipient db.User, collab db.Collaboration, comment db.Comment) error {
+	if recipient.ChatID == 0 {
+		return fmt.Errorf("user %s has no chat ID", recipient.ID)
+	}
+
+	author := comment.User.Username
+	if comment.User.Name != nil && *comment.User.Name != "" {
+		author = *comment.User.Name
+	}
+
+	ru := recipient.LanguageCode == db.LanguageRU
+
+	var msgText, btnText string
+	switch {
+	case comment.ParentID == nil && ru:
+		msgText = fmt.Sprintf("💬 %s спрашивает о проекте \"%s\":\n\n%s", author, collab.Title, comment.Body)
+	case comment.ParentID == nil:
+		msgText = fmt.Sprintf("💬 %s asked about \"%s\":\n\n%s", author, collab.Title, comment.Body)
+	case ru:
+		msgText = fmt.Sprintf("💬 %s ответил в обсуждении проекта \"%s\":\n\n%s", author, collab.Title, comment.Body)
+	default:
+		msgText = fmt.Sprintf("💬 %s replied in the discussion of \"%s\":\n\n%s", author, collab.Title, comment.Body)
+	}
+	if ru {
+		btnText = "Посмотреть коллаборацию"
+	} else {
+		btnText = "View Collaboration"
+	}
+
+	keyboard := models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{Text: btnText, URL: fmt.Sprintf("%s?startapp=c_%s", n.botWebApp, collab.ID)}},
+		},
+	}
+
+	_, err := n.bot.SendMessage(context.Background(), &telegram.SendMessageParams{
+		ChatID:      n.getChatID(recipient.ChatID),
+		Text:        msgText,
+		ReplyMarkup: &keyboard,
+	})
+
+	return err
+}
+
+// NotifyCommentReported tells the admins a comment was reported
+func (n *Notifier) NotifyCommentReported(comment db.Comment) error {
+	msgText := fmt.Sprintf("🚩 Comment reported (%d):\n%s\nBy: @%s\nCollaboration: %s",
+		comment.ReportCount, comment.Body, comment.User.Username, comment.CollaborationID)
+	if comment.HiddenAt != nil {
+		msgText += "\nHidden until reviewed"
+	}
+
+	_, err := n.adminBot.SendMessage(context.Background(), &telegram.SendMessageParams{
+		ChatID: n.getChatID(n.adminChatID),
+		Text:   msgText,
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{{Text: "Review", URL: n.adminWebApp}},
+			},
+		},
+	})
+
+	return err
+}
+
+// SendBroadcast delivers an admin broadcast to a single chat in the given
+// language. Image broadcasts go out as a photo with the text as caption.
+func (n *Notifier) SendBroadcast(chatID int64, lang db.LanguageCode, b db.Broadcast) error {
+	text := b.LocalizedText(lang)
+
+	var replyMarkup models.ReplyMarkup
+	if b.ButtonPath != nil && *b.ButtonPath != "" {
+		replyMarkup = &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{
+				{
+					{
+						Text:   b.LocalizedButtonText(lang),
+						WebApp: &models.WebAppInfo{URL: n.webappURL + *b.ButtonPath},
+					},
+				},
+			},
+		}
+	}
+
+	if b.ImageURL != nil && *b.ImageURL != "" {
+		_, err := n.bot.SendPhoto(context.Background(), &telegram.SendPhotoParams{
+			ChatID:      n.getChatID(chatID),
+			Photo:       &models.InputFileString{Data: *b.ImageURL},
+			Caption:     text,
+			ReplyMarkup: replyMarkup,
+		})
+		return err
+	}
+
+	_, err := n.bot.SendMessage(context.Background(), &telegram.SendMessageParams{
+		ChatID:      n.getChatID(chatID),
+		Text:        text,
+		ReplyMarkup: replyMarkup,
+	})
+
+	return err
+}
+
+// NotifyCollaborationInvite tells a user they were invited to the team of a
+// collaboration, they accept or decline in the app
+func (n *Notifier) NotifyCollaborationInvite(invitee db.User, collab db.Collaboration, role db.CollaborationRole) error {
+	if invitee.ChatID == 0 {
+		return fmt.Errorf("invitee %s has no chat ID", invitee.ID)
+	}
+
+	var msgText, viewText string
+	if invitee.LanguageCode == db.LanguageRU {
+		roleText := "редактора"
+		if role == db.CollaborationRoleOwner {
+			roleText = "владельца"
+		}
+		msgText = fmt.Sprintf("🤝 Вас пригласили в команду коллаборации \"%s\" в роли %s. Откройте её, чтобы принять или отклонить приглашение.",
+			collab.Title, roleText)
+		viewText = "Посмотреть коллаборацию"
+	} else {
+		msgText = fmt.Sprintf("🤝 You were invited to join the team of \"%s\" as %s. Open it to accept or decline the invitation.",
+			collab.Title, role)
+		viewText = "View Collaboration"
+	}
+
+	keyboard := models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{Text: viewText, URL: fmt.Sprintf("%s?startapp=c_%s", n.botWebApp, collab.ID)}},
+		},
+	}
+
+	_, err := n.bot.SendMessage(context.Background(), &telegram.SendMessageParams{
+		ChatID:      n.getChatID(invitee.ChatID),
+		Text:        msgText,
+		ReplyMarkup: &keyboard,
+	})
+
+	if err != nil && strings.Contains(err.Error(), "Forbidden") &&
+		(strings.Contains(err.Error(), "bot was blocked by the user") ||
+			strings.Contains(err.Error(), "user is deactivated")) {
+		return ErrUserBlockedBot
+	}
+
+	return err
+}
+
+// CallbackExtendCollaboration prefixes the callback data of the "extend"
+// button, followed by the collaboration ID
+const CallbackExtendCollaboration = "collab:extend:"
+
+// NotifyCollaborationExpiring reminds the owner that a collaboration is about
+// to leave the feed, with a button to keep it open longer
+func (n *Notifier) NotifyCollaborationExpiring(collab db.Collaboration) error {
+	if collab.User.ChatID == 0 {
+		return fmt.Errorf("collaboration owner %s has no chat ID", collab.User.ID)
+	}
+
+	days := int(db.CollaborationExtension.Hours() / 24)
+
+	var msgText, extendText, viewText string
+	if collab.User.LanguageCode == db.LanguageRU {
+		msgText = fmt.Sprintf("⏳ Ваша коллаборация \"%s\" будет скрыта из ленты %s. Продлите её, если она всё ещё актуальна.",
+			collab.Title, collab.ExpiresAt.Format("02.01.2006"))
+		extendText = fmt.Sprintf("Продлить на %d дней", days)
+		viewText = "Посмотреть коллаборацию"
+	} else {
+		msgText = fmt.Sprintf("⏳ Your collaboration \"%s\" leaves the feed on %s. Extend it if it's still relevant.",
+			collab.Title, collab.ExpiresAt.Format("Jan 2, 2006"))
+		extendText = fmt.Sprintf("Extend by %d days", days)
+		viewText = "View Collaboration"
+	}
+
+	keyboard := models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			{{Text: extendText, CallbackData: CallbackExtendCollaboration + collab.ID}},
+			{{Text: viewText, URL: fmt.Sprintf("%s?startapp=c_%s", n.botWebApp, collab.ID)}},
+		},
+	}
+
+	_, err := n.bot.SendMessage(context.Background(), &telegram.SendMessageParams{
+		ChatID:      n.getChatID(collab.User.ChatID),
+		Text:        msgText,
+		ReplyMarkup: &keyboard,
+	})
+
+	if err != nil && strings.Contains(err.Error(), "Forbidden") &&
+		(strings.Contains(err.Error(), "bot was blocked by the user") ||
+			strings.Contains(err.Error(), "user is deactivated")) {
+		return ErrUserBlockedBot
+	}
+
+	return err
+}
@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepEvery is how many operations pass between sweeps of stale entries
+const sweepEvery = 1000
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	idleAfter time.Time // full again from here on, safe to forget
+}
+
+type quota struct {
+	day   string
+	count int
+}
+
+// MemoryStore keeps limits in process memory. State is lost on restart and
+// not shared between instances.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]bucket
+	quotas  map[string]quota
+	ops     int
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]bucket),
+		quotas:  make(map[string]quota),
+	}
+}
+
+func (m *MemoryStore) TakeToken(_ context.Context, key string, perSecond float64, burst int, now time.Time) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.maybeSweep(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = bucket{tokens: float64(burst), updatedAt: now}
+	}
+
+	tokens, wait := Take(b.tokens, b.updatedAt, now, perSecond, burst)
+	refillTime := time.Duration((float64(burst) - tokens) / perSecond * float64(time.Second))
+	m.buckets[key] = bucket{tokens: tokens, updatedAt: now, idleAfter: now.Add(refillTime)}
+
+	return wait, nil
+}
+
+func (m *MemoryStore) IncrementQuota(_ context.Context, key, day string, limit int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	q := m.quotas[key]
+	if q.day != day {
+		q = quota{day: day}
+	}
+
+	if q.count >= limit {
+		return false, nil
+	}
+
+	q.count++
+	m.quotas[key] = q
+
+	return true, nil
+}
+
+func (m *MemoryStore) ReturnToken(_ context.Context, key string, burst int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// A forgotten bucket is full already
+	if b, ok := m.buckets[key]; ok {
+		b.tokens = min(b.tokens+1, float64(burst))
+		m.buckets[key] = b
+	}
+
+	return nil
+}
+
+func (m *MemoryStore) DecrementQuota(_ context.Context, key, day string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if q, ok := m.quotas[key]; ok && q.day == day && q.count > 0 {
+		q.count--
+		m.quotas[key] = q
+	}
+
+	return nil
+}
+
+func (m *MemoryStore) maybeSweep(now time.Time) {
+	m.ops++
+	if m.ops < sweepEvery {
+		return
+	}
+	m.ops = 0
+
+	for key, b := range m.buckets {
+		if now.After(b.idleAfter) {
+			delete(m.buckets, key)
+		}
+	}
+
+	today := now.UTC().Format(time.DateOnly)
+	for key, q := range m.quotas {
+		if q.day != today {
+			delete(m.quotas, key)
+		}
+	}
+}
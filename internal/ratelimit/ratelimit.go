@@ -0,0 +1,194 @@
+// Package ratelimit implements per-user and per-IP token buckets and daily
+// per-user quotas on top of a pluggable store.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Rule is a token bucket: Burst requests at once, refilled at PerMinute.
+// A zero rule doesn't limit.
+type Rule struct {
+	PerMinute float64 `yaml:"per_minute"`
+	Burst     int     `yaml:"burst"`
+}
+
+func (r Rule) enabled() bool {
+	return r.PerMinute > 0 && r.Burst > 0
+}
+
+// Policy limits a single route
+type Policy struct {
+	User  Rule `yaml:"user"`
+	IP    Rule `yaml:"ip"`
+	Daily int  `yaml:"daily"` // Requests per user per UTC day, 0 for no quota
+}
+
+// Store keeps bucket and quota state. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// TakeToken takes a token from the bucket at key. It returns zero if a
+	// token was available, or how long until one will be.
+	TakeToken(ctx context.Context, key string, perSecond float64, burst int, now time.Time) (time.Duration, error)
+	// IncrementQuota counts a request against key for day unless limit is
+	// already reached, and reports whether it was counted
+	IncrementQuota(ctx context.Context, key, day string, limit int) (bool, error)
+	// ReturnToken puts a taken token back into the bucket at key, up to burst
+	ReturnToken(ctx context.Context, key string, burst int) error
+	// DecrementQuota uncounts a request counted against key for day
+	DecrementQuota(ctx context.Context, key, day string) error
+}
+
+// Result of a limit check. RetryAfter is set when the request isn't allowed.
+type Result struct {
+	Allowed    bool
+	RetryAfter time.Duration
+	Reason     string
+}
+
+type Limiter struct {
+	store    Store
+	policies map[string]Policy
+	now      func() time.Time
+}
+
+func New(store Store, policies map[string]Policy) *Limiter {
+	return &Limiter{
+		store:    store,
+		policies: policies,
+		now:      time.Now,
+	}
+}
+
+// Allow checks and consumes the route's limits for a request. userID and ip
+// may be empty, in which case the matching bucket is skipped. Routes without
+// a policy are not limited. A denied request takes nothing: tokens taken
+// before the limit that denied it are returned.
+func (l *Limiter) Allow(ctx context.Context, route, userID, ip string) (Result, error) {
+	policy, ok := l.policies[route]
+	if !ok {
+		return Result{Allowed: true}, nil
+	}
+
+	now := l.now()
+
+	if userID != "" && policy.User.enabled() {
+		wait, err := l.store.TakeToken(ctx, userKey(route, userID), policy.User.PerMinute/60, policy.User.Burst, now)
+		if err != nil {
+			return Result{}, err
+		}
+		if wait > 0 {
+			return Result{RetryAfter: wait, Reason: "too many requests"}, nil
+		}
+	}
+
+	if ip != "" && policy.IP.enabled() {
+		wait, err := l.store.TakeToken(ctx, ipKey(route, ip), policy.IP.PerMinute/60, policy.IP.Burst, now)
+		if err != nil {
+			return Result{}, err
+		}
+		if wait > 0 {
+			if err := l.returnTokens(ctx, route, policy, userID, ""); err != nil {
+				return Result{}, err
+			}
+			return Result{RetryAfter: wait, Reason: "too many requests from this address"}, nil
+		}
+	}
+
+	if userID != "" && policy.Daily > 0 {
+		day := quotaDay(now)
+		counted, err := l.store.IncrementQuota(ctx, quotaKey(route, userID), day, policy.Daily)
+		if err != nil {
+			return Result{}, err
+		}
+		if !counted {
+			if err := l.returnTokens(ctx, route, policy, userID, ip); err != nil {
+				return Result{}, err
+			}
+			tomorrow := now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+			return Result{RetryAfter: tomorrow.Sub(now), Reason: "daily limit reached"}, nil
+		}
+	}
+
+	return Result{Allowed: true}, nil
+}
+
+// Refund gives back what an allowed request took from the route's limits,
+// for requests that turned out not to count. The quota is given back on the
+// current day, a request refunded after midnight UTC leaves it alone.
+func (l *Limiter) Refund(ctx context.Context, route, userID, ip string) error {
+	policy, ok := l.policies[route]
+	if !ok {
+		return nil
+	}
+
+	if err := l.returnTokens(ctx, route, policy, userID, ip); err != nil {
+		return err
+	}
+
+	if userID != "" && policy.Daily > 0 {
+		if err := l.store.DecrementQuota(ctx, quotaKey(route, userID), quotaDay(l.now())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// returnTokens puts back the tokens taken from the user's and the address's
+// buckets, skipping the empty ones
+func (l *Limiter) returnTokens(ctx context.Context, route string, policy Policy, userID, ip string) error {
+	if userID != "" && policy.User.enabled() {
+		if err := l.store.ReturnToken(ctx, userKey(route, userID), policy.User.Burst); err != nil {
+			return err
+		}
+	}
+
+	if ip != "" && policy.IP.enabled() {
+		if err := l.store.ReturnToken(ctx, ipKey(route, ip), policy.IP.Burst); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func userKey(route, userID string) string {
+	return fmt.Sprintf("%s:user:%s", route, userID)
+}
+
+func ipKey(route, ip string) string {
+	return fmt.Sprintf("%s:ip:%s", route, ip)
+}
+
+func quotaKey(route, userID string) string {
+	return fmt.Sprintf("%s:%s", route, userID)
+}
+
+// quotaDay is the UTC day quotas are counted for
+func quotaDay(now time.Time) string {
+	return now.UTC().Format(time.DateOnly)
+}
+
+// refill returns the bucket's tokens after elapsed time, capped at burst
+func refill(tokens float64, elapsed time.Duration, perSecond float64, burst int) float64 {
+	if elapsed > 0 {
+		tokens += elapsed.Seconds() * perSecond
+	}
+	return min(tokens, float64(burst))
+}
+
+// Take applies a token bucket step. It returns the new token count and the
+// wait before a token is available, zero if one was taken. Stores share it so
+// they behave the same.
+func Take(tokens float64, updatedAt, now time.Time, perSecond float64, burst int) (float64, time.Duration) {
+	tokens = refill(tokens, now.Sub(updatedAt), perSecond, burst)
+	if tokens >= 1 {
+		return tokens - 1, 0
+	}
+
+	wait := time.Duration((1 - tokens) / perSecond * float64(time.Second))
+	return tokens, max(wait, time.Second)
+}
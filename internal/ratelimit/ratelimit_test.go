@@ -0,0 +1,231 @@
+package ratelimit_test
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/peatch-io/peatch/internal/db"
+	"github.com/peatch-io/peatch/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTake_Refill(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		tokens  float64
+		elapsed time.Duration
+		left    float64
+		wait    time.Duration
+	}{
+		{"full bucket", 5, 0, 4, 0},
+		{"last token", 1, 0, 0, 0},
+		{"empty bucket", 0, 0, 0, 2 * time.Second},
+		{"partly refilled", 0, time.Second, 0.5, time.Second},
+		{"refilled", 0, 2 * time.Second, 0, 0},
+		{"refill stops at burst", 0, time.Hour, 4, 0},
+		{"waits at least a second", 0.9, 0, 0.9, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			left, wait := ratelimit.Take(tt.tokens, start, start.Add(tt.elapsed), 0.5, 5)
+			assert.InDelta(t, tt.left, left, 1e-9)
+			assert.Equal(t, tt.wait, wait)
+		})
+	}
+}
+
+// stores runs a test against every store
+func stores(t *testing.T, test func(t *testing.T, store ratelimit.Store)) {
+	t.Run("memory", func(t *testing.T) {
+		test(t, ratelimit.NewMemoryStore())
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		// A file so connections are really concurrent
+		storage, err := db.NewStorage(filepath.Join(t.TempDir(), "ratelimit.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { storage.Close() })
+		require.NoError(t, storage.InitSchema())
+
+		test(t, storage)
+	})
+}
+
+func TestStore_BucketRefill(t *testing.T) {
+	stores(t, func(t *testing.T, store ratelimit.Store) {
+		ctx := context.Background()
+		now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+		// Two tokens, one more every 10 seconds
+		take := func(at time.Time) time.Duration {
+			wait, err := store.TakeToken(ctx, "route:user:1", 0.1, 2, at)
+			require.NoError(t, err)
+			return wait
+		}
+
+		assert.Zero(t, take(now))
+		assert.Zero(t, take(now))
+		assert.Equal(t, 10*time.Second, take(now))
+		assert.Equal(t, 5*time.Second, take(now.Add(5*time.Second)))
+		assert.Zero(t, take(now.Add(10*time.Second)))
+
+		// A returned token can be taken again
+		assert.Equal(t, 10*time.Second, take(now.Add(10*time.Second)))
+		require.NoError(t, store.ReturnToken(ctx, "route:user:1", 2))
+		assert.Zero(t, take(now.Add(10*time.Second)))
+
+		// Buckets are separate
+		wait, err := store.TakeToken(ctx, "route:user:2", 0.1, 2, now)
+		require.NoError(t, err)
+		assert.Zero(t, wait)
+	})
+}
+
+func TestStore_QuotaRollover(t *testing.T) {
+	stores(t, func(t *testing.T, store ratelimit.Store) {
+		ctx := context.Background()
+
+		count := func(day string) bool {
+			counted, err := store.IncrementQuota(ctx, "route:1", day, 2)
+			require.NoError(t, err)
+			return counted
+		}
+
+		assert.True(t, count("2026-01-01"))
+		assert.True(t, count("2026-01-01"))
+		assert.False(t, count("2026-01-01"))
+
+		// A refund frees a slot, only on its day
+		require.NoError(t, store.DecrementQuota(ctx, "route:1", "2026-01-01"))
+		assert.True(t, count("2026-01-01"))
+		assert.False(t, count("2026-01-01"))
+
+		// The quota starts over the next day
+		assert.True(t, count("2026-01-02"))
+		assert.True(t, count("2026-01-02"))
+		assert.False(t, count("2026-01-02"))
+	})
+}
+
+func TestStore_ConcurrentTakes(t *testing.T) {
+	stores(t, func(t *testing.T, store ratelimit.Store) {
+		ctx := context.Background()
+		now := time.Now()
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		taken := 0
+
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				wait, err := store.TakeToken(ctx, "route:ip:1", 0.001, 5, now)
+				assert.NoError(t, err)
+
+				if err == nil && wait == 0 {
+					mu.Lock()
+					taken++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 5, taken)
+	})
+}
+
+func TestLimiter_Refund(t *testing.T) {
+	ctx := context.Background()
+
+	limiter := ratelimit.New(ratelimit.NewMemoryStore(), map[string]ratelimit.Policy{
+		"comment": {
+			User:  ratelimit.Rule{PerMinute: 1, Burst: 1},
+			IP:    ratelimit.Rule{PerMinute: 1, Burst: 1},
+			Daily: 1,
+		},
+	})
+
+	result, err := limiter.Allow(ctx, "comment", "user-1", "192.0.2.1")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	result, err = limiter.Allow(ctx, "comment", "user-1", "192.0.2.1")
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+
+	// Refunding gives back the buckets and the quota
+	require.NoError(t, limiter.Refund(ctx, "comment", "user-1", "192.0.2.1"))
+
+	result, err = limiter.Allow(ctx, "comment", "user-1", "192.0.2.1")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// Routes without a policy have nothing to refund
+	require.NoError(t, limiter.Refund(ctx, "unknown", "user-1", "192.0.2.1"))
+}
+
+func TestLimiter_DeniedRequestsTakeNothing(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("address limit", func(t *testing.T) {
+		limiter := ratelimit.New(ratelimit.NewMemoryStore(), map[string]ratelimit.Policy{
+			"follow": {
+				User: ratelimit.Rule{PerMinute: 1, Burst: 2},
+				IP:   ratelimit.Rule{PerMinute: 1, Burst: 1},
+			},
+		})
+
+		result, err := limiter.Allow(ctx, "follow", "user-1", "192.0.2.1")
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+
+		// The busy address is denied without spending the user's last token
+		for i := 0; i < 3; i++ {
+			result, err = limiter.Allow(ctx, "follow", "user-1", "192.0.2.1")
+			require.NoError(t, err)
+			assert.False(t, result.Allowed)
+			assert.Equal(t, "too many requests from this address", result.Reason)
+		}
+
+		result, err = limiter.Allow(ctx, "follow", "user-1", "192.0.2.2")
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("daily quota", func(t *testing.T) {
+		limiter := ratelimit.New(ratelimit.NewMemoryStore(), map[string]ratelimit.Policy{
+			"comment": {
+				User:  ratelimit.Rule{PerMinute: 1, Burst: 2},
+				IP:    ratelimit.Rule{PerMinute: 1, Burst: 2},
+				Daily: 1,
+			},
+		})
+
+		result, err := limiter.Allow(ctx, "comment", "user-1", "192.0.2.1")
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+
+		// Past the quota the buckets stay as they were, so it stays the reason
+		for i := 0; i < 3; i++ {
+			result, err = limiter.Allow(ctx, "comment", "user-1", "192.0.2.1")
+			require.NoError(t, err)
+			assert.False(t, result.Allowed)
+			assert.Equal(t, "daily limit reached", result.Reason)
+		}
+
+		// The address still has its token for someone else
+		result, err = limiter.Allow(ctx, "comment", "user-2", "192.0.2.1")
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+}
@@ -6,11 +6,13 @@ import (
 	"fmt"
 	telegram "github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 	"github.com/peatch-io/peatch/internal/contract"
 	"github.com/peatch-io/peatch/internal/db"
 	"github.com/peatch-io/peatch/internal/handler"
 	"github.com/peatch-io/peatch/internal/middleware"
+	"github.com/peatch-io/peatch/internal/nanoid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -38,18 +40,36 @@ type MockNotificationService struct {
 	UserVerificationDeniedFunc          func(user db.User) error
 	CollaborationVerificationDeniedFunc func(collab db.Collaboration) error
 	NewPendingUserFunc                  func(user db.User) error
-	NewPendingCollaborationFunc         func(collab db.Collaboration) error
+	NewPendingCollaborationFunc         func(collab db.Collaboration, duplicates []db.SimilarCollaboration) error
 	UserFollowFunc                      func(user db.User, follower db.User) error
 	CollabInterestFunc                  func(user db.User, collab db.Collaboration) error
+	ApplicationStatusFunc               func(application db.Application) error
 	SendCollaborationToCommunityFunc    func(collab db.Collaboration) error
+	SendBroadcastFunc                   func(chatID int64, lang db.LanguageCode, b db.Broadcast) error
+	MatchingOpportunityFunc             func(collab db.Collaboration, users []db.User) error
+	SavedSearchMatchesFunc              func(collab db.Collaboration, matches []db.SavedSearchMatch) error
+	NewCommentFunc                      func(recipient db.User, collab db.Collaboration, comment db.Comment) error
+	CommentReportedFunc                 func(comment db.Comment) error
 	// Call tracking for testing
-	CollabInterestRecord TestCallRecord
-	UserFollowRecord     TestCallRecord // For tracking user follow notifications
+	CollabInterestRecord     TestCallRecord
+	CollabInterestRecipients []string       // Users told about an application, one per team member
+	CollabInviteRecipients   []string       // Users invited to join a team
+	UserFollowRecord         TestCallRecord // For tracking user follow notifications
+	ApplicationStatusRecord  TestCallRecord // ToFollowID is the application, FollowerID the applicant
 }
 
 func (m *MockNotificationService) NotifyUsersWithMatchingOpportunity(collab db.Collaboration, users []db.User) error {
-	//TODO implement me
-	panic("implement me")
+	if m.MatchingOpportunityFunc != nil {
+		return m.MatchingOpportunityFunc(collab, users)
+	}
+	return nil
+}
+
+func (m *MockNotificationService) NotifySavedSearchMatches(collab db.Collaboration, matches []db.SavedSearchMatch) error {
+	if m.SavedSearchMatchesFunc != nil {
+		return m.SavedSearchMatchesFunc(collab, matches)
+	}
+	return nil
 }
 
 func (m *MockNotificationService) NotifyUserVerified(user db.User) error {
@@ -87,9 +107,9 @@ func (m *MockNotificationService) NotifyNewPendingUser(user db.User) error {
 	return nil
 }
 
-func (m *MockNotificationService) NotifyNewPendingCollaboration(collab db.Collaboration) error {
+func (m *MockNotificationService) NotifyNewPendingCollaboration(collab db.Collaboration, duplicates []db.SimilarCollaboration) error {
 	if m.NewPendingCollaborationFunc != nil {
-		return m.NewPendingCollaborationFunc(collab)
+		return m.NewPendingCollaborationFunc(collab, duplicates)
 	}
 	return nil
 }
@@ -105,13 +125,44 @@ func (m *MockNotificationService) NotifyUserFollow(userID db.User, follower db.U
 	return nil
 }
 
-func (m *MockNotificationService) NotifyCollabInterest(collab db.Collaboration, user db.User) error {
+func (m *MockNotificationService) NotifyCollabInterest(recipient db.User, collab db.Collaboration, application db.Application) error {
 	m.CollabInterestRecord.Called = true
-	m.CollabInterestRecord.FollowerID = user.ID
+	m.CollabInterestRecord.FollowerID = application.UserID
 	m.CollabInterestRecord.ToFollowID = collab.ID
+	m.CollabInterestRecipients = append(m.CollabInterestRecipients, recipient.ID)
 
 	if m.CollabInterestFunc != nil {
-		return m.CollabInterestFunc(user, collab)
+		return m.CollabInterestFunc(application.User, collab)
+	}
+	return nil
+}
+
+func (m *MockNotificationService) NotifyCollaborationInvite(invitee db.User, collab db.Collaboration, role db.CollaborationRole) error {
+	m.CollabInviteRecipients = append(m.CollabInviteRecipients, invitee.ID)
+	return nil
+}
+
+func (m *MockNotificationService) NotifyNewComment(recipient db.User, collab db.Collaboration, comment db.Comment) error {
+	if m.NewCommentFunc != nil {
+		return m.NewCommentFunc(recipient, collab, comment)
+	}
+	return nil
+}
+
+func (m *MockNotificationService) NotifyCommentReported(comment db.Comment) error {
+	if m.CommentReportedFunc != nil {
+		return m.CommentReportedFunc(comment)
+	}
+	return nil
+}
+
+func (m *MockNotificationService) NotifyApplicationStatusChanged(application db.Application) error {
+	m.ApplicationStatusRecord.Called = true
+	m.ApplicationStatusRecord.FollowerID = application.UserID
+	m.ApplicationStatusRecord.ToFollowID = application.ID
+
+	if m.ApplicationStatusFunc != nil {
+		return m.ApplicationStatusFunc(application)
 	}
 	return nil
 }
@@ -123,6 +174,13 @@ func (m *MockNotificationService) SendCollaborationToCommunityChatWithImage(coll
 	return nil
 }
 
+func (m *MockNotificationService) SendBroadcast(chatID int64, lang db.LanguageCode, b db.Broadcast) error {
+	if m.SendBroadcastFunc != nil {
+		return m.SendBroadcastFunc(chatID, lang, b)
+	}
+	return nil
+}
+
 type MockPhotoUploader struct {
 	UploadedFiles map[string]string
 }
@@ -151,8 +209,11 @@ func (m *MockEmbeddingService) GenerateEmbedding(ctx context.Context, text strin
 }
 
 const (
-	TestBotToken       = "test-bot-token"
-	TelegramTestUserID = 927635965
+	TestBotToken           = "test-bot-token"
+	TestJWTSecret          = "test-jwt-secret"
+	TestWebhookSecret      = "test-webhook-secret"
+	TestAdminWebhookSecret = "test-admin-webhook-secret"
+	TelegramTestUserID     = 927635965
 )
 
 func (m *MockPhotoUploader) UploadFile(ctx context.Context, key string, body io.Reader, contentType string) error {
@@ -194,28 +255,38 @@ type testSetup struct {
 	MockBot              *MockTelegramBot
 	MockNotifier         *MockNotificationService
 	MockEmbeddingService *MockEmbeddingService
+	Telegram             *FakeTelegram // Bot API both bots talk to
 	Teardown             func()
 }
 
-func SetupTestEnvironment(t *testing.T) *testSetup {
+// SetupTestEnvironment starts the API on a fresh in-memory database, with the
+// test config changed by configure
+func SetupTestEnvironment(t *testing.T, configure ...func(*handler.Config)) *testSetup {
 	t.Helper()
 
 	hConfig := handler.Config{
-		JWTSecret:        "test-jwt-secret",
-		WebhookURL:       "http://localhost/test/webhook",
-		TelegramBotToken: TestBotToken,
-		AdminBotToken:    "test-tg-admin-token",
-		AssetsURL:        "http://localhost/assets",
-		AdminChatID:      12345,
-		CommunityChatID:  67890,
-		WebAppURL:        "http://localhost/webapp",
-		BotWebApp:        "http://localhost/botwebapp",
-		ImageServiceURL:  "http://localhost/images",
+		JWTSecret:          TestJWTSecret,
+		WebhookURL:         "http://localhost/test/webhook",
+		WebhookSecret:      TestWebhookSecret,
+		AdminWebhookSecret: TestAdminWebhookSecret,
+		TelegramBotToken:   TestBotToken,
+		AdminBotToken:      "test-tg-admin-token",
+		AssetsURL:          "http://localhost/assets",
+		AdminChatID:        12345,
+		CommunityChatID:    67890,
+		WebAppURL:          "http://localhost/webapp",
+		BotWebApp:          "http://localhost/botwebapp",
+		ImageServiceURL:    "http://localhost/images",
+	}
+	for _, fn := range configure {
+		fn(&hConfig)
 	}
 
 	// Use a shared in-memory database for tests to avoid connection issues
-	// The ?cache=shared ensures all connections see the same database
-	storage, err := db.NewStorage("file::memory:?cache=shared")
+	// The ?cache=shared ensures all connections see the same database, and the
+	// name keeps it to this test even if a goroutine of the last one is still
+	// holding a connection
+	storage, err := db.NewStorage(fmt.Sprintf("file:%s?mode=memory&cache=shared", nanoid.Must()))
 	require.NoError(t, err, "Failed to create in-memory storage")
 
 	err = storage.InitSchema()
@@ -232,7 +303,14 @@ func SetupTestEnvironment(t *testing.T) *testSetup {
 	mockNotifierClient := new(MockNotificationService)
 	mockEmbeddingSvc := new(MockEmbeddingService)
 
-	h := handler.New(storage, hConfig, mockS3Client, logger, nil, mockNotifierClient, mockEmbeddingSvc)
+	fakeTelegram := NewFakeTelegram(t)
+	if hConfig.TelegramAPIURL == "" {
+		hConfig.TelegramAPIURL = fakeTelegram.URL
+	}
+	bot := fakeTelegram.NewBot(t, hConfig.TelegramBotToken)
+	adminBot := fakeTelegram.NewBot(t, hConfig.AdminBotToken)
+
+	h := handler.New(storage, hConfig, mockS3Client, logger, bot, adminBot, mockNotifierClient, mockEmbeddingSvc)
 
 	e := echo.New()
 	middleware.Setup(e, logger)
@@ -252,6 +330,7 @@ func SetupTestEnvironment(t *testing.T) *testSetup {
 		MockBot:              mockBotClient,
 		MockNotifier:         mockNotifierClient,
 		MockEmbeddingService: mockEmbeddingSvc,
+		Telegram:             fakeTelegram,
 		Teardown:             teardown,
 	}
 }
@@ -312,3 +391,27 @@ func AuthHelper(t *testing.T, e *echo.Echo, telegramID int64, username, firstNam
 
 	return resp, nil
 }
+
+// AdminAuthHelper registers an admin and returns a signed admin JWT for it
+func AdminAuthHelper(t *testing.T, storage *db.Storage, chatID int64, username string) string {
+	t.Helper()
+
+	admin, err := storage.CreateAdmin(context.Background(), db.Admin{
+		ID:       fmt.Sprintf("admin_%d", chatID),
+		Username: username,
+		ChatID:   chatID,
+	})
+	require.NoError(t, err, "Failed to create admin")
+
+	claims := &contract.AdminJWTClaims{
+		AdminID: admin.ID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(TestJWTSecret))
+	require.NoError(t, err, "Failed to sign admin token")
+
+	return token
+}
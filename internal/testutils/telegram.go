@@ -0,0 +1,145 @@
+package testutils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	telegram "github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/require"
+)
+
+// TelegramCall is a Bot API request received by FakeTelegram
+type TelegramCall struct {
+	Token  string
+	Method string
+	Params map[string]string // Form fields, objects like reply_markup stay JSON encoded
+}
+
+// FakeTelegram is a Bot API server that records every call. Messages are
+// answered with a sent message, getUpdates with the queued updates from the
+// offset on and anything else with true.
+type FakeTelegram struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	calls   []TelegramCall
+	updates map[string][]models.Update // By bot token
+}
+
+func NewFakeTelegram(t *testing.T) *FakeTelegram {
+	t.Helper()
+
+	fake := &FakeTelegram{}
+	fake.Server = httptest.NewServer(http.HandlerFunc(fake.serve))
+	t.Cleanup(fake.Close)
+
+	return fake
+}
+
+// NewBot returns a bot client talking to the fake server
+func (f *FakeTelegram) NewBot(t *testing.T, token string) *telegram.Bot {
+	t.Helper()
+
+	bot, err := telegram.New(token, telegram.WithServerURL(f.URL), telegram.WithSkipGetMe())
+	require.NoError(t, err)
+
+	return bot
+}
+
+func (f *FakeTelegram) serve(w http.ResponseWriter, r *http.Request) {
+	// Paths look like /bot<token>/<method>
+	token, method, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/bot"), "/")
+
+	params := make(map[string]string)
+	if r.Header.Get("Content-Type") == "application/json" {
+		var fields map[string]json.RawMessage
+		json.NewDecoder(r.Body).Decode(&fields)
+		for key, value := range fields {
+			params[key] = string(value)
+		}
+	} else if err := r.ParseMultipartForm(1 << 20); err == nil {
+		for key, values := range r.MultipartForm.Value {
+			params[key] = values[0]
+		}
+	}
+
+	f.mu.Lock()
+	f.calls = append(f.calls, TelegramCall{Token: token, Method: method, Params: params})
+
+	var result interface{} = true
+	switch method {
+	case "sendMessage", "sendPhoto":
+		result = models.Message{ID: len(f.calls), Chat: models.Chat{ID: parseInt(params["chat_id"])}}
+	case "getUpdates":
+		result = f.pendingUpdates(token, parseInt(params["offset"]))
+	}
+	f.mu.Unlock()
+
+	// Stands in for the long poll, so an idle client doesn't spin
+	if updates, ok := result.([]models.Update); ok && len(updates) == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": result})
+}
+
+func parseInt(value string) int64 {
+	var n int64
+	json.Unmarshal([]byte(value), &n)
+	return n
+}
+
+// QueueUpdates makes updates available to getUpdates of the bot with the token
+func (f *FakeTelegram) QueueUpdates(token string, updates ...models.Update) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.updates == nil {
+		f.updates = make(map[string][]models.Update)
+	}
+	f.updates[token] = append(f.updates[token], updates...)
+}
+
+// pendingUpdates returns the queued updates Telegram would still deliver at
+// the offset. Callers hold the lock.
+func (f *FakeTelegram) pendingUpdates(token string, offset int64) []models.Update {
+	updates := []models.Update{}
+	for _, update := range f.updates[token] {
+		if update.ID >= offset {
+			updates = append(updates, update)
+		}
+	}
+
+	return updates
+}
+
+// Calls returns the calls of the methods, in order
+func (f *FakeTelegram) Calls(methods ...string) []TelegramCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var calls []TelegramCall
+	for _, call := range f.calls {
+		if slices.Contains(methods, call.Method) {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// Reset forgets the calls made so far
+func (f *FakeTelegram) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = nil
+}